@@ -56,3 +56,63 @@ Gateway API resource name used by Istio-managed gateway deployment/service.
 {{- define "litefunctions.gatewayName" -}}
 litefunctions-gateway
 {{- end }}
+
+{{/*
+Comma separated hosts the gateway routes to the portal, ingestor and gitea,
+worked out like gateway.yaml does. The portal refuses to map them as
+custom domains.
+*/}}
+{{- define "litefunctions.platformHosts" -}}
+{{- $portalHost := .Values.ui.domain | default "litefunctions.portal" }}
+{{- $giteaHost := "gitea.local" }}
+{{- with .Values.gitea }}
+  {{- with .ingress }}
+    {{- if and .hosts (gt (len .hosts) 0) }}
+      {{- $giteaHost = (index .hosts 0).host | default $giteaHost }}
+    {{- end }}
+  {{- end }}
+{{- end }}
+{{- $portalHosts := .Values.gatewayApi.hosts.portal | default (list) }}
+{{- if eq (len $portalHosts) 0 }}
+  {{- $portalHosts = list "litefunctions.portal" $portalHost | uniq }}
+{{- end }}
+{{- $giteaHosts := .Values.gatewayApi.hosts.gitea | default (list) }}
+{{- if eq (len $giteaHosts) 0 }}
+  {{- $giteaHosts = list "litefunctions.gitea" $giteaHost | uniq }}
+{{- end }}
+{{- concat $portalHosts $giteaHosts | uniq | join "," }}
+{{- end }}
+
+{{/*
+TLS secret for a custom function domain. Each domain gets its own certificate
+so one whose DNS isn't pointed at the gateway yet can't block the others.
+*/}}
+{{- define "litefunctions.domainSecretName" -}}
+{{- printf "litefunctions-domain-%s-tls" (. | replace "." "-") | trunc 63 | trimSuffix "-" }}
+{{- end }}
+
+{{/*
+cert-manager Certificate for a custom function domain.
+Expects a dict with root, host, issuer and kind.
+*/}}
+{{- define "litefunctions.domainCertificate" -}}
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: {{ include "litefunctions.domainSecretName" .host }}
+  namespace: {{ .root.Release.Namespace }}
+  annotations:
+    "helm.sh/hook": post-install,post-upgrade
+    "helm.sh/hook-weight": "11"
+    "helm.sh/hook-delete-policy": before-hook-creation
+spec:
+  secretName: {{ include "litefunctions.domainSecretName" .host }}
+  duration: {{ .root.Values.gatewayApi.tls.certManager.duration | default "2160h" | quote }}
+  renewBefore: {{ .root.Values.gatewayApi.tls.certManager.renewBefore | default "360h" | quote }}
+  issuerRef:
+    name: {{ .issuer }}
+    kind: {{ .kind }}
+  dnsNames:
+  - {{ .host | quote }}
+{{- end }}
@@ -0,0 +1,573 @@
+// Package gateway holds the endpoint configuration the portal distributes to
+// ingestors over JetStream KV.
+package gateway
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	EndpointsBucket   = "litefunctions-endpoints"
+	ApiKeysBucket     = "litefunctions-apikeys"
+	CacheBucket       = "litefunctions-response-cache"
+	PayloadsBucket    = "litefunctions-payloads"
+	CallbacksBucket   = "litefunctions-callbacks"
+	MaintenanceBucket = "litefunctions-maintenance"
+	DomainsBucket     = "litefunctions-domains"
+	BatchesBucket     = "litefunctions-batches"
+	SchedulesBucket   = "litefunctions-schedules"
+	QuotasBucket      = "litefunctions-quotas"
+	QuotaUsageBucket  = "litefunctions-quota-usage"
+
+	ApiKeyHeader      = "X-Api-Key"
+	ApiKeyUsedSubject = "litefunctions.apikeys.used"
+)
+
+// PayloadRefHeader replaces the body of a NATS message whose payload was too
+// large to inline. It names the object holding the body in PayloadsBucket.
+const PayloadRefHeader = "X-Litefunction-Payload-Ref"
+
+// DeadlineHeader carries the caller's deadline (RFC 3339) to runtimes so
+// they can stop work nobody is waiting for.
+const DeadlineHeader = "X-Litefunction-Deadline"
+
+// RequestIDHeader carries the id the ingestor gives every request. It is set
+// on responses, problem details and callback deliveries.
+const RequestIDHeader = "X-Litefunction-Request-Id"
+
+// Functions invoke each other by sending a NATS request to
+// InvokeSubject.{project}.{name}, which the ingestors answer. The reply
+// carries the status in InvokeStatusHeader and the response headers and body
+// as the message headers and data.
+const (
+	InvokeSubject      = "litefunctions.invoke"
+	InvokeMethodHeader = "X-Litefunction-Method"
+	InvokeStatusHeader = "X-Litefunction-Status"
+
+	// CallerHeader names the calling function as {project}/{name}, and
+	// CallChainHeader lists every function on the call path, caller last.
+	CallerHeader    = "X-Litefunction-Caller"
+	CallChainHeader = "X-Litefunction-Call-Chain"
+
+	// CallTokenHeader carries the identity signed by the ingestor for the
+	// request a function is serving. Runtimes pass it on with every call
+	// and the ingestor takes the caller's identity from it alone.
+	CallTokenHeader = "X-Litefunction-Call-Token"
+)
+
+// The caller's identity as established by authentication. Inbound copies are
+// stripped first, so later stages and runtimes can rely on them.
+const (
+	ApiKeyIDHeader = "X-Litefunction-Api-Key-Id"
+	SubjectHeader  = "X-Litefunction-Subject"
+	ClaimsHeader   = "X-Litefunction-Claims"
+)
+
+// CallChain splits a CallChainHeader value.
+func CallChain(header string) []string {
+	var chain []string
+	for _, fn := range strings.Split(header, ",") {
+		if fn = strings.TrimSpace(fn); fn != "" {
+			chain = append(chain, fn)
+		}
+	}
+	return chain
+}
+
+// Async invocations are queued in one of three priority lanes so urgent work
+// isn't stuck behind bulk jobs. Callers pick a lane with PriorityHeader,
+// otherwise the endpoint's Priority applies.
+const (
+	PriorityHeader = "X-Async-Priority"
+
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// SyncLane carries requests a client is waiting on, sync calls and streams.
+// Runtimes serve it apart from the async lanes so a queue of async work never
+// holds up a caller.
+const SyncLane = "sync"
+
+// ValidPriority reports whether p names a lane.
+func ValidPriority(p string) bool {
+	return p == PriorityHigh || p == PriorityNormal || p == PriorityLow
+}
+
+const (
+	ScopePublic = "public"
+	ScopeAuthn  = "authn"
+	ScopeJWT    = "jwt"
+)
+
+// Automatic reports whether e is the public GET endpoint the portal
+// provisions for every function, with no policy added since. It says nothing
+// about the methods the function serves.
+func (e *Endpoint) Automatic() bool {
+	return e.Method == "GET" && e.Scope == ScopePublic && e.Policy == nil && e.Name == "/"+e.Project+"/"+e.Function
+}
+
+// EndpointMethods are the methods an endpoint can be published for.
+var EndpointMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// ValidEndpointMethod reports whether an endpoint can be published for
+// method.
+func ValidEndpointMethod(method string) bool {
+	return slices.Contains(EndpointMethods, method)
+}
+
+const (
+	PolicyEnforce = "enforce"
+	PolicyAudit   = "audit"
+
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+type Endpoint struct {
+	ID        string           `json:"id"`
+	Project   string           `json:"project"`
+	Function  string           `json:"function"`
+	Name      string           `json:"name"`
+	Method    string           `json:"method"`
+	Scope     string           `json:"scope"`
+	JWT       *JWTConfig       `json:"jwt,omitempty"`
+	Policy    *Policy          `json:"policy,omitempty"`
+	CORS      *CORSConfig      `json:"cors,omitempty"`
+	Cache     *CacheConfig     `json:"cache,omitempty"`
+	Schema    *SchemaConfig    `json:"schema,omitempty"`
+	Callback  *CallbackConfig  `json:"callback,omitempty"`
+	Fault     *FaultConfig     `json:"fault,omitempty"`
+	Transform *TransformConfig `json:"transform,omitempty"`
+	// Priority is the lane async invocations are queued in, PriorityNormal
+	// when empty.
+	Priority string `json:"priority,omitempty"`
+	// Middleware runs after the ingestor's own checks, in order, for every
+	// invocation mode of the endpoint.
+	Middleware []MiddlewareConfig `json:"middleware,omitempty"`
+}
+
+// JWTConfig describes how bearer tokens are verified for jwt scoped
+// endpoints. RequiredClaims maps a claim to the value it must hold, an empty
+// value only requires the claim to be present.
+type JWTConfig struct {
+	JwksURL        string            `json:"jwks_url"`
+	Issuer         string            `json:"issuer,omitempty"`
+	Audiences      []string          `json:"audiences,omitempty"`
+	RequiredClaims map[string]string `json:"required_claims,omitempty"`
+}
+
+// Policy is an ordered list of authorization rules. The first rule whose
+// expression matches decides the request, otherwise Default applies. In
+// audit mode decisions are only logged.
+type Policy struct {
+	Mode    string       `json:"mode"`
+	Default string       `json:"default"`
+	Rules   []PolicyRule `json:"rules"`
+}
+
+type PolicyRule struct {
+	Name   string `json:"name,omitempty"`
+	Effect string `json:"effect"`
+	Expr   string `json:"expr"`
+}
+
+// CORSConfig is applied by the ingestor to responses for browser callers and
+// used to answer preflight requests. Origins may be "*" or use a leading
+// wildcard label such as "https://*.example.com". AllowedMethods defaults to
+// the endpoint's method.
+type CORSConfig struct {
+	AllowedOrigins   []string `json:"allowed_origins"`
+	AllowedMethods   []string `json:"allowed_methods,omitempty"`
+	AllowedHeaders   []string `json:"allowed_headers,omitempty"`
+	ExposedHeaders   []string `json:"exposed_headers,omitempty"`
+	AllowCredentials bool     `json:"allow_credentials,omitempty"`
+	MaxAge           int      `json:"max_age,omitempty"`
+}
+
+// AllowsOrigin reports whether origin matches one of the allowed origins.
+func (c *CORSConfig) AllowsOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		scheme, host, ok := strings.Cut(allowed, "://*.")
+		if ok && strings.HasPrefix(origin, scheme+"://") && strings.HasSuffix(origin, "."+host) {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheConfig enables the ingestor response cache for a GET endpoint. TTL is
+// an upper bound, a shorter max-age from the runtime wins. The full query
+// string is part of the cache key unless VaryQuery lists the parameters that
+// matter.
+type CacheConfig struct {
+	TTL         int      `json:"ttl"`
+	VaryHeaders []string `json:"vary_headers,omitempty"`
+	VaryQuery   []string `json:"vary_query,omitempty"`
+}
+
+// SchemaConfig holds JSON Schemas the ingestor validates requests against.
+// Query parameters are checked as an object of strings, or arrays of strings
+// for repeated parameters.
+type SchemaConfig struct {
+	Body  json.RawMessage `json:"body,omitempty"`
+	Query json.RawMessage `json:"query,omitempty"`
+}
+
+// CallbackConfig has the ingestor POST the result of async invocations to URL,
+// signed with Secret. With AllowCallerURL callers may name their own https
+// target in the X-Callback-Url header instead.
+type CallbackConfig struct {
+	URL            string `json:"url,omitempty"`
+	Secret         string `json:"secret"`
+	AllowCallerURL bool   `json:"allow_caller_url,omitempty"`
+}
+
+// FaultConfig injects failures into a share of an endpoint's requests for
+// resilience testing. With Header set only requests carrying that header are
+// candidates. The ingestor ignores it once ExpiresAt has passed.
+type FaultConfig struct {
+	Percentage  float64   `json:"percentage"`
+	DelayMs     int       `json:"delay_ms,omitempty"`
+	ErrorStatus int       `json:"error_status,omitempty"`
+	DropAsync   bool      `json:"drop_async,omitempty"`
+	Header      string    `json:"header,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Active reports whether the fault applies to requests at now.
+func (f *FaultConfig) Active(now time.Time) bool {
+	return f.Percentage > 0 && now.Before(f.ExpiresAt)
+}
+
+// TransformConfig rewrites requests before they reach the runtime and
+// filters the headers of its responses. Header values and Path may use
+// ${project}, ${function}, ${method}, ${header.Name}, ${query.name} and
+// ${secret.name}; secrets are read from files mounted into the ingestor, so
+// their values never pass through the portal. Runtimes reached over NATS
+// only see X-Litefunction-* headers and no path, so only body rules reach
+// them.
+type TransformConfig struct {
+	Request  *RequestTransform  `json:"request,omitempty"`
+	Response *ResponseTransform `json:"response,omitempty"`
+}
+
+// RequestTransform steps run in field order: renames, removals, set
+// headers, the runtime path and finally query parameters copied into the
+// JSON body under the mapped field name.
+type RequestTransform struct {
+	RenameHeaders map[string]string `json:"rename_headers,omitempty"`
+	RemoveHeaders []string          `json:"remove_headers,omitempty"`
+	SetHeaders    map[string]string `json:"set_headers,omitempty"`
+	Path          string            `json:"path,omitempty"`
+	QueryToBody   map[string]string `json:"query_to_body,omitempty"`
+}
+
+// ResponseTransform drops RemoveHeaders and, when AllowHeaders is set, every
+// runtime header not listed before applying SetHeaders.
+type ResponseTransform struct {
+	RemoveHeaders []string          `json:"remove_headers,omitempty"`
+	AllowHeaders  []string          `json:"allow_headers,omitempty"`
+	SetHeaders    map[string]string `json:"set_headers,omitempty"`
+}
+
+// MiddlewareConfig enables a middleware compiled into the ingestor by name.
+// Config is handed to the middleware as is.
+type MiddlewareConfig struct {
+	Name   string            `json:"name"`
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// CallbackDelivery records the attempts made to deliver one async result.
+type CallbackDelivery struct {
+	RequestID string            `json:"request_id"`
+	URL       string            `json:"url"`
+	Status    string            `json:"status"`
+	Attempts  []CallbackAttempt `json:"attempts,omitempty"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+type CallbackAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+	DeliveryExpired   = "expired"
+)
+
+// CallbackKeyPrefix scopes delivery records to a function.
+func CallbackKeyPrefix(project, function string) string {
+	return fmt.Sprintf("%s.%s.", project, function)
+}
+
+// Ingestors publish an Invocation for every request they answer to
+// InvocationsSubject.{project}.{function}. The portal captures them in
+// InvocationsStream and keeps them for the runs view.
+const (
+	InvocationsStream  = "litefunctions-invocations"
+	InvocationsSubject = "litefunctions.invocations"
+)
+
+type Invocation struct {
+	RequestID     string    `json:"request_id"`
+	Project       string    `json:"project"`
+	Function      string    `json:"function"`
+	Endpoint      string    `json:"endpoint"`
+	Method        string    `json:"method"`
+	Source        string    `json:"source"`
+	Status        int       `json:"status"`
+	LatencyMs     int64     `json:"latency_ms"`
+	ColdStart     bool      `json:"cold_start"`
+	Async         bool      `json:"async"`
+	RequestBytes  int64     `json:"request_bytes"`
+	ResponseBytes int64     `json:"response_bytes"`
+	Error         string    `json:"error,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+}
+
+const (
+	SourceHTTP     = "http"
+	SourceGRPC     = "grpc"
+	SourceInternal = "internal"
+	SourceSchedule = "schedule"
+)
+
+// Batch summarises a fan-out over many inputs. Items are stored under their
+// own keys so a large batch never exceeds the KV value limit.
+type Batch struct {
+	ID          string    `json:"id"`
+	Project     string    `json:"project"`
+	Function    string    `json:"function"`
+	Status      string    `json:"status"`
+	Total       int       `json:"total"`
+	Parallelism int       `json:"parallelism"`
+	Priority    string    `json:"priority,omitempty"`
+	Submitted   int       `json:"submitted"`
+	Succeeded   int       `json:"succeeded"`
+	Failed      int       `json:"failed"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type BatchItem struct {
+	Index     int             `json:"index"`
+	RequestID string          `json:"request_id,omitempty"`
+	Status    string          `json:"status"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+const (
+	BatchRunning   = "running"
+	BatchCompleted = "completed"
+
+	ItemPending   = "pending"
+	ItemRunning   = "running"
+	ItemSucceeded = "succeeded"
+	ItemFailed    = "failed"
+)
+
+// BatchKey and BatchItemKey scope batch records to a function.
+func BatchKey(project, function, id string) string {
+	return fmt.Sprintf("%s.%s.%s", project, function, id)
+}
+
+func BatchItemKey(project, function, id string, index int) string {
+	return fmt.Sprintf("%s.%s.%s.items.%d", project, function, id, index)
+}
+
+// A request carrying DelayHeader (a duration or a number of seconds) or
+// RunAtHeader (RFC 3339) is stored and invoked once it is due instead of
+// right away.
+const (
+	DelayHeader = "X-Litefunction-Delay"
+	RunAtHeader = "X-Litefunction-Run-At"
+)
+
+const (
+	ScheduleScheduled   = "scheduled"
+	ScheduleDispatching = "dispatching"
+	ScheduleDispatched  = "dispatched"
+	ScheduleCancelled   = "cancelled"
+	ScheduleFailed      = "failed"
+)
+
+// Schedule is a request held back until RunAt. It keeps everything needed to
+// replay the request, identity headers included, since it was authorized when
+// it was scheduled.
+type Schedule struct {
+	ID        string              `json:"id"`
+	Project   string              `json:"project"`
+	Function  string              `json:"function"`
+	Method    string              `json:"method"`
+	Path      string              `json:"path"`
+	RawQuery  string              `json:"raw_query,omitempty"`
+	Header    map[string][]string `json:"header,omitempty"`
+	Body      []byte              `json:"body,omitempty"`
+	RunAt     time.Time           `json:"run_at"`
+	Status    string              `json:"status"`
+	ClaimedAt time.Time           `json:"claimed_at"`
+	// Owner is the credential that scheduled the invocation, "key:{id}" or
+	// "sub:{subject}", empty on public endpoints. Only it can read or cancel
+	// the entry.
+	Owner string `json:"owner,omitempty"`
+	// RequestID and ResponseStatus describe the dispatched invocation.
+	RequestID      string    `json:"request_id,omitempty"`
+	ResponseStatus int       `json:"response_status,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func ScheduleKey(project, function, id string) string {
+	return fmt.Sprintf("%s.%s.%s", project, function, id)
+}
+
+// CacheKeyPrefix scopes cached responses to a function so they can be purged
+// together.
+func CacheKeyPrefix(project, function string) string {
+	return fmt.Sprintf("%s.%s.", project, function)
+}
+
+// Maintenance takes a function, or a whole project when Function is empty,
+// out of service. The ingestor answers 503 with Message without activating
+// anything.
+type Maintenance struct {
+	Project  string    `json:"project"`
+	Function string    `json:"function,omitempty"`
+	Message  string    `json:"message,omitempty"`
+	Since    time.Time `json:"since"`
+}
+
+func MaintenanceKey(project, function string) string {
+	if function == "" {
+		return project
+	}
+	return fmt.Sprintf("%s.%s", project, function)
+}
+
+// Domain maps a custom hostname onto a project. Requests for Host are served
+// as if they were sent to /lambda/{Project}, after BasePath is stripped. It
+// is keyed by Host in DomainsBucket.
+type Domain struct {
+	Host     string `json:"host"`
+	Project  string `json:"project"`
+	BasePath string `json:"base_path,omitempty"`
+}
+
+type ApiKey struct {
+	ID        string     `json:"id"`
+	Project   string     `json:"project"`
+	Endpoints []string   `json:"endpoints,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type ApiKeyUsage struct {
+	ID     string    `json:"id"`
+	UsedAt time.Time `json:"used_at"`
+}
+
+func EndpointKey(project, function, method string) string {
+	return fmt.Sprintf("%s.%s.%s", project, function, strings.ToUpper(method))
+}
+
+// HashApiKey is the only form of an api key that is persisted or shared.
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Allows reports whether the key may call the endpoint. An empty allowlist
+// grants every endpoint in the key's project.
+func (k *ApiKey) Allows(ep *Endpoint) bool {
+	if k.Project != ep.Project {
+		return false
+	}
+	if len(k.Endpoints) == 0 {
+		return true
+	}
+	for _, id := range k.Endpoints {
+		if id == ep.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *ApiKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && now.After(*k.ExpiresAt)
+}
+
+// Quota caps the invocations a project, or one of its api keys when ApiKeyID
+// is set, may make per UTC day and month. Zero leaves a period unlimited. It
+// is keyed by QuotaKey in QuotasBucket.
+type Quota struct {
+	Project  string `json:"project"`
+	ApiKeyID string `json:"api_key_id,omitempty"`
+	Daily    int64  `json:"daily,omitempty"`
+	Monthly  int64  `json:"monthly,omitempty"`
+}
+
+// Ingestors report the tightest quota applying to a request in these
+// headers. QuotaResetHeader is the number of seconds until it resets.
+const (
+	QuotaLimitHeader     = "X-Quota-Limit"
+	QuotaRemainingHeader = "X-Quota-Remaining"
+	QuotaResetHeader     = "X-Quota-Reset"
+)
+
+const (
+	QuotaDaily   = "day"
+	QuotaMonthly = "month"
+)
+
+var QuotaPeriods = []string{QuotaDaily, QuotaMonthly}
+
+func QuotaKey(project, apiKeyID string) string {
+	if apiKeyID == "" {
+		return project
+	}
+	return fmt.Sprintf("%s.apikey.%s", project, apiKeyID)
+}
+
+// Limit is the quota for period, zero when it is unlimited.
+func (q *Quota) Limit(period string) int64 {
+	if period == QuotaDaily {
+		return q.Daily
+	}
+	return q.Monthly
+}
+
+// QuotaWindow returns the id of the period containing now and when it ends.
+func QuotaWindow(period string, now time.Time) (string, time.Time) {
+	now = now.UTC()
+	if period == QuotaDaily {
+		start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		return start.Format("20060102"), start.AddDate(0, 0, 1)
+	}
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start.Format("200601"), start.AddDate(0, 1, 0)
+}
+
+// QuotaUsageKey names the counter in QuotaUsageBucket holding the
+// invocations made under quotaKey in the period containing now.
+func QuotaUsageKey(quotaKey, period string, now time.Time) string {
+	window, _ := QuotaWindow(period, now)
+	return fmt.Sprintf("%s.%s.%s", quotaKey, period, window)
+}
@@ -0,0 +1,178 @@
+// Package policy compiles and evaluates endpoint authorization rules written
+// in CEL. The portal uses it to validate rules when they are saved and the
+// ingestor to evaluate them before a function is activated.
+//
+// Rules see the following variables:
+//
+//	request.method, request.path, request.ip  string
+//	request.headers, request.query            map(string, string), lower-cased header names
+//	subject, api_key_id                       string, empty when not authenticated
+//	claims                                    map(string, dyn), verified jwt claims
+//
+// and the inCidr(ip, cidr) function.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+)
+
+type Input struct {
+	Method   string
+	Path     string
+	IP       string
+	Headers  map[string]string
+	Query    map[string]string
+	Subject  string
+	ApiKeyID string
+	Claims   map[string]any
+}
+
+type Decision struct {
+	Allow bool
+	// Rule names the rule that decided, "default" when none matched.
+	Rule string
+	// Err is set when a deny rule failed to evaluate, e.g. a missing header
+	// or claim. Such requests are denied; allow rules that fail to evaluate
+	// simply do not match.
+	Err error
+}
+
+// Engine caches compiled programs by expression, so endpoints sharing a rule
+// and repeated evaluations only pay for compilation once.
+type Engine struct {
+	env      *cel.Env
+	mu       sync.RWMutex
+	programs map[string]cel.Program
+}
+
+func NewEngine() (*Engine, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("request", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("subject", cel.StringType),
+		cel.Variable("api_key_id", cel.StringType),
+		cel.Variable("claims", cel.MapType(cel.StringType, cel.DynType)),
+		ext.Strings(),
+		cel.Function("inCidr",
+			cel.Overload("in_cidr_string_string",
+				[]*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(inCidr),
+			),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating policy environment: %w", err)
+	}
+	return &Engine{env: env, programs: map[string]cel.Program{}}, nil
+}
+
+// Validate checks a policy is well formed and every rule compiles to a
+// boolean expression.
+func (e *Engine) Validate(p *gateway.Policy) error {
+	if p.Mode != gateway.PolicyEnforce && p.Mode != gateway.PolicyAudit {
+		return fmt.Errorf("unknown policy mode %q", p.Mode)
+	}
+	if p.Default != gateway.EffectAllow && p.Default != gateway.EffectDeny {
+		return fmt.Errorf("unknown default effect %q", p.Default)
+	}
+	for i, rule := range p.Rules {
+		if rule.Effect != gateway.EffectAllow && rule.Effect != gateway.EffectDeny {
+			return fmt.Errorf("rule %d: unknown effect %q", i+1, rule.Effect)
+		}
+		if _, err := e.program(rule.Expr); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (e *Engine) Evaluate(p *gateway.Policy, in Input) Decision {
+	vars := map[string]any{
+		"request": map[string]any{
+			"method":  in.Method,
+			"path":    in.Path,
+			"ip":      in.IP,
+			"headers": in.Headers,
+			"query":   in.Query,
+		},
+		"subject":    in.Subject,
+		"api_key_id": in.ApiKeyID,
+		"claims":     in.Claims,
+	}
+	if vars["claims"] == nil {
+		vars["claims"] = map[string]any{}
+	}
+
+	for i, rule := range p.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("rule %d", i+1)
+		}
+		prg, err := e.program(rule.Expr)
+		if err != nil {
+			return Decision{Rule: name, Err: err}
+		}
+		out, _, err := prg.Eval(vars)
+		if err == nil {
+			if _, ok := out.Value().(bool); !ok {
+				err = errors.New("rule did not evaluate to a bool")
+			}
+		}
+		if err != nil {
+			if rule.Effect == gateway.EffectDeny {
+				return Decision{Rule: name, Err: err}
+			}
+			continue
+		}
+		if out.Value().(bool) {
+			return Decision{Allow: rule.Effect == gateway.EffectAllow, Rule: name}
+		}
+	}
+	return Decision{Allow: p.Default == gateway.EffectAllow, Rule: "default"}
+}
+
+func (e *Engine) program(expr string) (cel.Program, error) {
+	e.mu.RLock()
+	prg, ok := e.programs[expr]
+	e.mu.RUnlock()
+	if ok {
+		return prg, nil
+	}
+
+	ast, iss := e.env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if t := ast.OutputType(); t != cel.BoolType && t != cel.DynType {
+		return nil, fmt.Errorf("expression must return a bool, got %s", ast.OutputType())
+	}
+	prg, err := e.env.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.programs[expr] = prg
+	e.mu.Unlock()
+	return prg, nil
+}
+
+func inCidr(ip, cidr ref.Val) ref.Val {
+	addr, err := netip.ParseAddr(fmt.Sprint(ip.Value()))
+	if err != nil {
+		return types.False
+	}
+	prefix, err := netip.ParsePrefix(fmt.Sprint(cidr.Value()))
+	if err != nil {
+		return types.NewErr("invalid cidr %q", cidr.Value())
+	}
+	return types.Bool(prefix.Contains(addr.Unmap()))
+}
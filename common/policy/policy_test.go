@@ -0,0 +1,128 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+)
+
+func TestEvaluate(t *testing.T) {
+	engine, err := NewEngine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	allow := func(name, expr string) gateway.PolicyRule {
+		return gateway.PolicyRule{Name: name, Effect: gateway.EffectAllow, Expr: expr}
+	}
+	deny := func(name, expr string) gateway.PolicyRule {
+		return gateway.PolicyRule{Name: name, Effect: gateway.EffectDeny, Expr: expr}
+	}
+	in := Input{
+		Method:  "POST",
+		Path:    "/lambda/shop/orders",
+		IP:      "10.1.2.3",
+		Headers: map[string]string{"x-tenant": "acme"},
+		Query:   map[string]string{},
+		Subject: "user-1",
+		Claims:  map[string]any{"roles": []any{"admin"}},
+	}
+
+	cases := []struct {
+		name  string
+		rules []gateway.PolicyRule
+		def   string
+		allow bool
+		rule  string
+		err   bool
+	}{
+		{"no rules falls back to deny", nil, gateway.EffectDeny, false, "default", false},
+		{"no rules falls back to allow", nil, gateway.EffectAllow, true, "default", false},
+		{"matching allow", []gateway.PolicyRule{allow("admins", `"admin" in claims.roles`)}, gateway.EffectDeny, true, "admins", false},
+		{"matching deny", []gateway.PolicyRule{deny("office", `inCidr(request.ip, "10.0.0.0/8")`)}, gateway.EffectAllow, false, "office", false},
+		{"first match wins over a later allow", []gateway.PolicyRule{
+			deny("tenant", `request.headers["x-tenant"] == "acme"`),
+			allow("admins", `"admin" in claims.roles`),
+		}, gateway.EffectAllow, false, "tenant", false},
+		{"first match wins over a later deny", []gateway.PolicyRule{
+			allow("admins", `"admin" in claims.roles`),
+			deny("tenant", `request.headers["x-tenant"] == "acme"`),
+		}, gateway.EffectDeny, true, "admins", false},
+		{"non matching rules fall through", []gateway.PolicyRule{
+			deny("get only", `request.method == "GET"`),
+			allow("other subject", `subject == "user-2"`),
+		}, gateway.EffectAllow, true, "default", false},
+		{"deny rule that errors denies", []gateway.PolicyRule{
+			deny("missing header", `request.headers["x-missing"] == "x"`),
+		}, gateway.EffectAllow, false, "missing header", true},
+		{"allow rule that errors is skipped", []gateway.PolicyRule{
+			allow("missing claim", `claims.scope == "write"`),
+		}, gateway.EffectDeny, false, "default", false},
+		{"allow rule that errors doesn't hide a later deny", []gateway.PolicyRule{
+			allow("missing claim", `claims.scope == "write"`),
+			deny("office", `inCidr(request.ip, "10.0.0.0/8")`),
+		}, gateway.EffectAllow, false, "office", false},
+		{"non boolean result errors", []gateway.PolicyRule{
+			deny("not a bool", `request.headers["x-tenant"]`),
+		}, gateway.EffectAllow, false, "not a bool", true},
+		{"unnamed rules are numbered", []gateway.PolicyRule{
+			{Effect: gateway.EffectAllow, Expr: "false"},
+			{Effect: gateway.EffectAllow, Expr: "true"},
+		}, gateway.EffectDeny, true, "rule 2", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := engine.Evaluate(&gateway.Policy{Mode: gateway.PolicyEnforce, Default: tc.def, Rules: tc.rules}, in)
+			if d.Allow != tc.allow || d.Rule != tc.rule || (d.Err != nil) != tc.err {
+				t.Errorf("decision = %+v, want allow %v by %q, error %v", d, tc.allow, tc.rule, tc.err)
+			}
+		})
+	}
+}
+
+func TestInCidr(t *testing.T) {
+	engine, err := NewEngine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		ip    string
+		allow bool
+	}{
+		{"192.168.1.20", true},
+		{"::ffff:192.168.1.20", true},
+		{"192.168.2.20", false},
+		{"not an ip", false},
+	}
+	p := &gateway.Policy{Mode: gateway.PolicyEnforce, Default: gateway.EffectDeny, Rules: []gateway.PolicyRule{
+		{Effect: gateway.EffectAllow, Expr: `inCidr(request.ip, "192.168.1.0/24")`},
+	}}
+	for _, tc := range cases {
+		if d := engine.Evaluate(p, Input{IP: tc.ip}); d.Allow != tc.allow {
+			t.Errorf("%s: allow = %v, want %v", tc.ip, d.Allow, tc.allow)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	engine, err := NewEngine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name   string
+		policy gateway.Policy
+		ok     bool
+	}{
+		{"valid", gateway.Policy{Mode: gateway.PolicyAudit, Default: gateway.EffectDeny, Rules: []gateway.PolicyRule{{Effect: gateway.EffectAllow, Expr: `subject != ""`}}}, true},
+		{"unknown mode", gateway.Policy{Mode: "log", Default: gateway.EffectDeny}, false},
+		{"unknown default", gateway.Policy{Mode: gateway.PolicyEnforce, Default: "maybe"}, false},
+		{"unknown effect", gateway.Policy{Mode: gateway.PolicyEnforce, Default: gateway.EffectDeny, Rules: []gateway.PolicyRule{{Effect: "log", Expr: "true"}}}, false},
+		{"syntax error", gateway.Policy{Mode: gateway.PolicyEnforce, Default: gateway.EffectDeny, Rules: []gateway.PolicyRule{{Effect: gateway.EffectAllow, Expr: "subject =="}}}, false},
+		{"not a bool", gateway.Policy{Mode: gateway.PolicyEnforce, Default: gateway.EffectDeny, Rules: []gateway.PolicyRule{{Effect: gateway.EffectAllow, Expr: "subject"}}}, false},
+	}
+	for _, tc := range cases {
+		if err := engine.Validate(&tc.policy); (err == nil) != tc.ok {
+			t.Errorf("%s: err = %v", tc.name, err)
+		}
+	}
+}
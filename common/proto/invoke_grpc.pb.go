@@ -0,0 +1,166 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.0
+// - protoc             v6.30.2
+// source: invoke.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	InvokeService_Invoke_FullMethodName       = "/server.InvokeService/Invoke"
+	InvokeService_InvokeStream_FullMethodName = "/server.InvokeService/InvokeStream"
+)
+
+// InvokeServiceClient is the client API for InvokeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// InvokeService is served by the ingestor for callers that speak gRPC. Calls
+// go through the same checks and dispatch as /lambda requests; metadata is
+// passed on as request headers and the call deadline bounds the function.
+type InvokeServiceClient interface {
+	Invoke(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (*InvokeResponse, error)
+	// InvokeStream maps to the websocket path: every request message is
+	// delivered to the function and every result is streamed back.
+	InvokeStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamRequest, StreamResponse], error)
+}
+
+type invokeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInvokeServiceClient(cc grpc.ClientConnInterface) InvokeServiceClient {
+	return &invokeServiceClient{cc}
+}
+
+func (c *invokeServiceClient) Invoke(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (*InvokeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InvokeResponse)
+	err := c.cc.Invoke(ctx, InvokeService_Invoke_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokeServiceClient) InvokeStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamRequest, StreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &InvokeService_ServiceDesc.Streams[0], InvokeService_InvokeStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamRequest, StreamResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type InvokeService_InvokeStreamClient = grpc.BidiStreamingClient[StreamRequest, StreamResponse]
+
+// InvokeServiceServer is the server API for InvokeService service.
+// All implementations must embed UnimplementedInvokeServiceServer
+// for forward compatibility.
+//
+// InvokeService is served by the ingestor for callers that speak gRPC. Calls
+// go through the same checks and dispatch as /lambda requests; metadata is
+// passed on as request headers and the call deadline bounds the function.
+type InvokeServiceServer interface {
+	Invoke(context.Context, *InvokeRequest) (*InvokeResponse, error)
+	// InvokeStream maps to the websocket path: every request message is
+	// delivered to the function and every result is streamed back.
+	InvokeStream(grpc.BidiStreamingServer[StreamRequest, StreamResponse]) error
+	mustEmbedUnimplementedInvokeServiceServer()
+}
+
+// UnimplementedInvokeServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedInvokeServiceServer struct{}
+
+func (UnimplementedInvokeServiceServer) Invoke(context.Context, *InvokeRequest) (*InvokeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Invoke not implemented")
+}
+func (UnimplementedInvokeServiceServer) InvokeStream(grpc.BidiStreamingServer[StreamRequest, StreamResponse]) error {
+	return status.Error(codes.Unimplemented, "method InvokeStream not implemented")
+}
+func (UnimplementedInvokeServiceServer) mustEmbedUnimplementedInvokeServiceServer() {}
+func (UnimplementedInvokeServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeInvokeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InvokeServiceServer will
+// result in compilation errors.
+type UnsafeInvokeServiceServer interface {
+	mustEmbedUnimplementedInvokeServiceServer()
+}
+
+func RegisterInvokeServiceServer(s grpc.ServiceRegistrar, srv InvokeServiceServer) {
+	// If the following call panics, it indicates UnimplementedInvokeServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&InvokeService_ServiceDesc, srv)
+}
+
+func _InvokeService_Invoke_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvokeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvokeServiceServer).Invoke(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvokeService_Invoke_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvokeServiceServer).Invoke(ctx, req.(*InvokeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvokeService_InvokeStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(InvokeServiceServer).InvokeStream(&grpc.GenericServerStream[StreamRequest, StreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type InvokeService_InvokeStreamServer = grpc.BidiStreamingServer[StreamRequest, StreamResponse]
+
+// InvokeService_ServiceDesc is the grpc.ServiceDesc for InvokeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InvokeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "server.InvokeService",
+	HandlerType: (*InvokeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Invoke",
+			Handler:    _InvokeService_Invoke_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "InvokeStream",
+			Handler:       _InvokeService_InvokeStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "invoke.proto",
+}
@@ -0,0 +1,122 @@
+// Package schema validates request bodies and query parameters against the
+// JSON Schemas attached to endpoints. The portal compiles schemas when they
+// are saved and the ingestor before a function is invoked.
+package schema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+const (
+	LocationBody  = "body"
+	LocationQuery = "query"
+)
+
+// Violation is one reason a request was rejected. Path is a JSON pointer into
+// the offending document.
+type Violation struct {
+	Location string `json:"location"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+}
+
+type Validator struct {
+	body  *jsonschema.Schema
+	query *jsonschema.Schema
+}
+
+// Compile builds a validator for the configured schemas. Remote $refs are not
+// resolved, schemas must be self contained.
+func Compile(cfg *gateway.SchemaConfig) (*Validator, error) {
+	body, err := compile(LocationBody, cfg.Body)
+	if err != nil {
+		return nil, err
+	}
+	query, err := compile(LocationQuery, cfg.Query)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{body: body, query: query}, nil
+}
+
+func compile(name string, raw []byte) (*jsonschema.Schema, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%s schema is not valid json: %w", name, err)
+	}
+	c := jsonschema.NewCompiler()
+	c.UseLoader(jsonschema.SchemeURLLoader{})
+	loc := fmt.Sprintf("mem:///%s.json", name)
+	if err := c.AddResource(loc, doc); err != nil {
+		return nil, fmt.Errorf("invalid %s schema: %w", name, err)
+	}
+	sch, err := c.Compile(loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s schema: %w", name, err)
+	}
+	return sch, nil
+}
+
+func (v *Validator) HasBody() bool {
+	return v.body != nil
+}
+
+func (v *Validator) ValidateBody(data []byte) []Violation {
+	if v.body == nil {
+		return nil
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return []Violation{{Location: LocationBody, Path: "", Message: "body is not valid json"}}
+	}
+	return violations(LocationBody, v.body.Validate(doc))
+}
+
+func (v *Validator) ValidateQuery(values url.Values) []Violation {
+	if v.query == nil {
+		return nil
+	}
+	doc := make(map[string]any, len(values))
+	for k, vals := range values {
+		if len(vals) == 1 {
+			doc[k] = vals[0]
+			continue
+		}
+		items := make([]any, len(vals))
+		for i, val := range vals {
+			items[i] = val
+		}
+		doc[k] = items
+	}
+	return violations(LocationQuery, v.query.Validate(doc))
+}
+
+func violations(location string, err error) []Violation {
+	if err == nil {
+		return nil
+	}
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return []Violation{{Location: location, Message: err.Error()}}
+	}
+	var out []Violation
+	for _, unit := range verr.BasicOutput().Errors {
+		if unit.Error == nil || len(unit.Errors) > 0 {
+			continue
+		}
+		out = append(out, Violation{Location: location, Path: unit.InstanceLocation, Message: unit.Error.String()})
+	}
+	if len(out) == 0 {
+		out = append(out, Violation{Location: location, Message: verr.Error()})
+	}
+	return out
+}
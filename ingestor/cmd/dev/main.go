@@ -0,0 +1,121 @@
+// Command dev runs the ingestor locally without Kubernetes. It embeds NATS,
+// answers activations itself and runs the Go functions in ./functions/go,
+// rebuilding them when they change. From the ingestor module:
+//
+//	go run ./cmd/dev -project proj -functions ~/src/proj/functions
+//	curl localhost:3000/lambda/proj/fn
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/proto"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/dev"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/server"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	project := flag.String("project", "local", "project the functions are served under")
+	functions := flag.String("functions", "functions", "directory holding go/<name>.go functions")
+	runtime := flag.String("runtime", "../runtimes/go", "Go runtime source the functions are built into")
+	port := flag.Int("port", 3000, "ingestor http port")
+	natsPort := flag.Int("nats-port", 4222, "embedded nats port")
+	basePort := flag.Int("function-port", 8081, "http port of the first function")
+	async := flag.String("async", "", "comma separated functions to treat as async")
+	poll := flag.Duration("poll", 500*time.Millisecond, "how often sources are checked for changes")
+	flag.Parse()
+
+	if err := run(*project, *functions, *runtime, *port, *natsPort, *basePort, *async, *poll); err != nil {
+		slog.Error("dev emulator stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(project, functions, runtime string, port, natsPort, basePort int, async string, poll time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dataDir, err := os.MkdirTemp("", "litefunctions-dev-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dataDir)
+
+	ns, err := dev.StartNATS(natsPort, dataDir+"/jetstream")
+	if err != nil {
+		return err
+	}
+	defer ns.Shutdown()
+
+	asyncFns := map[string]bool{}
+	for _, name := range strings.Split(async, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			asyncFns[name] = true
+		}
+	}
+	runner := dev.NewRunner(dev.Options{
+		Project:      project,
+		FunctionsDir: functions,
+		RuntimeDir:   runtime,
+		BuildDir:     dataDir + "/build",
+		NatsURL:      ns.ClientURL(),
+		BasePort:     basePort,
+		Async:        asyncFns,
+		PollInterval: poll,
+	})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		runner.Run(ctx)
+	}()
+	defer func() { <-stopped }()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	activator := grpc.NewServer()
+	proto.RegisterFunctionServiceServer(activator, dev.NewActivator(runner))
+	go activator.Serve(lis)
+	defer activator.Stop()
+
+	pkg.LoadSettings()
+	pkg.Settings.ListenPort = port
+	pkg.Settings.NatsUrl = ns.ClientURL()
+	pkg.Settings.OperatorUrl = lis.Addr().String()
+	pkg.Settings.RuntimeHost = "127.0.0.1"
+	pkg.Settings.TLSCertFile, pkg.Settings.TLSKeyFile = "", ""
+
+	nc, err := nats.Connect(pkg.Settings.NatsUrl)
+	if err != nil {
+		return fmt.Errorf("error connecting to embedded nats: %w", err)
+	}
+	defer nc.Close()
+	s, err := server.NewServer(nc)
+	if err != nil {
+		return err
+	}
+	errs := make(chan error, 1)
+	go func() { errs <- s.Start() }()
+	slog.Info("dev emulator ready", "url", fmt.Sprintf("http://localhost:%d/lambda/%s/<function>", port, project), "functions", functions)
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errs:
+		stop()
+		return err
+	}
+}
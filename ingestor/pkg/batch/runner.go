@@ -0,0 +1,235 @@
+// Package batch fans one function out over many inputs and records per-item
+// progress.
+package batch
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+var ErrNotFound = errors.New("batch not found")
+
+// Options tune execution. At most Parallelism items of a batch run at once,
+// callers may ask for up to MaxParallelism. Results above MaxResult bytes are
+// dropped and the item marked truncated.
+type Options struct {
+	Parallelism    int
+	MaxParallelism int
+	ItemTimeout    time.Duration
+	MaxResult      int
+	RecordTTL      time.Duration
+}
+
+// Submit sends item index to the function and returns its request id and a
+// wait for the result. An error fails the item without submitting it.
+type Submit func(ctx context.Context, index int) (string, func(time.Duration) ([]byte, error), error)
+
+// Runner records batches in a KV bucket shared by the ingestor replicas, so
+// any of them can report status. Items run in the replica that accepted the
+// batch, a restart abandons the ones not yet finished.
+type Runner struct {
+	kv     jetstream.KeyValue
+	opts   Options
+	logger *slog.Logger
+}
+
+func NewRunner(ctx context.Context, js jetstream.JetStream, opts Options) (*Runner, error) {
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      gateway.BatchesBucket,
+		Description: "litefunctions batch progress and results",
+		TTL:         opts.RecordTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating batches bucket: %w", err)
+	}
+	return &Runner{kv: kv, opts: opts, logger: slog.Default()}, nil
+}
+
+func NewID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Parallelism clamps a requested parallelism, 0 meaning the default.
+func (r *Runner) Parallelism(requested int) int {
+	if requested <= 0 {
+		return max(r.opts.Parallelism, 1)
+	}
+	return min(requested, max(r.opts.MaxParallelism, 1))
+}
+
+// Start records the batch and runs its items in the background.
+func (r *Runner) Start(ctx context.Context, b *gateway.Batch, submit Submit) error {
+	b.Status = gateway.BatchRunning
+	b.CreatedAt = time.Now().UTC()
+	if err := r.put(ctx, gateway.BatchKey(b.Project, b.Function, b.ID), b, &b.UpdatedAt); err != nil {
+		return err
+	}
+	go r.run(b, submit)
+	return nil
+}
+
+func (r *Runner) run(b *gateway.Batch, submit Submit) {
+	key := gateway.BatchKey(b.Project, b.Function, b.ID)
+	var mu sync.Mutex
+	snapshot := func() gateway.Batch {
+		mu.Lock()
+		defer mu.Unlock()
+		return *b
+	}
+
+	// workers only update the counters, a single writer records the latest
+	// progress so that a slow bucket doesn't hold them up
+	dirty := make(chan struct{}, 1)
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		for range dirty {
+			progress := snapshot()
+			r.record(key, &progress, &progress.UpdatedAt)
+		}
+	}()
+	progress := func(update func()) {
+		mu.Lock()
+		update()
+		mu.Unlock()
+		select {
+		case dirty <- struct{}{}:
+		default:
+		}
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range b.Parallelism {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				item := r.runItem(b, i, submit, func() { progress(func() { b.Submitted++ }) })
+				progress(func() {
+					if item.Status == gateway.ItemSucceeded {
+						b.Succeeded++
+					} else {
+						b.Failed++
+					}
+				})
+			}
+		}()
+	}
+	for i := range b.Total {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	close(dirty)
+	<-written
+
+	b.Status = gateway.BatchCompleted
+	r.record(key, b, &b.UpdatedAt)
+	r.logger.Info("batch completed", "project", b.Project, "name", b.Function, "batch_id", b.ID,
+		"succeeded", b.Succeeded, "failed", b.Failed)
+}
+
+func (r *Runner) runItem(b *gateway.Batch, index int, submit Submit, submitted func()) *gateway.BatchItem {
+	key := gateway.BatchItemKey(b.Project, b.Function, b.ID, index)
+	item := &gateway.BatchItem{Index: index, Status: gateway.ItemFailed}
+	defer r.record(key, item, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.opts.ItemTimeout)
+	defer cancel()
+	reqID, wait, err := submit(ctx, index)
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+	submitted()
+	item.RequestID = reqID
+	item.Status = gateway.ItemRunning
+	r.record(key, item, nil)
+
+	result, err := wait(r.opts.ItemTimeout)
+	if err != nil {
+		item.Status = gateway.ItemFailed
+		item.Error = err.Error()
+		return item
+	}
+	item.Status = gateway.ItemSucceeded
+	switch {
+	case len(result) > r.opts.MaxResult:
+		item.Truncated = true
+	case json.Valid(result):
+		item.Result = result
+	default:
+		item.Result, _ = json.Marshal(string(result))
+	}
+	return item
+}
+
+func (r *Runner) Get(ctx context.Context, project, function, id string) (*gateway.Batch, error) {
+	entry, err := r.kv.Get(ctx, gateway.BatchKey(project, function, id))
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var b gateway.Batch
+	if err := json.Unmarshal(entry.Value(), &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Items returns up to limit items starting at offset. Items not started yet
+// have no record and are reported as pending.
+func (r *Runner) Items(ctx context.Context, b *gateway.Batch, offset, limit int) ([]gateway.BatchItem, error) {
+	items := []gateway.BatchItem{}
+	for i := offset; i < min(offset+limit, b.Total); i++ {
+		entry, err := r.kv.Get(ctx, gateway.BatchItemKey(b.Project, b.Function, b.ID, i))
+		if errors.Is(err, jetstream.ErrKeyNotFound) {
+			items = append(items, gateway.BatchItem{Index: i, Status: gateway.ItemPending})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var item gateway.BatchItem
+		if err := json.Unmarshal(entry.Value(), &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (r *Runner) record(key string, v any, updatedAt *time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.put(ctx, key, v, updatedAt); err != nil {
+		r.logger.Warn("failed to record batch progress", "key", key, "error", err)
+	}
+}
+
+func (r *Runner) put(ctx context.Context, key string, v any, updatedAt *time.Time) error {
+	if updatedAt != nil {
+		*updatedAt = time.Now().UTC()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = r.kv.Put(ctx, key, data)
+	return err
+}
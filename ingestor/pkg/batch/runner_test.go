@@ -0,0 +1,84 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func newTestRunner(t *testing.T) *Runner {
+	t.Helper()
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoSigs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns.Start()
+	t.Cleanup(ns.Shutdown)
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server did not start")
+	}
+	nc, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc.Close)
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner, err := NewRunner(context.Background(), js, Options{Parallelism: 4, MaxParallelism: 8, ItemTimeout: time.Second, MaxResult: 1024, RecordTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runner
+}
+
+func TestRunBatch(t *testing.T) {
+	runner := newTestRunner(t)
+	b := &gateway.Batch{ID: NewID(), Project: "shop", Function: "orders", Total: 20, Parallelism: 4}
+	submit := func(ctx context.Context, index int) (string, func(time.Duration) ([]byte, error), error) {
+		if index%5 == 0 {
+			return "", nil, fmt.Errorf("item %d refused", index)
+		}
+		return fmt.Sprint("req-", index), func(time.Duration) ([]byte, error) {
+			return []byte(fmt.Sprint(index)), nil
+		}, nil
+	}
+	if err := runner.Start(context.Background(), b, submit); err != nil {
+		t.Fatal(err)
+	}
+
+	var got *gateway.Batch
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		var err error
+		if got, err = runner.Get(context.Background(), "shop", "orders", b.ID); err != nil {
+			t.Fatal(err)
+		}
+		if got.Status == gateway.BatchCompleted {
+			break
+		}
+	}
+	if got.Status != gateway.BatchCompleted || got.Submitted != 16 || got.Succeeded != 16 || got.Failed != 4 {
+		t.Fatalf("batch = %+v", got)
+	}
+
+	items, err := runner.Items(context.Background(), got, 0, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if item.Index%5 == 0 {
+			if item.Status != gateway.ItemFailed || item.Error == "" {
+				t.Errorf("item %d = %+v, want it failed", item.Index, item)
+			}
+		} else if item.Status != gateway.ItemSucceeded || string(item.Result) != fmt.Sprint(item.Index) {
+			t.Errorf("item %d = %+v", item.Index, item)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package broker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// ErrPayloadTooLarge is returned for a body above the inline limit sent to a
+// runtime that can't follow claim checks.
+var ErrPayloadTooLarge = errors.New("payload is too large for the function's runtime")
+
+// Payloads moves bodies above the inline limit into a JetStream object store
+// and sends a claim check instead, since NATS messages are capped by the
+// server's max payload. Only the runtimes in languages resolve claim checks,
+// larger bodies for the others are refused. A nil *Payloads inlines
+// everything.
+type Payloads struct {
+	obj       jetstream.ObjectStore
+	limit     int
+	languages map[string]bool
+}
+
+func NewPayloads(ctx context.Context, js jetstream.JetStream, ttl time.Duration, limit int, languages map[string]bool) (*Payloads, error) {
+	obj, err := js.CreateOrUpdateObjectStore(ctx, jetstream.ObjectStoreConfig{
+		Bucket:      gateway.PayloadsBucket,
+		Description: "litefunctions large request and response bodies",
+		TTL:         ttl,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating payloads bucket: %w", err)
+	}
+	return &Payloads{obj: obj, limit: limit, languages: languages}, nil
+}
+
+// message builds the NATS message for body, offloading it under ref when it
+// exceeds the inline limit and the lang runtime supports it. Only the first
+// limit bytes are buffered, the rest is streamed into the object store.
+func (p *Payloads) message(ctx context.Context, lang, subject, ref string, body io.Reader, header nats.Header) (*nats.Msg, error) {
+	msg := &nats.Msg{Subject: subject, Header: header}
+	if p == nil {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("error reading request body: %s", err)
+		}
+		msg.Data = data
+		return msg, nil
+	}
+
+	head, err := io.ReadAll(io.LimitReader(body, int64(p.limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading request body: %s", err)
+	}
+	if len(head) <= p.limit {
+		msg.Data = head
+		return msg, nil
+	}
+	if !p.languages[lang] {
+		return nil, fmt.Errorf("%w: %s functions take at most %d bytes", ErrPayloadTooLarge, lang, p.limit)
+	}
+	if _, err := p.obj.Put(ctx, jetstream.ObjectMeta{Name: ref}, io.MultiReader(bytes.NewReader(head), body)); err != nil {
+		return nil, fmt.Errorf("error storing large payload: %w", err)
+	}
+	msg.Header.Set(gateway.PayloadRefHeader, ref)
+	return msg, nil
+}
+
+// resolve returns the body of msg, fetching it from the object store when the
+// runtime replied with a claim check. Fetched objects are removed, results
+// are read exactly once.
+func (p *Payloads) resolve(ctx context.Context, msg *nats.Msg) ([]byte, error) {
+	ref := msg.Header.Get(gateway.PayloadRefHeader)
+	if ref == "" {
+		return msg.Data, nil
+	}
+	if p == nil {
+		return nil, fmt.Errorf("received payload reference %q but payload store is disabled", ref)
+	}
+	data, err := p.obj.GetBytes(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching large payload: %w", err)
+	}
+	_ = p.obj.Delete(ctx, ref)
+	return data, nil
+}
@@ -0,0 +1,132 @@
+package broker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func newTestPayloads(t *testing.T, limit int) (*nats.Conn, *Payloads) {
+	t.Helper()
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoSigs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns.Start()
+	t.Cleanup(ns.Shutdown)
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server did not start")
+	}
+	nc, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc.Close)
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payloads, err := NewPayloads(context.Background(), js, time.Hour, limit, map[string]bool{"go": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nc, payloads
+}
+
+func TestOffloadRoundTrip(t *testing.T) {
+	nc, payloads := newTestPayloads(t, 1024)
+	ctx := context.Background()
+	large := bytes.Repeat([]byte("0123456789"), 1000)
+	result := bytes.Repeat([]byte("result"), 1000)
+
+	// stands in for a runtime: reads the request through the claim check and
+	// answers with one of its own
+	received := make(chan []byte, 1)
+	runtime, err := nc.Subscribe("shop.upload.exec.go.*", func(msg *nats.Msg) {
+		ref := msg.Header.Get(gateway.PayloadRefHeader)
+		if ref == "" || len(msg.Data) != 0 {
+			t.Errorf("large request sent inline: ref %q, %d bytes", ref, len(msg.Data))
+			received <- nil
+			return
+		}
+		body, err := payloads.obj.GetBytes(ctx, ref)
+		if err != nil {
+			t.Error(err)
+		}
+		received <- body
+
+		resRef := ref + ".res"
+		if _, err := payloads.obj.PutBytes(ctx, resRef, result); err != nil {
+			t.Error(err)
+		}
+		reply := nats.NewMsg("shop.upload.res.go." + msg.Subject[len("shop.upload.exec.go."):])
+		reply.Header.Set(gateway.PayloadRefHeader, resRef)
+		_ = nc.PublishMsg(reply)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer runtime.Unsubscribe()
+
+	r := httptest.NewRequest("POST", "/lambda/shop/upload", bytes.NewReader(large))
+	req := NewReq(r, "go")
+	pending, err := Expect(nc, payloads, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Submit(nc, payloads, r, req); err != nil {
+		t.Fatal(err)
+	}
+	if body := <-received; !bytes.Equal(body, large) {
+		t.Fatalf("runtime read %d bytes, want %d", len(body), len(large))
+	}
+	got, err := pending.Wait(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, result) {
+		t.Fatalf("result is %d bytes, want %d", len(got), len(result))
+	}
+	if _, err := payloads.obj.GetInfo(ctx, payloadRef("shop", "upload", req.ReqId)+".res"); err == nil {
+		t.Error("resolved result was not removed from the object store")
+	}
+}
+
+func TestSmallPayloadsStayInline(t *testing.T) {
+	_, payloads := newTestPayloads(t, 1024)
+	msg, err := payloads.message(context.Background(), "go", "shop.upload.exec.go.x", "shop.upload.req.x", bytes.NewReader([]byte("small")), nats.Header{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(msg.Data) != "small" || msg.Header.Get(gateway.PayloadRefHeader) != "" {
+		t.Errorf("message = %q, headers %v", msg.Data, msg.Header)
+	}
+	if got, err := payloads.resolve(context.Background(), msg); err != nil || string(got) != "small" {
+		t.Errorf("resolve = %q, %v", got, err)
+	}
+
+	var disabled *Payloads
+	if _, err := disabled.resolve(context.Background(), &nats.Msg{Header: nats.Header{gateway.PayloadRefHeader: {"ref"}}}); err == nil {
+		t.Error("claim check accepted with the payload store disabled")
+	}
+}
+
+func TestLargePayloadsNeedClaimChecks(t *testing.T) {
+	_, payloads := newTestPayloads(t, 1024)
+	large := bytes.Repeat([]byte("0123456789"), 1000)
+	_, err := payloads.message(context.Background(), "python", "shop.upload.exec.python.x", "shop.upload.req.x", bytes.NewReader(large), nats.Header{})
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("got %v for a runtime without claim checks, want ErrPayloadTooLarge", err)
+	}
+	if _, err := payloads.obj.GetInfo(context.Background(), "shop.upload.req.x"); err == nil {
+		t.Error("refused payload was stored")
+	}
+}
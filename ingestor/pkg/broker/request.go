@@ -1,14 +1,18 @@
 package broker
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"math/rand"
 	"net/http"
 	"strings"
 
+	"github.com/ashupednekar/litefunctions/common/gateway"
 	"github.com/gorilla/websocket"
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 )
 
 type Req struct {
@@ -16,56 +20,147 @@ type Req struct {
 	Name    string
 	Lang    string
 	ReqId   string
+	// Priority selects the lane: the async priorities, with empty and
+	// "normal" using the default one, or gateway.SyncLane.
+	Priority string
 }
 
+// subject is {project}.{name}.exec.{lang}.{id}, with the lane inserted
+// before the id for sync, high and low priority requests. The id stays the last
+// token so runtimes subscribed to every lane can still read it.
+func (req *Req) subject() string {
+	if req.Priority == "" || req.Priority == gateway.PriorityNormal {
+		return fmt.Sprintf("%s.%s.exec.%s.%s", req.Project, req.Name, req.Lang, req.ReqId)
+	}
+	return fmt.Sprintf("%s.%s.exec.%s.%s.%s", req.Project, req.Name, req.Lang, req.Priority, req.ReqId)
+}
+
+// EnvelopePrefix marks request headers set by the ingestor itself. Inbound
+// copies are stripped before authentication so callers cannot spoof them.
+const EnvelopePrefix = "X-Litefunction-"
+
 const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
-func Submit(nc *nats.Conn, r *http.Request, lang string) (*Req, error) {
+// NewReq allocates the request id up front so callers can subscribe to the
+// result before the request is submitted.
+func NewReq(r *http.Request, lang string) *Req {
 	project, name := parsePath(r.URL.Path)
-	body, err := io.ReadAll(r.Body)
+	return &Req{Project: project, Name: name, Lang: lang, ReqId: randString(8)}
+}
+
+// NewSyncReq is NewReq for a request the client waits on.
+func NewSyncReq(r *http.Request, lang string) *Req {
+	req := NewReq(r, lang)
+	req.Priority = gateway.SyncLane
+	return req
+}
+
+func Submit(nc *nats.Conn, payloads *Payloads, r *http.Request, req *Req) (*Req, error) {
+	msg, err := payloads.message(
+		r.Context(),
+		req.Lang,
+		req.subject(),
+		payloadRef(req.Project, req.Name, req.ReqId),
+		r.Body,
+		Envelope(r),
+	)
 	if err != nil {
-		return nil, fmt.Errorf("error reading request body: %s", err)
+		return nil, err
 	}
-	reqID := randString(8)
-	if err := nc.Publish(
-		fmt.Sprintf("%s.%s.exec.%s.%s", project, name, lang, reqID),
-		body,
-	); err != nil {
+	if err := nc.PublishMsg(msg); err != nil {
 		return nil, fmt.Errorf("error submitting request: %v", err)
 	}
-	return &Req{Project: project, Name: name, Lang: lang, ReqId: reqID}, nil
+	return req, nil
 }
 
-func Produce(nc *nats.Conn, w http.ResponseWriter, r *http.Request, lang string) (*websocket.Conn, *Req, error) {
-	project, name := parsePath(r.URL.Path)
-	reqID := randString(8)
-	req := Req{Project: project, Name: name, Lang: lang, ReqId: reqID}
+// SubmitDeduped publishes through JetStream with msgID as the Nats-Msg-Id, so
+// the project stream drops repeats within its duplicate window. It reports
+// whether the stream flagged the message as a duplicate. Runtimes without a
+// project stream still get the message over core NATS.
+func SubmitDeduped(ctx context.Context, js jetstream.JetStream, payloads *Payloads, r *http.Request, req *Req, msgID string) (*Req, bool, error) {
+	msg, err := payloads.message(ctx, req.Lang, req.subject(), payloadRef(req.Project, req.Name, req.ReqId), r.Body, Envelope(r))
+	if err != nil {
+		return nil, false, err
+	}
+
+	ack, err := js.PublishMsg(ctx, msg, jetstream.WithMsgID(msgID))
+	if errors.Is(err, jetstream.ErrNoStreamResponse) {
+		msg.Header.Del(jetstream.MsgIDHeader)
+		if err := js.Conn().PublishMsg(msg); err != nil {
+			return nil, false, fmt.Errorf("error submitting request: %v", err)
+		}
+		return req, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error submitting request: %v", err)
+	}
+	return req, ack.Duplicate, nil
+}
+
+func Produce(nc *nats.Conn, payloads *Payloads, w http.ResponseWriter, r *http.Request, lang string) (*websocket.Conn, *Req, error) {
+	req := NewSyncReq(r, lang)
 	upgrader := websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 	}
 	// Do not echo request headers into the response. Gorilla rejects
 	// application-specific Sec-WebSocket-Extensions headers.
+	header := Envelope(r)
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error upgrading connection: %s", err)
 	}
 	go func() {
 		defer conn.Close()
-		for {
-			_, msg, err := conn.ReadMessage()
-			if err != nil {
-				return
-			}
-			if err := nc.Publish(
-				fmt.Sprintf("%s.%s.exec.%s.%s", project, name, lang, reqID),
-				msg,
-			); err != nil {
-				return
-			}
-		}
+		Forward(context.Background(), nc, payloads, req, header, func() ([]byte, error) {
+			_, data, err := conn.ReadMessage()
+			return data, err
+		})
 	}()
-	return conn, &req, nil
+	return conn, req, nil
+}
+
+// Forward publishes every message returned by next to the function until
+// next fails. It backs websocket and gRPC streams alike.
+func Forward(ctx context.Context, nc *nats.Conn, payloads *Payloads, req *Req, header nats.Header, next func() ([]byte, error)) {
+	subject := req.subject()
+	for seq := 0; ; seq++ {
+		data, err := next()
+		if err != nil {
+			return
+		}
+		msg, err := payloads.message(
+			ctx,
+			req.Lang,
+			subject,
+			fmt.Sprintf("%s.%d", payloadRef(req.Project, req.Name, req.ReqId), seq),
+			bytes.NewReader(data),
+			nats.Header(http.Header(header).Clone()),
+		)
+		if err != nil {
+			return
+		}
+		if err := nc.PublishMsg(msg); err != nil {
+			return
+		}
+	}
+}
+
+// Envelope carries the gateway-set X-Litefunction-* request headers (caller
+// identity, claims) into the NATS message so runtimes see the same context as
+// proxied HTTP requests.
+func Envelope(r *http.Request) nats.Header {
+	header := nats.Header{}
+	for k, vals := range r.Header {
+		if strings.HasPrefix(k, EnvelopePrefix) {
+			header[k] = vals
+		}
+	}
+	return header
+}
+
+func payloadRef(project, name, reqID string) string {
+	return fmt.Sprintf("%s.%s.req.%s", project, name, reqID)
 }
 
 func parsePath(path string) (string, string) {
@@ -0,0 +1,32 @@
+package broker
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+)
+
+func TestSubjectLanes(t *testing.T) {
+	r := httptest.NewRequest("POST", "/lambda/shop/orders", nil)
+	req := NewReq(r, "go")
+	req.ReqId = "abc"
+	cases := map[string]string{
+		"":                     "shop.orders.exec.go.abc",
+		gateway.PriorityNormal: "shop.orders.exec.go.abc",
+		gateway.PriorityHigh:   "shop.orders.exec.go.high.abc",
+		gateway.PriorityLow:    "shop.orders.exec.go.low.abc",
+	}
+	for priority, want := range cases {
+		req.Priority = priority
+		if got := req.subject(); got != want {
+			t.Errorf("priority %q: subject %s, want %s", priority, got, want)
+		}
+	}
+
+	sync := NewSyncReq(r, "go")
+	sync.ReqId = "abc"
+	if got := sync.subject(); got != "shop.orders.exec.go.sync.abc" {
+		t.Errorf("sync subject %s", got)
+	}
+}
@@ -1,7 +1,10 @@
 package broker
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
@@ -9,7 +12,12 @@ import (
 	"github.com/nats-io/nats.go"
 )
 
-func Reply(nc *nats.Conn, req *Req) ([]byte, error) {
+// ErrNoReply is returned when the runtime does not answer in time.
+var ErrNoReply = errors.New("no reply from runtime")
+
+// Reply waits for the first result. A deadline on ctx replaces the
+// configured reply timeout.
+func Reply(ctx context.Context, nc *nats.Conn, payloads *Payloads, req *Req) ([]byte, error) {
 	subscriber, err := nc.SubscribeSync(
 		fmt.Sprintf("%s.%s.res.%s.%s", req.Project, req.Name, req.Lang, req.ReqId),
 	)
@@ -20,19 +28,69 @@ func Reply(nc *nats.Conn, req *Req) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("reply timeout improperly configured: %s", err)
 	}
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	defer subscriber.Unsubscribe()
 	msg, err := subscriber.NextMsg(timeout)
+	if errors.Is(err, nats.ErrTimeout) {
+		return nil, fmt.Errorf("%w within %s", ErrNoReply, timeout)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error returning response: %s", err)
 	}
-	return msg.Data, nil
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return payloads.resolve(ctx, msg)
+}
+
+// Pending is a subscription to a single result, opened before the request is
+// submitted so a fast runtime can't reply before anyone listens.
+type Pending struct {
+	sub      *nats.Subscription
+	payloads *Payloads
+}
+
+func Expect(nc *nats.Conn, payloads *Payloads, req *Req) (*Pending, error) {
+	sub, err := nc.SubscribeSync(fmt.Sprintf("%s.%s.res.%s.%s", req.Project, req.Name, req.Lang, req.ReqId))
+	if err != nil {
+		return nil, fmt.Errorf("error starting subscriber: %s", err)
+	}
+	return &Pending{sub: sub, payloads: payloads}, nil
 }
 
-func Subscribe(nc *nats.Conn, req *Req) (<-chan []byte, func(), error) {
+// Wait blocks for the first result and releases the subscription.
+func (p *Pending) Wait(timeout time.Duration) ([]byte, error) {
+	defer p.Cancel()
+	msg, err := p.sub.NextMsg(timeout)
+	if errors.Is(err, nats.ErrTimeout) {
+		return nil, fmt.Errorf("%w within %s", ErrNoReply, timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error awaiting result: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return p.payloads.resolve(ctx, msg)
+}
+
+func (p *Pending) Cancel() {
+	_ = p.sub.Unsubscribe()
+}
+
+func Subscribe(nc *nats.Conn, payloads *Payloads, req *Req) (<-chan []byte, func(), error) {
 	res := make(chan []byte, 32)
 	subject := fmt.Sprintf("%s.%s.res.%s.%s", req.Project, req.Name, req.Lang, req.ReqId)
 
 	subscriber, err := nc.Subscribe(subject, func(msg *nats.Msg) {
-		payload := append([]byte(nil), msg.Data...)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		data, err := payloads.resolve(ctx, msg)
+		cancel()
+		if err != nil {
+			slog.Error("failed to resolve streamed payload", "subject", msg.Subject, "error", err)
+			return
+		}
+		payload := append([]byte(nil), data...)
 		select {
 		case res <- payload:
 		default:
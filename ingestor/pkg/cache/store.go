@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const StatusHeader = "X-Litefunction-Cache"
+
+type Entry struct {
+	Status    int         `json:"status"`
+	Header    http.Header `json:"header,omitempty"`
+	Body      []byte      `json:"body,omitempty"`
+	StoredAt  time.Time   `json:"stored_at"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// Store keeps cached responses in a JetStream KV bucket shared by every
+// ingestor replica. The bucket TTL caps how long any entry can live, entries
+// also carry their own expiry.
+type Store struct {
+	kv     jetstream.KeyValue
+	maxTTL time.Duration
+}
+
+func NewStore(ctx context.Context, js jetstream.JetStream, maxTTL time.Duration) (*Store, error) {
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      gateway.CacheBucket,
+		Description: "litefunctions cached responses",
+		TTL:         maxTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating response cache bucket: %w", err)
+	}
+	return &Store{kv: kv, maxTTL: maxTTL}, nil
+}
+
+// Key derives the cache key from the request parts the endpoint varies on.
+// The caller identity set by authentication is always included, so responses
+// are never shared between callers of protected endpoints.
+func Key(cfg *gateway.CacheConfig, r *http.Request, project, name string, identity []string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n", r.Method, r.URL.Path)
+
+	query := r.URL.Query()
+	if len(cfg.VaryQuery) > 0 {
+		for _, k := range slices.Sorted(slices.Values(cfg.VaryQuery)) {
+			fmt.Fprintf(h, "q:%s=%s\n", k, strings.Join(query[k], ","))
+		}
+	} else {
+		fmt.Fprintf(h, "q:%s\n", query.Encode())
+	}
+	for _, k := range slices.Sorted(slices.Values(cfg.VaryHeaders)) {
+		fmt.Fprintf(h, "h:%s=%s\n", strings.ToLower(k), strings.Join(r.Header.Values(k), ","))
+	}
+	for _, id := range identity {
+		fmt.Fprintf(h, "id:%s\n", id)
+	}
+	return gateway.CacheKeyPrefix(project, name) + hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns nil when there is no fresh entry for key.
+func (s *Store) Get(ctx context.Context, key string) (*Entry, error) {
+	kve, err := s.kv.Get(ctx, key)
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading cached response: %w", err)
+	}
+	var entry Entry
+	if err := json.Unmarshal(kve.Value(), &entry); err != nil {
+		return nil, fmt.Errorf("error decoding cached response: %w", err)
+	}
+	if time.Now().After(entry.ExpiresAt) {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func (s *Store) Put(ctx context.Context, key string, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := s.kv.Put(ctx, key, data); err != nil {
+		return fmt.Errorf("error storing cached response: %w", err)
+	}
+	return nil
+}
+
+// TTL decides how long a runtime response may be cached. It returns 0 when
+// the response must not be stored: non-200 statuses, cookies, or a
+// Cache-Control of no-store, no-cache or private.
+func (s *Store) TTL(cfg *gateway.CacheConfig, status int, header http.Header) time.Duration {
+	if status != http.StatusOK || header.Get("Set-Cookie") != "" {
+		return 0
+	}
+	ttl := min(time.Duration(cfg.TTL)*time.Second, s.maxTTL)
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(strings.ToLower(directive)), "=")
+		switch name {
+		case "no-store", "no-cache", "private":
+			return 0
+		case "max-age", "s-maxage":
+			if secs, err := strconv.Atoi(value); err == nil {
+				ttl = min(ttl, time.Duration(secs)*time.Second)
+			}
+		}
+	}
+	return max(ttl, 0)
+}
+
+// Replay writes a cached entry with its hit status and age.
+func (e *Entry) Replay(w http.ResponseWriter) {
+	for k, vals := range e.Header {
+		if _, ok := w.Header()[k]; ok {
+			continue
+		}
+		for _, v := range vals {
+			w.Header().Add(k, v)
+		}
+	}
+	w.Header().Set(StatusHeader, "HIT")
+	w.Header().Set("Age", strconv.Itoa(int(time.Since(e.StoredAt).Seconds())))
+	w.WriteHeader(e.Status)
+	_, _ = w.Write(e.Body)
+}
@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoSigs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns.Start()
+	t.Cleanup(ns.Shutdown)
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server did not start")
+	}
+	nc, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc.Close)
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewStore(context.Background(), js, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestKey(t *testing.T) {
+	req := func(target string, header http.Header) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		return r
+	}
+	all := &gateway.CacheConfig{TTL: 60}
+	vary := &gateway.CacheConfig{TTL: 60, VaryQuery: []string{"page", "lang"}, VaryHeaders: []string{"Accept-Language"}}
+	key := func(cfg *gateway.CacheConfig, r *http.Request, identity ...string) string {
+		return Key(cfg, r, "shop", "catalog", identity)
+	}
+
+	base := key(all, req("/lambda/shop/catalog?page=1", nil))
+	if got := key(all, req("/lambda/shop/catalog?page=1", nil)); got != base {
+		t.Error("key is not stable")
+	}
+	if key(all, req("/lambda/shop/catalog?page=2", nil)) == base {
+		t.Error("the full query string is not part of the key")
+	}
+	if key(all, req("/lambda/shop/catalog?page=1", nil), "key-1") == base {
+		t.Error("caller identity is not part of the key")
+	}
+
+	varied := key(vary, req("/lambda/shop/catalog?lang=en&page=1&utm=x", http.Header{"Accept-Language": {"en"}}))
+	if key(vary, req("/lambda/shop/catalog?page=1&lang=en&utm=y", http.Header{"Accept-Language": {"en"}})) != varied {
+		t.Error("parameters outside vary_query or their order changed the key")
+	}
+	if key(vary, req("/lambda/shop/catalog?lang=en&page=1", http.Header{"Accept-Language": {"de"}})) == varied {
+		t.Error("vary header is not part of the key")
+	}
+	if key(vary, req("/lambda/shop/catalog?lang=de&page=1", http.Header{"Accept-Language": {"en"}})) == varied {
+		t.Error("vary query parameter is not part of the key")
+	}
+	if k := Key(all, req("/lambda/shop/catalog", nil), "shop", "catalog", nil); k[:len(gateway.CacheKeyPrefix("shop", "catalog"))] != gateway.CacheKeyPrefix("shop", "catalog") {
+		t.Errorf("key %s is not scoped to the function", k)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	s := &Store{maxTTL: 5 * time.Minute}
+	cfg := &gateway.CacheConfig{TTL: 600}
+	cases := []struct {
+		name   string
+		status int
+		header http.Header
+		want   time.Duration
+	}{
+		{"capped by the bucket", 200, nil, 5 * time.Minute},
+		{"shorter max-age wins", 200, http.Header{"Cache-Control": {"public, max-age=30"}}, 30 * time.Second},
+		{"s-maxage", 200, http.Header{"Cache-Control": {"s-maxage=10"}}, 10 * time.Second},
+		{"no-store", 200, http.Header{"Cache-Control": {"no-store"}}, 0},
+		{"private", 200, http.Header{"Cache-Control": {"Private, max-age=60"}}, 0},
+		{"cookies", 200, http.Header{"Set-Cookie": {"session=1"}}, 0},
+		{"errors", 500, nil, 0},
+	}
+	for _, tc := range cases {
+		if got := s.TTL(cfg, tc.status, tc.header); got != tc.want {
+			t.Errorf("%s: ttl = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestStoreAndPurge(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	r := httptest.NewRequest(http.MethodGet, "/lambda/shop/catalog", nil)
+	catalog := Key(&gateway.CacheConfig{}, r, "shop", "catalog", nil)
+	other := Key(&gateway.CacheConfig{}, r, "shop", "catalog2", nil)
+	expired := Key(&gateway.CacheConfig{}, r, "shop", "stale", nil)
+
+	now := time.Now()
+	entry := Entry{Status: 200, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`[]`), StoredAt: now.Add(-3 * time.Second), ExpiresAt: now.Add(time.Minute)}
+	for _, key := range []string{catalog, other} {
+		if err := store.Put(ctx, key, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Put(ctx, expired, Entry{Status: 200, StoredAt: now.Add(-time.Hour), ExpiresAt: now.Add(-time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Get(ctx, catalog)
+	if err != nil || got == nil {
+		t.Fatalf("Get = %v, %v", got, err)
+	}
+	w := httptest.NewRecorder()
+	got.Replay(w)
+	if w.Body.String() != `[]` || w.Header().Get(StatusHeader) != "HIT" || w.Header().Get("Age") != "3" {
+		t.Errorf("replay = %q %v", w.Body.String(), w.Header())
+	}
+	if got, err := store.Get(ctx, expired); err != nil || got != nil {
+		t.Errorf("expired entry served: %v, %v", got, err)
+	}
+
+	// the portal purges a function by listing its prefix
+	lister, err := store.kv.ListKeysFiltered(ctx, gateway.CacheKeyPrefix("shop", "catalog")+">")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key := range lister.Keys() {
+		if err := store.kv.Purge(ctx, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := store.Get(ctx, catalog); got != nil {
+		t.Error("purged entry still served")
+	}
+	if got, _ := store.Get(ctx, other); got == nil {
+		t.Error("purging catalog dropped catalog2's entries")
+	}
+}
@@ -0,0 +1,200 @@
+// Package callback delivers the results of async invocations to webhook
+// targets.
+package callback
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/egress"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// URLHeader lets callers name their own target when the endpoint allows it.
+	URLHeader = "X-Callback-Url"
+
+	TimestampHeader = "X-Litefunction-Timestamp"
+	SignatureHeader = "X-Litefunction-Signature"
+)
+
+var (
+	ErrInvalidURL = errors.New("callback url must be an absolute https url")
+	// ErrBlockedAddress is returned for targets on loopback, link-local,
+	// private or otherwise internal addresses.
+	ErrBlockedAddress = egress.ErrBlockedAddress
+)
+
+// Target is where a single result is delivered.
+type Target struct {
+	URL    string
+	Secret string
+}
+
+// Options tune delivery. A result is retried MaxAttempts times with
+// exponential backoff starting at Backoff and capped at MaxBackoff.
+type Options struct {
+	ResultTimeout time.Duration
+	MaxAttempts   int
+	Backoff       time.Duration
+	MaxBackoff    time.Duration
+	RecordTTL     time.Duration
+}
+
+// Dispatcher posts results and records every attempt in a KV bucket shared by
+// the ingestor replicas. Deliveries live in the replica that accepted the
+// request, a restart abandons the ones still in progress.
+type Dispatcher struct {
+	kv     jetstream.KeyValue
+	client *http.Client
+	opts   Options
+	logger *slog.Logger
+}
+
+func NewDispatcher(ctx context.Context, js jetstream.JetStream, opts Options) (*Dispatcher, error) {
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      gateway.CallbacksBucket,
+		Description: "litefunctions callback delivery attempts",
+		TTL:         opts.RecordTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating callbacks bucket: %w", err)
+	}
+	return &Dispatcher{
+		kv:     kv,
+		client: newClient(egress.CheckDial),
+		opts:   opts,
+		logger: slog.Default(),
+	}, nil
+}
+
+// newClient doesn't follow redirects, a receiver could otherwise bounce the
+// signed result anywhere. control vets every address the client dials.
+func newClient(control func(network, address string, c syscall.RawConn) error) *http.Client {
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: egress.Transport(control),
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// ValidateURL only accepts https targets so results never leave in the clear.
+// Internal IP literals and localhost are refused up front, hostnames are
+// checked again once resolved when the result is posted.
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return ErrInvalidURL
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrBlockedAddress
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && egress.Blocked(addr) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// Sign returns the signature receivers verify: the hex HMAC-SHA256 of the
+// timestamp, a dot and the body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver waits for the result using wait and posts it to target. It is meant
+// to run in its own goroutine.
+func (d *Dispatcher) Deliver(project, name, reqID string, target Target, wait func(time.Duration) ([]byte, error)) {
+	key := gateway.CallbackKeyPrefix(project, name) + reqID
+	rec := &gateway.CallbackDelivery{RequestID: reqID, URL: target.URL, Status: gateway.DeliveryPending}
+	d.record(key, rec)
+
+	result, err := wait(d.opts.ResultTimeout)
+	if err != nil {
+		d.logger.Warn("no result for callback", "project", project, "name", name, "request_id", reqID, "error", err)
+		rec.Status = gateway.DeliveryExpired
+		d.record(key, rec)
+		return
+	}
+
+	backoff := d.opts.Backoff
+	for attempt := 1; attempt <= d.opts.MaxAttempts; attempt++ {
+		status, err := d.post(target, reqID, result)
+		a := gateway.CallbackAttempt{At: time.Now().UTC(), StatusCode: status}
+		if err != nil {
+			a.Error = err.Error()
+		}
+		rec.Attempts = append(rec.Attempts, a)
+
+		if err == nil && status >= 200 && status < 300 {
+			rec.Status = gateway.DeliveryDelivered
+			d.record(key, rec)
+			d.logger.Info("callback delivered", "project", project, "name", name, "request_id", reqID, "attempts", attempt)
+			return
+		}
+		// other client errors mean the receiver rejected the result
+		retryable := err != nil || status >= 500 || status == http.StatusTooManyRequests || status == http.StatusRequestTimeout
+		if !retryable || attempt == d.opts.MaxAttempts {
+			break
+		}
+		d.record(key, rec)
+		time.Sleep(backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)))
+		backoff = min(backoff*2, d.opts.MaxBackoff)
+	}
+	rec.Status = gateway.DeliveryFailed
+	d.record(key, rec)
+	d.logger.Warn("callback delivery failed", "project", project, "name", name, "request_id", reqID, "attempts", len(rec.Attempts))
+}
+
+func (d *Dispatcher) post(target Target, reqID string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", http.DetectContentType(body))
+	req.Header.Set(gateway.RequestIDHeader, reqID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(target.Secret, timestamp, body))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) record(key string, rec *gateway.CallbackDelivery) {
+	rec.UpdatedAt = time.Now().UTC()
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := d.kv.Put(ctx, key, data); err != nil {
+		d.logger.Warn("failed to record callback delivery", "key", key, "error", err)
+	}
+}
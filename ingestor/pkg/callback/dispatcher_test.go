@@ -0,0 +1,198 @@
+package callback
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/egress"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// newTestDispatcher delivers to srv. The dial guard is left out since test
+// servers listen on loopback, TestDialGuard covers it.
+func newTestDispatcher(t *testing.T, srv *httptest.Server) *Dispatcher {
+	t.Helper()
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoSigs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns.Start()
+	t.Cleanup(ns.Shutdown)
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server did not start")
+	}
+	nc, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc.Close)
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDispatcher(context.Background(), js, Options{
+		ResultTimeout: time.Second,
+		MaxAttempts:   3,
+		Backoff:       time.Millisecond,
+		MaxBackoff:    time.Millisecond,
+		RecordTTL:     time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.client = newClient(nil)
+	d.client.Transport.(*http.Transport).TLSClientConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig
+	return d
+}
+
+func delivery(t *testing.T, d *Dispatcher, reqID string) *gateway.CallbackDelivery {
+	t.Helper()
+	entry, err := d.kv.Get(context.Background(), gateway.CallbackKeyPrefix("shop", "orders")+reqID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec gateway.CallbackDelivery
+	if err := json.Unmarshal(entry.Value(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	return &rec
+}
+
+func result(body string) func(time.Duration) ([]byte, error) {
+	return func(time.Duration) ([]byte, error) { return []byte(body), nil }
+}
+
+func TestDeliverSignsAndRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		want := Sign("s3cret", r.Header.Get(TimestampHeader), body)
+		if r.Header.Get(SignatureHeader) != want || r.Header.Get(gateway.RequestIDHeader) != "req1" || string(body) != `{"ok":true}` {
+			t.Errorf("unexpected delivery: %v %s", r.Header, body)
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	d := newTestDispatcher(t, srv)
+
+	d.Deliver("shop", "orders", "req1", Target{URL: srv.URL, Secret: "s3cret"}, result(`{"ok":true}`))
+	rec := delivery(t, d, "req1")
+	if rec.Status != gateway.DeliveryDelivered || len(rec.Attempts) != 3 {
+		t.Fatalf("delivery = %s after %d attempts", rec.Status, len(rec.Attempts))
+	}
+	if rec.Attempts[0].StatusCode != http.StatusServiceUnavailable || rec.Attempts[2].StatusCode != http.StatusOK {
+		t.Errorf("attempts = %+v", rec.Attempts)
+	}
+}
+
+func TestDeliverGivesUp(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		attempts int
+	}{
+		{"server errors exhaust the attempts", http.StatusBadGateway, 3},
+		{"rejected results are not retried", http.StatusBadRequest, 1},
+		{"redirects are not followed", http.StatusFound, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var redirected atomic.Bool
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/elsewhere" {
+					redirected.Store(true)
+					return
+				}
+				if tc.status == http.StatusFound {
+					http.Redirect(w, r, "/elsewhere", tc.status)
+					return
+				}
+				w.WriteHeader(tc.status)
+			}))
+			defer srv.Close()
+			d := newTestDispatcher(t, srv)
+
+			d.Deliver("shop", "orders", "req1", Target{URL: srv.URL, Secret: "s3cret"}, result("{}"))
+			rec := delivery(t, d, "req1")
+			if rec.Status != gateway.DeliveryFailed || len(rec.Attempts) != tc.attempts {
+				t.Errorf("delivery = %s after %d attempts, want failed after %d", rec.Status, len(rec.Attempts), tc.attempts)
+			}
+			if redirected.Load() {
+				t.Error("redirect was followed")
+			}
+		})
+	}
+}
+
+func TestDeliverWithoutResult(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("posted without a result")
+	}))
+	defer srv.Close()
+	d := newTestDispatcher(t, srv)
+
+	d.Deliver("shop", "orders", "req1", Target{URL: srv.URL}, func(time.Duration) ([]byte, error) {
+		return nil, errors.New("no reply")
+	})
+	if rec := delivery(t, d, "req1"); rec.Status != gateway.DeliveryExpired {
+		t.Errorf("delivery = %s", rec.Status)
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	cases := map[string]error{
+		"https://hooks.example.com/results": nil,
+		"https://203.0.113.10:8443/cb":      nil,
+		"http://hooks.example.com/results":  ErrInvalidURL,
+		"/results":                          ErrInvalidURL,
+		"https://localhost/cb":              ErrBlockedAddress,
+		"https://api.localhost./cb":         ErrBlockedAddress,
+		"https://127.0.0.1/cb":              ErrBlockedAddress,
+		"https://10.1.2.3/cb":               ErrBlockedAddress,
+		"https://192.168.0.5/cb":            ErrBlockedAddress,
+		"https://169.254.169.254/latest":    ErrBlockedAddress,
+		"https://[::1]/cb":                  ErrBlockedAddress,
+		"https://[fe80::1]/cb":              ErrBlockedAddress,
+		"https://[::ffff:10.0.0.1]/cb":      ErrBlockedAddress,
+		"https://0.0.0.0/cb":                ErrBlockedAddress,
+		"https://100.100.100.200/cb":        ErrBlockedAddress,
+	}
+	for raw, want := range cases {
+		if err := ValidateURL(raw); !errors.Is(err, want) {
+			t.Errorf("ValidateURL(%q) = %v, want %v", raw, err, want)
+		}
+	}
+}
+
+func TestDialGuard(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	client := newClient(egress.CheckDial)
+	// through a proxy the guard would vet the proxy instead of the target
+	if client.Transport.(*http.Transport).Proxy != nil {
+		t.Fatal("callback client uses a proxy")
+	}
+	client.Transport.(*http.Transport).TLSClientConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig
+	if _, err := client.Post(srv.URL, "text/plain", nil); !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("post to loopback = %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Error("request reached the loopback server")
+	}
+}
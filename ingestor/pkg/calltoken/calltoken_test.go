@@ -0,0 +1,74 @@
+package calltoken
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	signer := NewSigner([]byte("k1"))
+	id := Identity{
+		Function: "shop/stock",
+		Chain:    []string{"shop/orders", "shop/stock"},
+		ApiKeyID: "key-1",
+		Subject:  "user-1",
+		Claims:   `{"roles":["admin"]}`,
+		Expires:  now.Add(time.Minute),
+	}
+	token, err := signer.Sign(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := signer.Verify(token, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Function != id.Function || strings.Join(got.Chain, ",") != "shop/orders,shop/stock" ||
+		got.ApiKeyID != id.ApiKeyID || got.Subject != id.Subject || got.Claims != id.Claims || !got.Expires.Equal(id.Expires) {
+		t.Errorf("identity = %+v", got)
+	}
+
+	payload, sig, _ := strings.Cut(token, ".")
+	forged := id
+	forged.Subject = "admin"
+	forgedToken, _ := NewSigner([]byte("k2")).Sign(forged)
+	forgedPayload, _, _ := strings.Cut(forgedToken, ".")
+
+	cases := map[string]struct {
+		token string
+		at    time.Time
+		err   error
+	}{
+		"expired":           {token, now.Add(time.Minute), ErrExpired},
+		"other key":         {forgedToken, now, ErrInvalid},
+		"swapped payload":   {forgedPayload + "." + sig, now, ErrInvalid},
+		"no signature":      {payload, now, ErrInvalid},
+		"garbage signature": {payload + ".!!", now, ErrInvalid},
+		"empty":             {"", now, ErrInvalid},
+		"empty identity":    {mustSign(t, signer, Identity{Expires: now.Add(time.Hour)}), now, ErrInvalid},
+		"not json":          {signed(signer, base64.RawURLEncoding.EncodeToString([]byte("nope"))), now, ErrInvalid},
+	}
+	for name, tc := range cases {
+		if _, err := signer.Verify(tc.token, tc.at); !errors.Is(err, tc.err) {
+			t.Errorf("%s: err = %v, want %v", name, err, tc.err)
+		}
+	}
+}
+
+func mustSign(t *testing.T, s *Signer, id Identity) string {
+	t.Helper()
+	token, err := s.Sign(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func signed(s *Signer, payload string) string {
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload))
+}
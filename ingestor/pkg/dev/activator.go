@@ -0,0 +1,43 @@
+package dev
+
+import (
+	"context"
+
+	"github.com/ashupednekar/litefunctions/common/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Activator answers the ingestor's activation calls in place of the
+// operator. Functions found by the runner are always running, activation
+// only reports where to reach them.
+type Activator struct {
+	proto.UnimplementedFunctionServiceServer
+	runner *Runner
+}
+
+func NewActivator(runner *Runner) *Activator {
+	return &Activator{runner: runner}
+}
+
+func (a *Activator) Activate(ctx context.Context, req *proto.ActivateRequest) (*proto.ActivateResponse, error) {
+	fn, coldStart, ok := a.runner.activate(req.Name)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "function %s not found in %s", req.Name, a.runner.opts.FunctionsDir)
+	}
+	resp := &proto.ActivateResponse{
+		IsActive:  true,
+		Language:  "go",
+		IsAsync:   fn.Async,
+		Project:   a.runner.opts.Project,
+		Name:      fn.Name,
+		ColdStart: coldStart,
+	}
+	// stream handlers are only reachable over NATS, leaving the service out
+	// has the ingestor send sync requests there too
+	if !fn.Stream {
+		resp.ServiceName = a.runner.opts.Project + "-" + fn.Name
+		resp.ServicePort = int32(fn.Port)
+	}
+	return resp, nil
+}
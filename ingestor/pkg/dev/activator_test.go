@@ -0,0 +1,124 @@
+package dev
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sleeper is a stand-in for a built function that runs until stopped.
+func sleeper(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "fn")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\nexec sleep 60\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return bin
+}
+
+func TestActivate(t *testing.T) {
+	r := NewRunner(Options{Project: "proj", FunctionsDir: t.TempDir(), BasePort: 9000})
+	r.processes["hello"] = &process{fn: Function{Name: "hello", Port: r.port("hello")}}
+	r.processes["chat"] = &process{fn: Function{Name: "chat", Stream: true, Port: r.port("chat")}}
+	r.processes["report"] = &process{fn: Function{Name: "report", Async: true, Port: r.port("report")}}
+	a := NewActivator(r)
+	ctx := context.Background()
+
+	resp, err := a.Activate(ctx, &proto.ActivateRequest{Name: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsActive || resp.Language != "go" || resp.IsAsync || resp.ServiceName != "proj-hello" || resp.ServicePort != 9000 || !resp.ColdStart {
+		t.Fatalf("unexpected activation %+v", resp)
+	}
+	if resp, _ = a.Activate(ctx, &proto.ActivateRequest{Name: "hello"}); resp.ColdStart {
+		t.Error("second activation reported a cold start")
+	}
+
+	if resp, _ = a.Activate(ctx, &proto.ActivateRequest{Name: "chat"}); resp.ServiceName != "" || resp.ServicePort != 0 {
+		t.Errorf("stream function reachable over HTTP: %+v", resp)
+	}
+	if resp, _ = a.Activate(ctx, &proto.ActivateRequest{Name: "report"}); !resp.IsAsync || resp.ServicePort != 9002 {
+		t.Errorf("async function %+v", resp)
+	}
+
+	if _, err := a.Activate(ctx, &proto.ActivateRequest{Name: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("got %v for a missing function, want NotFound", err)
+	}
+}
+
+func TestPortsSurviveRebuilds(t *testing.T) {
+	r := NewRunner(Options{BasePort: 9000})
+	if r.port("a") != 9000 || r.port("b") != 9001 || r.port("a") != 9000 {
+		t.Fatalf("ports %v", r.ports)
+	}
+}
+
+func TestFailedBuildKeepsRunning(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "go"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(dir, "go", "hello.go")
+	if err := os.WriteFile(src, []byte("package pkg\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRunner(Options{Project: "proj", FunctionsDir: dir, RuntimeDir: filepath.Join(dir, "missing"), BuildDir: t.TempDir(), BasePort: 9000})
+	p, err := r.start(Function{Name: "hello", Port: r.port("hello")}, sleeper(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.stopAll()
+	r.processes["hello"] = p
+	r.attempted["hello"] = time.Time{}
+
+	if err := r.scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if r.processes["hello"] != p {
+		t.Fatal("failed build replaced the running function")
+	}
+	select {
+	case <-p.done:
+		t.Fatal("running function stopped by a failed build")
+	default:
+	}
+	if _, _, ok := r.activate("hello"); !ok {
+		t.Error("function not activatable after a failed build")
+	}
+}
+
+func TestRemovedFunctionStops(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "go"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRunner(Options{Project: "proj", FunctionsDir: dir, BasePort: 9000})
+	p, err := r.start(Function{Name: "hello", Port: r.port("hello")}, sleeper(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.processes["hello"] = p
+	r.attempted["hello"] = time.Now()
+
+	if err := r.scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-p.done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("removed function still running")
+	}
+	if _, _, ok := r.activate("hello"); ok {
+		t.Error("removed function still activatable")
+	}
+	if _, err := os.Stat(p.bin); !os.IsNotExist(err) {
+		t.Errorf("binary left behind: %v", err)
+	}
+}
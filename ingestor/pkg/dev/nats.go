@@ -0,0 +1,33 @@
+// Package dev runs the ingestor on a laptop: an embedded NATS server stands
+// in for the cluster's, an in-memory activator for the operator, and Go
+// functions from a local directory run as child processes that are rebuilt
+// whenever their source changes.
+package dev
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+// StartNATS starts a NATS server with JetStream on localhost, keeping its
+// streams and buckets under storeDir.
+func StartNATS(port int, storeDir string) (*server.Server, error) {
+	ns, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      port,
+		JetStream: true,
+		StoreDir:  storeDir,
+		NoSigs:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ns.Start()
+	if !ns.ReadyForConnections(10 * time.Second) {
+		ns.Shutdown()
+		return nil, errors.New("embedded nats server did not start")
+	}
+	return ns, nil
+}
@@ -0,0 +1,283 @@
+package dev
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options configure the runner. FunctionsDir is laid out like a project
+// repository, with Go functions in go/<name>.go. RuntimeDir is the Go
+// runtime's source they are built into, the way the project workflows build
+// images. Builds go to BuildDir.
+type Options struct {
+	Project      string
+	FunctionsDir string
+	RuntimeDir   string
+	BuildDir     string
+	NatsURL      string
+	// BasePort is the HTTP port of the first function, the next ones count
+	// up from it.
+	BasePort     int
+	Async        map[string]bool
+	PollInterval time.Duration
+}
+
+// Function is a Go function found in the functions directory.
+type Function struct {
+	Name string
+	// Stream is set when the source defines StreamHandler. It is built in
+	// place of the runtime's stream handler rather than its Handle, as the
+	// workflows do.
+	Stream bool
+	Async  bool
+	Port   int
+}
+
+var streamHandler = regexp.MustCompile(`func\s+StreamHandler`)
+
+// Runner keeps a child process running for every function and rebuilds it
+// when its source changes. A failed build leaves the previous version
+// running.
+type Runner struct {
+	opts   Options
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	processes map[string]*process
+	attempted map[string]time.Time
+	ports     map[string]int
+}
+
+type process struct {
+	fn        Function
+	bin       string
+	cmd       *exec.Cmd
+	done      chan struct{}
+	stopping  atomic.Bool
+	activated bool
+}
+
+func NewRunner(opts Options) *Runner {
+	return &Runner{
+		opts:      opts,
+		logger:    slog.Default(),
+		processes: map[string]*process{},
+		attempted: map[string]time.Time{},
+		ports:     map[string]int{},
+	}
+}
+
+// Run starts every function, then looks for changed, added and removed
+// sources every PollInterval until ctx is done, when all functions are
+// stopped.
+func (r *Runner) Run(ctx context.Context) error {
+	defer r.stopAll()
+	ticker := time.NewTicker(r.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.scan(ctx); err != nil {
+			r.logger.Warn("failed to read functions", "dir", r.opts.FunctionsDir, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) scan(ctx context.Context) error {
+	dir := filepath.Join(r.opts.FunctionsDir, "go")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	found := map[string]bool{}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".go" || strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), ".go")
+		found[name] = true
+
+		r.mu.Lock()
+		last, seen := r.attempted[name]
+		r.attempted[name] = info.ModTime()
+		r.mu.Unlock()
+		if seen && last.Equal(info.ModTime()) {
+			continue
+		}
+		if seen {
+			r.logger.Info("function changed, rebuilding", "name", name)
+		}
+		r.reload(ctx, name, filepath.Join(dir, e.Name()))
+	}
+
+	r.mu.Lock()
+	var removed []*process
+	for name := range r.attempted {
+		if found[name] {
+			continue
+		}
+		if p, ok := r.processes[name]; ok {
+			removed = append(removed, p)
+			delete(r.processes, name)
+		}
+		delete(r.attempted, name)
+	}
+	r.mu.Unlock()
+	for _, p := range removed {
+		r.logger.Info("function removed, stopping", "name", p.fn.Name)
+		p.stop()
+	}
+	return nil
+}
+
+func (r *Runner) reload(ctx context.Context, name, src string) {
+	source, err := os.ReadFile(src)
+	if err != nil {
+		r.logger.Error("failed to read function", "name", name, "error", err)
+		return
+	}
+	fn := Function{
+		Name:   name,
+		Stream: streamHandler.Match(source),
+		Async:  r.opts.Async[name],
+		Port:   r.port(name),
+	}
+	start := time.Now()
+	bin, err := r.build(ctx, fn, source)
+	if err != nil {
+		r.logger.Error("function build failed, keeping the previous version", "name", name, "error", err)
+		return
+	}
+
+	r.mu.Lock()
+	prev := r.processes[name]
+	r.mu.Unlock()
+	if prev != nil {
+		prev.stop()
+	}
+	p, err := r.start(fn, bin)
+	if err != nil {
+		r.logger.Error("failed to start function", "name", name, "error", err)
+		return
+	}
+	r.mu.Lock()
+	r.processes[name] = p
+	r.mu.Unlock()
+	r.logger.Info("function running", "name", name, "port", fn.Port, "stream", fn.Stream, "build_ms", time.Since(start).Milliseconds())
+}
+
+// build copies the runtime, drops the function's source in and compiles it.
+// Every build gets its own binary, the running one can't be overwritten.
+func (r *Runner) build(ctx context.Context, fn Function, source []byte) (string, error) {
+	work := filepath.Join(r.opts.BuildDir, "src", fn.Name)
+	if err := os.RemoveAll(work); err != nil {
+		return "", err
+	}
+	if err := os.CopyFS(work, os.DirFS(r.opts.RuntimeDir)); err != nil {
+		return "", fmt.Errorf("error copying runtime: %w", err)
+	}
+	target := "function.go"
+	if fn.Stream {
+		target = "function_async.go"
+	}
+	if err := os.WriteFile(filepath.Join(work, "pkg", target), source, 0o644); err != nil {
+		return "", err
+	}
+
+	bin := filepath.Join(r.opts.BuildDir, "bin", fn.Name+"-"+strconv.FormatInt(time.Now().UnixNano(), 36))
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", bin, "./cmd/main.go")
+	cmd.Dir = work
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("%w\n%s", err, out)
+	}
+	return bin, nil
+}
+
+func (r *Runner) start(fn Function, bin string) (*process, error) {
+	cmd := exec.Command(bin)
+	cmd.Env = append(os.Environ(),
+		"PROJECT="+r.opts.Project,
+		"NAME="+fn.Name,
+		"NATS_URL="+r.opts.NatsURL,
+		"HTTP_PORT="+strconv.Itoa(fn.Port),
+		"LITEFUNCTIONS_DEV=true",
+	)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	p := &process{fn: fn, bin: bin, cmd: cmd, done: make(chan struct{})}
+	go func() {
+		err := cmd.Wait()
+		close(p.done)
+		if !p.stopping.Load() {
+			r.logger.Error("function exited, save its source to restart it", "name", fn.Name, "error", err)
+		}
+	}()
+	return p, nil
+}
+
+func (p *process) stop() {
+	p.stopping.Store(true)
+	_ = p.cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-p.done:
+	case <-time.After(5 * time.Second):
+		_ = p.cmd.Process.Kill()
+		<-p.done
+	}
+	_ = os.Remove(p.bin)
+}
+
+func (r *Runner) stopAll() {
+	r.mu.Lock()
+	processes := r.processes
+	r.processes = map[string]*process{}
+	r.mu.Unlock()
+	for _, p := range processes {
+		p.stop()
+	}
+}
+
+// port keeps a function on the same port across rebuilds.
+func (r *Runner) port(name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	port, ok := r.ports[name]
+	if !ok {
+		port = r.opts.BasePort + len(r.ports)
+		r.ports[name] = port
+	}
+	return port
+}
+
+// activate reports a running function, and whether this is its first call
+// since it was (re)started.
+func (r *Runner) activate(name string) (Function, bool, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.processes[name]
+	if !ok {
+		return Function{}, false, false
+	}
+	cold := !p.activated
+	p.activated = true
+	return p.fn, cold, true
+}
@@ -0,0 +1,54 @@
+// Package egress guards the requests the ingestor makes to addresses
+// configured by its users, such as callback targets and JWKS URLs, so that
+// they can't be pointed at the cluster, the node or the cloud metadata
+// service.
+package egress
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var ErrBlockedAddress = errors.New("url resolves to an internal address")
+
+// sharedAddressSpace is the carrier-grade NAT range, which clusters and
+// cloud providers use internally too.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Blocked reports whether addr is internal: loopback, private, link-local,
+// multicast, unspecified or shared address space.
+func Blocked(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() ||
+		sharedAddressSpace.Contains(addr)
+}
+
+// CheckDial is a net.Dialer Control func refusing blocked addresses. It runs
+// after name resolution, so a hostname can't be pointed at the cluster
+// either.
+func CheckDial(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	if Blocked(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// Transport is the default transport dialing through control. Proxies are
+// never used, through one control would only see the proxy's address.
+func Transport(control func(network, address string, c syscall.RawConn) error) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: 5 * time.Second, Control: control}).DialContext
+	return transport
+}
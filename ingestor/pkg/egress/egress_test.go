@@ -0,0 +1,36 @@
+package egress
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCheckDial(t *testing.T) {
+	for address, ok := range map[string]bool{
+		"203.0.113.10:443":       true,
+		"[2001:db8::1]:443":      true,
+		"100.128.0.1:443":        true,
+		"127.0.0.1:443":          false,
+		"172.20.0.4:443":         false,
+		"169.254.169.254:80":     false,
+		"100.64.0.1:443":         false,
+		"100.127.255.254:443":    false,
+		"[fd00::1]:443":          false,
+		"[::ffff:127.0.0.1]:443": false,
+		"not-an-address":         false,
+	} {
+		if err := CheckDial("tcp", address, nil); (err == nil) != ok {
+			t.Errorf("CheckDial(%s) = %v", address, err)
+		}
+	}
+}
+
+func TestTransportSkipsProxies(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://203.0.113.10:3128")
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	if proxy := Transport(CheckDial).Proxy; proxy != nil {
+		if u, _ := proxy(req); u != nil {
+			t.Fatalf("requests go through %s", u)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package idempotency
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// Recorder passes writes through to the client while keeping a copy of the
+// response so it can be stored once the handler returns.
+type Recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w}
+}
+
+func (r *Recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *Recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *Recorder) Status() int {
+	return r.status
+}
+
+func (r *Recorder) Body() []byte {
+	return r.body.Bytes()
+}
+
+func (r *Recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,137 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const Header = "Idempotency-Key"
+
+const (
+	statePending   = "pending"
+	stateCompleted = "completed"
+)
+
+var ErrInFlight = errors.New("request with this idempotency key is still in flight")
+
+type Record struct {
+	State     string      `json:"state"`
+	StartedAt time.Time   `json:"started_at"`
+	Status    int         `json:"status,omitempty"`
+	Header    http.Header `json:"header,omitempty"`
+	Body      []byte      `json:"body,omitempty"`
+}
+
+// Store keeps the first response for an idempotency key in a JetStream KV
+// bucket so every ingestor replica replays the same result.
+type Store struct {
+	kv          jetstream.KeyValue
+	lockTimeout time.Duration
+}
+
+func NewStore(ctx context.Context, js jetstream.JetStream, bucket string, ttl, lockTimeout time.Duration) (*Store, error) {
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      bucket,
+		Description: "litefunctions idempotent responses",
+		TTL:         ttl,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating idempotency bucket: %w", err)
+	}
+	return &Store{kv: kv, lockTimeout: lockTimeout}, nil
+}
+
+// Key scopes a client supplied idempotency key to a function and to the
+// caller, so one caller can't replay another's response by reusing its key.
+// caller is empty for anonymous requests. The keys are hashed since KV keys
+// only allow a restricted character set.
+func Key(project, name, caller, key string) string {
+	sum := sha256.Sum256([]byte(caller + "\x00" + key))
+	return fmt.Sprintf("%s.%s.%s", project, name, hex.EncodeToString(sum[:]))
+}
+
+// Begin claims the key for a new request. It returns the completed record if
+// one exists, nil if the caller now owns the key, or ErrInFlight if another
+// request is still running. Pending claims older than the lock timeout are
+// taken over, so a crashed replica does not block the key until it expires.
+func (s *Store) Begin(ctx context.Context, key string) (*Record, error) {
+	pending, err := json.Marshal(Record{State: statePending, StartedAt: time.Now().UTC()})
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.kv.Create(ctx, key, pending)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, jetstream.ErrKeyExists) {
+		return nil, fmt.Errorf("error claiming idempotency key: %w", err)
+	}
+
+	entry, err := s.kv.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrKeyNotFound) {
+			return nil, ErrInFlight
+		}
+		return nil, fmt.Errorf("error reading idempotency key: %w", err)
+	}
+	var rec Record
+	if err := json.Unmarshal(entry.Value(), &rec); err != nil {
+		return nil, fmt.Errorf("error decoding idempotency record: %w", err)
+	}
+	if rec.State == stateCompleted {
+		return &rec, nil
+	}
+	if time.Since(rec.StartedAt) < s.lockTimeout {
+		return nil, ErrInFlight
+	}
+	if _, err := s.kv.Update(ctx, key, pending, entry.Revision()); err != nil {
+		return nil, ErrInFlight
+	}
+	return nil, nil
+}
+
+func (s *Store) Complete(ctx context.Context, key string, status int, header http.Header, body []byte) error {
+	data, err := json.Marshal(Record{
+		State:     stateCompleted,
+		StartedAt: time.Now().UTC(),
+		Status:    status,
+		Header:    header,
+		Body:      body,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := s.kv.Put(ctx, key, data); err != nil {
+		return fmt.Errorf("error storing idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Release drops a pending claim so the client can retry with the same key.
+func (s *Store) Release(ctx context.Context, key string) error {
+	return s.kv.Delete(ctx, key)
+}
+
+// Replay writes a stored response back to the client. Headers the gateway has
+// already set for this request, such as CORS, are not overwritten.
+func (rec *Record) Replay(w http.ResponseWriter) {
+	for k, vals := range rec.Header {
+		if _, ok := w.Header()[k]; ok {
+			continue
+		}
+		for _, v := range vals {
+			w.Header().Add(k, v)
+		}
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(rec.Status)
+	_, _ = w.Write(rec.Body)
+}
@@ -0,0 +1,140 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func newTestStore(t *testing.T, lockTimeout time.Duration) *Store {
+	t.Helper()
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoSigs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns.Start()
+	t.Cleanup(ns.Shutdown)
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server did not start")
+	}
+	nc, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc.Close)
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewStore(context.Background(), js, "test-idempotency", time.Hour, lockTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestKeyScoping(t *testing.T) {
+	a := Key("shop", "orders", "key:k1", "abc")
+	if a != Key("shop", "orders", "key:k1", "abc") {
+		t.Fatal("key is not stable")
+	}
+	for _, other := range []string{
+		Key("shop", "refunds", "key:k1", "abc"),
+		Key("blog", "orders", "key:k1", "abc"),
+		Key("shop", "orders", "key:k1", "abd"),
+		Key("shop", "orders", "key:k2", "abc"),
+		Key("shop", "orders", "", "abc"),
+	} {
+		if other == a {
+			t.Errorf("%s collides with %s", other, a)
+		}
+	}
+	if k := Key("shop", "orders", "sub:a.b", "with spaces/and.dots*"); strings.ContainsAny(k, " /*") || strings.Count(k, ".") != 2 {
+		t.Errorf("client key was not hashed: %s", k)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	store := newTestStore(t, time.Minute)
+	ctx := context.Background()
+	key := Key("shop", "orders", "key:k1", "abc")
+
+	rec, err := store.Begin(ctx, key)
+	if err != nil || rec != nil {
+		t.Fatalf("first Begin = %v, %v", rec, err)
+	}
+	if _, err := store.Begin(ctx, key); !errors.Is(err, ErrInFlight) {
+		t.Fatalf("concurrent Begin err = %v, want ErrInFlight", err)
+	}
+
+	header := http.Header{"Content-Type": {"application/json"}, "Access-Control-Allow-Origin": {"https://stale.example"}}
+	if err := store.Complete(ctx, key, http.StatusCreated, header, []byte(`{"id":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	rec, err = store.Begin(ctx, key)
+	if err != nil || rec == nil {
+		t.Fatalf("Begin after Complete = %v, %v", rec, err)
+	}
+
+	w := httptest.NewRecorder()
+	w.Header().Set("Access-Control-Allow-Origin", "https://app.example")
+	rec.Replay(w)
+	if w.Code != http.StatusCreated || w.Body.String() != `{"id":1}` || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("replay = %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example" {
+		t.Errorf("replay overwrote a header set for this request: %q", got)
+	}
+}
+
+func TestReleaseAndTakeover(t *testing.T) {
+	store := newTestStore(t, 50*time.Millisecond)
+	ctx := context.Background()
+	key := Key("shop", "orders", "key:k1", "abc")
+
+	if _, err := store.Begin(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Release(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if rec, err := store.Begin(ctx, key); err != nil || rec != nil {
+		t.Fatalf("Begin after Release = %v, %v", rec, err)
+	}
+
+	// a claim left behind by a crashed replica is taken over once stale
+	time.Sleep(100 * time.Millisecond)
+	if rec, err := store.Begin(ctx, key); err != nil || rec != nil {
+		t.Fatalf("Begin on a stale claim = %v, %v", rec, err)
+	}
+	if _, err := store.Begin(ctx, key); !errors.Is(err, ErrInFlight) {
+		t.Fatalf("Begin on a fresh claim err = %v, want ErrInFlight", err)
+	}
+}
+
+func TestCallersDontShareKeys(t *testing.T) {
+	store := newTestStore(t, time.Minute)
+	ctx := context.Background()
+	alice, bob := Key("shop", "orders", "sub:alice", "abc"), Key("shop", "orders", "sub:bob", "abc")
+
+	if _, err := store.Begin(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Complete(ctx, alice, http.StatusOK, http.Header{}, []byte(`{"owner":"alice"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if rec, err := store.Begin(ctx, bob); err != nil || rec != nil {
+		t.Fatalf("Begin for another caller = %v, %v, want a fresh claim", rec, err)
+	}
+	if rec, err := store.Begin(ctx, alice); err != nil || rec == nil || string(rec.Body) != `{"owner":"alice"}` {
+		t.Fatalf("Begin for the same caller = %v, %v, want the stored response", rec, err)
+	}
+}
@@ -0,0 +1,187 @@
+package jwtauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/egress"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRefetch bounds how often an unknown kid can force a JWKS download.
+const minRefetch = 30 * time.Second
+
+// maxKeySets bounds the JWKS URLs cached at once, the least recently used
+// is dropped to make room.
+const maxKeySets = 256
+
+var (
+	ErrInvalidToken       = errors.New("invalid bearer token")
+	ErrClaimsNotSatisfied = errors.New("required claims not satisfied")
+)
+
+var validMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// Verifier checks bearer tokens against the JWKS configured on an endpoint.
+// Key sets are cached per URL. Once older than the refresh interval they are
+// refetched in the background while requests keep using them, and past
+// maxStale, when the provider could not be reached, tokens are rejected.
+type Verifier struct {
+	client   *http.Client
+	refresh  time.Duration
+	maxStale time.Duration
+	mu       sync.Mutex
+	sets     map[string]*keySet
+}
+
+// NewVerifier fetches key sets over https only, from public addresses, so
+// that an endpoint's JWKS URL can't be used to reach into the cluster.
+func NewVerifier(refresh, maxStale time.Duration) *Verifier {
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: egress.Transport(egress.CheckDial),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Scheme != "https" || len(via) >= 5 {
+				return errors.New("jwks redirect refused")
+			}
+			return nil
+		},
+	}
+	return &Verifier{
+		client:   client,
+		refresh:  refresh,
+		maxStale: max(maxStale, refresh),
+		sets:     map[string]*keySet{},
+	}
+}
+
+// BearerToken extracts the token from an Authorization header.
+func BearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(auth[7:])
+}
+
+func (v *Verifier) Verify(ctx context.Context, cfg *gateway.JWTConfig, token string) (jwt.MapClaims, error) {
+	if cfg == nil || cfg.JwksURL == "" {
+		return nil, fmt.Errorf("%w: endpoint has no jwks configured", ErrInvalidToken)
+	}
+	if u, err := url.Parse(cfg.JwksURL); err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("%w: endpoint jwks url is not an https url", ErrInvalidToken)
+	}
+	set := v.keySet(cfg.JwksURL)
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(validMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, set, kid)
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+
+	if len(cfg.Audiences) > 0 {
+		aud, _ := claims.GetAudience()
+		if !slices.ContainsFunc(aud, func(a string) bool { return slices.Contains(cfg.Audiences, a) }) {
+			return nil, fmt.Errorf("%w: audience not accepted", ErrInvalidToken)
+		}
+	}
+	for name, want := range cfg.RequiredClaims {
+		if !hasClaim(name, claims[name], want) {
+			return nil, fmt.Errorf("%w: %q", ErrClaimsNotSatisfied, name)
+		}
+	}
+	return claims, nil
+}
+
+func (v *Verifier) keySet(url string) *keySet {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	set, ok := v.sets[url]
+	if !ok {
+		if len(v.sets) >= maxKeySets {
+			var oldest *keySet
+			for _, s := range v.sets {
+				if oldest == nil || s.used.Before(oldest.used) {
+					oldest = s
+				}
+			}
+			delete(v.sets, oldest.url)
+		}
+		set = &keySet{url: url}
+		v.sets[url] = set
+	}
+	set.used = time.Now()
+	return set
+}
+
+// key returns the signing key for kid. An unknown kid (keys rotated at the
+// identity provider) waits for a fetch, while a known one is served from the
+// cache, refreshed in the background once stale. Concurrent requests share a
+// single download, and failed ones are not retried for minRefetch.
+func (v *Verifier) key(ctx context.Context, set *keySet, kid string) (any, error) {
+	key, fetched := set.lookup(kid)
+	age := time.Since(fetched)
+	if key != nil && age < v.refresh {
+		return key, nil
+	}
+	d := set.download(v.client, minRefetch)
+	if key != nil && age < v.maxStale {
+		return key, nil
+	}
+	if d == nil {
+		if key != nil {
+			return nil, fmt.Errorf("signing keys are older than %s and could not be refreshed", v.maxStale)
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if err := d.wait(ctx); err != nil {
+		return nil, err
+	}
+	if key, _ = set.lookup(kid); key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// hasClaim matches want against a claim, any item of a list claim and any
+// of the space separated values of scope (RFC 8693).
+func hasClaim(name string, value any, want string) bool {
+	if value == nil {
+		return false
+	}
+	if want == "" {
+		return true
+	}
+	if list, ok := value.([]any); ok {
+		for _, item := range list {
+			if fmt.Sprint(item) == want {
+				return true
+			}
+		}
+		return false
+	}
+	if scopes, ok := value.(string); ok && name == "scope" {
+		return slices.Contains(strings.Fields(scopes), want)
+	}
+	return fmt.Sprint(value) == want
+}
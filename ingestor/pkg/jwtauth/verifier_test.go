@@ -0,0 +1,268 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeJWKS(w http.ResponseWriter, key *rsa.PrivateKey) {
+	_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "k1",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()})
+	tok.Header["kid"] = kid
+	s, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// testVerifier trusts the test server's certificate and, unlike the
+// verifier's own client, lets it be reached on loopback.
+func testVerifier(jwks *httptest.Server, refresh, maxStale time.Duration) *Verifier {
+	v := NewVerifier(refresh, maxStale)
+	v.client = jwks.Client()
+	return v
+}
+
+func TestVerify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJWKS(w, key)
+	}))
+	defer jwks.Close()
+
+	cfg := &gateway.JWTConfig{
+		JwksURL:        jwks.URL,
+		Issuer:         "https://issuer.test",
+		Audiences:      []string{"litefunctions"},
+		RequiredClaims: map[string]string{"roles": "admin"},
+	}
+	sign := func(claims jwt.MapClaims, kid string) string {
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		tok.Header["kid"] = kid
+		s, err := tok.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   "user-1",
+			"iss":   "https://issuer.test",
+			"aud":   "litefunctions",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": []string{"dev", "admin"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		token  func() string
+		expect error
+	}{
+		{name: "valid", token: func() string { return sign(valid(), "k1") }},
+		{name: "expired", expect: ErrInvalidToken, token: func() string {
+			c := valid()
+			c["exp"] = time.Now().Add(-time.Hour).Unix()
+			return sign(c, "k1")
+		}},
+		{name: "missing exp", expect: ErrInvalidToken, token: func() string {
+			c := valid()
+			delete(c, "exp")
+			return sign(c, "k1")
+		}},
+		{name: "wrong issuer", expect: ErrInvalidToken, token: func() string {
+			c := valid()
+			c["iss"] = "https://other.test"
+			return sign(c, "k1")
+		}},
+		{name: "wrong audience", expect: ErrInvalidToken, token: func() string {
+			c := valid()
+			c["aud"] = "other"
+			return sign(c, "k1")
+		}},
+		{name: "unknown kid", expect: ErrInvalidToken, token: func() string { return sign(valid(), "k2") }},
+		{name: "missing claim", expect: ErrClaimsNotSatisfied, token: func() string {
+			c := valid()
+			c["roles"] = []string{"dev"}
+			return sign(c, "k1")
+		}},
+		{name: "hs256", expect: ErrInvalidToken, token: func() string {
+			s, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, valid()).SignedString([]byte("secret"))
+			return s
+		}},
+	}
+
+	v := testVerifier(jwks, time.Minute, time.Hour)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := v.Verify(context.Background(), cfg, tt.token())
+			if tt.expect == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if sub, _ := claims.GetSubject(); sub != "user-1" {
+					t.Fatalf("expected subject user-1, got %q", sub)
+				}
+				return
+			}
+			if !errors.Is(err, tt.expect) {
+				t.Fatalf("expected %v, got %v", tt.expect, err)
+			}
+		})
+	}
+}
+
+func TestConcurrentRequestsShareOneFetch(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fetches atomic.Int32
+	jwks := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		writeJWKS(w, key)
+	}))
+	defer jwks.Close()
+
+	v := testVerifier(jwks, time.Minute, time.Hour)
+	cfg := &gateway.JWTConfig{JwksURL: jwks.URL}
+	token := signRS256(t, key, "k1")
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := v.Verify(context.Background(), cfg, token)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("jwks fetched %d times, want 1", n)
+	}
+}
+
+func TestStaleKeysExpire(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var down atomic.Bool
+	jwks := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		writeJWKS(w, key)
+	}))
+	defer jwks.Close()
+
+	v := testVerifier(jwks, 20*time.Millisecond, 200*time.Millisecond)
+	cfg := &gateway.JWTConfig{JwksURL: jwks.URL}
+	token := signRS256(t, key, "k1")
+	if _, err := v.Verify(context.Background(), cfg, token); err != nil {
+		t.Fatal(err)
+	}
+
+	down.Store(true)
+	time.Sleep(50 * time.Millisecond)
+	if _, err := v.Verify(context.Background(), cfg, token); err != nil {
+		t.Fatalf("stale key was not served while the provider is down: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if _, err := v.Verify(context.Background(), cfg, token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("err = %v, want keys past max stale rejected", err)
+	}
+}
+
+func TestJwksURLGuard(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fetches atomic.Int32
+	jwks := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		writeJWKS(w, key)
+	}))
+	defer jwks.Close()
+	token := signRS256(t, key, "k1")
+
+	v := NewVerifier(time.Minute, time.Hour)
+	v.client.Transport.(*http.Transport).TLSClientConfig = jwks.Client().Transport.(*http.Transport).TLSClientConfig
+	for _, url := range []string{jwks.URL, "http://issuer.example/jwks", "file:///etc/passwd"} {
+		if _, err := v.Verify(context.Background(), &gateway.JWTConfig{JwksURL: url}, token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: err = %v, want the token rejected", url, err)
+		}
+	}
+	if fetches.Load() != 0 {
+		t.Error("jwks fetched from a loopback address")
+	}
+}
+
+func TestKeySetsAreBounded(t *testing.T) {
+	v := NewVerifier(time.Minute, time.Hour)
+	first := v.keySet("https://issuer.example/0")
+	for i := 1; i <= maxKeySets; i++ {
+		v.keySet(fmt.Sprintf("https://issuer.example/%d", i))
+	}
+	if len(v.sets) != maxKeySets {
+		t.Fatalf("%d key sets cached, want %d", len(v.sets), maxKeySets)
+	}
+	if v.sets[first.url] != nil {
+		t.Error("least recently used key set kept")
+	}
+}
+
+func TestScopeClaim(t *testing.T) {
+	if !hasClaim("scope", "read:orders write:orders", "write:orders") {
+		t.Error("scope not matched in a space separated list")
+	}
+	if hasClaim("scope", "read:orders write:orders", "write") {
+		t.Error("partial scope matched")
+	}
+	if hasClaim("role", "read:orders write:orders", "write:orders") {
+		t.Error("only scope is space separated")
+	}
+	if !hasClaim("roles", []any{"dev", "admin"}, "admin") {
+		t.Error("list claim not matched")
+	}
+}
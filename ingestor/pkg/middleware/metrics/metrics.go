@@ -0,0 +1,184 @@
+// Package metrics is the "metrics" middleware: it counts invocations by
+// function, mode and status and observes how long they take, served in the
+// Prometheus text format by Handler on METRICS_LISTEN_PORT.
+//
+//	MIDDLEWARE=metrics
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+)
+
+// Buckets are the upper bounds, in seconds, of the latency histogram.
+var Buckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+func init() {
+	middleware.Register("metrics", func(map[string]string) (middleware.Middleware, error) {
+		return Default.middleware, nil
+	})
+}
+
+// Default collects what every "metrics" middleware observes.
+var Default = NewRegistry()
+
+// Registry holds the invocation counters and latency histograms.
+type Registry struct {
+	mu        sync.Mutex
+	counts    map[countKey]uint64
+	latencies map[latencyKey]*histogram
+}
+
+type countKey struct {
+	project, function, mode, code string
+}
+
+type latencyKey struct {
+	project, function, mode string
+}
+
+type histogram struct {
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+func NewRegistry() *Registry {
+	return &Registry{counts: map[countKey]uint64{}, latencies: map[latencyKey]*histogram{}}
+}
+
+// Observe records an invocation that ended with status after took.
+func (reg *Registry) Observe(c *middleware.Call, status int, took time.Duration) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.counts[countKey{c.Project, c.Function, string(c.Mode), strconv.Itoa(status)}]++
+	key := latencyKey{c.Project, c.Function, string(c.Mode)}
+	h, ok := reg.latencies[key]
+	if !ok {
+		h = &histogram{buckets: make([]uint64, len(Buckets))}
+		reg.latencies[key] = h
+	}
+	seconds := took.Seconds()
+	for i, le := range Buckets {
+		if seconds <= le {
+			h.buckets[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (reg *Registry) middleware(next middleware.Handler) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		next(sw, r, c)
+		status := sw.status
+		if status == 0 {
+			// the handler returned without writing anything
+			status = http.StatusOK
+		}
+		reg.Observe(c, status, time.Since(start))
+	}
+}
+
+// Handler serves the metrics in the Prometheus text exposition format.
+func (reg *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		reg.WriteTo(w)
+	})
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format, series
+// sorted so that the output is stable.
+func (reg *Registry) WriteTo(w io.Writer) (int64, error) {
+	reg.mu.Lock()
+	var b strings.Builder
+	b.WriteString("# HELP litefunctions_invocations_total Invocations handled by the ingestor.\n")
+	b.WriteString("# TYPE litefunctions_invocations_total counter\n")
+	counts := make([]string, 0, len(reg.counts))
+	for k, n := range reg.counts {
+		counts = append(counts, fmt.Sprintf("litefunctions_invocations_total{%s,code=%q} %d\n", labels(k.project, k.function, k.mode), k.code, n))
+	}
+	slices.Sort(counts)
+	for _, line := range counts {
+		b.WriteString(line)
+	}
+
+	b.WriteString("# HELP litefunctions_invocation_duration_seconds Time taken to answer invocations.\n")
+	b.WriteString("# TYPE litefunctions_invocation_duration_seconds histogram\n")
+	keys := make([]latencyKey, 0, len(reg.latencies))
+	for k := range reg.latencies {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b latencyKey) int {
+		return strings.Compare(labels(a.project, a.function, a.mode), labels(b.project, b.function, b.mode))
+	})
+	for _, k := range keys {
+		h, l := reg.latencies[k], labels(k.project, k.function, k.mode)
+		for i, le := range Buckets {
+			fmt.Fprintf(&b, "litefunctions_invocation_duration_seconds_bucket{%s,le=%q} %d\n", l, strconv.FormatFloat(le, 'g', -1, 64), h.buckets[i])
+		}
+		fmt.Fprintf(&b, "litefunctions_invocation_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", l, h.count)
+		fmt.Fprintf(&b, "litefunctions_invocation_duration_seconds_sum{%s} %s\n", l, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "litefunctions_invocation_duration_seconds_count{%s} %d\n", l, h.count)
+	}
+	reg.mu.Unlock()
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// labels formats the labels every series carries. %q escapes quotes,
+// backslashes and newlines as the exposition format expects.
+func labels(project, function, mode string) string {
+	return fmt.Sprintf("project=%q,function=%q,mode=%q", project, function, mode)
+}
+
+// statusWriter records the status written, keeping the writer flushable and,
+// for websocket upgrades, hijackable.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusWriter) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusWriter) Write(p []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(p)
+}
+
+func (s *statusWriter) Flush() {
+	_ = http.NewResponseController(s.ResponseWriter).Flush()
+}
+
+func (s *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(s.ResponseWriter).Hijack()
+	if err == nil && s.status == 0 {
+		s.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+func (s *statusWriter) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
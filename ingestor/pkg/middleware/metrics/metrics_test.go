@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+)
+
+func TestMiddleware(t *testing.T) {
+	reg := NewRegistry()
+	handler := reg.middleware(func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		if c.Function == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	for _, name := range []string{"orders", "orders", "missing"} {
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), &middleware.Call{Project: "shop", Function: name, Mode: middleware.Sync})
+	}
+
+	w := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+	for _, want := range []string{
+		`litefunctions_invocations_total{project="shop",function="orders",mode="sync",code="200"} 2`,
+		`litefunctions_invocations_total{project="shop",function="missing",mode="sync",code="404"} 1`,
+		`litefunctions_invocation_duration_seconds_bucket{project="shop",function="orders",mode="sync",le="+Inf"} 2`,
+		`litefunctions_invocation_duration_seconds_count{project="shop",function="orders",mode="sync"} 2`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("missing %s in\n%s", want, body)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	reg := NewRegistry()
+	c := &middleware.Call{Project: "shop", Function: "orders", Mode: middleware.GRPC}
+	reg.Observe(c, http.StatusOK, 20*time.Millisecond)
+	reg.Observe(c, http.StatusOK, 3*time.Second)
+	h := reg.latencies[latencyKey{"shop", "orders", "grpc"}]
+	// 0.025 is the first bucket holding 20ms and 5 the first holding 3s
+	if h.buckets[1] != 0 || h.buckets[2] != 1 || h.buckets[8] != 1 || h.buckets[9] != 2 || h.count != 2 {
+		t.Errorf("buckets %v, count %d", h.buckets, h.count)
+	}
+}
+
+func TestHijack(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w}
+		conn, _, err := sw.Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+		if sw.status != http.StatusSwitchingProtocols {
+			t.Errorf("status %d after hijacking", sw.status)
+		}
+	}))
+	defer srv.Close()
+	if resp, err := http.Get(srv.URL); err == nil {
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,129 @@
+// Package middleware is the pipeline every invocation passes through at the
+// ingestor, whether it arrives as a plain request, an SSE or websocket
+// stream, a gRPC call or a call from another function.
+//
+// The ingestor's own stages (logging, CORS, invocation records, maintenance,
+// authentication, validation, quotas, request transforms) are middleware
+// too. Named middleware, such as the built-in "ratelimit", "metrics" and
+// "transform", is registered from an init function; custom ingestor builds
+// compile in their own by importing its package for the side effect:
+//
+//	import _ "example.com/acme/ratelimit"
+//
+// It then runs for endpoints that list it, or for every endpoint when named
+// in the MIDDLEWARE setting.
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+)
+
+// Mode is how an invocation reached the ingestor.
+type Mode string
+
+const (
+	Sync     Mode = "sync"
+	SSE      Mode = "sse"
+	WS       Mode = "ws"
+	Batch    Mode = "batch"
+	Internal Mode = "internal"
+	// GRPC is a unary InvokeService call and GRPCStream an InvokeStream.
+	GRPC       Mode = "grpc"
+	GRPCStream Mode = "grpc-stream"
+)
+
+// Call describes the invocation being handled.
+type Call struct {
+	Project  string
+	Function string
+	Mode     Mode
+	// Endpoint is the configuration for the request's method, nil when the
+	// function has none.
+	Endpoint *gateway.Endpoint
+}
+
+// Handler serves a call. A middleware ends the chain by answering the
+// request itself instead of calling next. Websocket upgrades hijack the
+// connection, so in WS mode a wrapped ResponseWriter has to keep
+// implementing http.Hijacker.
+type Handler func(w http.ResponseWriter, r *http.Request, c *Call)
+
+type Middleware func(next Handler) Handler
+
+// Chain wraps h so that mws run in order before it.
+func Chain(h Handler, mws ...Middleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// Factory builds a middleware from the config it was enabled with.
+type Factory func(config map[string]string) (Middleware, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register makes a middleware available under name. It panics when the name
+// is taken, as two plugins silently shadowing each other is never intended.
+func Register(name string, f Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := factories[name]; ok {
+		panic("middleware: " + name + " registered twice")
+	}
+	factories[name] = f
+}
+
+func Lookup(name string) (Factory, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	f, ok := factories[name]
+	return f, ok
+}
+
+// Registered lists the registered middleware names, sorted.
+func Registered() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ParseList reads a comma separated list of middleware with optional
+// query-style config, e.g. "ratelimit?rps=50&burst=100,audit".
+func ParseList(raw string) ([]gateway.MiddlewareConfig, error) {
+	var list []gateway.MiddlewareConfig
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		name, query, _ := strings.Cut(part, "?")
+		values, err := url.ParseQuery(query)
+		if err != nil || name == "" {
+			return nil, fmt.Errorf("invalid middleware %q", part)
+		}
+		cfg := gateway.MiddlewareConfig{Name: name}
+		if len(values) > 0 {
+			cfg.Config = make(map[string]string, len(values))
+			for k := range values {
+				cfg.Config[k] = values.Get(k)
+			}
+		}
+		list = append(list, cfg)
+	}
+	return list, nil
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	step := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(w http.ResponseWriter, r *http.Request, c *Call) {
+				order = append(order, name)
+				next(w, r, c)
+			}
+		}
+	}
+	stop := func(next Handler) Handler {
+		return func(w http.ResponseWriter, r *http.Request, c *Call) {
+			order = append(order, "stop")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}
+	final := func(w http.ResponseWriter, r *http.Request, c *Call) { order = append(order, "final") }
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	Chain(final, step("a"), step("b"))(httptest.NewRecorder(), r, &Call{})
+	if want := []string{"a", "b", "final"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("got %v, want %v", order, want)
+	}
+
+	order = nil
+	w := httptest.NewRecorder()
+	Chain(final, step("a"), stop, step("b"))(w, r, &Call{})
+	if want := []string{"a", "stop"}; !reflect.DeepEqual(order, want) || w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got %v (%d), want %v", order, w.Code, want)
+	}
+}
+
+func TestParseList(t *testing.T) {
+	list, err := ParseList(" ratelimit?rps=50&burst=100 , audit,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "ratelimit" || list[0].Config["rps"] != "50" ||
+		list[0].Config["burst"] != "100" || list[1].Name != "audit" || list[1].Config != nil {
+		t.Fatalf("unexpected list %+v", list)
+	}
+	if _, err := ParseList("?rps=1"); err == nil {
+		t.Fatal("expected an error for a missing name")
+	}
+}
@@ -0,0 +1,133 @@
+// Package ratelimit is the "ratelimit" middleware: a token bucket per
+// function, or per caller of a function, answered with 429 and Retry-After
+// once it runs dry. Buckets are kept by each ingestor replica, so the rate
+// a function sees is the configured one times the replicas.
+//
+//	MIDDLEWARE=ratelimit?rps=50&burst=100&by=key
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+	"golang.org/x/time/rate"
+)
+
+// idleAfter is how long an unused bucket is kept. It is full again by then.
+const idleAfter = 10 * time.Minute
+
+func init() {
+	middleware.Register("ratelimit", New)
+}
+
+// New builds the middleware from its config: rps, the sustained rate, burst,
+// the bucket size (rps rounded up by default), and by, "function" for one
+// bucket per function or "key" for one per api key or token subject.
+func New(config map[string]string) (middleware.Middleware, error) {
+	l, err := newLimiter(config)
+	if err != nil {
+		return nil, err
+	}
+	return l.middleware, nil
+}
+
+func newLimiter(config map[string]string) (*limiter, error) {
+	rps, err := strconv.ParseFloat(config["rps"], 64)
+	if err != nil || rps <= 0 {
+		return nil, errors.New("rps must be a positive number")
+	}
+	burst := int(math.Ceil(rps))
+	if v, ok := config["burst"]; ok {
+		if burst, err = strconv.Atoi(v); err != nil || burst < 1 {
+			return nil, errors.New("burst must be a positive integer")
+		}
+	}
+	by := config["by"]
+	switch by {
+	case "":
+		by = "function"
+	case "function", "key":
+	default:
+		return nil, fmt.Errorf("unknown by %q, expected function or key", by)
+	}
+	return &limiter{limit: rate.Limit(rps), burst: burst, byKey: by == "key", buckets: map[string]*bucket{}, now: time.Now}, nil
+}
+
+type bucket struct {
+	*rate.Limiter
+	lastUsed time.Time
+}
+
+type limiter struct {
+	limit rate.Limit
+	burst int
+	byKey bool
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+func (l *limiter) middleware(next middleware.Handler) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		if wait := l.reserve(l.key(r, c)); wait > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			problem.Write(w, http.StatusTooManyRequests, problem.RateLimited, "rate limit exceeded, retry later")
+			return
+		}
+		next(w, r, c)
+	}
+}
+
+// key names the bucket of the call. Anonymous callers of a function share
+// one.
+func (l *limiter) key(r *http.Request, c *middleware.Call) string {
+	key := c.Project + "/" + c.Function
+	if !l.byKey {
+		return key
+	}
+	if id := r.Header.Get(gateway.ApiKeyIDHeader); id != "" {
+		return key + "/key:" + id
+	}
+	if sub := r.Header.Get(gateway.SubjectHeader); sub != "" {
+		return key + "/sub:" + sub
+	}
+	return key
+}
+
+// reserve takes a token from the bucket for key, or returns how long until
+// one is available without taking it.
+func (l *limiter) reserve(key string) time.Duration {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.swept) > idleAfter {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastUsed) > idleAfter {
+				delete(l.buckets, k)
+			}
+		}
+		l.swept = now
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{Limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.buckets[key] = b
+	}
+	b.lastUsed = now
+	res := b.ReserveN(now, 1)
+	if wait := res.DelayFrom(now); wait > 0 {
+		res.CancelAt(now)
+		return wait
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+)
+
+func TestLimit(t *testing.T) {
+	l, err := newLimiter(map[string]string{"rps": "1", "burst": "2", "by": "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	handler := l.middleware(func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {})
+	call := func(key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/lambda/shop/orders", nil)
+		if key != "" {
+			r.Header.Set(gateway.ApiKeyIDHeader, key)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r, &middleware.Call{Project: "shop", Function: "orders"})
+		return w
+	}
+
+	for i := range 2 {
+		if w := call("a"); w.Code != http.StatusOK {
+			t.Fatalf("call %d got %d within the burst", i, w.Code)
+		}
+	}
+	w := call("a")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("got %d with Retry-After %q, want 429 and 1", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := call("b"); w.Code != http.StatusOK {
+		t.Fatalf("another key got %d", w.Code)
+	}
+	now = now.Add(time.Second)
+	if w := call("a"); w.Code != http.StatusOK {
+		t.Fatalf("got %d once a token was added", w.Code)
+	}
+
+	now = now.Add(2 * idleAfter)
+	call("")
+	if len(l.buckets) != 1 {
+		t.Errorf("idle buckets kept: %d", len(l.buckets))
+	}
+}
+
+func TestConfig(t *testing.T) {
+	for _, config := range []map[string]string{
+		{},
+		{"rps": "0"},
+		{"rps": "5", "burst": "-1"},
+		{"rps": "5", "by": "ip"},
+	} {
+		if _, err := New(config); err == nil {
+			t.Errorf("%v accepted", config)
+		}
+	}
+	l, err := newLimiter(map[string]string{"rps": "2.5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.burst != 3 || l.byKey {
+		t.Errorf("defaults: burst %d, by key %v", l.burst, l.byKey)
+	}
+}
@@ -0,0 +1,99 @@
+// Package problem writes the ingestor's error responses as RFC 9457 problem
+// details. Every error carries a stable code callers can branch on, the
+// request ID to quote when reporting it and a message that is safe to show;
+// what went wrong internally only goes to the logs.
+package problem
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/common/schema"
+)
+
+const ContentType = "application/problem+json"
+
+// Code identifies the kind of error independently of the message, which may
+// change.
+type Code string
+
+const (
+	BadRequest         Code = "bad_request"
+	ValidationFailed   Code = "validation_failed"
+	PayloadTooLarge    Code = "payload_too_large"
+	Unauthorized       Code = "unauthorized"
+	Forbidden          Code = "forbidden"
+	NotFound           Code = "not_found"
+	FunctionNotFound   Code = "function_not_found"
+	MethodNotAllowed   Code = "method_not_allowed"
+	Conflict           Code = "conflict"
+	CallLoop           Code = "call_loop"
+	QuotaExceeded      Code = "quota_exceeded"
+	RateLimited        Code = "rate_limited"
+	Maintenance        Code = "maintenance"
+	FeatureDisabled    Code = "feature_disabled"
+	ActivationFailed   Code = "activation_failed"
+	RuntimeUnavailable Code = "runtime_unavailable"
+	UpstreamFailed     Code = "upstream_failed"
+	ReplyTimeout       Code = "reply_timeout"
+	DeadlineExceeded   Code = "deadline_exceeded"
+	InjectedFault      Code = "injected_fault"
+	Internal           Code = "internal_error"
+)
+
+// Details is the response body. Type is left out, which RFC 9457 reads as
+// about:blank: the title is the status text and Code narrows it down.
+type Details struct {
+	Title      string             `json:"title"`
+	Status     int                `json:"status"`
+	Code       Code               `json:"code"`
+	Detail     string             `json:"detail,omitempty"`
+	RequestID  string             `json:"request_id,omitempty"`
+	Violations []schema.Violation `json:"violations,omitempty"`
+}
+
+func New(status int, code Code, detail string) *Details {
+	return &Details{Title: http.StatusText(status), Status: status, Code: code, Detail: detail}
+}
+
+// Write answers with a problem. detail must be safe to show to callers.
+func Write(w http.ResponseWriter, status int, code Code, detail string) {
+	New(status, code, detail).Write(w)
+}
+
+// Write sends d with the request ID of the response, assigning one when
+// the request was not tracked.
+func (d *Details) Write(w http.ResponseWriter) {
+	id := w.Header().Get(gateway.RequestIDHeader)
+	if id == "" {
+		b := make([]byte, 8)
+		_, _ = rand.Read(b)
+		id = hex.EncodeToString(b)
+		w.Header().Set(gateway.RequestIDHeader, id)
+	}
+	d.RequestID = id
+	w.Header().Del("Content-Length")
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(d.Status)
+	_ = json.NewEncoder(w).Encode(d)
+}
+
+// Message is the text to report for an error response: the detail of a
+// problem, otherwise the body itself.
+func Message(header http.Header, body []byte) string {
+	if strings.HasPrefix(header.Get("Content-Type"), ContentType) {
+		var d Details
+		if err := json.Unmarshal(body, &d); err == nil {
+			if d.Detail != "" {
+				return d.Detail
+			}
+			return d.Title
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
@@ -0,0 +1,51 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+)
+
+func TestWrite(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set(gateway.RequestIDHeader, "abc123")
+	Write(w, http.StatusGatewayTimeout, ReplyTimeout, "the function did not reply in time")
+
+	if w.Code != http.StatusGatewayTimeout || w.Header().Get("Content-Type") != ContentType {
+		t.Fatalf("status = %d, content type = %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	var d Details
+	if err := json.Unmarshal(w.Body.Bytes(), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Code != ReplyTimeout || d.RequestID != "abc123" || d.Title != "Gateway Timeout" || d.Status != http.StatusGatewayTimeout {
+		t.Errorf("details = %+v", d)
+	}
+	if got := Message(w.Header(), w.Body.Bytes()); got != "the function did not reply in time" {
+		t.Errorf("message = %q", got)
+	}
+}
+
+func TestWriteAssignsRequestID(t *testing.T) {
+	w := httptest.NewRecorder()
+	Write(w, http.StatusNotFound, NotFound, "")
+
+	var d Details
+	_ = json.Unmarshal(w.Body.Bytes(), &d)
+	if d.RequestID == "" || d.RequestID != w.Header().Get(gateway.RequestIDHeader) {
+		t.Errorf("request id = %q, header = %q", d.RequestID, w.Header().Get(gateway.RequestIDHeader))
+	}
+	if got := Message(w.Header(), w.Body.Bytes()); got != "Not Found" {
+		t.Errorf("message = %q", got)
+	}
+}
+
+func TestMessagePlainBody(t *testing.T) {
+	h := http.Header{"Content-Type": {"text/plain"}}
+	if got := Message(h, []byte("boom\n")); got != "boom" {
+		t.Errorf("message = %q", got)
+	}
+}
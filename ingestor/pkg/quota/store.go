@@ -0,0 +1,180 @@
+// Package quota counts invocations against the daily and monthly quotas set
+// from the portal. Counters live in a JetStream KV bucket so every ingestor
+// replica, and the portal, see the same consumption.
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// retention keeps a counter around for a while after its period ends, so
+// the portal can still show last month's consumption.
+const retention = 62 * 24 * time.Hour
+
+// maxAttempts bounds the compare-and-set retries of a contended counter.
+const maxAttempts = 16
+
+// Limit is one quota period applying to a request.
+type Limit struct {
+	// Scope is "project" or "api key", for messages.
+	Scope  string
+	Period string
+	Max    int64
+	Key    string
+	Reset  time.Time
+}
+
+// Status describes the tightest limit after a request was counted, or the
+// limit that turned it away.
+type Status struct {
+	Limit     Limit
+	Remaining int64
+	Exceeded  bool
+}
+
+type Store struct {
+	kv jetstream.KeyValue
+}
+
+func NewStore(ctx context.Context, js jetstream.JetStream) (*Store, error) {
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      gateway.QuotaUsageBucket,
+		Description: "litefunctions quota consumption",
+		TTL:         retention,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating quota usage bucket: %w", err)
+	}
+	return &Store{kv: kv}, nil
+}
+
+// Scoped names the quota a Limit came from.
+type Scoped struct {
+	Scope string
+	Quota *gateway.Quota
+}
+
+// Limits lists the periods of quotas that are set, counted from now.
+func Limits(now time.Time, scoped ...Scoped) []Limit {
+	var limits []Limit
+	for _, s := range scoped {
+		if s.Quota == nil {
+			continue
+		}
+		key := gateway.QuotaKey(s.Quota.Project, s.Quota.ApiKeyID)
+		for _, period := range gateway.QuotaPeriods {
+			limit := s.Quota.Limit(period)
+			if limit <= 0 {
+				continue
+			}
+			_, reset := gateway.QuotaWindow(period, now)
+			limits = append(limits, Limit{
+				Scope:  s.Scope,
+				Period: period,
+				Max:    limit,
+				Key:    gateway.QuotaUsageKey(key, period, now),
+				Reset:  reset,
+			})
+		}
+	}
+	return limits
+}
+
+// Consume counts n invocations against every limit, unless one of them
+// doesn't have n left, in which case nothing is counted. Each counter is
+// checked and incremented in one compare-and-set, so no number of replicas
+// pushes it past its limit. The counts taken from earlier limits are given
+// back when a later one turns the request away, until then they may turn
+// away a concurrent request that would have fit.
+func (s *Store) Consume(ctx context.Context, limits []Limit, n int64) (Status, error) {
+	var tightest Status
+	for i, l := range limits {
+		count, ok, err := s.add(ctx, l.Key, n, l.Max)
+		if err != nil {
+			return Status{}, errors.Join(err, s.release(ctx, limits[:i], n))
+		}
+		if !ok {
+			if err := s.release(ctx, limits[:i], n); err != nil {
+				return Status{}, err
+			}
+			return Status{Limit: l, Remaining: max(l.Max-count, 0), Exceeded: true}, nil
+		}
+		remaining := max(l.Max-count, 0)
+		if i == 0 || remaining < tightest.Remaining {
+			tightest = Status{Limit: l, Remaining: remaining}
+		}
+	}
+	return tightest, nil
+}
+
+// release gives back n invocations counted against limits, even once ctx is
+// done, as they were never used.
+func (s *Store) release(ctx context.Context, limits []Limit, n int64) error {
+	ctx = context.WithoutCancel(ctx)
+	var errs []error
+	for _, l := range limits {
+		if _, _, err := s.add(ctx, l.Key, -n, 0); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Store) get(ctx context.Context, key string) (int64, uint64, error) {
+	entry, err := s.kv.Get(ctx, key)
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("error reading quota counter: %w", err)
+	}
+	count, err := strconv.ParseInt(string(entry.Value()), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error decoding quota counter %s: %w", key, err)
+	}
+	return count, entry.Revision(), nil
+}
+
+// add increments the counter at key by n and returns the new count. When
+// limit is positive and the counter doesn't have n left, it is left as is
+// and the current count returned with ok unset.
+func (s *Store) add(ctx context.Context, key string, n, limit int64) (int64, bool, error) {
+	for range maxAttempts {
+		count, rev, err := s.get(ctx, key)
+		if err != nil {
+			return 0, false, err
+		}
+		if limit > 0 && count+n > limit {
+			return count, false, nil
+		}
+		value := []byte(strconv.FormatInt(count+n, 10))
+		if rev == 0 {
+			_, err = s.kv.Create(ctx, key, value)
+		} else {
+			_, err = s.kv.Update(ctx, key, value, rev)
+		}
+		if err == nil {
+			return count + n, true, nil
+		}
+		if !conflict(err) {
+			return 0, false, fmt.Errorf("error updating quota counter: %w", err)
+		}
+	}
+	return 0, false, fmt.Errorf("quota counter %s is too contended", key)
+}
+
+// conflict reports whether another replica wrote the counter first.
+func conflict(err error) bool {
+	if errors.Is(err, jetstream.ErrKeyExists) {
+		return true
+	}
+	var apiErr *jetstream.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode == jetstream.JSErrCodeStreamWrongLastSequence
+}
@@ -0,0 +1,171 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoSigs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns.Start()
+	t.Cleanup(ns.Shutdown)
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server did not start")
+	}
+	nc, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc.Close)
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewStore(context.Background(), js)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestLimits(t *testing.T) {
+	now := time.Date(2026, 3, 31, 22, 0, 0, 0, time.UTC)
+	limits := Limits(now,
+		Scoped{Scope: "api key", Quota: &gateway.Quota{Project: "shop", ApiKeyID: "k1", Daily: 10}},
+		Scoped{Scope: "project", Quota: nil},
+		Scoped{Scope: "project", Quota: &gateway.Quota{Project: "shop", Daily: 100, Monthly: 1000}},
+	)
+	if len(limits) != 3 {
+		t.Fatalf("limits = %+v", limits)
+	}
+	if limits[0].Key != "shop.apikey.k1.day.20260331" || !limits[0].Reset.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("api key limit = %+v", limits[0])
+	}
+	if limits[2].Key != "shop.month.202603" || limits[2].Max != 1000 {
+		t.Errorf("monthly limit = %+v", limits[2])
+	}
+}
+
+func TestConsume(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	limits := Limits(now,
+		Scoped{Scope: "api key", Quota: &gateway.Quota{Project: "shop", ApiKeyID: "k1", Daily: 3}},
+		Scoped{Scope: "project", Quota: &gateway.Quota{Project: "shop", Monthly: 10}},
+	)
+
+	for want := int64(2); want >= 0; want-- {
+		status, err := store.Consume(ctx, limits, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.Exceeded || status.Remaining != want || status.Limit.Scope != "api key" {
+			t.Fatalf("status = %+v, want %d remaining on the api key", status, want)
+		}
+	}
+	status, err := store.Consume(ctx, limits, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Exceeded || status.Limit.Period != gateway.QuotaDaily {
+		t.Fatalf("status = %+v, want the daily quota exceeded", status)
+	}
+
+	// a rejected request is not counted against the other quotas
+	project := Limits(now, Scoped{Scope: "project", Quota: &gateway.Quota{Project: "shop", Monthly: 10}})
+	if status, err = store.Consume(ctx, project, 7); err != nil || status.Exceeded || status.Remaining != 0 {
+		t.Fatalf("status = %+v, err = %v", status, err)
+	}
+	if status, _ = store.Consume(ctx, project, 1); !status.Exceeded {
+		t.Fatalf("status = %+v, want the monthly quota exceeded", status)
+	}
+}
+
+func TestConsumeRollsBack(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	limits := Limits(now,
+		Scoped{Scope: "api key", Quota: &gateway.Quota{Project: "shop", ApiKeyID: "k1", Daily: 10}},
+		Scoped{Scope: "project", Quota: &gateway.Quota{Project: "shop", Daily: 2}},
+	)
+	for range 2 {
+		if status, err := store.Consume(ctx, limits, 1); err != nil || status.Exceeded {
+			t.Fatalf("status = %+v, err = %v", status, err)
+		}
+	}
+	status, err := store.Consume(ctx, limits, 1)
+	if err != nil || !status.Exceeded || status.Limit.Scope != "project" {
+		t.Fatalf("status = %+v, err = %v, want the project quota exceeded", status, err)
+	}
+	// the api key is charged for the two requests that ran only
+	if count, _, err := store.get(ctx, limits[0].Key); err != nil || count != 2 {
+		t.Fatalf("api key counter = %d, err = %v", count, err)
+	}
+}
+
+func TestConsumeConcurrently(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	project := &gateway.Quota{Project: "shop", Daily: 5}
+
+	var (
+		wg       sync.WaitGroup
+		admitted atomic.Int64
+		keys     = make([]Limit, 20)
+	)
+	for i := range keys {
+		limits := Limits(now,
+			Scoped{Scope: "api key", Quota: &gateway.Quota{Project: "shop", ApiKeyID: fmt.Sprintf("k%d", i), Daily: 10}},
+			Scoped{Scope: "project", Quota: project},
+		)
+		keys[i] = limits[0]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, err := store.Consume(ctx, limits, 1)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !status.Exceeded {
+				admitted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if admitted.Load() != 5 {
+		t.Errorf("%d requests admitted, want 5", admitted.Load())
+	}
+	limit := Limits(now, Scoped{Scope: "project", Quota: project})[0]
+	if count, _, err := store.get(ctx, limit.Key); err != nil || count != 5 {
+		t.Errorf("project counter = %d, err = %v, want 5", count, err)
+	}
+	var charged int64
+	for _, l := range keys {
+		count, _, err := store.get(ctx, l.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		charged += count
+	}
+	if charged != 5 {
+		t.Errorf("api keys charged %d times, want 5", charged)
+	}
+}
@@ -0,0 +1,94 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Registry keeps an in-memory copy of a KV bucket written by the portal, so
+// lookups on the request path never leave the process.
+type Registry[T any] struct {
+	bucket string
+	mu     sync.RWMutex
+	items  map[string]*T
+}
+
+const loadTimeout = 10 * time.Second
+
+// New loads the bucket and keeps following updates until ctx is done. It
+// returns once the initial values have been received.
+func New[T any](ctx context.Context, js jetstream.JetStream, bucket string) (*Registry[T], error) {
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{Bucket: bucket})
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s bucket: %w", bucket, err)
+	}
+	watcher, err := kv.WatchAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error watching %s bucket: %w", bucket, err)
+	}
+
+	r := &Registry[T]{bucket: bucket, items: map[string]*T{}}
+	ready := make(chan struct{})
+	go r.follow(watcher, ready)
+
+	select {
+	case <-ready:
+		return r, nil
+	case <-time.After(loadTimeout):
+		return nil, fmt.Errorf("timed out loading %s bucket", bucket)
+	}
+}
+
+func (r *Registry[T]) follow(watcher jetstream.KeyWatcher, ready chan struct{}) {
+	defer watcher.Stop()
+	initial := true
+	for entry := range watcher.Updates() {
+		if entry == nil {
+			if initial {
+				initial = false
+				close(ready)
+			}
+			continue
+		}
+		r.apply(entry)
+	}
+	slog.Warn("registry watcher stopped", "bucket", r.bucket)
+}
+
+func (r *Registry[T]) apply(entry jetstream.KeyValueEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entry.Operation() != jetstream.KeyValuePut {
+		delete(r.items, entry.Key())
+		return
+	}
+	var item T
+	if err := json.Unmarshal(entry.Value(), &item); err != nil {
+		slog.Warn("ignoring malformed registry entry", "bucket", r.bucket, "key", entry.Key(), "error", err)
+		delete(r.items, entry.Key())
+		return
+	}
+	r.items[entry.Key()] = &item
+}
+
+func (r *Registry[T]) Get(key string) (*T, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	item, ok := r.items[key]
+	return item, ok
+}
+
+// Of returns a registry holding fixed items that never change, for tests
+// and tools that run without the portal's buckets.
+func Of[T any](bucket string, items map[string]*T) *Registry[T] {
+	if items == nil {
+		items = map[string]*T{}
+	}
+	return &Registry[T]{bucket: bucket, items: items}
+}
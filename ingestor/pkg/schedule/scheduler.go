@@ -0,0 +1,276 @@
+// Package schedule holds back async invocations until they are due and
+// dispatches them from whichever ingestor replica claims them first.
+package schedule
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+var (
+	ErrNotFound   = errors.New("scheduled invocation not found")
+	ErrNotPending = errors.New("scheduled invocation already dispatched or cancelled")
+)
+
+// Options tune the scheduler. Due entries are looked for every PollInterval.
+// A replica has DispatchTimeout to hand an entry to its function, claims older
+// than twice that are taken over, so a replica dying mid-dispatch delays the
+// entry rather than losing it. Finished entries are kept for Retention.
+type Options struct {
+	PollInterval    time.Duration
+	DispatchTimeout time.Duration
+	Retention       time.Duration
+}
+
+// Dispatch invokes the scheduled request and returns the request id and
+// status of the invocation.
+type Dispatch func(ctx context.Context, s *gateway.Schedule) (string, int, error)
+
+// Scheduler stores entries in a KV bucket shared by the ingestor replicas.
+// Every replica watches the bucket and claims due entries by revision, so
+// each entry is dispatched once even with several replicas polling.
+type Scheduler struct {
+	kv     jetstream.KeyValue
+	opts   Options
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+// entry is what the poll loop needs to know about a stored schedule.
+type entry struct {
+	status    string
+	runAt     time.Time
+	claimedAt time.Time
+	updatedAt time.Time
+}
+
+func NewScheduler(ctx context.Context, js jetstream.JetStream, opts Options) (*Scheduler, error) {
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      gateway.SchedulesBucket,
+		Description: "litefunctions scheduled invocations",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating schedules bucket: %w", err)
+	}
+	return &Scheduler{kv: kv, opts: opts, logger: slog.Default(), entries: map[string]entry{}}, nil
+}
+
+func NewID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Add stores a new entry.
+func (s *Scheduler) Add(ctx context.Context, sch *gateway.Schedule) error {
+	now := time.Now().UTC()
+	sch.Status = gateway.ScheduleScheduled
+	sch.CreatedAt, sch.UpdatedAt = now, now
+	data, err := json.Marshal(sch)
+	if err != nil {
+		return err
+	}
+	if _, err := s.kv.Create(ctx, gateway.ScheduleKey(sch.Project, sch.Function, sch.ID), data); err != nil {
+		return fmt.Errorf("error storing scheduled invocation: %w", err)
+	}
+	return nil
+}
+
+func (s *Scheduler) Get(ctx context.Context, project, function, id string) (*gateway.Schedule, error) {
+	sch, _, err := s.get(ctx, gateway.ScheduleKey(project, function, id))
+	return sch, err
+}
+
+// Cancel stops an entry that hasn't been dispatched yet. A failed write is
+// retried since it usually means a replica touched the entry meanwhile.
+func (s *Scheduler) Cancel(ctx context.Context, project, function, id string) (*gateway.Schedule, error) {
+	key := gateway.ScheduleKey(project, function, id)
+	var err error
+	for range 3 {
+		var sch *gateway.Schedule
+		var rev uint64
+		if sch, rev, err = s.get(ctx, key); err != nil {
+			return nil, err
+		}
+		if sch.Status != gateway.ScheduleScheduled {
+			return sch, ErrNotPending
+		}
+		sch.Status = gateway.ScheduleCancelled
+		if err = s.update(ctx, key, sch, rev); err == nil {
+			return sch, nil
+		}
+	}
+	return nil, fmt.Errorf("error cancelling scheduled invocation: %w", err)
+}
+
+// Run watches the bucket and dispatches due entries until ctx is done.
+func (s *Scheduler) Run(ctx context.Context, dispatch Dispatch) error {
+	watcher, err := s.kv.WatchAll(ctx)
+	if err != nil {
+		return fmt.Errorf("error watching schedules: %w", err)
+	}
+	defer watcher.Stop()
+
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case update, ok := <-watcher.Updates():
+			if !ok {
+				return errors.New("schedules watcher stopped")
+			}
+			if update != nil {
+				s.track(update)
+			}
+		case <-ticker.C:
+			s.poll(ctx, dispatch)
+		}
+	}
+}
+
+func (s *Scheduler) track(update jetstream.KeyValueEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if update.Operation() != jetstream.KeyValuePut {
+		delete(s.entries, update.Key())
+		return
+	}
+	var sch gateway.Schedule
+	if err := json.Unmarshal(update.Value(), &sch); err != nil {
+		s.logger.Warn("ignoring invalid scheduled invocation", "key", update.Key(), "error", err)
+		delete(s.entries, update.Key())
+		return
+	}
+	s.entries[update.Key()] = entry{status: sch.Status, runAt: sch.RunAt, claimedAt: sch.ClaimedAt, updatedAt: sch.UpdatedAt}
+}
+
+func (s *Scheduler) poll(ctx context.Context, dispatch Dispatch) {
+	now := time.Now()
+	var due, expired []string
+	s.mu.Lock()
+	for key, e := range s.entries {
+		switch e.status {
+		case gateway.ScheduleScheduled:
+			if !now.Before(e.runAt) {
+				due = append(due, key)
+			}
+		case gateway.ScheduleDispatching:
+			if now.Sub(e.claimedAt) > 2*s.opts.DispatchTimeout {
+				due = append(due, key)
+			}
+		default:
+			if now.Sub(e.updatedAt) > s.opts.Retention {
+				expired = append(expired, key)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	for _, key := range due {
+		sch, ok := s.claim(ctx, key)
+		if ok {
+			go s.dispatch(sch, dispatch)
+		}
+	}
+	for _, key := range expired {
+		if err := s.kv.Delete(ctx, key); err != nil && !errors.Is(err, jetstream.ErrKeyNotFound) {
+			s.logger.Warn("failed to remove finished schedule", "key", key, "error", err)
+		}
+	}
+}
+
+// claim marks a due entry as dispatching. It fails when another replica
+// changed the entry first.
+func (s *Scheduler) claim(ctx context.Context, key string) (*gateway.Schedule, bool) {
+	sch, rev, err := s.get(ctx, key)
+	if err != nil {
+		return nil, false
+	}
+	now := time.Now().UTC()
+	switch sch.Status {
+	case gateway.ScheduleScheduled:
+		if now.Before(sch.RunAt) {
+			return nil, false
+		}
+	case gateway.ScheduleDispatching:
+		if now.Sub(sch.ClaimedAt) <= 2*s.opts.DispatchTimeout {
+			return nil, false
+		}
+		s.logger.Warn("taking over stale schedule claim", "key", key, "claimed_at", sch.ClaimedAt)
+	default:
+		return nil, false
+	}
+	sch.Status = gateway.ScheduleDispatching
+	sch.ClaimedAt = now
+	if err := s.update(ctx, key, sch, rev); err != nil {
+		return nil, false
+	}
+	return sch, true
+}
+
+func (s *Scheduler) dispatch(sch *gateway.Schedule, dispatch Dispatch) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.DispatchTimeout)
+	defer cancel()
+	reqID, status, err := dispatch(ctx, sch)
+	sch.RequestID, sch.ResponseStatus = reqID, status
+	sch.Status = gateway.ScheduleDispatched
+	if err != nil {
+		sch.Status = gateway.ScheduleFailed
+		sch.Error = err.Error()
+	}
+	s.logger.Info("scheduled invocation dispatched", "project", sch.Project, "name", sch.Function,
+		"schedule_id", sch.ID, "request_id", reqID, "status", status, "late_ms", time.Since(sch.RunAt).Milliseconds())
+
+	// the body isn't needed anymore
+	sch.Body = nil
+	sch.UpdatedAt = time.Now().UTC()
+	data, err := json.Marshal(sch)
+	if err != nil {
+		return
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := s.kv.Put(ctx, gateway.ScheduleKey(sch.Project, sch.Function, sch.ID), data); err != nil {
+		s.logger.Warn("failed to record scheduled invocation", "schedule_id", sch.ID, "error", err)
+	}
+}
+
+func (s *Scheduler) get(ctx context.Context, key string) (*gateway.Schedule, uint64, error) {
+	e, err := s.kv.Get(ctx, key)
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return nil, 0, ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	var sch gateway.Schedule
+	if err := json.Unmarshal(e.Value(), &sch); err != nil {
+		return nil, 0, err
+	}
+	return &sch, e.Revision(), nil
+}
+
+// update writes sch only if the entry is still at rev.
+func (s *Scheduler) update(ctx context.Context, key string, sch *gateway.Schedule, rev uint64) error {
+	sch.UpdatedAt = time.Now().UTC()
+	data, err := json.Marshal(sch)
+	if err != nil {
+		return err
+	}
+	_, err = s.kv.Update(ctx, key, data, rev)
+	return err
+}
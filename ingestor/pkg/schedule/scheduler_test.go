@@ -0,0 +1,163 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// newTestSchedulers starts a NATS server and returns n schedulers sharing its
+// bucket, like ingestor replicas do.
+func newTestSchedulers(t *testing.T, n int, opts Options) []*Scheduler {
+	t.Helper()
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoSigs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns.Start()
+	t.Cleanup(ns.Shutdown)
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server did not start")
+	}
+	var schedulers []*Scheduler
+	for range n {
+		nc, err := nats.Connect(ns.ClientURL())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(nc.Close)
+		js, err := jetstream.New(nc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := NewScheduler(context.Background(), js, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schedulers = append(schedulers, s)
+	}
+	return schedulers
+}
+
+func addEntry(t *testing.T, s *Scheduler, runAt time.Time) *gateway.Schedule {
+	t.Helper()
+	sch := &gateway.Schedule{ID: NewID(), Project: "shop", Function: "orders", Method: "POST", Path: "/lambda/shop/orders", RunAt: runAt}
+	if err := s.Add(context.Background(), sch); err != nil {
+		t.Fatal(err)
+	}
+	return sch
+}
+
+func TestClaim(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSchedulers(t, 2, Options{DispatchTimeout: 50 * time.Millisecond})
+	a, b := s[0], s[1]
+
+	later := addEntry(t, a, time.Now().Add(time.Hour))
+	if _, ok := a.claim(ctx, gateway.ScheduleKey("shop", "orders", later.ID)); ok {
+		t.Error("claimed an entry that isn't due")
+	}
+
+	due := addEntry(t, a, time.Now().Add(-time.Second))
+	key := gateway.ScheduleKey("shop", "orders", due.ID)
+	sch, ok := a.claim(ctx, key)
+	if !ok || sch.Status != gateway.ScheduleDispatching {
+		t.Fatalf("claim = %v, %+v", ok, sch)
+	}
+	if _, ok := b.claim(ctx, key); ok {
+		t.Error("a second replica claimed an entry being dispatched")
+	}
+
+	// the first replica died mid-dispatch
+	time.Sleep(120 * time.Millisecond)
+	taken, ok := b.claim(ctx, key)
+	if !ok || !taken.ClaimedAt.After(sch.ClaimedAt) {
+		t.Fatalf("stale claim not taken over: %v, %+v", ok, taken)
+	}
+	if _, ok := a.claim(ctx, key); ok {
+		t.Error("claim taken over twice")
+	}
+}
+
+func TestCancel(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSchedulers(t, 1, Options{DispatchTimeout: time.Minute})[0]
+
+	sch := addEntry(t, s, time.Now().Add(-time.Second))
+	cancelled, err := s.Cancel(ctx, "shop", "orders", sch.ID)
+	if err != nil || cancelled.Status != gateway.ScheduleCancelled {
+		t.Fatalf("cancel = %v, %+v", err, cancelled)
+	}
+	if _, ok := s.claim(ctx, gateway.ScheduleKey("shop", "orders", sch.ID)); ok {
+		t.Error("claimed a cancelled entry")
+	}
+	if _, err := s.Cancel(ctx, "shop", "orders", sch.ID); !errors.Is(err, ErrNotPending) {
+		t.Errorf("second cancel = %v", err)
+	}
+
+	claimed := addEntry(t, s, time.Now().Add(-time.Second))
+	if _, ok := s.claim(ctx, gateway.ScheduleKey("shop", "orders", claimed.ID)); !ok {
+		t.Fatal("claim failed")
+	}
+	if _, err := s.Cancel(ctx, "shop", "orders", claimed.ID); !errors.Is(err, ErrNotPending) {
+		t.Errorf("cancel while dispatching = %v", err)
+	}
+	if _, err := s.Cancel(ctx, "shop", "orders", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("cancel of a missing entry = %v", err)
+	}
+}
+
+func TestRunDispatchesOnce(t *testing.T) {
+	opts := Options{PollInterval: 10 * time.Millisecond, DispatchTimeout: time.Minute, Retention: time.Hour}
+	s := newTestSchedulers(t, 3, opts)
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+	dispatch := func(ctx context.Context, sch *gateway.Schedule) (string, int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[sch.ID]++
+		return "req-" + sch.ID, 202, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, replica := range s {
+		go replica.Run(ctx, dispatch)
+	}
+
+	var ids []string
+	for range 5 {
+		ids = append(ids, addEntry(t, s[0], time.Now().Add(50*time.Millisecond)).ID)
+	}
+	for _, id := range ids {
+		var sch *gateway.Schedule
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			var err error
+			if sch, err = s[1].Get(context.Background(), "shop", "orders", id); err != nil {
+				t.Fatal(err)
+			}
+			if sch.Status == gateway.ScheduleDispatched {
+				break
+			}
+		}
+		if sch.Status != gateway.ScheduleDispatched || sch.RequestID != "req-"+id || sch.ResponseStatus != 202 {
+			t.Errorf("entry %s = %+v", id, sch)
+		}
+	}
+	// give the other replicas a few more polls
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	for _, id := range ids {
+		if calls[id] != 1 {
+			t.Errorf("entry %s dispatched %d times", id, calls[id])
+		}
+	}
+}
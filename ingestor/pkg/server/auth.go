@@ -0,0 +1,152 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/broker"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/jwtauth"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+	"github.com/nats-io/nats.go"
+)
+
+// authenticate enforces the endpoint scope before the function is activated,
+// so rejected callers never wake a runtime. Functions the portal has not
+// published any endpoint for are treated as public; once it has, methods
+// without an endpoint are refused rather than let through unauthenticated.
+func (h *IngestHandler) authenticate(w http.ResponseWriter, r *http.Request, project, name string) bool {
+	for k := range r.Header {
+		if strings.HasPrefix(k, broker.EnvelopePrefix) {
+			r.Header.Del(k)
+		}
+	}
+
+	ep, ok := h.server.endpoints.Get(gateway.EndpointKey(project, name, r.Method))
+	if !ok && isPreflight(r) {
+		// Preflights carry no credentials, so they can't be forwarded to a
+		// function that may not check the method. Endpoints with CORS were
+		// answered already, the rest get no grant.
+		if allowed := h.publishedMethods(project, name); slices.Contains(allowed, strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))) {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			w.WriteHeader(http.StatusNoContent)
+			return false
+		}
+	}
+	if !ok {
+		if allowed := h.publishedMethods(project, name); len(allowed) > 0 {
+			h.refuseMethod(w, r, allowed, project, name)
+			return false
+		}
+		return true
+	}
+	switch ep.Scope {
+	case gateway.ScopeAuthn:
+		return h.authenticateApiKey(w, r, ep, project, name)
+	case gateway.ScopeJWT:
+		return h.authenticateJWT(w, r, ep, project, name)
+	default:
+		return true
+	}
+}
+
+func (h *IngestHandler) authenticateApiKey(w http.ResponseWriter, r *http.Request, ep *gateway.Endpoint, project, name string) bool {
+	key := strings.TrimSpace(r.Header.Get(gateway.ApiKeyHeader))
+	r.Header.Del(gateway.ApiKeyHeader)
+	if key == "" {
+		h.logger.Warn("missing api key", "project", project, "name", name)
+		problem.Write(w, http.StatusUnauthorized, problem.Unauthorized, "api key required")
+		return false
+	}
+	spec, ok := h.server.apiKeys.Get(gateway.HashApiKey(key))
+	if !ok || spec.Expired(time.Now()) {
+		h.logger.Warn("invalid api key", "project", project, "name", name)
+		problem.Write(w, http.StatusUnauthorized, problem.Unauthorized, "invalid api key")
+		return false
+	}
+	if !spec.Allows(ep) {
+		h.logger.Warn("api key not allowed for endpoint", "project", project, "name", name, "key_id", spec.ID)
+		problem.Write(w, http.StatusForbidden, problem.Forbidden, "api key is not allowed for this endpoint")
+		return false
+	}
+
+	r.Header.Set(gateway.ApiKeyIDHeader, spec.ID)
+	h.server.usage.report(spec.ID)
+	return true
+}
+
+// authenticateJWT verifies the bearer token against the endpoint's JWKS and
+// passes the subject and claims on to the function.
+func (h *IngestHandler) authenticateJWT(w http.ResponseWriter, r *http.Request, ep *gateway.Endpoint, project, name string) bool {
+	token := jwtauth.BearerToken(r)
+	if token == "" {
+		h.logger.Warn("missing bearer token", "project", project, "name", name)
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		problem.Write(w, http.StatusUnauthorized, problem.Unauthorized, "bearer token required")
+		return false
+	}
+	claims, err := h.server.jwt.Verify(r.Context(), ep.JWT, token)
+	if errors.Is(err, jwtauth.ErrClaimsNotSatisfied) {
+		h.logger.Warn("bearer token lacks required claims", "project", project, "name", name, "error", err)
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+		problem.Write(w, http.StatusForbidden, problem.Forbidden, "bearer token lacks required claims")
+		return false
+	}
+	if err != nil {
+		h.logger.Warn("bearer token rejected", "project", project, "name", name, "error", err)
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		problem.Write(w, http.StatusUnauthorized, problem.Unauthorized, "invalid bearer token")
+		return false
+	}
+
+	data, err := json.Marshal(claims)
+	if err != nil {
+		h.logger.Error("failed to encode token claims", "project", project, "name", name, "error", err)
+		problem.Write(w, http.StatusInternalServerError, problem.Internal, "could not pass the token claims on")
+		return false
+	}
+	if sub, err := claims.GetSubject(); err == nil && sub != "" {
+		r.Header.Set(gateway.SubjectHeader, sub)
+	}
+	r.Header.Set(gateway.ClaimsHeader, base64.RawURLEncoding.EncodeToString(data))
+	return true
+}
+
+// usageReporter tells the portal when keys are used, at most once per
+// interval per key, so last-used timestamps stay cheap to maintain.
+type usageReporter struct {
+	nc       *nats.Conn
+	interval time.Duration
+	mu       sync.Mutex
+	last     map[string]time.Time
+}
+
+func newUsageReporter(nc *nats.Conn) *usageReporter {
+	return &usageReporter{nc: nc, interval: time.Minute, last: map[string]time.Time{}}
+}
+
+func (u *usageReporter) report(id string) {
+	now := time.Now().UTC()
+	u.mu.Lock()
+	if now.Sub(u.last[id]) < u.interval {
+		u.mu.Unlock()
+		return
+	}
+	u.last[id] = now
+	u.mu.Unlock()
+
+	data, err := json.Marshal(gateway.ApiKeyUsage{ID: id, UsedAt: now})
+	if err != nil {
+		return
+	}
+	if err := u.nc.Publish(gateway.ApiKeyUsedSubject, data); err != nil {
+		slog.Warn("failed to report api key usage", "key_id", id, "error", err)
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/registry"
+)
+
+func TestAuthenticateFailsClosed(t *testing.T) {
+	key := "lf_test"
+	h := &IngestHandler{logger: slog.Default(), server: &Server{
+		endpoints: registry.Of(gateway.EndpointsBucket, map[string]*gateway.Endpoint{
+			gateway.EndpointKey("shop", "orders", "GET"): {ID: "ep1", Project: "shop", Scope: gateway.ScopeAuthn},
+			gateway.EndpointKey("shop", "report", "GET"): {ID: "ep2", Project: "shop", Function: "report", Name: "/shop/report", Method: "GET", Scope: gateway.ScopePublic},
+		}),
+		apiKeys: registry.Of(gateway.ApiKeysBucket, map[string]*gateway.ApiKey{
+			gateway.HashApiKey(key): {ID: "k1", Project: "shop"},
+		}),
+		usage: newUsageReporter(nil),
+	}}
+	h.server.usage.last["k1"] = time.Now().UTC()
+
+	cases := []struct {
+		name    string
+		method  string
+		target  string
+		apiKey  string
+		ok      bool
+		status  int
+		allowed string
+	}{
+		{"key on the published method", "GET", "orders", key, true, 0, ""},
+		{"no key on the published method", "GET", "orders", "", false, http.StatusUnauthorized, ""},
+		{"unpublished method", "POST", "orders", key, false, http.StatusMethodNotAllowed, "GET"},
+		{"unpublished method without key", "DELETE", "orders", "", false, http.StatusMethodNotAllowed, "GET"},
+		{"function without endpoints", "POST", "legacy", "", true, 0, ""},
+		{"function with only the automatic endpoint", "POST", "report", "", true, 0, ""},
+		{"preflight for the published method", "OPTIONS", "orders", "", false, http.StatusNoContent, "GET"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "/", nil)
+			if tc.method == http.MethodOptions {
+				r.Header.Set("Origin", "https://app.example")
+				r.Header.Set("Access-Control-Request-Method", "GET")
+			}
+			if tc.apiKey != "" {
+				r.Header.Set(gateway.ApiKeyHeader, tc.apiKey)
+			}
+			w := httptest.NewRecorder()
+			ok := h.authenticate(w, r, "shop", tc.target)
+			if ok != tc.ok || (!ok && w.Code != tc.status) {
+				t.Fatalf("authenticate = %v, status %d, want %v, %d", ok, w.Code, tc.ok, tc.status)
+			}
+			if got := w.Header().Get("Allow"); got != tc.allowed {
+				t.Errorf("Allow = %q, want %q", got, tc.allowed)
+			}
+		})
+	}
+}
+
+func TestValidateMethod(t *testing.T) {
+	h := &IngestHandler{logger: slog.Default(), server: &Server{
+		endpoints: registry.Of(gateway.EndpointsBucket, map[string]*gateway.Endpoint{
+			gateway.EndpointKey("shop", "orders", "GET"):  {},
+			gateway.EndpointKey("shop", "orders", "POST"): {},
+		}),
+	}}
+	cases := []struct {
+		name     string
+		method   string
+		target   string
+		expected string
+		ok       bool
+	}{
+		{"published endpoint wins over the function's method", "POST", "orders", "GET", true},
+		{"unpublished method", "PUT", "orders", "", false},
+		{"function method without endpoints", "POST", "legacy", "GET", false},
+		{"no method recorded", "DELETE", "legacy", "", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "/", nil)
+			if ok := h.validateMethod(w, r, tc.expected, "shop", tc.target); ok != tc.ok {
+				t.Fatalf("validateMethod = %v, status %d", ok, w.Code)
+			}
+		})
+	}
+}
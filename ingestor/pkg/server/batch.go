@@ -0,0 +1,227 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/batch"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/broker"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+)
+
+// maxBatchBody bounds the body of a batch submission.
+const maxBatchBody = 32 << 20
+
+// Batch accepts a JSON array, or NDJSON with Content-Type
+// application/x-ndjson, and runs every element through the function in the
+// background. It answers 202 with the batch ID.
+func (h *IngestHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	if h.server.batches == nil {
+		problem.Write(w, http.StatusServiceUnavailable, problem.FeatureDisabled, "batches are not enabled")
+		return
+	}
+	h.pipeline(middleware.Batch, h.startBatch)(w, r)
+}
+
+func (h *IngestHandler) startBatch(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+	project, name := c.Project, c.Function
+
+	items, err := readBatch(w, r)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.Write(w, http.StatusRequestEntityTooLarge, problem.PayloadTooLarge, err.Error())
+			return
+		}
+		problem.Write(w, http.StatusBadRequest, problem.BadRequest, err.Error())
+		return
+	}
+	if len(items) == 0 {
+		problem.Write(w, http.StatusBadRequest, problem.BadRequest, "batch has no items")
+		return
+	}
+	if len(items) > pkg.Settings.BatchMaxItems {
+		problem.Write(w, http.StatusRequestEntityTooLarge, problem.PayloadTooLarge, fmt.Sprintf("batch exceeds %d items", pkg.Settings.BatchMaxItems))
+		return
+	}
+	// batches are bulk work, so they run in the low lane unless asked
+	priority, err := requestedPriority(r)
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, problem.BadRequest, err.Error())
+		return
+	}
+	if priority == "" {
+		priority = gateway.PriorityLow
+	}
+	if !h.consumeQuota(w, r, project, name, int64(len(items))) {
+		return
+	}
+	requested, _ := strconv.Atoi(r.URL.Query().Get("parallelism"))
+
+	b := &gateway.Batch{
+		ID:          batch.NewID(),
+		Project:     project,
+		Function:    name,
+		Total:       len(items),
+		Parallelism: h.server.batches.Parallelism(requested),
+		Priority:    priority,
+	}
+	header := r.Header.Clone()
+	if err := h.server.batches.Start(r.Context(), b, h.batchItem(project, name, priority, header, items)); err != nil {
+		h.logger.Error("failed to start batch", "project", project, "name", name, "error", err)
+		problem.Write(w, http.StatusInternalServerError, problem.Internal, "could not start the batch")
+		return
+	}
+	h.logger.Info("batch accepted", "project", project, "name", name, "batch_id", b.ID, "items", b.Total)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/batch/"+project+"/"+name+"/"+b.ID)
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id":          b.ID,
+		"total":       b.Total,
+		"parallelism": b.Parallelism,
+		"priority":    b.Priority,
+		"status_url":  "/batch/" + project + "/" + name + "/" + b.ID,
+	})
+}
+
+// batchItem runs one element the way dispatch runs a request: schema
+// validation, the method check and request transforms apply to each item on
+// its own. Async functions get the item over the project stream, sync ones
+// answer it in place over their service or NATS.
+func (h *IngestHandler) batchItem(project, name, priority string, header http.Header, items [][]byte) batch.Submit {
+	return func(ctx context.Context, index int) (string, func(time.Duration) ([]byte, error), error) {
+		r, err := http.NewRequestWithContext(ctx, http.MethodPost, "/lambda/"+project+"/"+name, bytes.NewReader(items[index]))
+		if err != nil {
+			return "", nil, err
+		}
+		r.Header = header.Clone()
+		r.Header.Set("Content-Type", "application/json")
+		r.ContentLength = int64(len(items[index]))
+		// batches can outlive the token signed for the submitting request
+		if err := h.issueCallToken(r, project, name); err != nil {
+			return "", nil, err
+		}
+
+		rejected := &bufferedResponse{header: http.Header{}}
+		if !h.validateRequest(rejected, r, project, name) {
+			return "", nil, errors.New(problem.Message(rejected.header, rejected.body.Bytes()))
+		}
+		info, ok := h.activate(rejected, r, project, name)
+		if !ok {
+			return "", nil, errors.New(problem.Message(rejected.header, rejected.body.Bytes()))
+		}
+		if r, err = h.endpointTransform(r, project, name); err != nil {
+			return "", nil, err
+		}
+		if !info.IsAsync {
+			return "", func(time.Duration) ([]byte, error) {
+				w := &bufferedResponse{header: http.Header{}}
+				h.invoke(w, r, info, project, name, "")
+				if w.statusCode() >= http.StatusBadRequest {
+					return nil, errors.New(problem.Message(w.header, w.body.Bytes()))
+				}
+				return w.body.Bytes(), nil
+			}, nil
+		}
+
+		req := broker.NewReq(r, info.Language)
+		req.Priority = priority
+		pending, err := broker.Expect(h.server.nc, h.server.payloads, req)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, err := broker.Submit(h.server.nc, h.server.payloads, r, req); err != nil {
+			pending.Cancel()
+			return "", nil, err
+		}
+		return req.ReqId, pending.Wait, nil
+	}
+}
+
+// BatchStatus reports progress and a page of item results, selected with
+// ?offset= and ?limit=. Callers need the credentials the batch was submitted
+// with.
+func (h *IngestHandler) BatchStatus(w http.ResponseWriter, r *http.Request) {
+	project, name, id := r.PathValue("project"), r.PathValue("name"), r.PathValue("id")
+	if h.server.batches == nil {
+		problem.Write(w, http.StatusServiceUnavailable, problem.FeatureDisabled, "batches are not enabled")
+		return
+	}
+	// the endpoint is configured for the POST that submitted the batch
+	auth := r.Clone(r.Context())
+	auth.Method = http.MethodPost
+	if !h.authenticate(w, auth, project, name) || !h.authorize(w, auth, project, name) {
+		return
+	}
+
+	b, err := h.server.batches.Get(r.Context(), project, name, id)
+	if errors.Is(err, batch.ErrNotFound) {
+		problem.Write(w, http.StatusNotFound, problem.NotFound, "batch not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to read batch", "project", project, "name", name, "batch_id", id, "error", err)
+		problem.Write(w, http.StatusInternalServerError, problem.Internal, "could not read the batch")
+		return
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	items, err := h.server.batches.Items(r.Context(), b, max(offset, 0), min(limit, 1000))
+	if err != nil {
+		h.logger.Error("failed to read batch items", "project", project, "name", name, "batch_id", id, "error", err)
+		problem.Write(w, http.StatusInternalServerError, problem.Internal, "could not read the batch items")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		*gateway.Batch
+		Items []gateway.BatchItem `json:"items"`
+	}{b, items})
+}
+
+// readBatch splits the body into item payloads.
+func readBatch(w http.ResponseWriter, r *http.Request) ([][]byte, error) {
+	body := http.MaxBytesReader(w, r.Body, maxBatchBody)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-ndjson" {
+		var items [][]byte
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 64<<10), maxBatchBody)
+		for scanner.Scan() {
+			if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+				items = append(items, append([]byte(nil), line...))
+			}
+		}
+		return items, scanner.Err()
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, errors.New("batch must be a JSON array or NDJSON")
+	}
+	items := make([][]byte, len(raw))
+	for i, item := range raw {
+		items[i] = item
+	}
+	return items, nil
+}
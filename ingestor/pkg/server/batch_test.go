@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/common/proto"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/registry"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/upstream"
+	"google.golang.org/grpc"
+)
+
+func TestReadBatch(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		want        []string
+	}{
+		{"array", "application/json", `[{"id":1}, "two", 3]`, []string{`{"id":1}`, `"two"`, `3`}},
+		{"ndjson", "application/x-ndjson", "{\"id\":1}\n\n  {\"id\":2}  \n", []string{`{"id":1}`, `{"id":2}`}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/batch/shop/orders", strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", tc.contentType)
+			items, err := readBatch(httptest.NewRecorder(), r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(items) != len(tc.want) {
+				t.Fatalf("got %d items, want %d", len(items), len(tc.want))
+			}
+			for i := range items {
+				if string(items[i]) != tc.want[i] {
+					t.Errorf("item %d = %s, want %s", i, items[i], tc.want[i])
+				}
+			}
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/batch/shop/orders", strings.NewReader(`{"id":1}`))
+	if _, err := readBatch(httptest.NewRecorder(), r); err == nil {
+		t.Error("expected an error for a body that is not an array")
+	}
+}
+
+// fakeActivator answers activations with a fixed response.
+type fakeActivator struct {
+	proto.FunctionServiceClient
+	info *proto.ActivateResponse
+}
+
+func (f *fakeActivator) Activate(context.Context, *proto.ActivateRequest, ...grpc.CallOption) (*proto.ActivateResponse, error) {
+	return f.info, nil
+}
+
+func TestBatchItemDispatch(t *testing.T) {
+	runtime := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(append([]byte("echo "), body...))
+	}))
+	defer runtime.Close()
+	host, port, _ := net.SplitHostPort(runtime.Listener.Addr().String())
+	servicePort, _ := strconv.Atoi(port)
+	pkg.Settings = &pkg.IngestorConf{RuntimeHost: host}
+
+	activator := &fakeActivator{}
+	h := &IngestHandler{logger: slog.Default(), server: &Server{
+		grpcClient: activator,
+		upstream:   upstream.NewClient(upstream.Options{BreakerThreshold: 0.5, BreakerMinRequests: 20, BreakerWindow: time.Minute, BreakerCooldown: time.Second, DialTimeout: time.Second}),
+		endpoints:  registry.Of(gateway.EndpointsBucket, map[string]*gateway.Endpoint{}),
+	}}
+	submit := h.batchItem("shop", "orders", gateway.PriorityLow, http.Header{}, [][]byte{[]byte(`{"id":1}`)})
+
+	// sync functions with a service answer the item over it
+	activator.info = &proto.ActivateResponse{Method: "POST", ServiceName: "orders", ServicePort: int32(servicePort)}
+	_, wait, err := submit(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := wait(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result) != `echo {"id":1}` {
+		t.Errorf("result = %s", result)
+	}
+
+	// items are POSTs, a function deployed for another method refuses them
+	activator.info = &proto.ActivateResponse{Method: "GET", ServiceName: "orders", ServicePort: int32(servicePort)}
+	if _, _, err := submit(context.Background(), 0); err == nil {
+		t.Error("item sent to a GET function")
+	}
+}
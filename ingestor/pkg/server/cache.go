@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/cache"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/idempotency"
+)
+
+// withCache serves GET requests from the shared response cache when the
+// endpoint enables it, so hits never activate the function. Misses run next
+// and are stored when the runtime response allows it.
+func (h *IngestHandler) withCache(w http.ResponseWriter, r *http.Request, project, name string, next func(http.ResponseWriter)) {
+	ep, ok := h.server.endpoints.Get(gateway.EndpointKey(project, name, r.Method))
+	if h.server.cache == nil || r.Method != http.MethodGet || !ok || ep.Cache == nil || ep.Cache.TTL <= 0 {
+		next(w)
+		return
+	}
+
+	key := cache.Key(ep.Cache, r, project, name, []string{r.Header.Get(gateway.SubjectHeader), r.Header.Get(gateway.ApiKeyIDHeader)})
+	if !strings.Contains(strings.ToLower(r.Header.Get("Cache-Control")), "no-cache") {
+		entry, err := h.server.cache.Get(r.Context(), key)
+		if err != nil {
+			h.logger.Warn("failed to read response cache", "project", project, "name", name, "error", err)
+		}
+		if entry != nil {
+			h.logger.Info("serving cached response", "project", project, "name", name)
+			entry.Replay(w)
+			return
+		}
+	}
+
+	w.Header().Set(cache.StatusHeader, "MISS")
+	rw := idempotency.NewRecorder(w)
+	next(rw)
+
+	ttl := h.server.cache.TTL(ep.Cache, rw.Status(), rw.Header())
+	if ttl == 0 {
+		return
+	}
+	header := rw.Header().Clone()
+	header.Del(cache.StatusHeader)
+	header.Del(FaultHeader)
+	header.Del(gateway.RequestIDHeader)
+	for k := range header {
+		// CORS headers depend on the caller's origin and are applied per request
+		if strings.HasPrefix(k, "Access-Control-") {
+			header.Del(k)
+		}
+	}
+	now := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.server.cache.Put(ctx, key, cache.Entry{
+		Status:    rw.Status(),
+		Header:    header,
+		Body:      rw.Body(),
+		StoredAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}); err != nil {
+		h.logger.Warn("failed to store response in cache", "project", project, "name", name, "error", err)
+	}
+}
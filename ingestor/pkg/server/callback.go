@@ -0,0 +1,42 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/callback"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+)
+
+// callbackTarget resolves where the result of an async invocation is posted.
+// A caller supplied X-Callback-Url wins when the endpoint allows it. It
+// returns false after writing a 400 for an unusable caller url.
+func (h *IngestHandler) callbackTarget(w http.ResponseWriter, r *http.Request, project, name string) (*callback.Target, bool) {
+	requested := strings.TrimSpace(r.Header.Get(callback.URLHeader))
+	ep, ok := h.server.endpoints.Get(gateway.EndpointKey(project, name, r.Method))
+	if h.server.callbacks == nil || !ok || ep.Callback == nil {
+		if requested != "" {
+			problem.Write(w, http.StatusBadRequest, problem.BadRequest, "callbacks are not enabled for this endpoint")
+			return nil, false
+		}
+		return nil, true
+	}
+
+	target := &callback.Target{URL: ep.Callback.URL, Secret: ep.Callback.Secret}
+	if requested != "" {
+		if !ep.Callback.AllowCallerURL {
+			problem.Write(w, http.StatusBadRequest, problem.BadRequest, "caller supplied callbacks are not allowed for this endpoint")
+			return nil, false
+		}
+		if err := callback.ValidateURL(requested); err != nil {
+			problem.Write(w, http.StatusBadRequest, problem.BadRequest, err.Error())
+			return nil, false
+		}
+		target.URL = requested
+	}
+	if target.URL == "" {
+		return nil, true
+	}
+	return target, true
+}
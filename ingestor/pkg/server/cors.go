@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+)
+
+// handleCORS applies the endpoint's CORS configuration. Preflight requests are
+// answered here without activating the function, in which case it returns
+// true and the caller must stop. Requests to endpoints without CORS configured
+// are left alone, authenticate answers their preflights without a grant.
+func (h *IngestHandler) handleCORS(w http.ResponseWriter, r *http.Request, project, name string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+
+	preflight := isPreflight(r)
+	method := r.Method
+	if preflight {
+		method = r.Header.Get("Access-Control-Request-Method")
+	}
+	ep, ok := h.server.endpoints.Get(gateway.EndpointKey(project, name, method))
+	if !ok || ep.CORS == nil {
+		return false
+	}
+	cfg := ep.CORS
+
+	if !cfg.AllowsOrigin(origin) {
+		if preflight {
+			h.logger.Warn("cors origin not allowed", "project", project, "name", name, "origin", origin)
+			problem.Write(w, http.StatusForbidden, problem.Forbidden, "origin not allowed")
+			return true
+		}
+		return false
+	}
+
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	if slices.Contains(cfg.AllowedOrigins, "*") && !cfg.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if cfg.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if !preflight {
+		if len(cfg.ExposedHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(cfg.ExposedHeaders, ", "))
+		}
+		return false
+	}
+
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{ep.Method}
+	}
+	header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+		if slices.Contains(cfg.AllowedHeaders, "*") {
+			header.Set("Access-Control-Allow-Headers", requested)
+		} else if len(cfg.AllowedHeaders) > 0 {
+			header.Set("Access-Control-Allow-Headers", strings.Join(cfg.AllowedHeaders, ", "))
+		}
+	}
+	if cfg.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+// isPreflight reports whether r is a CORS preflight, which browsers send
+// without credentials ahead of the actual request.
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != ""
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/registry"
+)
+
+func TestAllowsOrigin(t *testing.T) {
+	cfg := &gateway.CORSConfig{AllowedOrigins: []string{"https://app.example.com", "https://*.shop.test"}}
+	cases := map[string]bool{
+		"https://app.example.com":      true,
+		"https://APP.example.com":      true,
+		"https://eu.shop.test":         true,
+		"https://a.b.shop.test":        true,
+		"https://shop.test":            false,
+		"http://eu.shop.test":          false,
+		"https://evilshop.test":        false,
+		"https://app.example.com.evil": false,
+	}
+	for origin, want := range cases {
+		if got := cfg.AllowsOrigin(origin); got != want {
+			t.Errorf("AllowsOrigin(%q) = %v, want %v", origin, got, want)
+		}
+	}
+	if !(&gateway.CORSConfig{AllowedOrigins: []string{"*"}}).AllowsOrigin("https://anything.test") {
+		t.Error("* does not allow every origin")
+	}
+}
+
+func TestHandleCORS(t *testing.T) {
+	h := &IngestHandler{logger: slog.Default(), server: &Server{
+		endpoints: registry.Of(gateway.EndpointsBucket, map[string]*gateway.Endpoint{
+			gateway.EndpointKey("shop", "orders", "POST"): {Method: "POST", CORS: &gateway.CORSConfig{
+				AllowedOrigins:   []string{"https://app.example.com"},
+				AllowedHeaders:   []string{"Content-Type"},
+				ExposedHeaders:   []string{"X-Request-Id"},
+				AllowCredentials: true,
+				MaxAge:           600,
+			}},
+			gateway.EndpointKey("shop", "catalog", "GET"): {Method: "GET", CORS: &gateway.CORSConfig{
+				AllowedOrigins: []string{"*"},
+				AllowedHeaders: []string{"*"},
+			}},
+			gateway.EndpointKey("shop", "legacy", "GET"): {Method: "GET"},
+		}),
+	}}
+
+	cases := []struct {
+		name    string
+		method  string
+		target  string
+		origin  string
+		request string
+		headers string
+		stop    bool
+		status  int
+		want    map[string]string
+	}{
+		{
+			name: "preflight", method: "OPTIONS", target: "orders", origin: "https://app.example.com", request: "POST", headers: "content-type",
+			stop: true, status: http.StatusNoContent,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Allow-Methods":     "POST",
+				"Access-Control-Allow-Headers":     "Content-Type",
+				"Access-Control-Max-Age":           "600",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name: "preflight from another origin", method: "OPTIONS", target: "orders", origin: "https://evil.test", request: "POST",
+			stop: true, status: http.StatusForbidden,
+			want: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name: "actual request", method: "POST", target: "orders", origin: "https://app.example.com",
+			want: map[string]string{
+				"Access-Control-Allow-Origin":   "https://app.example.com",
+				"Access-Control-Expose-Headers": "X-Request-Id",
+				"Access-Control-Allow-Methods":  "",
+			},
+		},
+		{
+			name: "actual request from another origin", method: "POST", target: "orders", origin: "https://evil.test",
+			want: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name: "wildcard echoes requested headers", method: "OPTIONS", target: "catalog", origin: "https://any.test", request: "GET", headers: "x-trace, authorization",
+			stop: true, status: http.StatusNoContent,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":  "*",
+				"Access-Control-Allow-Headers": "x-trace, authorization",
+				"Access-Control-Allow-Methods": "GET",
+			},
+		},
+		{
+			name: "endpoint without cors is left to the function", method: "OPTIONS", target: "legacy", origin: "https://app.example.com", request: "GET",
+			want: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name: "no origin", method: "POST", target: "orders",
+			want: map[string]string{"Access-Control-Allow-Origin": "", "Vary": ""},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "/", nil)
+			if tc.origin != "" {
+				r.Header.Set("Origin", tc.origin)
+			}
+			if tc.request != "" {
+				r.Header.Set("Access-Control-Request-Method", tc.request)
+			}
+			if tc.headers != "" {
+				r.Header.Set("Access-Control-Request-Headers", tc.headers)
+			}
+			w := httptest.NewRecorder()
+			stop := h.handleCORS(w, r, "shop", tc.target)
+			if stop != tc.stop || (stop && w.Code != tc.status) {
+				t.Fatalf("handleCORS = %v, status %d, want %v, %d", stop, w.Code, tc.stop, tc.status)
+			}
+			for k, v := range tc.want {
+				if got := w.Header().Get(k); got != v {
+					t.Errorf("%s = %q, want %q", k, got, v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+)
+
+// routeHost serves custom domains mapped to a project from the portal. With
+// api.shop.example.com mapped to project shop under /v1, a request for
+// /v1/orders is handled as /lambda/shop/orders, and /v1/sse/orders as
+// /lambda/sse/shop/orders. Hosts without a mapping fall through untouched.
+func (s *Server) routeHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, ok := s.domains.Get(requestHost(r))
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		path, ok := domainPath(*d, r.URL.Path)
+		if !ok {
+			problem.Write(w, http.StatusNotFound, problem.NotFound, "no function is served at this path")
+			return
+		}
+		r.URL.Path = path
+		r.URL.RawPath = ""
+		next.ServeHTTP(w, r)
+	})
+}
+
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// domainPath maps a path on a custom domain onto the /lambda routes. Only
+// the domain's own project is reachable, so /lambda/{other}/... on a custom
+// domain is just a function named "lambda" that doesn't exist.
+func domainPath(d gateway.Domain, path string) (string, bool) {
+	rest := path
+	if base := strings.TrimSuffix(d.BasePath, "/"); base != "" {
+		if rest != base && !strings.HasPrefix(rest, base+"/") {
+			return "", false
+		}
+		rest = strings.TrimPrefix(rest, base)
+	}
+	rest = strings.Trim(rest, "/")
+	mode := ""
+	if m, name, ok := strings.Cut(rest, "/"); ok && (m == "sse" || m == "ws") {
+		mode, rest = m+"/", name
+	}
+	if rest == "" || strings.Contains(rest, "/") {
+		return "", false
+	}
+	return "/lambda/" + mode + d.Project + "/" + rest, true
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+)
+
+func TestDomainPath(t *testing.T) {
+	d := gateway.Domain{Host: "api.shop.example.com", Project: "shop", BasePath: "/v1"}
+	cases := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"/v1/orders", "/lambda/shop/orders", true},
+		{"/v1/sse/orders", "/lambda/sse/shop/orders", true},
+		{"/v1/ws/orders/", "/lambda/ws/shop/orders", true},
+		{"/v2/orders", "", false},
+		{"/v1", "", false},
+		{"/v1/orders/1", "", false},
+	}
+	for _, c := range cases {
+		got, ok := domainPath(d, c.path)
+		if got != c.want || ok != c.ok {
+			t.Errorf("domainPath(%q) = %q, %v; want %q, %v", c.path, got, ok, c.want, c.ok)
+		}
+	}
+	if got, _ := domainPath(gateway.Domain{Project: "shop"}, "/orders"); got != "/lambda/shop/orders" {
+		t.Errorf("domain without base path mapped to %q", got)
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+)
+
+// FaultHeader tells callers which fault was injected into their request.
+const FaultHeader = "X-Litefunction-Fault"
+
+type dropAsyncKey struct{}
+
+// injectFault applies the endpoint's fault configuration to a sampled share
+// of requests. Latency is added before anything else; a forced status ends
+// the request here. Dropped async requests are marked on the returned
+// request and acknowledged without being published.
+func (h *IngestHandler) injectFault(w http.ResponseWriter, r *http.Request, project, name string) (*http.Request, bool) {
+	ep, ok := h.server.endpoints.Get(gateway.EndpointKey(project, name, r.Method))
+	if !ok || ep.Fault == nil || !ep.Fault.Active(time.Now()) {
+		return r, false
+	}
+	f := ep.Fault
+	if f.Header != "" && r.Header.Get(f.Header) == "" {
+		return r, false
+	}
+	if rand.Float64()*100 >= f.Percentage {
+		return r, false
+	}
+
+	if f.DelayMs > 0 {
+		w.Header().Add(FaultHeader, "latency")
+		select {
+		case <-time.After(time.Duration(f.DelayMs) * time.Millisecond):
+		case <-r.Context().Done():
+			return r, true
+		}
+	}
+	if f.ErrorStatus > 0 {
+		h.logger.Info("injecting fault", "project", project, "name", name, "status", f.ErrorStatus)
+		w.Header().Add(FaultHeader, "error")
+		problem.Write(w, f.ErrorStatus, problem.InjectedFault, "injected fault")
+		return r, true
+	}
+	if f.DropAsync {
+		r = r.WithContext(context.WithValue(r.Context(), dropAsyncKey{}, true))
+	}
+	return r, false
+}
+
+func dropAsync(r *http.Request) bool {
+	drop, _ := r.Context().Value(dropAsyncKey{}).(bool)
+	return drop
+}
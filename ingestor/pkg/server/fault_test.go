@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/registry"
+)
+
+func TestInjectFault(t *testing.T) {
+	later := time.Now().Add(time.Hour)
+	earlier := time.Now().Add(-time.Second)
+	endpoints := map[string]*gateway.Endpoint{
+		gateway.EndpointKey("shop", "error", "GET"):   {Fault: &gateway.FaultConfig{Percentage: 100, ErrorStatus: 502, ExpiresAt: later}},
+		gateway.EndpointKey("shop", "expired", "GET"): {Fault: &gateway.FaultConfig{Percentage: 100, ErrorStatus: 502, ExpiresAt: earlier}},
+		gateway.EndpointKey("shop", "unset", "GET"):   {Fault: &gateway.FaultConfig{Percentage: 100, ErrorStatus: 502}},
+		gateway.EndpointKey("shop", "off", "GET"):     {Fault: &gateway.FaultConfig{Percentage: 0, ErrorStatus: 502, ExpiresAt: later}},
+		gateway.EndpointKey("shop", "opt-in", "GET"):  {Fault: &gateway.FaultConfig{Percentage: 100, ErrorStatus: 500, Header: "X-Chaos", ExpiresAt: later}},
+		gateway.EndpointKey("shop", "drop", "GET"):    {Fault: &gateway.FaultConfig{Percentage: 100, DropAsync: true, ExpiresAt: later}},
+		gateway.EndpointKey("shop", "slow", "GET"):    {Fault: &gateway.FaultConfig{Percentage: 100, DelayMs: 20, ErrorStatus: 503, ExpiresAt: later}},
+	}
+	h := &IngestHandler{logger: slog.Default(), server: &Server{endpoints: registry.Of(gateway.EndpointsBucket, endpoints)}}
+
+	cases := []struct {
+		name   string
+		target string
+		header string
+		stop   bool
+		status int
+		drop   bool
+		fault  []string
+	}{
+		{"active fault", "error", "", true, 502, false, []string{"error"}},
+		{"expired fault is ignored", "expired", "", false, 0, false, nil},
+		{"fault without expiry is ignored", "unset", "", false, 0, false, nil},
+		{"zero percentage", "off", "", false, 0, false, nil},
+		{"header gated without the header", "opt-in", "", false, 0, false, nil},
+		{"header gated with the header", "opt-in", "X-Chaos", true, 500, false, []string{"error"}},
+		{"dropped async requests continue marked", "drop", "", false, 0, true, nil},
+		{"latency before the error", "slow", "", true, 503, false, []string{"latency", "error"}},
+		{"no endpoint", "missing", "", false, 0, false, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				r.Header.Set(tc.header, "1")
+			}
+			w := httptest.NewRecorder()
+			r, stop := h.injectFault(w, r, "shop", tc.target)
+			if stop != tc.stop || (stop && w.Code != tc.status) {
+				t.Fatalf("injectFault = %v, status %d, want %v, %d", stop, w.Code, tc.stop, tc.status)
+			}
+			if dropAsync(r) != tc.drop {
+				t.Errorf("dropAsync = %v", dropAsync(r))
+			}
+			if got := w.Header().Values(FaultHeader); len(got) != len(tc.fault) || (len(got) > 0 && got[len(got)-1] != tc.fault[len(tc.fault)-1]) {
+				t.Errorf("%s = %v, want %v", FaultHeader, got, tc.fault)
+			}
+		})
+	}
+}
+
+func TestInjectedLatencyStopsWithTheRequest(t *testing.T) {
+	h := &IngestHandler{logger: slog.Default(), server: &Server{endpoints: registry.Of(gateway.EndpointsBucket, map[string]*gateway.Endpoint{
+		gateway.EndpointKey("shop", "slow", "GET"): {Fault: &gateway.FaultConfig{Percentage: 100, DelayMs: 60000, ExpiresAt: time.Now().Add(time.Hour)}},
+	})}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+
+	start := time.Now()
+	if _, stop := h.injectFault(httptest.NewRecorder(), r, "shop", "slow"); !stop {
+		t.Error("request went on after its context ended")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("delay ran for %s after the request was gone", elapsed)
+	}
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/common/proto"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/broker"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcIngress serves proto.InvokeService by replaying calls through the HTTP
+// handlers, so gRPC callers get the same authentication, policies, caching
+// and dispatch as /lambda requests. CORS and delayed invocations are left to
+// HTTP callers.
+type grpcIngress struct {
+	proto.UnimplementedInvokeServiceServer
+	handler *IngestHandler
+}
+
+// newGRPCServer serves the ingress with TLS when TLS_CERT_FILE and
+// TLS_KEY_FILE are set, reloading the certificate like the HTTPS listener.
+func newGRPCServer(h *IngestHandler) (*grpc.Server, error) {
+	var opts []grpc.ServerOption
+	if pkg.Settings.TLSCertFile != "" && pkg.Settings.TLSKeyFile != "" {
+		certs := &certLoader{certFile: pkg.Settings.TLSCertFile, keyFile: pkg.Settings.TLSKeyFile}
+		if _, err := certs.GetCertificate(nil); err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(&tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		})))
+	}
+	srv := grpc.NewServer(opts...)
+	proto.RegisterInvokeServiceServer(srv, &grpcIngress{handler: h})
+	return srv, nil
+}
+
+func (g *grpcIngress) Invoke(ctx context.Context, in *proto.InvokeRequest) (*proto.InvokeResponse, error) {
+	if !validTarget(in.Project, in.Function) {
+		return nil, status.Error(codes.InvalidArgument, "project and function are required")
+	}
+	method := strings.ToUpper(in.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	query := url.Values{}
+	for k, v := range in.Query {
+		query.Set(k, v)
+	}
+	u := url.URL{Path: "/lambda/" + in.Project + "/" + in.Function, RawQuery: query.Encode()}
+	r, err := http.NewRequestWithContext(withSource(ctx, gateway.SourceGRPC), method, u.String(), bytes.NewReader(in.Body))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	incomingRequest(ctx, r)
+
+	w := &bufferedResponse{header: http.Header{}}
+	g.handler.pipeline(middleware.GRPC, g.handler.call)(w, r)
+
+	headers := make(map[string]string, len(w.header))
+	md := metadata.MD{}
+	for k, vals := range w.header {
+		headers[k] = strings.Join(vals, ", ")
+		md.Append(strings.ToLower(k), vals...)
+	}
+	if w.statusCode() >= http.StatusBadRequest {
+		_ = grpc.SetHeader(ctx, md)
+		return nil, status.Error(grpcCode(w.statusCode()), problem.Message(w.header, w.body.Bytes()))
+	}
+	return &proto.InvokeResponse{Status: int32(w.statusCode()), Headers: headers, Body: w.body.Bytes()}, nil
+}
+
+// InvokeStream follows the websocket path. Results are streamed back until
+// the caller cancels the call.
+func (g *grpcIngress) InvokeStream(stream proto.InvokeService_InvokeStreamServer) error {
+	ctx := stream.Context()
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if !validTarget(first.Project, first.Function) {
+		return status.Error(codes.InvalidArgument, "project and function are required in the first message")
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/lambda/ws/"+first.Project+"/"+first.Function, nil)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	incomingRequest(ctx, r)
+
+	w := &bufferedResponse{header: http.Header{}}
+	info, ok := g.handler.admitStream(w, r)
+	if !ok {
+		return status.Error(grpcCode(w.statusCode()), problem.Message(w.header, w.body.Bytes()))
+	}
+
+	srv := g.handler.server
+	req := broker.NewSyncReq(r, info.Language)
+	ch, cleanup, err := broker.Subscribe(srv.nc, srv.payloads, req)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer cleanup()
+
+	pending := first.Body
+	go broker.Forward(ctx, srv.nc, srv.payloads, req, broker.Envelope(r), func() ([]byte, error) {
+		for len(pending) == 0 {
+			msg, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			pending = msg.Body
+		}
+		data := pending
+		pending = nil
+		return data, nil
+	})
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case res, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(&proto.StreamResponse{Body: res}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func validTarget(project, function string) bool {
+	return project != "" && function != "" && !strings.ContainsAny(project+function, "/?#")
+}
+
+// incomingRequest maps call metadata onto request headers and records the
+// caller's address for policies. CORS headers are dropped, a call must not
+// pass for a browser preflight, which skips authentication.
+func incomingRequest(ctx context.Context, r *http.Request) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for k, vals := range md {
+		if strings.HasPrefix(k, ":") || strings.HasPrefix(k, "grpc-") || strings.HasSuffix(k, "-bin") ||
+			k == "content-type" || k == "te" || k == "origin" || strings.HasPrefix(k, "access-control-") {
+			continue
+		}
+		r.Header[http.CanonicalHeaderKey(k)] = vals
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		r.RemoteAddr = p.Addr.String()
+	}
+}
+
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusMethodNotAllowed, http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if httpStatus >= http.StatusInternalServerError {
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// bufferedResponse collects what the HTTP handlers write for a gRPC call.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) statusCode() int {
+	if b.status == 0 {
+		return http.StatusOK
+	}
+	return b.status
+}
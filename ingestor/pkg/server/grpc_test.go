@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/common/proto"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/registry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCPipeline(t *testing.T) {
+	h := &IngestHandler{logger: slog.Default(), server: &Server{
+		maintenance: registry.Of(gateway.MaintenanceBucket, map[string]*gateway.Maintenance{}),
+		endpoints: registry.Of(gateway.EndpointsBucket, map[string]*gateway.Endpoint{
+			gateway.EndpointKey("shop", "orders", "POST"): {Method: "POST", CORS: &gateway.CORSConfig{AllowedOrigins: []string{"*"}}},
+		}),
+	}}
+
+	for _, mode := range []middleware.Mode{middleware.GRPC, middleware.GRPCStream} {
+		t.Run(string(mode), func(t *testing.T) {
+			var reached bool
+			chain := middleware.Chain(func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+				reached = true
+				if _, ok := r.Context().Value(runAtKey{}).(time.Time); ok {
+					t.Error("gRPC call was turned into a scheduled invocation")
+				}
+			}, h.stages(mode)...)
+
+			r := httptest.NewRequest(http.MethodPost, "/lambda/shop/orders", nil)
+			r.Header.Set("Origin", "https://app.example.com")
+			r.Header.Set(gateway.DelayHeader, "1h")
+			w := httptest.NewRecorder()
+			chain(w, r, &middleware.Call{Project: "shop", Function: "orders", Mode: mode})
+			if !reached {
+				t.Fatalf("call stopped with %d", w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+				t.Errorf("CORS applied to a gRPC call: %q", got)
+			}
+		})
+	}
+}
+
+func TestIncomingRequest(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"x-api-key", "lf_test",
+		"origin", "https://app.example.com",
+		"access-control-request-method", "POST",
+		"grpc-timeout", "1S",
+		"trace-bin", "xx",
+	))
+	r := httptest.NewRequest(http.MethodOptions, "/lambda/shop/orders", nil)
+	incomingRequest(ctx, r)
+	if r.Header.Get(gateway.ApiKeyHeader) != "lf_test" {
+		t.Errorf("metadata not mapped: %v", r.Header)
+	}
+	for _, k := range []string{"Origin", "Access-Control-Request-Method", "Grpc-Timeout", "Trace-Bin"} {
+		if r.Header.Get(k) != "" {
+			t.Errorf("%s passed through", k)
+		}
+	}
+	if isPreflight(r) {
+		t.Error("gRPC call looks like a preflight")
+	}
+}
+
+func TestGRPCCode(t *testing.T) {
+	cases := map[int]codes.Code{
+		http.StatusBadRequest:          codes.InvalidArgument,
+		http.StatusUnauthorized:        codes.Unauthenticated,
+		http.StatusForbidden:           codes.PermissionDenied,
+		http.StatusNotFound:            codes.NotFound,
+		http.StatusMethodNotAllowed:    codes.FailedPrecondition,
+		http.StatusTooManyRequests:     codes.ResourceExhausted,
+		http.StatusServiceUnavailable:  codes.Unavailable,
+		http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+		http.StatusInternalServerError: codes.Internal,
+		http.StatusTeapot:              codes.Unknown,
+	}
+	for httpStatus, want := range cases {
+		if got := grpcCode(httpStatus); got != want {
+			t.Errorf("grpcCode(%d) = %s, want %s", httpStatus, got, want)
+		}
+	}
+}
+
+// writeCert writes a self-signed certificate for localhost and returns its
+// files and a pool trusting it.
+func writeCert(t *testing.T) (string, string, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
+
+func TestGRPCServerTLS(t *testing.T) {
+	certFile, keyFile, pool := writeCert(t)
+	pkg.Settings = &pkg.IngestorConf{TLSCertFile: certFile, TLSKeyFile: keyFile}
+	srv, err := newGRPCServer(&IngestHandler{logger: slog.Default(), server: &Server{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(lis)
+	defer srv.Stop()
+	addr := fmt.Sprintf("localhost:%d", lis.Addr().(*net.TCPAddr).Port)
+
+	invoke := func(creds credentials.TransportCredentials) error {
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		// an empty target is refused by the ingress before reaching the handlers
+		_, err = proto.NewInvokeServiceClient(conn).Invoke(ctx, &proto.InvokeRequest{})
+		return err
+	}
+	if err := invoke(credentials.NewTLS(&tls.Config{RootCAs: pool})); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("call over tls = %v", err)
+	}
+	if err := invoke(insecure.NewCredentials()); status.Code(err) != codes.Unavailable {
+		t.Errorf("plaintext call = %v, want it refused", err)
+	}
+
+	pkg.Settings = &pkg.IngestorConf{TLSCertFile: filepath.Join(t.TempDir(), "missing.crt"), TLSKeyFile: keyFile}
+	if _, err := newGRPCServer(&IngestHandler{}); err == nil {
+		t.Error("server started without its certificate")
+	}
+}
@@ -1,15 +1,28 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/common/proto"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
 	"github.com/ashupednekar/litefunctions/ingestor/pkg/broker"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/idempotency"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/upstream"
 	"github.com/gorilla/websocket"
 )
 
@@ -26,56 +39,211 @@ func NewIngestHandler(server *Server) *IngestHandler {
 }
 
 func (h *IngestHandler) Sync(w http.ResponseWriter, r *http.Request) {
-	project, name := parsePath(r.URL.Path)
+	h.pipeline(middleware.Sync, h.call)(w, r)
+}
+
+// call runs an admitted request: it is scheduled when a delay was asked for,
+// otherwise faults, the response cache and dispatch apply.
+func (h *IngestHandler) call(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+	if runAt, ok := r.Context().Value(runAtKey{}).(time.Time); ok {
+		h.schedule(w, r, c.Project, c.Function, runAt)
+		return
+	}
+	r, done := h.injectFault(w, r, c.Project, c.Function)
+	if done {
+		return
+	}
+	h.withCache(w, r, c.Project, c.Function, func(w http.ResponseWriter) {
+		h.dispatch(w, r, c.Project, c.Function)
+	})
+}
 
-	h.logger.Info("handling sync request", "project", project, "name", name)
+// dispatch activates the function and invokes it, honouring Idempotency-Key.
+func (h *IngestHandler) dispatch(w http.ResponseWriter, r *http.Request, project, name string) {
+	info, ok := h.activate(w, r, project, name)
+	if !ok {
+		return
+	}
 
-	info, err := h.server.activateFunction(project, name)
+	key := strings.TrimSpace(r.Header.Get(idempotency.Header))
+	if key != "" && h.server.idem != nil {
+		h.syncIdempotent(w, r, info, project, name, key)
+		return
+	}
+	h.invoke(w, r, info, project, name, "")
+}
+
+// syncIdempotent replays the stored response for a repeated Idempotency-Key,
+// rejects repeats that arrive while the first request is still running, and
+// otherwise records the response of a fresh invocation. Server errors are not
+// stored so that clients can retry them with the same key. Keys are kept per
+// API key or JWT subject, the same key from another caller is a new request.
+func (h *IngestHandler) syncIdempotent(w http.ResponseWriter, r *http.Request, info *proto.ActivateResponse, project, name, key string) {
+	storeKey := idempotency.Key(project, name, requestOwner(r.Header), key)
+	rec, err := h.server.idem.Begin(r.Context(), storeKey)
+	if errors.Is(err, idempotency.ErrInFlight) {
+		h.logger.Warn("idempotent request still in flight", "project", project, "name", name)
+		problem.Write(w, http.StatusConflict, problem.Conflict, "a request with this idempotency key is still in progress")
+		return
+	}
 	if err != nil {
-		h.logger.Error("failed to activate function", "error", err)
-		http.Error(w, fmt.Sprintf("%s", err), http.StatusBadRequest)
+		h.logger.Error("failed to claim idempotency key", "error", err)
+		problem.Write(w, http.StatusInternalServerError, problem.Internal, "could not claim the idempotency key")
 		return
 	}
-	if !h.validateMethod(w, r, info.Method, project, name) {
+	if rec != nil {
+		h.logger.Info("replaying idempotent response", "project", project, "name", name, "status", rec.Status)
+		rec.Replay(w)
 		return
 	}
 
-	if info.IsAsync {
-		_, err := broker.Submit(h.server.nc, r, info.Language)
-		if err != nil {
-			h.logger.Error("failed to submit request to broker", "error", err)
-			http.Error(w, fmt.Sprintf("%s", err), http.StatusBadRequest)
-			return
+	rw := idempotency.NewRecorder(w)
+	h.invoke(rw, r, info, project, name, storeKey)
+
+	// the client may be gone by now, the outcome still has to be stored
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if rw.Status() == 0 || rw.Status() >= http.StatusInternalServerError {
+		if err := h.server.idem.Release(ctx, storeKey); err != nil {
+			h.logger.Error("failed to release idempotency key", "error", err)
 		}
-		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	if err := h.server.idem.Complete(ctx, storeKey, rw.Status(), rw.Header().Clone(), rw.Body()); err != nil {
+		h.logger.Error("failed to store idempotent response", "error", err)
+		_ = h.server.idem.Release(ctx, storeKey)
+	}
+}
+
+// invoke dispatches an already transformed request to the runtime. A
+// non-empty msgID dedupes async submissions on the project stream.
+func (h *IngestHandler) invoke(w http.ResponseWriter, r *http.Request, info *proto.ActivateResponse, project, name, msgID string) {
+	if info.IsAsync {
+		h.submitAsync(w, r, info, project, name, msgID)
 		return
 	}
 
 	if info.ServiceName != "" && info.ServicePort > 0 {
-		if err := proxyToRuntime(w, r, project, name, "default", info.ServiceName, int(info.ServicePort)); err != nil {
-			h.logger.Error("failed to proxy request to runtime", "error", err)
-			http.Error(w, fmt.Sprintf("%s", err), http.StatusBadGateway)
+		err := proxyToRuntime(h.server.upstream, h.server.upstreamProtocol(info.Language), w, r, project, name, "default", info.ServiceName, int(info.ServicePort), h.responseTransform(r, project, name))
+		if open, ok := upstream.IsCircuitOpen(err); ok {
+			h.logger.Warn("runtime circuit open, failing fast", "project", project, "name", name)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(open.RetryAfter.Seconds()))))
+			problem.Write(w, http.StatusServiceUnavailable, problem.RuntimeUnavailable, "the function is failing, retry later")
+			return
+		}
+		if err != nil {
+			h.logger.Error("failed to proxy request to runtime", "project", project, "name", name, "error", err)
+			problem.Write(w, http.StatusBadGateway, problem.UpstreamFailed, "the function could not be reached")
 		}
 		return
 	}
 
-	req, err := broker.Submit(h.server.nc, r, info.Language)
+	req, err := broker.Submit(h.server.nc, h.server.payloads, r, broker.NewSyncReq(r, info.Language))
 	if err != nil {
-		h.logger.Error("failed to submit request to broker", "error", err)
-		http.Error(w, fmt.Sprintf("%s", err), http.StatusBadRequest)
+		h.submitFailed(w, err)
+		return
+	}
+	res, err := broker.Reply(r.Context(), h.server.nc, h.server.payloads, req)
+	if errors.Is(err, broker.ErrNoReply) {
+		h.logger.Warn("function did not reply in time", "project", project, "name", name, "error", err)
+		problem.Write(w, http.StatusGatewayTimeout, problem.ReplyTimeout, "the function did not reply in time")
 		return
 	}
-	res, err := broker.Reply(h.server.nc, req)
 	if err != nil {
 		h.logger.Error("failed to get reply from broker", "error", err)
-		http.Error(w, fmt.Sprintf("%s", err), http.StatusInternalServerError)
+		problem.Write(w, http.StatusBadGateway, problem.UpstreamFailed, "the function's reply could not be read")
 		return
 	}
+	header := http.Header{}
+	if err := transformResponse(header, h.responseTransform(r, project, name), transformVars(r, project, name)); err != nil {
+		h.logger.Error("failed to transform response", "error", err)
+		problem.Write(w, http.StatusInternalServerError, problem.Internal, "could not transform the response")
+		return
+	}
+	for k, vals := range header {
+		w.Header()[k] = vals
+	}
 	h.logger.Info("sync request completed", "project", project, "name", name, "language", info.Language)
 	w.Write(res)
 }
 
-func proxyToRuntime(w http.ResponseWriter, r *http.Request, project, name, namespace, service string, port int) error {
+// submitFailed answers a request the broker didn't take.
+func (h *IngestHandler) submitFailed(w http.ResponseWriter, err error) {
+	if errors.Is(err, broker.ErrPayloadTooLarge) {
+		problem.Write(w, http.StatusRequestEntityTooLarge, problem.PayloadTooLarge, err.Error())
+		return
+	}
+	h.logger.Error("failed to submit request to broker", "error", err)
+	problem.Write(w, http.StatusServiceUnavailable, problem.UpstreamFailed, "the request could not be queued")
+}
+
+// submitAsync publishes the request and answers 202. When a callback applies
+// the result subscription is opened before publishing and delivery continues
+// in the background.
+func (h *IngestHandler) submitAsync(w http.ResponseWriter, r *http.Request, info *proto.ActivateResponse, project, name, msgID string) {
+	if dropAsync(r) {
+		h.logger.Info("injecting fault, dropping async request", "project", project, "name", name)
+		w.Header().Add(FaultHeader, "drop")
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	priority, ok := h.asyncPriority(w, r, project, name)
+	if !ok {
+		return
+	}
+	target, ok := h.callbackTarget(w, r, project, name)
+	if !ok {
+		return
+	}
+	req := broker.NewReq(r, info.Language)
+	req.Priority = priority
+	if rec := invocationFrom(r.Context()); rec != nil {
+		rec.RequestID = req.ReqId
+	}
+	var pending *broker.Pending
+	if target != nil {
+		var err error
+		if pending, err = broker.Expect(h.server.nc, h.server.payloads, req); err != nil {
+			h.logger.Error("failed to watch for async result", "error", err)
+			problem.Write(w, http.StatusInternalServerError, problem.Internal, "could not watch for the result")
+			return
+		}
+	}
+
+	var dup bool
+	var err error
+	if msgID != "" {
+		_, dup, err = broker.SubmitDeduped(r.Context(), h.server.js, h.server.payloads, r, req, msgID)
+	} else {
+		_, err = broker.Submit(h.server.nc, h.server.payloads, r, req)
+	}
+	if err != nil {
+		if pending != nil {
+			pending.Cancel()
+		}
+		h.submitFailed(w, err)
+		return
+	}
+	if dup {
+		h.logger.Info("dropped duplicate async submission", "project", project, "name", name)
+	}
+
+	if pending != nil {
+		if dup {
+			pending.Cancel()
+		} else {
+			go h.server.callbacks.Deliver(project, name, req.ReqId, *target, pending.Wait)
+		}
+	}
+	w.Header().Set(gateway.RequestIDHeader, req.ReqId)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// maxReplayBody bounds the bodies buffered so a failed attempt can be retried,
+// larger bodies are streamed and sent once.
+const maxReplayBody = 1 << 20
+
+func proxyToRuntime(client *upstream.Client, proto upstream.Protocol, w http.ResponseWriter, r *http.Request, project, name, namespace, service string, port int, rt *gateway.ResponseTransform) error {
 	start := time.Now()
 	runtimePath := strings.TrimPrefix(r.URL.Path, "/lambda/"+project+"/"+name)
 	if runtimePath == "" {
@@ -85,7 +253,8 @@ func proxyToRuntime(w http.ResponseWriter, r *http.Request, project, name, names
 		runtimePath = "/" + runtimePath
 	}
 
-	base := fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", service, namespace, port)
+	host := strings.NewReplacer("{service}", service, "{namespace}", namespace).Replace(pkg.Settings.RuntimeHost)
+	base := fmt.Sprintf("http://%s:%d", host, port)
 	u, err := url.Parse(base)
 	if err != nil {
 		return err
@@ -93,36 +262,64 @@ func proxyToRuntime(w http.ResponseWriter, r *http.Request, project, name, names
 	u.Path = runtimePath
 	u.RawQuery = r.URL.RawQuery
 
-	req, err := http.NewRequestWithContext(r.Context(), r.Method, u.String(), r.Body)
+	var reqBody io.Reader = r.Body
+	if upstream.Idempotent(r) && r.ContentLength >= 0 && r.ContentLength <= maxReplayBody {
+		buf, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(buf)
+	}
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, u.String(), reqBody)
 	if err != nil {
 		return err
 	}
 	req.Header = r.Header.Clone()
-	req.Header.Set("X-Litefunction-Name", name)
-	req.Header.Set("X-Litefunction-Project", project)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req, project+"/"+name, proto)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	body, readErr := io.ReadAll(resp.Body)
-	if readErr != nil {
-		slog.Error("failed reading runtime response body", "project", project, "name", name, "service", service, "error", readErr)
-		return readErr
+	stream := streamingResponse(resp)
+	var body []byte
+	if !stream {
+		var readErr error
+		if body, readErr = io.ReadAll(resp.Body); readErr != nil {
+			slog.Error("failed reading runtime response body", "project", project, "name", name, "service", service, "error", readErr)
+			return readErr
+		}
+	}
+	if err := transformResponse(resp.Header, rt, transformVars(r, project, name)); err != nil {
+		return err
 	}
 
 	for k, vals := range resp.Header {
+		// CORS headers set by the ingestor take precedence over the runtime's
+		if strings.HasPrefix(k, "Access-Control-") && w.Header().Get(k) != "" {
+			continue
+		}
 		for _, v := range vals {
 			w.Header().Add(k, v)
 		}
 	}
 	w.WriteHeader(resp.StatusCode)
-	if _, err = w.Write(body); err != nil {
+	written := int64(len(body))
+	if stream {
+		// the status is out already, a broken stream can only be logged
+		if written, err = streamBody(w, resp.Body); err != nil {
+			slog.Warn("runtime response stream ended early", "project", project, "name", name, "service", service, "error", err)
+		}
+	} else if _, err = w.Write(body); err != nil {
 		slog.Error("failed writing response body to client", "project", project, "name", name, "service", service, "error", err)
 		return err
 	}
+	for k, vals := range resp.Trailer {
+		for _, v := range vals {
+			w.Header().Add(http.TrailerPrefix+k, v)
+		}
+	}
 
 	attrs := []any{
 		"project", project,
@@ -132,10 +329,11 @@ func proxyToRuntime(w http.ResponseWriter, r *http.Request, project, name, names
 		"upstream", u.String(),
 		"service", service,
 		"status", resp.StatusCode,
-		"bytes", len(body),
+		"bytes", written,
+		"proto", resp.Proto,
 		"duration_ms", time.Since(start).Milliseconds(),
 	}
-	if resp.StatusCode >= 400 {
+	if resp.StatusCode >= 400 && !stream {
 		snippet := string(body)
 		if len(snippet) > 512 {
 			snippet = snippet[:512]
@@ -148,30 +346,69 @@ func proxyToRuntime(w http.ResponseWriter, r *http.Request, project, name, names
 	return nil
 }
 
+// streamingResponse reports whether the runtime's response is passed on as
+// it arrives instead of being read in full first.
+func streamingResponse(resp *http.Response) bool {
+	ct := strings.ToLower(resp.Header.Get("Content-Type"))
+	for _, prefix := range []string{"text/event-stream", "application/grpc-web", "application/x-ndjson"} {
+		if strings.HasPrefix(ct, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// streamBody copies body to w, flushing after every read so each chunk
+// reaches the client as soon as the runtime produces it.
+func streamBody(w http.ResponseWriter, body io.Reader) (int64, error) {
+	rc := http.NewResponseController(w)
+	buf := make([]byte, 32*1024)
+	var written int64
+	for {
+		n, readErr := body.Read(buf)
+		if n > 0 {
+			m, err := w.Write(buf[:n])
+			written += int64(m)
+			if err != nil {
+				return written, err
+			}
+			if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return written, err
+			}
+		}
+		if readErr == io.EOF {
+			return written, nil
+		}
+		if readErr != nil {
+			return written, readErr
+		}
+	}
+}
+
 func (h *IngestHandler) RuntimeHook(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.Header().Set("Allow", http.MethodPost)
-		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		problem.Write(w, http.StatusMethodNotAllowed, problem.MethodNotAllowed, "runtime hooks are triggered with POST")
 		return
 	}
 
 	language := strings.ToLower(strings.TrimSpace(r.PathValue("language")))
 	if language == "" {
-		http.Error(w, "language is required", http.StatusBadRequest)
+		problem.Write(w, http.StatusBadRequest, problem.BadRequest, "language is required")
 		return
 	}
 	if strings.ContainsAny(language, ".*>") {
-		http.Error(w, "invalid language", http.StatusBadRequest)
+		problem.Write(w, http.StatusBadRequest, problem.BadRequest, "invalid language")
 		return
 	}
 
 	project := r.PathValue("project")
 	if project == "" {
-		http.Error(w, "project is required", http.StatusBadRequest)
+		problem.Write(w, http.StatusBadRequest, problem.BadRequest, "project is required")
 		return
 	}
 	if strings.ContainsAny(project, ".*>") {
-		http.Error(w, "invalid project", http.StatusBadRequest)
+		problem.Write(w, http.StatusBadRequest, problem.BadRequest, "invalid project")
 		return
 	}
 
@@ -180,7 +417,7 @@ func (h *IngestHandler) RuntimeHook(w http.ResponseWriter, r *http.Request) {
 	h.logger.Info("publishing runtime hook", "project", project, "language", language, "subject", subject)
 	if err := h.server.nc.Publish(subject, payload); err != nil {
 		h.logger.Error("failed to publish runtime hook", "project", project, "language", language, "error", err)
-		http.Error(w, "failed to publish hook", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, problem.Internal, "failed to publish hook")
 		return
 	}
 	h.logger.Info("runtime hook published", "project", project, "language", language, "subject", subject)
@@ -190,33 +427,33 @@ func (h *IngestHandler) RuntimeHook(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *IngestHandler) SSE(w http.ResponseWriter, r *http.Request) {
-	project, name := parsePath(r.URL.Path)
-
-	h.logger.Info("handling SSE request", "project", project, "name", name)
+	h.pipeline(middleware.SSE, h.stream)(w, r)
+}
 
-	info, err := h.server.activateFunction(project, name)
-	if err != nil {
-		h.logger.Error("failed to activate function", "error", err)
-		http.Error(w, fmt.Sprintf("%s", err), http.StatusBadRequest)
-		return
-	}
-	if !h.validateMethod(w, r, info.Method, project, name) {
+// stream submits the request and writes every result back as an event.
+func (h *IngestHandler) stream(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+	info, ok := h.activate(w, r, c.Project, c.Function)
+	if !ok {
 		return
 	}
 
-	req, err := broker.Submit(h.server.nc, r, info.Language)
+	req, err := broker.Submit(h.server.nc, h.server.payloads, r, broker.NewSyncReq(r, info.Language))
 	if err != nil {
-		h.logger.Error("failed to submit request to broker", "error", err)
-		http.Error(w, fmt.Sprintf("%s", err), http.StatusBadRequest)
+		h.submitFailed(w, err)
 		return
 	}
-	ch, cleanup, err := broker.Subscribe(h.server.nc, req)
+	ch, cleanup, err := broker.Subscribe(h.server.nc, h.server.payloads, req)
 	if err != nil {
 		h.logger.Error("failed to subscribe to broker", "error", err)
-		http.Error(w, fmt.Sprintf("%s", err), http.StatusBadRequest)
+		problem.Write(w, http.StatusInternalServerError, problem.Internal, "could not subscribe to results")
 		return
 	}
 	defer cleanup()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	_ = rc.Flush()
 	for {
 		select {
 		case <-r.Context().Done():
@@ -226,35 +463,33 @@ func (h *IngestHandler) SSE(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			w.Write(res)
+			// without a flush HTTP/2 holds small events back in its buffers
+			_ = rc.Flush()
 		}
 	}
 }
 
 func (h *IngestHandler) WS(w http.ResponseWriter, r *http.Request) {
-	project, name := parsePath(r.URL.Path)
-
-	h.logger.Info("handling WS request", "project", project, "name", name)
+	h.pipeline(middleware.WS, h.socket)(w, r)
+}
 
-	info, err := h.server.activateFunction(project, name)
-	if err != nil {
-		h.logger.Error("failed to activate function", "error", err)
-		http.Error(w, fmt.Sprintf("%s", err), http.StatusBadRequest)
-		return
-	}
-	if !h.validateMethod(w, r, info.Method, project, name) {
+// socket upgrades the connection and relays messages both ways.
+func (h *IngestHandler) socket(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+	info, ok := h.activate(w, r, c.Project, c.Function)
+	if !ok {
 		return
 	}
 
-	conn, req, err := broker.Produce(h.server.nc, w, r, info.Language)
+	conn, req, err := broker.Produce(h.server.nc, h.server.payloads, w, r, info.Language)
 	if err != nil {
 		h.logger.Error("failed to produce message to broker", "error", err)
 		return
 	}
 	defer conn.Close()
-	ch, cleanup, err := broker.Subscribe(h.server.nc, req)
+	ch, cleanup, err := broker.Subscribe(h.server.nc, h.server.payloads, req)
 	if err != nil {
+		// the connection is upgraded, closing it is all that is left
 		h.logger.Error("failed to subscribe to broker", "error", err)
-		http.Error(w, fmt.Sprintf("%s", err), http.StatusBadRequest)
 		return
 	}
 	defer cleanup()
@@ -274,20 +509,63 @@ func (h *IngestHandler) WS(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// admitStream runs the pipeline for a gRPC stream and activates the
+// function.
+func (h *IngestHandler) admitStream(w http.ResponseWriter, r *http.Request) (*proto.ActivateResponse, bool) {
+	var info *proto.ActivateResponse
+	h.pipeline(middleware.GRPCStream, func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		info, _ = h.activate(w, r, c.Project, c.Function)
+	})(w, r)
+	return info, info != nil
+}
+
+// validateMethod checks the method against the endpoints the portal
+// published for the function, or against the method recorded on the
+// function when there are none.
 func (h *IngestHandler) validateMethod(w http.ResponseWriter, r *http.Request, expected, project, name string) bool {
-	if expected == "" {
-		return true
+	if allowed := h.publishedMethods(project, name); len(allowed) > 0 {
+		method := r.Method
+		if isPreflight(r) {
+			method = r.Header.Get("Access-Control-Request-Method")
+		}
+		if slices.Contains(allowed, strings.ToUpper(method)) {
+			return true
+		}
+		h.refuseMethod(w, r, allowed, project, name)
+		return false
 	}
-	if strings.EqualFold(r.Method, expected) {
+	if expected == "" || strings.EqualFold(r.Method, expected) {
 		return true
 	}
-	allowed := strings.ToUpper(expected)
-	w.Header().Set("Allow", allowed)
-	h.logger.Warn("method mismatch", "project", project, "name", name, "expected", allowed, "actual", r.Method)
-	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	h.refuseMethod(w, r, []string{strings.ToUpper(expected)}, project, name)
 	return false
 }
 
+// publishedMethods lists the methods the portal published an endpoint for.
+// While the only one is the automatic endpoint the function is still called
+// with any method, as before endpoints existed, and none are listed.
+func (h *IngestHandler) publishedMethods(project, name string) []string {
+	var methods []string
+	automatic := true
+	for _, m := range gateway.EndpointMethods {
+		if ep, ok := h.server.endpoints.Get(gateway.EndpointKey(project, name, m)); ok {
+			methods = append(methods, m)
+			automatic = automatic && ep.Automatic()
+		}
+	}
+	if automatic {
+		return nil
+	}
+	return methods
+}
+
+func (h *IngestHandler) refuseMethod(w http.ResponseWriter, r *http.Request, allowed []string, project, name string) {
+	list := strings.Join(allowed, ", ")
+	w.Header().Set("Allow", list)
+	h.logger.Warn("method not allowed", "project", project, "name", name, "allowed", list, "actual", r.Method)
+	problem.Write(w, http.StatusMethodNotAllowed, problem.MethodNotAllowed, "this function only accepts "+list)
+}
+
 func parsePath(path string) (string, string) {
 	trimmed := strings.Trim(path, "/")
 	if trimmed == "" {
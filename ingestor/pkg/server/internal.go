@@ -0,0 +1,216 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/calltoken"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+	"github.com/nats-io/nats.go"
+)
+
+// serveInternal answers function-to-function calls. Ingestor replicas share
+// a queue group, so every call is handled once.
+func (s *Server) serveInternal(h *IngestHandler) (*nats.Subscription, error) {
+	return s.nc.QueueSubscribe(gateway.InvokeSubject+".*.*", "litefunctions-ingestor", func(msg *nats.Msg) {
+		go h.Internal(msg)
+	})
+}
+
+// Internal runs a call from another function through the same policies,
+// validation, quotas and dispatch as /lambda requests. The caller was
+// authenticated when its own request came in, so credentials aren't checked
+// again. Its identity is taken from the call token signed for that request
+// instead, X-Litefunction-* headers on the message are ignored.
+func (h *IngestHandler) Internal(msg *nats.Msg) {
+	w := &bufferedResponse{header: http.Header{}}
+	defer h.respondInternal(msg, w)
+
+	project, name, ok := internalTarget(msg.Subject)
+	if !ok {
+		problem.Write(w, http.StatusBadRequest, problem.BadRequest, "invalid invocation subject")
+		return
+	}
+
+	id, ok := h.verifyCall(w, msg.Header)
+	if !ok {
+		return
+	}
+	ctx, cancel, ok := internalContext(w, msg.Header, id.Expires)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	method := strings.ToUpper(msg.Header.Get(gateway.InvokeMethodHeader))
+	if method == "" {
+		method = http.MethodPost
+	}
+	r, err := http.NewRequestWithContext(ctx, method, "/lambda/"+project+"/"+name, bytes.NewReader(msg.Data))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, problem.BadRequest, "invalid invocation")
+		return
+	}
+	for k, vals := range msg.Header {
+		if !reserved(k) {
+			r.Header[http.CanonicalHeaderKey(k)] = vals
+		}
+	}
+	setIdentity(r.Header, id)
+
+	h.pipeline(middleware.Internal, h.call)(w, r)
+}
+
+// verifyCall answers 401 unless the call carries a call token signed by an
+// ingestor that hasn't expired yet.
+func (h *IngestHandler) verifyCall(w http.ResponseWriter, header nats.Header) (*calltoken.Identity, bool) {
+	if h.server.callTokens == nil {
+		problem.Write(w, http.StatusUnauthorized, problem.Unauthorized, calltoken.ErrInvalid.Error())
+		return nil, false
+	}
+	id, err := h.server.callTokens.Verify(header.Get(gateway.CallTokenHeader), time.Now())
+	if err != nil {
+		h.logger.Warn("internal call rejected", "caller", header.Get(gateway.CallerHeader), "error", err)
+		problem.Write(w, http.StatusUnauthorized, problem.Unauthorized, err.Error())
+		return nil, false
+	}
+	return id, true
+}
+
+// setIdentity replaces the caller and end user headers with the ones id
+// was signed with.
+func setIdentity(header http.Header, id *calltoken.Identity) {
+	header.Set(gateway.CallerHeader, id.Function)
+	header.Set(gateway.CallChainHeader, strings.Join(id.Chain, ","))
+	for k, v := range map[string]string{gateway.ApiKeyIDHeader: id.ApiKeyID, gateway.SubjectHeader: id.Subject, gateway.ClaimsHeader: id.Claims} {
+		if v == "" {
+			header.Del(k)
+		} else {
+			header.Set(k, v)
+		}
+	}
+}
+
+// issueCallToken signs the identity r runs with for the function it is
+// about to reach, so that function's own calls can be verified. The call
+// chain grows by the function when r is itself a call, and the token
+// expires with r or after CALL_TOKEN_TTL.
+func (h *IngestHandler) issueCallToken(r *http.Request, project, name string) error {
+	if h.server.callTokens == nil {
+		return nil
+	}
+	expires := time.Now().Add(h.server.callTTL)
+	if deadline, ok := r.Context().Deadline(); ok && deadline.Before(expires) {
+		expires = deadline
+	}
+	target := project + "/" + name
+	token, err := h.server.callTokens.Sign(calltoken.Identity{
+		Function: target,
+		Chain:    append(gateway.CallChain(r.Header.Get(gateway.CallChainHeader)), target),
+		ApiKeyID: r.Header.Get(gateway.ApiKeyIDHeader),
+		Subject:  r.Header.Get(gateway.SubjectHeader),
+		Claims:   r.Header.Get(gateway.ClaimsHeader),
+		Expires:  expires,
+	})
+	if err != nil {
+		return err
+	}
+	r.Header.Set(gateway.CallTokenHeader, token)
+	return nil
+}
+
+// checkCall confines calls to the caller's project and rejects cycles and
+// chains deeper than MAX_CALL_DEPTH with 508.
+func (h *IngestHandler) checkCall(w http.ResponseWriter, r *http.Request, project, name string) bool {
+	caller := r.Header.Get(gateway.CallerHeader)
+	chain := gateway.CallChain(r.Header.Get(gateway.CallChainHeader))
+	if caller == "" || len(chain) == 0 || chain[len(chain)-1] != caller {
+		problem.Write(w, http.StatusBadRequest, problem.BadRequest, "caller identity missing")
+		return false
+	}
+	if callerProject, _, _ := strings.Cut(caller, "/"); callerProject != project {
+		h.logger.Warn("cross-project call rejected", "project", project, "name", name, "caller", caller)
+		problem.Write(w, http.StatusForbidden, problem.Forbidden, "functions can only invoke functions of their own project")
+		return false
+	}
+	target := project + "/" + name
+	if slices.Contains(chain, target) {
+		h.logger.Warn("call cycle rejected", "project", project, "name", name, "chain", chain)
+		problem.Write(w, http.StatusLoopDetected, problem.CallLoop, "call cycle: "+strings.Join(append(chain, target), " -> "))
+		return false
+	}
+	if len(chain) >= pkg.Settings.MaxCallDepth {
+		h.logger.Warn("call depth exceeded", "project", project, "name", name, "depth", len(chain))
+		problem.Write(w, http.StatusLoopDetected, problem.CallLoop, fmt.Sprintf("call depth exceeds %d", pkg.Settings.MaxCallDepth))
+		return false
+	}
+	return true
+}
+
+func (h *IngestHandler) respondInternal(msg *nats.Msg, w *bufferedResponse) {
+	if msg.Reply == "" {
+		return
+	}
+	if int64(w.body.Len()) > h.server.nc.MaxPayload()-4096 {
+		tooLarge := &bufferedResponse{header: http.Header{}}
+		tooLarge.header.Set(gateway.RequestIDHeader, w.header.Get(gateway.RequestIDHeader))
+		problem.Write(tooLarge, http.StatusBadGateway, problem.UpstreamFailed, "response too large for an internal call")
+		w = tooLarge
+	}
+	res := &nats.Msg{Header: nats.Header(w.header), Data: w.body.Bytes()}
+	res.Header.Set(gateway.InvokeStatusHeader, strconv.Itoa(w.statusCode()))
+	if err := msg.RespondMsg(res); err != nil {
+		h.logger.Error("failed to answer internal call", "subject", msg.Subject, "error", err)
+	}
+}
+
+// internalContext bounds the call by the caller's remaining deadline, or by
+// INTERNAL_CALL_TIMEOUT when it didn't send one, and never past limit, the
+// expiry of its call token.
+func internalContext(w http.ResponseWriter, header nats.Header, limit time.Time) (context.Context, context.CancelFunc, bool) {
+	deadline := limit
+	if raw := header.Get(gateway.DeadlineHeader); raw != "" {
+		requested, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			problem.Write(w, http.StatusBadRequest, problem.BadRequest, "invalid deadline")
+			return nil, nil, false
+		}
+		deadline = minTime(deadline, requested)
+	} else {
+		timeout, err := time.ParseDuration(pkg.Settings.InternalCallTimeout)
+		if err != nil {
+			timeout = 30 * time.Second
+		}
+		deadline = minTime(deadline, time.Now().Add(timeout))
+	}
+	if !time.Now().Before(deadline) {
+		problem.Write(w, http.StatusGatewayTimeout, problem.DeadlineExceeded, "deadline exceeded")
+		return nil, nil, false
+	}
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	return ctx, cancel, true
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func internalTarget(subject string) (string, string, bool) {
+	parts := strings.Split(strings.TrimPrefix(subject, gateway.InvokeSubject+"."), ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/calltoken"
+	"github.com/nats-io/nats.go"
+)
+
+func TestCheckCall(t *testing.T) {
+	pkg.Settings = &pkg.IngestorConf{MaxCallDepth: 3}
+	h := &IngestHandler{logger: slog.Default()}
+
+	cases := []struct {
+		name   string
+		caller string
+		chain  string
+		target string
+		status int
+	}{
+		{"allowed", "shop/orders", "shop/checkout, shop/orders", "shop/stock", 0},
+		{"no identity", "", "", "shop/stock", http.StatusBadRequest},
+		{"caller not last", "shop/orders", "shop/orders, shop/checkout", "shop/stock", http.StatusBadRequest},
+		{"other project", "shop/orders", "shop/orders", "billing/charge", http.StatusForbidden},
+		{"cycle", "shop/orders", "shop/stock, shop/orders", "shop/stock", http.StatusLoopDetected},
+		{"too deep", "shop/c", "shop/a, shop/b, shop/c", "shop/d", http.StatusLoopDetected},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set(gateway.CallerHeader, tc.caller)
+			r.Header.Set(gateway.CallChainHeader, tc.chain)
+			w := httptest.NewRecorder()
+			project, name, _ := internalTarget(gateway.InvokeSubject + "." + strings.Replace(tc.target, "/", ".", 1))
+			ok := h.checkCall(w, r, project, name)
+			if ok != (tc.status == 0) || (!ok && w.Code != tc.status) {
+				t.Errorf("checkCall = %v, status %d, want %d", ok, w.Code, tc.status)
+			}
+		})
+	}
+}
+
+func TestCallTokens(t *testing.T) {
+	h := &IngestHandler{logger: slog.Default(), server: &Server{callTokens: calltoken.NewSigner([]byte("secret")), callTTL: time.Hour}}
+
+	// the original request, authenticated with an api key
+	r := httptest.NewRequest(http.MethodPost, "/lambda/shop/orders", nil)
+	r.Header.Set(gateway.ApiKeyIDHeader, "key-1")
+	if err := h.issueCallToken(r, "shop", "orders"); err != nil {
+		t.Fatal(err)
+	}
+	token := r.Header.Get(gateway.CallTokenHeader)
+
+	// orders calls stock claiming to be someone else
+	msg := nats.Header{}
+	msg.Set(gateway.CallTokenHeader, token)
+	msg.Set(gateway.CallerHeader, "shop/admin")
+	msg.Set(gateway.CallChainHeader, "shop/admin")
+	msg.Set(gateway.ApiKeyIDHeader, "key-admin")
+	msg.Set(gateway.SubjectHeader, "root")
+	id, ok := h.verifyCall(httptest.NewRecorder(), msg)
+	if !ok {
+		t.Fatal("valid call token rejected")
+	}
+	call := httptest.NewRequest(http.MethodPost, "/lambda/shop/stock", nil)
+	call.Header.Set(gateway.SubjectHeader, "root")
+	setIdentity(call.Header, id)
+	if call.Header.Get(gateway.CallerHeader) != "shop/orders" || call.Header.Get(gateway.CallChainHeader) != "shop/orders" ||
+		call.Header.Get(gateway.ApiKeyIDHeader) != "key-1" || call.Header.Get(gateway.SubjectHeader) != "" {
+		t.Errorf("identity taken from the message: %v", call.Header)
+	}
+
+	// stock's own calls carry the longer chain
+	if err := h.issueCallToken(call, "shop", "stock"); err != nil {
+		t.Fatal(err)
+	}
+	next, err := h.server.callTokens.Verify(call.Header.Get(gateway.CallTokenHeader), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Function != "shop/stock" || strings.Join(next.Chain, ",") != "shop/orders,shop/stock" || next.ApiKeyID != "key-1" {
+		t.Errorf("callee token = %+v", next)
+	}
+
+	for name, header := range map[string]nats.Header{
+		"missing": {gateway.CallerHeader: {"shop/orders"}},
+		"forged":  {gateway.CallTokenHeader: {token[:len(token)-2] + "xx"}},
+	} {
+		w := httptest.NewRecorder()
+		if _, ok := h.verifyCall(w, header); ok || w.Code != http.StatusUnauthorized {
+			t.Errorf("%s token: ok %v, status %d", name, ok, w.Code)
+		}
+	}
+}
+
+func TestCallTokenFollowsTheDeadline(t *testing.T) {
+	h := &IngestHandler{logger: slog.Default(), server: &Server{callTokens: calltoken.NewSigner([]byte("secret")), callTTL: time.Hour}}
+	deadline := time.Now().Add(time.Minute)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	r := httptest.NewRequest(http.MethodPost, "/", nil).WithContext(ctx)
+	if err := h.issueCallToken(r, "shop", "orders"); err != nil {
+		t.Fatal(err)
+	}
+	id, err := h.server.callTokens.Verify(r.Header.Get(gateway.CallTokenHeader), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !id.Expires.Equal(deadline) {
+		t.Errorf("token expires %s, want the request deadline %s", id.Expires, deadline)
+	}
+
+	// a call can't ask for more time than its token has left
+	header := nats.Header{}
+	header.Set(gateway.DeadlineHeader, time.Now().Add(time.Hour).Format(time.RFC3339Nano))
+	callCtx, callCancel, ok := internalContext(httptest.NewRecorder(), header, id.Expires)
+	if !ok {
+		t.Fatal("call refused")
+	}
+	defer callCancel()
+	if got, _ := callCtx.Deadline(); !got.Equal(deadline) {
+		t.Errorf("call deadline %s, want %s", got, deadline)
+	}
+
+	w := httptest.NewRecorder()
+	if _, _, ok := internalContext(w, nats.Header{}, time.Now().Add(-time.Second)); ok || w.Code != http.StatusGatewayTimeout {
+		t.Errorf("expired call: ok %v, status %d", ok, w.Code)
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+)
+
+// maxErrorSnippet bounds the part of an error response kept in the record,
+// the same amount proxyToRuntime logs.
+const maxErrorSnippet = 512
+
+type invocationKey struct{}
+
+type sourceKey struct{}
+
+// withSource marks requests arriving through another ingress than HTTP.
+func withSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// invocationFrom returns the record of the request being handled, for the
+// handlers that learn about activation and request IDs.
+func invocationFrom(ctx context.Context) *gateway.Invocation {
+	rec, _ := ctx.Value(invocationKey{}).(*gateway.Invocation)
+	return rec
+}
+
+// track starts the invocation record for a request and returns the writer
+// and request to continue with. finish publishes the record once the request
+// has been answered. Publishing is fire-and-forget so a slow or missing
+// stream never holds up requests.
+func (h *IngestHandler) track(w http.ResponseWriter, r *http.Request, project, name, source string) (http.ResponseWriter, *http.Request, func()) {
+	if s, ok := r.Context().Value(sourceKey{}).(string); ok {
+		source = s
+	}
+	rec := &gateway.Invocation{
+		RequestID:    newRequestID(),
+		Project:      project,
+		Function:     name,
+		Endpoint:     r.URL.Path,
+		Method:       r.Method,
+		Source:       source,
+		RequestBytes: max(r.ContentLength, 0),
+		StartedAt:    time.Now().UTC(),
+	}
+	w.Header().Set(gateway.RequestIDHeader, rec.RequestID)
+	tw := &trackingWriter{ResponseWriter: w}
+	r = r.WithContext(context.WithValue(r.Context(), invocationKey{}, rec))
+
+	return tw, r, func() {
+		rec.Status = tw.status
+		if rec.Status == 0 {
+			rec.Status = http.StatusOK
+		}
+		rec.LatencyMs = time.Since(rec.StartedAt).Milliseconds()
+		rec.ResponseBytes = tw.bytes
+		rec.Error = problem.Message(tw.Header(), tw.snippet)
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return
+		}
+		if err := h.server.nc.Publish(gateway.InvocationsSubject+"."+project+"."+name, data); err != nil {
+			h.logger.Warn("failed to publish invocation record", "project", project, "name", name, "error", err)
+		}
+	}
+}
+
+// trackingWriter records the status, size and start of an error body.
+type trackingWriter struct {
+	http.ResponseWriter
+	status  int
+	bytes   int64
+	snippet []byte
+}
+
+func (t *trackingWriter) WriteHeader(status int) {
+	if t.status == 0 {
+		t.status = status
+	}
+	t.ResponseWriter.WriteHeader(status)
+}
+
+func (t *trackingWriter) Write(p []byte) (int, error) {
+	if t.status == 0 {
+		t.status = http.StatusOK
+	}
+	if t.status >= http.StatusBadRequest && len(t.snippet) < maxErrorSnippet {
+		t.snippet = append(t.snippet, p[:min(len(p), maxErrorSnippet-len(t.snippet))]...)
+	}
+	n, err := t.ResponseWriter.Write(p)
+	t.bytes += int64(n)
+	return n, err
+}
+
+func (t *trackingWriter) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// newTestConn starts a NATS server with JetStream for the test and connects
+// to it.
+func newTestConn(t *testing.T) *nats.Conn {
+	t.Helper()
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoSigs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns.Start()
+	t.Cleanup(ns.Shutdown)
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server did not start")
+	}
+	nc, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc.Close)
+	return nc
+}
+
+// trackOne runs handler under track and returns the response and the record
+// published for it.
+func trackOne(t *testing.T, r *http.Request, handler http.HandlerFunc) (*httptest.ResponseRecorder, gateway.Invocation) {
+	t.Helper()
+	nc := newTestConn(t)
+	sub, err := nc.SubscribeSync(gateway.InvocationsSubject + ".shop.orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	h := &IngestHandler{logger: slog.Default(), server: &Server{nc: nc}}
+
+	w := httptest.NewRecorder()
+	tw, tr, finish := h.track(w, r, "shop", "orders", gateway.SourceHTTP)
+	handler(tw, tr)
+	finish()
+
+	msg, err := sub.NextMsg(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec gateway.Invocation
+	if err := json.Unmarshal(msg.Data, &rec); err != nil {
+		t.Fatal(err)
+	}
+	return w, rec
+}
+
+func TestInvocationRecord(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/lambda/shop/orders/items?x=1", strings.NewReader(`{"id":1}`))
+	w, rec := trackOne(t, r, func(w http.ResponseWriter, r *http.Request) {
+		info := invocationFrom(r.Context())
+		info.ColdStart = true
+		info.Async = true
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("queued"))
+	})
+
+	if rec.RequestID == "" || rec.RequestID != w.Header().Get(gateway.RequestIDHeader) {
+		t.Errorf("request id %q, response header %q", rec.RequestID, w.Header().Get(gateway.RequestIDHeader))
+	}
+	if rec.Project != "shop" || rec.Function != "orders" || rec.Endpoint != "/lambda/shop/orders/items" || rec.Method != http.MethodPost {
+		t.Errorf("target = %s/%s %s %s", rec.Project, rec.Function, rec.Method, rec.Endpoint)
+	}
+	if rec.Source != gateway.SourceHTTP || rec.Status != http.StatusAccepted || !rec.ColdStart || !rec.Async {
+		t.Errorf("record = %+v", rec)
+	}
+	if rec.RequestBytes != 8 || rec.ResponseBytes != 6 || rec.Error != "" {
+		t.Errorf("bytes %d/%d, error %q", rec.RequestBytes, rec.ResponseBytes, rec.Error)
+	}
+	if rec.LatencyMs < 0 || rec.StartedAt.IsZero() {
+		t.Errorf("latency %d, started %s", rec.LatencyMs, rec.StartedAt)
+	}
+}
+
+func TestInvocationRecordErrors(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/lambda/shop/orders", nil)
+	_, rec := trackOne(t, r, func(w http.ResponseWriter, r *http.Request) {
+		problem.Write(w, http.StatusBadGateway, problem.UpstreamFailed, "the function could not be reached")
+	})
+	if rec.Status != http.StatusBadGateway || rec.Error != "the function could not be reached" {
+		t.Errorf("status %d, error %q", rec.Status, rec.Error)
+	}
+
+	// runtime errors keep only the start of the body
+	r = httptest.NewRequest(http.MethodGet, "/lambda/shop/orders", nil)
+	r = r.WithContext(withSource(r.Context(), gateway.SourceSchedule))
+	_, rec = trackOne(t, r, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(strings.Repeat("x", 400)))
+		w.Write([]byte(strings.Repeat("y", 400)))
+	})
+	if len(rec.Error) != maxErrorSnippet || rec.ResponseBytes != 800 {
+		t.Errorf("error of %d bytes for a response of %d", len(rec.Error), rec.ResponseBytes)
+	}
+	if rec.Source != gateway.SourceSchedule {
+		t.Errorf("source = %q", rec.Source)
+	}
+
+	// handlers that write nothing answered 200
+	r = httptest.NewRequest(http.MethodGet, "/lambda/shop/orders", nil)
+	if _, rec = trackOne(t, r, func(http.ResponseWriter, *http.Request) {}); rec.Status != http.StatusOK {
+		t.Errorf("status = %d", rec.Status)
+	}
+}
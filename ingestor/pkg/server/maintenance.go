@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+)
+
+const defaultMaintenanceMessage = "this function is temporarily unavailable"
+
+// inMaintenance answers 503 when the function or its project has been taken
+// out of service from the portal. It runs before authentication and
+// activation so a disabled function never starts.
+func (h *IngestHandler) inMaintenance(w http.ResponseWriter, project, name string) bool {
+	m, ok := h.server.maintenance.Get(gateway.MaintenanceKey(project, name))
+	if !ok {
+		m, ok = h.server.maintenance.Get(gateway.MaintenanceKey(project, ""))
+	}
+	if !ok {
+		return false
+	}
+	msg := m.Message
+	if msg == "" {
+		msg = defaultMaintenanceMessage
+	}
+	h.logger.Info("rejecting request, function in maintenance", "project", project, "name", name)
+	problem.Write(w, http.StatusServiceUnavailable, problem.Maintenance, msg)
+	return true
+}
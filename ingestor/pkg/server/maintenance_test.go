@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/registry"
+)
+
+func TestInMaintenance(t *testing.T) {
+	h := &IngestHandler{logger: slog.Default(), server: &Server{
+		maintenance: registry.Of(gateway.MaintenanceBucket, map[string]*gateway.Maintenance{
+			gateway.MaintenanceKey("shop", ""):         {Project: "shop", Message: "shop closed for inventory"},
+			gateway.MaintenanceKey("shop", "orders"):   {Project: "shop", Function: "orders", Message: "orders are being migrated"},
+			gateway.MaintenanceKey("blog", "comments"): {Project: "blog", Function: "comments"},
+		}),
+	}}
+
+	cases := []struct {
+		name     string
+		project  string
+		function string
+		detail   string
+	}{
+		{"function entry wins over the project's", "shop", "orders", "orders are being migrated"},
+		{"project entry covers its other functions", "shop", "catalog", "shop closed for inventory"},
+		{"default message", "blog", "comments", defaultMaintenanceMessage},
+		{"other functions keep serving", "blog", "posts", ""},
+		{"other projects keep serving", "docs", "orders", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			stopped := h.inMaintenance(w, tc.project, tc.function)
+			if stopped != (tc.detail != "") {
+				t.Fatalf("inMaintenance = %v", stopped)
+			}
+			if !stopped {
+				return
+			}
+			var p problem.Details
+			if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != http.StatusServiceUnavailable || p.Code != problem.Maintenance || p.Detail != tc.detail {
+				t.Errorf("answered %d %+v", w.Code, p)
+			}
+		})
+	}
+}
+
+func TestMaintenanceRunsBeforeAuthentication(t *testing.T) {
+	h := &IngestHandler{logger: slog.Default(), server: &Server{
+		maintenance: registry.Of(gateway.MaintenanceBucket, map[string]*gateway.Maintenance{
+			gateway.MaintenanceKey("shop", ""): {Project: "shop"},
+		}),
+		endpoints: registry.Of(gateway.EndpointsBucket, map[string]*gateway.Endpoint{
+			gateway.EndpointKey("shop", "orders", "GET"): {Project: "shop", Method: "GET", Scope: gateway.ScopeAuthn},
+		}),
+	}}
+	chain := middleware.Chain(func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		t.Error("function invoked during maintenance")
+	}, h.stages(middleware.Sync)...)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/lambda/shop/orders", nil)
+	chain(w, r, &middleware.Call{Project: "shop", Function: "orders", Mode: middleware.Sync})
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("unauthenticated request during maintenance answered %d, want 503", w.Code)
+	}
+}
@@ -0,0 +1,286 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/common/proto"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// pipeline builds the handler for an invocation mode: the ingestor's own
+// stages, then the MIDDLEWARE setting and the endpoint's middleware, then
+// the request transforms and final.
+func (h *IngestHandler) pipeline(mode middleware.Mode, final middleware.Handler) http.HandlerFunc {
+	mws := append(h.stages(mode), h.pluginStage)
+	// batch items are transformed one by one, streams are passed through as is
+	if mode == middleware.Sync || mode == middleware.Internal || mode == middleware.GRPC {
+		mws = append(mws, transformStage)
+	}
+	chain := middleware.Chain(final, mws...)
+	return func(w http.ResponseWriter, r *http.Request) {
+		project, name := r.PathValue("project"), r.PathValue("name")
+		if project == "" {
+			project, name = parsePath(r.URL.Path)
+		}
+		c := &middleware.Call{Project: project, Function: name, Mode: mode}
+		if ep, ok := h.server.endpoints.Get(gateway.EndpointKey(project, name, r.Method)); ok {
+			c.Endpoint = ep
+		}
+		chain(w, r, c)
+	}
+}
+
+// stages are the built-in middleware for mode, in the order they run.
+func (h *IngestHandler) stages(mode middleware.Mode) []middleware.Middleware {
+	stages := []middleware.Middleware{h.logStage}
+	// browsers only reach the ingestor over HTTP
+	if mode != middleware.Internal && mode != middleware.GRPC && mode != middleware.GRPCStream {
+		stages = append(stages, h.corsStage)
+	}
+	if mode == middleware.Sync || mode == middleware.Internal || mode == middleware.GRPC {
+		stages = append(stages, h.trackStage)
+	}
+	if mode == middleware.Sync {
+		// ahead of authentication, which strips X-Litefunction-* headers
+		stages = append(stages, h.scheduleStage)
+	}
+	stages = append(stages, h.maintenanceStage, h.authStage)
+	// streams carry no body up front and batches are validated per item
+	if mode != middleware.WS && mode != middleware.GRPCStream && mode != middleware.Batch {
+		stages = append(stages, h.validateStage)
+	}
+	// batches are counted per item once they are read
+	if mode != middleware.Batch {
+		stages = append(stages, h.quotaStage)
+	}
+	return stages
+}
+
+func (h *IngestHandler) logStage(next middleware.Handler) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		attrs := []any{"project", c.Project, "name", c.Function}
+		if c.Mode == middleware.Internal {
+			attrs = append(attrs, "caller", r.Header.Get(gateway.CallerHeader))
+		}
+		h.logger.Info("handling "+string(c.Mode)+" request", attrs...)
+		next(w, r, c)
+	}
+}
+
+func (h *IngestHandler) corsStage(next middleware.Handler) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		if !h.handleCORS(w, r, c.Project, c.Function) {
+			next(w, r, c)
+		}
+	}
+}
+
+func (h *IngestHandler) trackStage(next middleware.Handler) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		source := gateway.SourceHTTP
+		if c.Mode == middleware.Internal {
+			source = gateway.SourceInternal
+		}
+		w, r, finish := h.track(w, r, c.Project, c.Function, source)
+		defer finish()
+		next(w, r, c)
+	}
+}
+
+type runAtKey struct{}
+
+// scheduleStage reads a requested delay, which the final handler turns into
+// a scheduled invocation.
+func (h *IngestHandler) scheduleStage(next middleware.Handler) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		runAt, err := scheduledAt(r, time.Now())
+		if err != nil {
+			problem.Write(w, http.StatusBadRequest, problem.BadRequest, err.Error())
+			return
+		}
+		if !runAt.IsZero() {
+			r = r.WithContext(context.WithValue(r.Context(), runAtKey{}, runAt))
+		}
+		next(w, r, c)
+	}
+}
+
+func (h *IngestHandler) maintenanceStage(next middleware.Handler) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		if !h.inMaintenance(w, c.Project, c.Function) {
+			next(w, r, c)
+		}
+	}
+}
+
+// authStage identifies the caller, by its credentials or, for calls from
+// another function, by the call chain, applies the endpoint's policy and
+// signs the identity the function's own calls will carry.
+func (h *IngestHandler) authStage(next middleware.Handler) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		if c.Mode == middleware.Internal {
+			if !h.checkCall(w, r, c.Project, c.Function) {
+				return
+			}
+		} else if !h.authenticate(w, r, c.Project, c.Function) {
+			return
+		}
+		if !h.authorize(w, r, c.Project, c.Function) {
+			return
+		}
+		if err := h.issueCallToken(r, c.Project, c.Function); err != nil {
+			h.logger.Error("failed to sign call token", "project", c.Project, "name", c.Function, "error", err)
+			problem.Write(w, http.StatusInternalServerError, problem.Internal, "could not sign the call identity")
+			return
+		}
+		next(w, r, c)
+	}
+}
+
+func (h *IngestHandler) validateStage(next middleware.Handler) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		if h.validateRequest(w, r, c.Project, c.Function) {
+			next(w, r, c)
+		}
+	}
+}
+
+// pluginStage runs the middleware named in MIDDLEWARE and on the endpoint.
+// Names this ingestor wasn't built with are skipped, the portal can't know
+// which are compiled in. A known middleware that can't be built fails the
+// request rather than being skipped, since it may well be the one enforcing
+// access.
+func (h *IngestHandler) pluginStage(next middleware.Handler) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		var configs []gateway.MiddlewareConfig
+		if c.Endpoint != nil {
+			configs = c.Endpoint.Middleware
+		}
+		mws, err := h.server.plugins.resolve(configs)
+		if err != nil {
+			h.logger.Error("failed to build endpoint middleware", "project", c.Project, "name", c.Function, "error", err)
+			problem.Write(w, http.StatusInternalServerError, problem.Internal, "endpoint middleware unavailable")
+			return
+		}
+		middleware.Chain(next, mws...)(w, r, c)
+	}
+}
+
+// activate starts the function when needed and checks the request method
+// against the one it was deployed for.
+func (h *IngestHandler) activate(w http.ResponseWriter, r *http.Request, project, name string) (*proto.ActivateResponse, bool) {
+	info, err := h.server.activateFunction(project, name)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			h.logger.Warn("function not found", "project", project, "name", name, "error", err)
+			problem.Write(w, http.StatusNotFound, problem.FunctionNotFound, fmt.Sprintf("function %s not found in project %s", name, project))
+			return nil, false
+		}
+		h.logger.Error("failed to activate function", "project", project, "name", name, "error", err)
+		problem.Write(w, http.StatusServiceUnavailable, problem.ActivationFailed, "the function could not be started, retry later")
+		return nil, false
+	}
+	if rec := invocationFrom(r.Context()); rec != nil {
+		rec.ColdStart = info.ColdStart
+		rec.Async = info.IsAsync
+	}
+	if !h.validateMethod(w, r, info.Method, project, name) {
+		return nil, false
+	}
+	return info, true
+}
+
+// errUnknownMiddleware is returned for names no middleware was registered as.
+var errUnknownMiddleware = errors.New("middleware is not compiled into this ingestor")
+
+// plugins builds registered middleware once per distinct config.
+type plugins struct {
+	global []middleware.Middleware
+
+	mu    sync.Mutex
+	built map[string]middleware.Middleware
+	// unknown holds the endpoint middleware names already reported missing.
+	unknown map[string]bool
+}
+
+// newPlugins resolves the MIDDLEWARE setting, so a misspelt name stops the
+// ingestor at startup.
+func newPlugins(raw string) (*plugins, error) {
+	p := &plugins{built: map[string]middleware.Middleware{}, unknown: map[string]bool{}}
+	configs, err := middleware.ParseList(raw)
+	if err != nil {
+		return nil, err
+	}
+	for _, cfg := range configs {
+		mw, err := p.build(cfg)
+		if err != nil {
+			return nil, err
+		}
+		p.global = append(p.global, mw)
+	}
+	return p, nil
+}
+
+func (p *plugins) resolve(configs []gateway.MiddlewareConfig) ([]middleware.Middleware, error) {
+	if len(configs) == 0 {
+		return p.global, nil
+	}
+	mws := append([]middleware.Middleware{}, p.global...)
+	for _, cfg := range configs {
+		mw, err := p.build(cfg)
+		if errors.Is(err, errUnknownMiddleware) {
+			p.reportUnknown(cfg.Name)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		mws = append(mws, mw)
+	}
+	return mws, nil
+}
+
+// reportUnknown logs a missing middleware the first time an endpoint names it.
+func (p *plugins) reportUnknown(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.unknown[name] {
+		p.unknown[name] = true
+		slog.Warn("skipping endpoint middleware that is not compiled into this ingestor", "middleware", name)
+	}
+}
+
+func (p *plugins) build(cfg gateway.MiddlewareConfig) (middleware.Middleware, error) {
+	values := url.Values{}
+	for k, v := range cfg.Config {
+		values.Set(k, v)
+	}
+	key := cfg.Name + "?" + values.Encode()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if mw, ok := p.built[key]; ok {
+		return mw, nil
+	}
+	factory, ok := middleware.Lookup(cfg.Name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", errUnknownMiddleware, cfg.Name)
+	}
+	mw, err := factory(cfg.Config)
+	if err != nil {
+		return nil, fmt.Errorf("error building middleware %q: %w", cfg.Name, err)
+	}
+	p.built[key] = mw
+	return mw, nil
+}
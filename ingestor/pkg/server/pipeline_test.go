@@ -0,0 +1,51 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+)
+
+func TestPluginStage(t *testing.T) {
+	plugins, err := newPlugins("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &IngestHandler{logger: slog.Default(), server: &Server{plugins: plugins}}
+	var served int
+	stage := h.pluginStage(func(w http.ResponseWriter, r *http.Request, c *middleware.Call) { served++ })
+
+	cases := []struct {
+		name       string
+		middleware []gateway.MiddlewareConfig
+		want       []int
+	}{
+		{"unknown names are skipped", []gateway.MiddlewareConfig{{Name: "no-such-middleware"}}, []int{http.StatusOK, http.StatusOK}},
+		{"known ones still run", []gateway.MiddlewareConfig{
+			{Name: "no-such-middleware"},
+			{Name: "ratelimit", Config: map[string]string{"rps": "1", "burst": "1"}},
+		}, []int{http.StatusOK, http.StatusTooManyRequests}},
+		{"broken config fails the request", []gateway.MiddlewareConfig{
+			{Name: "ratelimit", Config: map[string]string{"rps": "fast"}},
+		}, []int{http.StatusInternalServerError}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &middleware.Call{Project: "shop", Function: tc.name, Endpoint: &gateway.Endpoint{Middleware: tc.middleware}}
+			for i, want := range tc.want {
+				w := httptest.NewRecorder()
+				stage(w, httptest.NewRequest(http.MethodGet, "/lambda/shop/orders", nil), c)
+				if w.Code != want {
+					t.Errorf("request %d: status %d, want %d", i, w.Code, want)
+				}
+			}
+		})
+	}
+	if served != 3 {
+		t.Errorf("%d requests served, want 3", served)
+	}
+}
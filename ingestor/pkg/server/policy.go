@@ -0,0 +1,145 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/common/policy"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+)
+
+// authorize evaluates the endpoint policy after authentication, so rules can
+// use the verified identity, and before activation so denied requests never
+// wake a runtime. Audit mode logs the decision and lets the request through.
+func (h *IngestHandler) authorize(w http.ResponseWriter, r *http.Request, project, name string) bool {
+	ep, ok := h.server.endpoints.Get(gateway.EndpointKey(project, name, r.Method))
+	if !ok || ep.Policy == nil {
+		return true
+	}
+
+	decision := h.server.policies.Evaluate(ep.Policy, h.policyInput(r))
+	attrs := []any{
+		"project", project,
+		"name", name,
+		"mode", ep.Policy.Mode,
+		"allow", decision.Allow,
+		"rule", decision.Rule,
+	}
+	if decision.Err != nil {
+		attrs = append(attrs, "error", decision.Err)
+	}
+
+	if ep.Policy.Mode == gateway.PolicyAudit {
+		h.logger.Info("policy decision", attrs...)
+		return true
+	}
+	if !decision.Allow {
+		h.logger.Warn("request denied by policy", attrs...)
+		problem.Write(w, http.StatusForbidden, problem.Forbidden, "denied by policy")
+		return false
+	}
+	h.logger.Debug("policy decision", attrs...)
+	return true
+}
+
+func (h *IngestHandler) policyInput(r *http.Request) policy.Input {
+	in := policy.Input{
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		IP:       h.server.proxies.clientIP(r),
+		Headers:  make(map[string]string, len(r.Header)),
+		Query:    map[string]string{},
+		Subject:  r.Header.Get(gateway.SubjectHeader),
+		ApiKeyID: r.Header.Get(gateway.ApiKeyIDHeader),
+	}
+	for k := range r.Header {
+		in.Headers[strings.ToLower(k)] = r.Header.Get(k)
+	}
+	for k, vals := range r.URL.Query() {
+		if len(vals) > 0 {
+			in.Query[k] = vals[0]
+		}
+	}
+	if raw := r.Header.Get(gateway.ClaimsHeader); raw != "" {
+		if data, err := base64.RawURLEncoding.DecodeString(raw); err == nil {
+			_ = json.Unmarshal(data, &in.Claims)
+		}
+	}
+	return in
+}
+
+// proxies knows which hops of X-Forwarded-For were added by proxies in front
+// of the ingestor. Only the part of the header they appended can be
+// believed: everything to its left came from the client.
+type proxies struct {
+	// forwarded is set when the ingestor is reached through a proxy that
+	// appends the address it was connected from
+	forwarded bool
+	trusted   []netip.Prefix
+}
+
+func newProxies(forwarded bool, cidrs string) (*proxies, error) {
+	p := &proxies{forwarded: forwarded}
+	for _, cidr := range strings.Split(cidrs, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(cidr)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		p.trusted = append(p.trusted, prefix.Masked())
+	}
+	return p, nil
+}
+
+// clientIP walks X-Forwarded-For from the right, past the proxy that
+// connected to the ingestor and any hop from a trusted range, and returns the
+// first address no trusted proxy vouches for.
+func (p *proxies) clientIP(r *http.Request) string {
+	peer := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		peer = host
+	}
+	if p == nil || !p.forwarded {
+		return peer
+	}
+	var hops []string
+	for _, fwd := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(fwd, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		client = hops[i]
+		addr, err := netip.ParseAddr(client)
+		if err != nil || !p.trusts(addr) {
+			break
+		}
+	}
+	return client
+}
+
+func (p *proxies) trusts(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
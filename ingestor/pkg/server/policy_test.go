@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	cases := []struct {
+		name      string
+		forwarded bool
+		trusted   string
+		xff       []string
+		want      string
+	}{
+		{"header ignored unless trusted", false, "", []string{"1.1.1.1"}, "10.0.0.9"},
+		{"no header", true, "", nil, "10.0.0.9"},
+		{"right-most hop", true, "", []string{"1.1.1.1, 2.2.2.2"}, "2.2.2.2"},
+		{"forged left-most hop ignored", true, "", []string{"6.6.6.6, 203.0.113.7"}, "203.0.113.7"},
+		{"trusted proxies skipped", true, "172.16.0.0/12, 192.0.2.1", []string{"6.6.6.6, 203.0.113.7, 192.0.2.1, 172.16.4.4"}, "203.0.113.7"},
+		{"repeated headers", true, "172.16.0.0/12", []string{"6.6.6.6", "203.0.113.7, 172.16.4.4"}, "203.0.113.7"},
+		{"garbage stops the walk", true, "172.16.0.0/12", []string{"6.6.6.6, nonsense, 172.16.4.4"}, "nonsense"},
+		{"all hops trusted", true, "172.16.0.0/12", []string{"172.16.1.1"}, "172.16.1.1"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := newProxies(tc.forwarded, tc.trusted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = "10.0.0.9:51234"
+			for _, v := range tc.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if got := p.clientIP(r); got != tc.want {
+				t.Errorf("clientIP = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := newProxies(true, "10.0.0.0/33"); err == nil {
+		t.Error("invalid cidr accepted")
+	}
+}
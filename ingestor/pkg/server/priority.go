@@ -0,0 +1,38 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+)
+
+// requestedPriority returns the lane the caller asked for with
+// PriorityHeader, empty when it didn't.
+func requestedPriority(r *http.Request) (string, error) {
+	priority := strings.ToLower(strings.TrimSpace(r.Header.Get(gateway.PriorityHeader)))
+	if priority != "" && !gateway.ValidPriority(priority) {
+		return "", fmt.Errorf("%s must be one of high, normal, low", gateway.PriorityHeader)
+	}
+	return priority, nil
+}
+
+// asyncPriority picks the lane of an async request: the caller's choice, then
+// the endpoint's, then the normal lane. Unknown priorities are answered with
+// 400.
+func (h *IngestHandler) asyncPriority(w http.ResponseWriter, r *http.Request, project, name string) (string, bool) {
+	priority, err := requestedPriority(r)
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, problem.BadRequest, err.Error())
+		return "", false
+	}
+	if priority != "" {
+		return priority, true
+	}
+	if ep, ok := h.server.endpoints.Get(gateway.EndpointKey(project, name, r.Method)); ok && ep.Priority != "" {
+		return ep.Priority, true
+	}
+	return gateway.PriorityNormal, true
+}
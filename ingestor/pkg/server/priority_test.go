@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+)
+
+func TestRequestedPriority(t *testing.T) {
+	cases := []struct {
+		header  string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"high", gateway.PriorityHigh, false},
+		{" LOW ", gateway.PriorityLow, false},
+		{"urgent", "", true},
+	}
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/lambda/shop/orders", nil)
+		r.Header.Set(gateway.PriorityHeader, tc.header)
+		got, err := requestedPriority(r)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("requestedPriority(%q) = %q, %v; want %q", tc.header, got, err, tc.want)
+		}
+	}
+}
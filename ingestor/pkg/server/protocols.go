@@ -0,0 +1,113 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/upstream"
+)
+
+// newHTTPServer serves HTTP/1.1 and, unless H2C_ENABLED is off, cleartext
+// HTTP/2 so the Gateway can multiplex requests over a few connections.
+// HTTP/2 over TLS is negotiated by ALPN when the server is started with TLS.
+func newHTTPServer(addr string, handler http.Handler) *http.Server {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(pkg.Settings.H2C)
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		Protocols:         protocols,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// newTLSServer terminates TLS with the certificate in TLS_CERT_FILE and
+// TLS_KEY_FILE. The files are usually a mounted secret, they are read again
+// when they change so renewed certificates are picked up without a restart.
+func newTLSServer(addr string, handler http.Handler) (*http.Server, error) {
+	certs := &certLoader{certFile: pkg.Settings.TLSCertFile, keyFile: pkg.Settings.TLSKeyFile}
+	if _, err := certs.GetCertificate(nil); err != nil {
+		return nil, err
+	}
+	srv := newHTTPServer(addr, handler)
+	srv.TLSConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+	}
+	return srv, nil
+}
+
+// certLoader keeps the loaded key pair and reloads it once the files are
+// modified, checking at most every certCheckInterval.
+type certLoader struct {
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+const certCheckInterval = 30 * time.Second
+
+func (c *certLoader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cert != nil && time.Since(c.checked) < certCheckInterval {
+		return c.cert, nil
+	}
+	c.checked = time.Now()
+	info, err := os.Stat(c.certFile)
+	if err != nil {
+		if c.cert != nil {
+			slog.Warn("failed to check tls certificate, keeping the loaded one", "error", err)
+			return c.cert, nil
+		}
+		return nil, fmt.Errorf("error reading tls certificate: %w", err)
+	}
+	if c.cert != nil && info.ModTime().Equal(c.modTime) {
+		return c.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		if c.cert != nil {
+			slog.Warn("failed to reload tls certificate, keeping the loaded one", "error", err)
+			return c.cert, nil
+		}
+		return nil, fmt.Errorf("error loading tls certificate: %w", err)
+	}
+	if c.cert != nil {
+		slog.Info("tls certificate reloaded", "file", c.certFile)
+	}
+	c.cert, c.modTime = &cert, info.ModTime()
+	return c.cert, nil
+}
+
+// parseLanguages reads a comma separated list such as UPSTREAM_H2C_LANGUAGES.
+func parseLanguages(raw string) map[string]bool {
+	languages := map[string]bool{}
+	for _, lang := range strings.Split(raw, ",") {
+		if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "" {
+			languages[lang] = true
+		}
+	}
+	return languages
+}
+
+// upstreamProtocol picks cleartext HTTP/2 for runtimes listed in
+// UPSTREAM_H2C_LANGUAGES and HTTP/1.1 for the rest.
+func (s *Server) upstreamProtocol(language string) upstream.Protocol {
+	if s.h2cLanguages[strings.ToLower(language)] {
+		return upstream.H2C
+	}
+	return upstream.HTTP1
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/quota"
+)
+
+// quotaStage counts the request against the project's quota and the api
+// key's. Batches are counted per item once they are read.
+func (h *IngestHandler) quotaStage(next middleware.Handler) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		if h.consumeQuota(w, r, c.Project, c.Function, 1) {
+			next(w, r, c)
+		}
+	}
+}
+
+// consumeQuota counts n invocations and reports the tightest quota in the
+// response headers, or answers 429 when a quota doesn't have n left. Quotas
+// are not enforced while the counters can't be reached, rather than failing
+// every request.
+func (h *IngestHandler) consumeQuota(w http.ResponseWriter, r *http.Request, project, name string, n int64) bool {
+	if h.server.quotaUsage == nil {
+		return true
+	}
+	projectQuota, _ := h.server.quotas.Get(gateway.QuotaKey(project, ""))
+	var keyQuota *gateway.Quota
+	if keyID := r.Header.Get(gateway.ApiKeyIDHeader); keyID != "" {
+		keyQuota, _ = h.server.quotas.Get(gateway.QuotaKey(project, keyID))
+	}
+	now := time.Now()
+	limits := quota.Limits(now,
+		quota.Scoped{Scope: "api key", Quota: keyQuota},
+		quota.Scoped{Scope: "project", Quota: projectQuota},
+	)
+	if len(limits) == 0 {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+	status, err := h.server.quotaUsage.Consume(ctx, limits, n)
+	if err != nil {
+		h.logger.Error("failed to count quota, letting the request through", "project", project, "name", name, "error", err)
+		return true
+	}
+	reset := strconv.Itoa(int(math.Ceil(status.Limit.Reset.Sub(now).Seconds())))
+	w.Header().Set(gateway.QuotaLimitHeader, strconv.FormatInt(status.Limit.Max, 10))
+	w.Header().Set(gateway.QuotaRemainingHeader, strconv.FormatInt(status.Remaining, 10))
+	w.Header().Set(gateway.QuotaResetHeader, reset)
+	if !status.Exceeded {
+		return true
+	}
+
+	h.logger.Warn("quota exhausted", "project", project, "name", name, "scope", status.Limit.Scope, "period", status.Limit.Period)
+	w.Header().Set("Retry-After", reset)
+	detail := fmt.Sprintf("the %s's %s quota of %d invocations is used up", status.Limit.Scope, periodName(status.Limit.Period), status.Limit.Max)
+	if n > 1 {
+		detail = fmt.Sprintf("a batch of %d items exceeds the %s's %s quota, %d invocations are left", n, status.Limit.Scope, periodName(status.Limit.Period), status.Remaining)
+	}
+	problem.Write(w, http.StatusTooManyRequests, problem.QuotaExceeded, detail)
+	return false
+}
+
+func periodName(period string) string {
+	if period == gateway.QuotaDaily {
+		return "daily"
+	}
+	return "monthly"
+}
@@ -7,5 +7,9 @@ func (s *Server) BuildRoutes() {
 	http.HandleFunc("/lambda/{project}/{name}", handler.Sync)
 	http.HandleFunc("/lambda/sse/{project}/{name}", handler.SSE)
 	http.HandleFunc("/lambda/ws/{project}/{name}", handler.WS)
+	http.HandleFunc("POST /batch/{project}/{name}", handler.Batch)
+	http.HandleFunc("GET /batch/{project}/{name}/{id}", handler.BatchStatus)
+	http.HandleFunc("GET /schedule/{project}/{name}/{id}", handler.Schedule)
+	http.HandleFunc("DELETE /schedule/{project}/{name}/{id}", handler.Schedule)
 	http.HandleFunc("/hook/{language}/{project}", handler.RuntimeHook)
 }
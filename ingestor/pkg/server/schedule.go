@@ -0,0 +1,215 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/schedule"
+)
+
+// unstoredHeaders are credentials that aren't kept with a scheduled request.
+// The identity they established is carried by the X-Litefunction-* headers
+// set during authentication, and signed again when the request runs.
+var unstoredHeaders = []string{"Authorization", "Cookie", gateway.ApiKeyHeader, gateway.CallTokenHeader}
+
+// scheduledAt reads DelayHeader or RunAtHeader, returning the zero time when
+// the request should run right away. It has to run before authentication
+// strips X-Litefunction-* headers.
+func scheduledAt(r *http.Request, now time.Time) (time.Time, error) {
+	delay, runAt := r.Header.Get(gateway.DelayHeader), r.Header.Get(gateway.RunAtHeader)
+	var at time.Time
+	switch {
+	case delay != "" && runAt != "":
+		return time.Time{}, fmt.Errorf("set either %s or %s", gateway.DelayHeader, gateway.RunAtHeader)
+	case delay != "":
+		if _, err := strconv.Atoi(delay); err == nil {
+			delay += "s"
+		}
+		d, err := time.ParseDuration(delay)
+		if err != nil || d < 0 {
+			return time.Time{}, fmt.Errorf("%s must be a duration or a number of seconds", gateway.DelayHeader)
+		}
+		at = now.Add(d)
+	case runAt != "":
+		t, err := time.Parse(time.RFC3339, runAt)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp", gateway.RunAtHeader)
+		}
+		at = t
+	default:
+		return time.Time{}, nil
+	}
+	maxDelay, err := time.ParseDuration(pkg.Settings.ScheduleMaxDelay)
+	if err == nil && at.Sub(now) > maxDelay {
+		return time.Time{}, fmt.Errorf("invocations can be scheduled at most %s ahead", maxDelay)
+	}
+	return at.UTC(), nil
+}
+
+// schedule stores the request to be invoked at runAt and answers 202 with
+// the schedule ID.
+func (h *IngestHandler) schedule(w http.ResponseWriter, r *http.Request, project, name string, runAt time.Time) {
+	if h.server.schedules == nil {
+		problem.Write(w, http.StatusServiceUnavailable, problem.FeatureDisabled, "scheduled invocations are not enabled")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(pkg.Settings.ScheduleMaxBody)))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.Write(w, http.StatusRequestEntityTooLarge, problem.PayloadTooLarge, err.Error())
+			return
+		}
+		problem.Write(w, http.StatusBadRequest, problem.BadRequest, err.Error())
+		return
+	}
+	header := r.Header.Clone()
+	for _, k := range unstoredHeaders {
+		header.Del(k)
+	}
+	sch := &gateway.Schedule{
+		ID:       schedule.NewID(),
+		Project:  project,
+		Function: name,
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		RawQuery: r.URL.RawQuery,
+		Header:   header,
+		Body:     body,
+		RunAt:    runAt,
+		Owner:    requestOwner(r.Header),
+	}
+	if err := h.server.schedules.Add(r.Context(), sch); err != nil {
+		h.logger.Error("failed to schedule invocation", "project", project, "name", name, "error", err)
+		problem.Write(w, http.StatusInternalServerError, problem.Internal, "could not schedule the invocation")
+		return
+	}
+	h.logger.Info("invocation scheduled", "project", project, "name", name, "schedule_id", sch.ID, "run_at", runAt)
+	statusURL := "/schedule/" + project + "/" + name + "/" + sch.ID
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", statusURL)
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id":         sch.ID,
+		"run_at":     sch.RunAt,
+		"status_url": statusURL,
+	})
+}
+
+// requestOwner names the credential an authenticated request was made with.
+func requestOwner(header http.Header) string {
+	if id := header.Get(gateway.ApiKeyIDHeader); id != "" {
+		return "key:" + id
+	}
+	if sub := header.Get(gateway.SubjectHeader); sub != "" {
+		return "sub:" + sub
+	}
+	return ""
+}
+
+// runScheduled replays a due request through dispatch. It was authorized and
+// validated when it was scheduled, only maintenance is checked again and the
+// endpoint's transforms applied.
+func (h *IngestHandler) runScheduled(ctx context.Context, sch *gateway.Schedule) (string, int, error) {
+	u := url.URL{Path: sch.Path, RawQuery: sch.RawQuery}
+	r, err := http.NewRequestWithContext(ctx, sch.Method, u.String(), bytes.NewReader(sch.Body))
+	if err != nil {
+		return "", 0, err
+	}
+	r.Header = http.Header(sch.Header).Clone()
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+
+	if err := h.issueCallToken(r, sch.Project, sch.Function); err != nil {
+		return "", 0, err
+	}
+	if r, err = h.endpointTransform(r, sch.Project, sch.Function); err != nil {
+		return "", http.StatusBadRequest, err
+	}
+
+	w := &bufferedResponse{header: http.Header{}}
+	tw, r, finish := h.track(w, r, sch.Project, sch.Function, gateway.SourceSchedule)
+	if !h.inMaintenance(tw, sch.Project, sch.Function) {
+		h.dispatch(tw, r, sch.Project, sch.Function)
+	}
+	finish()
+
+	var reqID string
+	if rec := invocationFrom(r.Context()); rec != nil {
+		reqID = rec.RequestID
+	}
+	status := w.statusCode()
+	if status >= http.StatusBadRequest {
+		return reqID, status, errors.New(problem.Message(w.header, w.body.Bytes()))
+	}
+	return reqID, status, nil
+}
+
+// Schedule reports a scheduled invocation on GET and cancels it on DELETE.
+// Callers need the credential the invocation was scheduled with, others are
+// told it doesn't exist.
+func (h *IngestHandler) Schedule(w http.ResponseWriter, r *http.Request) {
+	project, name, id := r.PathValue("project"), r.PathValue("name"), r.PathValue("id")
+	if h.server.schedules == nil {
+		problem.Write(w, http.StatusServiceUnavailable, problem.FeatureDisabled, "scheduled invocations are not enabled")
+		return
+	}
+	sch, err := h.server.schedules.Get(r.Context(), project, name, id)
+	if errors.Is(err, schedule.ErrNotFound) {
+		problem.Write(w, http.StatusNotFound, problem.NotFound, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to read schedule", "project", project, "name", name, "schedule_id", id, "error", err)
+		problem.Write(w, http.StatusInternalServerError, problem.Internal, "could not read the schedule")
+		return
+	}
+	auth := r.Clone(r.Context())
+	auth.Method = sch.Method
+	if !h.authenticate(w, auth, project, name) || !h.authorize(w, auth, project, name) {
+		return
+	}
+	if requestOwner(auth.Header) != sch.Owner {
+		h.logger.Warn("schedule requested by another caller", "project", project, "name", name, "schedule_id", id)
+		problem.Write(w, http.StatusNotFound, problem.NotFound, schedule.ErrNotFound.Error())
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		sch, err = h.server.schedules.Cancel(r.Context(), project, name, id)
+		switch {
+		case errors.Is(err, schedule.ErrNotPending):
+			problem.Write(w, http.StatusConflict, problem.Conflict, fmt.Sprintf("%s (%s)", err, sch.Status))
+			return
+		case err != nil:
+			h.logger.Error("failed to cancel schedule", "project", project, "name", name, "schedule_id", id, "error", err)
+			problem.Write(w, http.StatusInternalServerError, problem.Internal, "could not cancel the schedule")
+			return
+		}
+		h.logger.Info("scheduled invocation cancelled", "project", project, "name", name, "schedule_id", id)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id":              sch.ID,
+		"status":          sch.Status,
+		"method":          sch.Method,
+		"run_at":          sch.RunAt,
+		"request_id":      sch.RequestID,
+		"response_status": sch.ResponseStatus,
+		"error":           sch.Error,
+		"created_at":      sch.CreatedAt,
+		"updated_at":      sch.UpdatedAt,
+	})
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/registry"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/schedule"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func TestScheduledAt(t *testing.T) {
+	pkg.Settings = &pkg.IngestorConf{ScheduleMaxDelay: "24h"}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name    string
+		header  map[string]string
+		want    time.Time
+		wantErr bool
+	}{
+		{"immediate", nil, time.Time{}, false},
+		{"seconds", map[string]string{gateway.DelayHeader: "90"}, now.Add(90 * time.Second), false},
+		{"duration", map[string]string{gateway.DelayHeader: "2h"}, now.Add(2 * time.Hour), false},
+		{"run at", map[string]string{gateway.RunAtHeader: "2026-03-01T13:30:00+01:00"}, now.Add(30 * time.Minute), false},
+		{"negative", map[string]string{gateway.DelayHeader: "-5s"}, time.Time{}, true},
+		{"too far", map[string]string{gateway.DelayHeader: "48h"}, time.Time{}, true},
+		{"both", map[string]string{gateway.DelayHeader: "1m", gateway.RunAtHeader: "2026-03-01T13:00:00Z"}, time.Time{}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/lambda/shop/orders", nil)
+			for k, v := range tc.header {
+				r.Header.Set(k, v)
+			}
+			got, err := scheduledAt(r, now)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tc.wantErr)
+			}
+			if !got.Equal(tc.want) {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScheduleOwner(t *testing.T) {
+	js, err := jetstream.New(newTestConn(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedules, err := schedule.NewScheduler(context.Background(), js, schedule.Options{DispatchTimeout: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &IngestHandler{logger: slog.Default(), server: &Server{
+		schedules: schedules,
+		endpoints: registry.Of(gateway.EndpointsBucket, map[string]*gateway.Endpoint{
+			gateway.EndpointKey("shop", "orders", "POST"): {ID: "ep1", Project: "shop", Scope: gateway.ScopeAuthn},
+		}),
+		apiKeys: registry.Of(gateway.ApiKeysBucket, map[string]*gateway.ApiKey{
+			gateway.HashApiKey("lf_owner"): {ID: "k1", Project: "shop"},
+			gateway.HashApiKey("lf_other"): {ID: "k2", Project: "shop"},
+		}),
+		usage: newUsageReporter(nil),
+	}}
+	h.server.usage.last["k1"] = time.Now().UTC()
+	h.server.usage.last["k2"] = time.Now().UTC()
+
+	// scheduled through the pipeline, which authenticates with the owner's key
+	r := httptest.NewRequest(http.MethodPost, "/lambda/shop/orders", nil)
+	r.Header.Set(gateway.ApiKeyHeader, "lf_owner")
+	if !h.authenticate(httptest.NewRecorder(), r, "shop", "orders") {
+		t.Fatal("owner's key refused")
+	}
+	pkg.Settings = &pkg.IngestorConf{ScheduleMaxBody: 1024}
+	w := httptest.NewRecorder()
+	h.schedule(w, r, "shop", "orders", time.Now().Add(time.Hour))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("schedule = %d %s", w.Code, w.Body)
+	}
+	id := w.Header().Get("Location")[len("/schedule/shop/orders/"):]
+
+	call := func(method, key string) int {
+		r := httptest.NewRequest(method, "/schedule/shop/orders/"+id, nil)
+		r.SetPathValue("project", "shop")
+		r.SetPathValue("name", "orders")
+		r.SetPathValue("id", id)
+		r.Header.Set(gateway.ApiKeyHeader, key)
+		w := httptest.NewRecorder()
+		h.Schedule(w, r)
+		return w.Code
+	}
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		if code := call(method, "lf_other"); code != http.StatusNotFound {
+			t.Errorf("%s with another key = %d", method, code)
+		}
+	}
+	if code := call(http.MethodGet, "lf_owner"); code != http.StatusOK {
+		t.Errorf("GET by the owner = %d", code)
+	}
+	if code := call(http.MethodDelete, "lf_owner"); code != http.StatusOK {
+		t.Errorf("DELETE by the owner = %d", code)
+	}
+}
+
+func TestRequestOwner(t *testing.T) {
+	cases := []struct {
+		header map[string]string
+		want   string
+	}{
+		{map[string]string{gateway.ApiKeyIDHeader: "k1", gateway.SubjectHeader: "user-1"}, "key:k1"},
+		{map[string]string{gateway.SubjectHeader: "user-1"}, "sub:user-1"},
+		{nil, ""},
+	}
+	for _, tc := range cases {
+		header := http.Header{}
+		for k, v := range tc.header {
+			header.Set(k, v)
+		}
+		if got := requestOwner(header); got != tc.want {
+			t.Errorf("requestOwner(%v) = %q, want %q", tc.header, got, tc.want)
+		}
+	}
+}
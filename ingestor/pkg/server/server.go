@@ -2,24 +2,67 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
 
-	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/common/policy"
 	"github.com/ashupednekar/litefunctions/common/proto"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/batch"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/broker"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/cache"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/callback"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/calltoken"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/idempotency"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/jwtauth"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware/metrics"
+	// registers the "ratelimit" middleware
+	_ "github.com/ashupednekar/litefunctions/ingestor/pkg/middleware/ratelimit"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/quota"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/registry"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/schedule"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/upstream"
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
 type Server struct {
-	port       int
-	nc         *nats.Conn
-	logger     *slog.Logger
-	grpcClient proto.FunctionServiceClient
-	grpcConn   *grpc.ClientConn
+	port        int
+	nc          *nats.Conn
+	js          jetstream.JetStream
+	logger      *slog.Logger
+	grpcClient  proto.FunctionServiceClient
+	grpcConn    *grpc.ClientConn
+	idem        *idempotency.Store
+	endpoints   *registry.Registry[gateway.Endpoint]
+	apiKeys     *registry.Registry[gateway.ApiKey]
+	maintenance *registry.Registry[gateway.Maintenance]
+	domains     *registry.Registry[gateway.Domain]
+	quotas      *registry.Registry[gateway.Quota]
+	quotaUsage  *quota.Store
+	usage       *usageReporter
+	jwt         *jwtauth.Verifier
+	policies    *policy.Engine
+	cache       *cache.Store
+	schemas     *schemaCache
+	payloads    *broker.Payloads
+	callbacks   *callback.Dispatcher
+	batches     *batch.Runner
+	schedules   *schedule.Scheduler
+	upstream    *upstream.Client
+	proxies     *proxies
+	callTokens  *calltoken.Signer
+	callTTL     time.Duration
+
+	h2cLanguages map[string]bool
+	plugins      *plugins
 }
 
 func NewServer(nc *nats.Conn) (*Server, error) {
@@ -30,20 +73,364 @@ func NewServer(nc *nats.Conn) (*Server, error) {
 
 	client := proto.NewFunctionServiceClient(conn)
 
-	return &Server{
-		port:       pkg.Settings.ListenPort,
-		nc:         nc,
-		logger:     slog.Default(),
-		grpcClient: client,
-		grpcConn:   conn,
-	}, nil
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+
+	endpoints, err := registry.New[gateway.Endpoint](context.Background(), js, gateway.EndpointsBucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load endpoints: %w", err)
+	}
+	apiKeys, err := registry.New[gateway.ApiKey](context.Background(), js, gateway.ApiKeysBucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load api keys: %w", err)
+	}
+	maintenance, err := registry.New[gateway.Maintenance](context.Background(), js, gateway.MaintenanceBucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load maintenance flags: %w", err)
+	}
+	domains, err := registry.New[gateway.Domain](context.Background(), js, gateway.DomainsBucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load custom domains: %w", err)
+	}
+	quotas, err := registry.New[gateway.Quota](context.Background(), js, gateway.QuotasBucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load quotas: %w", err)
+	}
+
+	jwksRefresh, err := time.ParseDuration(pkg.Settings.JwksRefreshInterval)
+	if err != nil {
+		return nil, fmt.Errorf("jwks refresh interval improperly configured: %w", err)
+	}
+	jwksMaxStale, err := time.ParseDuration(pkg.Settings.JwksMaxStale)
+	if err != nil {
+		return nil, fmt.Errorf("jwks max stale improperly configured: %w", err)
+	}
+	trustedProxies, err := newProxies(pkg.Settings.TrustForwardedFor, pkg.Settings.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("trusted proxies improperly configured: %w", err)
+	}
+
+	upstreamClient, err := newUpstreamClient()
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := policy.NewEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	callTTL, err := time.ParseDuration(pkg.Settings.CallTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("call token ttl improperly configured: %w", err)
+	}
+
+	plugins, err := newPlugins(pkg.Settings.Middleware)
+	if err != nil {
+		return nil, fmt.Errorf("middleware improperly configured: %w", err)
+	}
+
+	s := &Server{
+		port:        pkg.Settings.ListenPort,
+		nc:          nc,
+		js:          js,
+		logger:      slog.Default(),
+		grpcClient:  client,
+		grpcConn:    conn,
+		endpoints:   endpoints,
+		apiKeys:     apiKeys,
+		maintenance: maintenance,
+		domains:     domains,
+		quotas:      quotas,
+		usage:       newUsageReporter(nc),
+		jwt:         jwtauth.NewVerifier(jwksRefresh, jwksMaxStale),
+		policies:    policies,
+		schemas:     newSchemaCache(),
+		upstream:    upstreamClient,
+		proxies:     trustedProxies,
+		callTokens:  newCallTokens(),
+		callTTL:     callTTL,
+
+		h2cLanguages: parseLanguages(pkg.Settings.UpstreamH2CLanguages),
+		plugins:      plugins,
+	}
+	s.idem = newIdempotencyStore(js)
+	s.cache = newCacheStore(js)
+	s.payloads = newPayloads(js)
+	s.callbacks = newCallbackDispatcher(js)
+	s.batches = newBatchRunner(js)
+	s.schedules = newScheduler(js)
+	s.quotaUsage = newQuotaStore(js)
+	return s, nil
+}
+
+func newUpstreamClient() (*upstream.Client, error) {
+	opts := upstream.Options{
+		MaxRetries:          pkg.Settings.UpstreamMaxRetries,
+		BreakerThreshold:    pkg.Settings.BreakerErrorThreshold,
+		BreakerMinRequests:  pkg.Settings.BreakerMinRequests,
+		MaxIdleConnsPerHost: pkg.Settings.UpstreamMaxIdleConns,
+	}
+	durations := []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"upstream retry backoff", pkg.Settings.UpstreamRetryBackoff, &opts.RetryBackoff},
+		{"upstream dial timeout", pkg.Settings.UpstreamDialTimeout, &opts.DialTimeout},
+		{"upstream idle timeout", pkg.Settings.UpstreamIdleTimeout, &opts.IdleConnTimeout},
+		{"breaker window", pkg.Settings.BreakerWindow, &opts.BreakerWindow},
+		{"breaker cooldown", pkg.Settings.BreakerCooldown, &opts.BreakerCooldown},
+	}
+	for _, d := range durations {
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s improperly configured: %w", d.name, err)
+		}
+		*d.dst = v
+	}
+	return upstream.NewClient(opts), nil
+}
+
+// newCallTokens signs with CALL_TOKEN_SECRET, or with a random key when it
+// is unset, in which case calls only verify on the replica that served the
+// calling request.
+func newCallTokens() *calltoken.Signer {
+	if pkg.Settings.CallTokenSecret != "" {
+		return calltoken.NewSigner([]byte(pkg.Settings.CallTokenSecret))
+	}
+	slog.Warn("CALL_TOKEN_SECRET is not set, function calls are verified with a key of this replica only")
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return calltoken.NewSigner(key)
+}
+
+// newIdempotencyStore returns nil when JetStream is unavailable, in which case
+// Idempotency-Key headers are ignored.
+func newIdempotencyStore(js jetstream.JetStream) *idempotency.Store {
+	ttl, err := time.ParseDuration(pkg.Settings.IdempotencyTTL)
+	if err != nil {
+		slog.Error("idempotency ttl improperly configured", "error", err)
+		return nil
+	}
+	lockTimeout, err := time.ParseDuration(pkg.Settings.IdempotencyLockTimeout)
+	if err != nil {
+		slog.Error("idempotency lock timeout improperly configured", "error", err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	store, err := idempotency.NewStore(ctx, js, pkg.Settings.IdempotencyBucket, ttl, lockTimeout)
+	if err != nil {
+		slog.Warn("idempotency keys disabled", "error", err)
+		return nil
+	}
+	return store
+}
+
+// newQuotaStore returns nil when JetStream is unavailable, in which case
+// quotas are not enforced.
+func newQuotaStore(js jetstream.JetStream) *quota.Store {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	store, err := quota.NewStore(ctx, js)
+	if err != nil {
+		slog.Warn("usage quotas disabled", "error", err)
+		return nil
+	}
+	return store
+}
+
+// newCacheStore returns nil when JetStream is unavailable, in which case
+// response caching is disabled.
+func newCacheStore(js jetstream.JetStream) *cache.Store {
+	maxTTL, err := time.ParseDuration(pkg.Settings.ResponseCacheMaxTTL)
+	if err != nil {
+		slog.Error("response cache max ttl improperly configured", "error", err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	store, err := cache.NewStore(ctx, js, maxTTL)
+	if err != nil {
+		slog.Warn("response caching disabled", "error", err)
+		return nil
+	}
+	return store
+}
+
+// newPayloads returns nil when JetStream is unavailable, in which case every
+// body is sent inline and is subject to the NATS max payload.
+func newPayloads(js jetstream.JetStream) *broker.Payloads {
+	ttl, err := time.ParseDuration(pkg.Settings.PayloadTTL)
+	if err != nil {
+		slog.Error("payload ttl improperly configured", "error", err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	payloads, err := broker.NewPayloads(ctx, js, ttl, pkg.Settings.PayloadInlineLimit, parseLanguages(pkg.Settings.PayloadLanguages))
+	if err != nil {
+		slog.Warn("large payload offloading disabled", "error", err)
+		return nil
+	}
+	return payloads
+}
+
+// newCallbackDispatcher returns nil when JetStream is unavailable, in which
+// case endpoints with callbacks reject async requests that ask for one.
+func newCallbackDispatcher(js jetstream.JetStream) *callback.Dispatcher {
+	opts := callback.Options{MaxAttempts: max(pkg.Settings.CallbackMaxAttempts, 1)}
+	var err error
+	if opts.ResultTimeout, err = time.ParseDuration(pkg.Settings.CallbackResultTimeout); err != nil {
+		slog.Error("callback result timeout improperly configured", "error", err)
+		return nil
+	}
+	if opts.Backoff, err = time.ParseDuration(pkg.Settings.CallbackBackoff); err != nil {
+		slog.Error("callback backoff improperly configured", "error", err)
+		return nil
+	}
+	if opts.MaxBackoff, err = time.ParseDuration(pkg.Settings.CallbackMaxBackoff); err != nil {
+		slog.Error("callback max backoff improperly configured", "error", err)
+		return nil
+	}
+	if opts.RecordTTL, err = time.ParseDuration(pkg.Settings.CallbackRecordTTL); err != nil {
+		slog.Error("callback record ttl improperly configured", "error", err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	dispatcher, err := callback.NewDispatcher(ctx, js, opts)
+	if err != nil {
+		slog.Warn("callback delivery disabled", "error", err)
+		return nil
+	}
+	return dispatcher
+}
+
+// newBatchRunner returns nil when JetStream is unavailable, in which case
+// batch submissions are answered with 503.
+func newBatchRunner(js jetstream.JetStream) *batch.Runner {
+	opts := batch.Options{
+		Parallelism:    pkg.Settings.BatchParallelism,
+		MaxParallelism: pkg.Settings.BatchMaxParallelism,
+		MaxResult:      pkg.Settings.BatchMaxResult,
+	}
+	var err error
+	if opts.ItemTimeout, err = time.ParseDuration(pkg.Settings.BatchItemTimeout); err != nil {
+		slog.Error("batch item timeout improperly configured", "error", err)
+		return nil
+	}
+	if opts.RecordTTL, err = time.ParseDuration(pkg.Settings.BatchRecordTTL); err != nil {
+		slog.Error("batch record ttl improperly configured", "error", err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	runner, err := batch.NewRunner(ctx, js, opts)
+	if err != nil {
+		slog.Warn("batches disabled", "error", err)
+		return nil
+	}
+	return runner
+}
+
+// newScheduler returns nil when JetStream is unavailable, in which case
+// delayed requests are answered with 503.
+func newScheduler(js jetstream.JetStream) *schedule.Scheduler {
+	var opts schedule.Options
+	durations := []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"schedule poll interval", pkg.Settings.SchedulePollInterval, &opts.PollInterval},
+		{"schedule dispatch timeout", pkg.Settings.ScheduleDispatchTimeout, &opts.DispatchTimeout},
+		{"schedule retention", pkg.Settings.ScheduleRetention, &opts.Retention},
+	}
+	for _, d := range durations {
+		v, err := time.ParseDuration(d.value)
+		if err != nil || v <= 0 {
+			slog.Error(d.name+" improperly configured", "value", d.value, "error", err)
+			return nil
+		}
+		*d.dst = v
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	scheduler, err := schedule.NewScheduler(ctx, js, opts)
+	if err != nil {
+		slog.Warn("scheduled invocations disabled", "error", err)
+		return nil
+	}
+	return scheduler
 }
 
 func (s *Server) Start() error {
 	defer s.grpcConn.Close()
 	s.BuildRoutes()
-	slog.Info("ingestor server listening", "port", s.port)
-	return http.ListenAndServe(fmt.Sprintf(":%d", s.port), nil)
+	sub, err := s.serveInternal(NewIngestHandler(s))
+	if err != nil {
+		return fmt.Errorf("failed to serve internal calls: %w", err)
+	}
+	defer sub.Unsubscribe()
+	if s.schedules != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			for ctx.Err() == nil {
+				if err := s.schedules.Run(ctx, NewIngestHandler(s).runScheduled); err != nil && ctx.Err() == nil {
+					slog.Error("scheduler stopped, restarting", "error", err)
+					time.Sleep(5 * time.Second)
+				}
+			}
+		}()
+	}
+	if pkg.Settings.GrpcListenPort > 0 {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", pkg.Settings.GrpcListenPort))
+		if err != nil {
+			return fmt.Errorf("failed to listen for grpc: %w", err)
+		}
+		srv, err := newGRPCServer(NewIngestHandler(s))
+		if err != nil {
+			return fmt.Errorf("failed to set up grpc tls: %w", err)
+		}
+		go func() {
+			slog.Info("ingestor grpc server listening", "port", pkg.Settings.GrpcListenPort)
+			if err := srv.Serve(lis); err != nil {
+				slog.Error("grpc server stopped", "error", err)
+			}
+		}()
+		defer srv.GracefulStop()
+	}
+	if pkg.Settings.MetricsListenPort > 0 {
+		srv := &http.Server{Addr: fmt.Sprintf(":%d", pkg.Settings.MetricsListenPort), Handler: metrics.Default.Handler(), ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			slog.Info("ingestor metrics listening", "port", pkg.Settings.MetricsListenPort)
+			if err := srv.ListenAndServe(); err != nil {
+				slog.Error("metrics server stopped", "error", err)
+			}
+		}()
+		defer srv.Close()
+	}
+	handler := s.routeHost(http.DefaultServeMux)
+	if pkg.Settings.TLSCertFile != "" && pkg.Settings.TLSKeyFile != "" {
+		srv, err := newTLSServer(fmt.Sprintf(":%d", pkg.Settings.TLSListenPort), handler)
+		if err != nil {
+			return fmt.Errorf("failed to set up tls: %w", err)
+		}
+		go func() {
+			slog.Info("ingestor tls server listening", "port", pkg.Settings.TLSListenPort)
+			if err := srv.ListenAndServeTLS("", ""); err != nil {
+				slog.Error("tls server stopped", "error", err)
+			}
+		}()
+		defer srv.Close()
+	}
+	slog.Info("ingestor server listening", "port", s.port, "h2c", pkg.Settings.H2C)
+	return newHTTPServer(fmt.Sprintf(":%d", s.port), handler).ListenAndServe()
 }
 
 func (s *Server) activateFunction(project, name string) (*proto.ActivateResponse, error) {
@@ -0,0 +1,309 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/broker"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+)
+
+// errTransform marks a request the endpoint's rules can't be applied to,
+// such as query_to_body on a body that isn't a JSON object.
+var errTransform = errors.New("request cannot be transformed")
+
+// maxTransformBody bounds the bodies buffered to merge query parameters in.
+const maxTransformBody = 1 << 20
+
+type requestStep func(r *http.Request, rt *gateway.RequestTransform, vars func(string) string) error
+
+// requestPipeline runs in order for every request. The identity and
+// deadline headers are set last so that no rule can replace them.
+var requestPipeline = []requestStep{
+	renameHeaders,
+	removeHeaders,
+	setHeaders,
+	rewritePath,
+	queryToBody,
+}
+
+func init() {
+	// listing "transform" is harmless, the stage runs once per request
+	middleware.Register("transform", func(map[string]string) (middleware.Middleware, error) {
+		return transformStage, nil
+	})
+}
+
+type transformedKey struct{}
+
+// transformStage applies the endpoint's request rules before the runtime
+// call. Scheduled requests are stored as they arrived and transformed when
+// they run, with the rules in force then.
+func transformStage(next middleware.Handler) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request, c *middleware.Call) {
+		if r.Context().Value(transformedKey{}) != nil || r.Context().Value(runAtKey{}) != nil {
+			next(w, r, c)
+			return
+		}
+		r, err := transformRequest(r.WithContext(context.WithValue(r.Context(), transformedKey{}, true)), c.Endpoint, c.Project, c.Function)
+		if err != nil {
+			slog.Warn("failed to transform request", "project", c.Project, "name", c.Function, "error", err)
+			if errors.Is(err, errTransform) {
+				problem.Write(w, http.StatusBadRequest, problem.BadRequest, err.Error())
+				return
+			}
+			problem.Write(w, http.StatusInternalServerError, problem.Internal, "could not transform the request")
+			return
+		}
+		next(w, r, c)
+	}
+}
+
+// transformRequest applies the request rules of ep, which may be nil, to a
+// copy of r. Rules never touch the ingestor's own X-Litefunction-* headers.
+func transformRequest(r *http.Request, ep *gateway.Endpoint, project, name string) (*http.Request, error) {
+	r = r.Clone(r.Context())
+	if ep != nil && ep.Transform != nil && ep.Transform.Request != nil {
+		vars := transformVars(r, project, name)
+		for _, step := range requestPipeline {
+			if err := step(r, ep.Transform.Request, vars); err != nil {
+				return nil, err
+			}
+		}
+	}
+	r.Header.Set("X-Litefunction-Name", name)
+	r.Header.Set("X-Litefunction-Project", project)
+	if deadline, ok := r.Context().Deadline(); ok {
+		r.Header.Set(gateway.DeadlineHeader, deadline.UTC().Format(time.RFC3339Nano))
+	}
+	return r, nil
+}
+
+// endpointTransform transforms a request that didn't come through the
+// pipeline, such as a batch item or a due scheduled request.
+func (h *IngestHandler) endpointTransform(r *http.Request, project, name string) (*http.Request, error) {
+	ep, _ := h.server.endpoints.Get(gateway.EndpointKey(project, name, r.Method))
+	return transformRequest(r, ep, project, name)
+}
+
+// responseTransform returns the endpoint's response rules, if any.
+func (h *IngestHandler) responseTransform(r *http.Request, project, name string) *gateway.ResponseTransform {
+	ep, ok := h.server.endpoints.Get(gateway.EndpointKey(project, name, r.Method))
+	if !ok || ep.Transform == nil {
+		return nil
+	}
+	return ep.Transform.Response
+}
+
+// transformResponse filters the runtime's headers in place. Secrets are
+// credentials for the runtime, they are never written back to callers.
+func transformResponse(header http.Header, rt *gateway.ResponseTransform, vars func(string) string) error {
+	if rt == nil {
+		return nil
+	}
+	requestVars := vars
+	vars = func(key string) string {
+		if strings.HasPrefix(key, "secret.") {
+			return ""
+		}
+		return requestVars(key)
+	}
+	for _, k := range rt.RemoveHeaders {
+		header.Del(k)
+	}
+	if len(rt.AllowHeaders) > 0 {
+		allowed := make(map[string]bool, len(rt.AllowHeaders))
+		for _, k := range rt.AllowHeaders {
+			allowed[http.CanonicalHeaderKey(k)] = true
+		}
+		for k := range header {
+			if !allowed[k] {
+				header.Del(k)
+			}
+		}
+	}
+	for k, v := range rt.SetHeaders {
+		val, err := expand(v, vars)
+		if err != nil {
+			return err
+		}
+		header.Set(k, val)
+	}
+	return nil
+}
+
+func reserved(header string) bool {
+	return strings.HasPrefix(http.CanonicalHeaderKey(header), broker.EnvelopePrefix)
+}
+
+func renameHeaders(r *http.Request, rt *gateway.RequestTransform, _ func(string) string) error {
+	for from, to := range rt.RenameHeaders {
+		if reserved(from) || reserved(to) {
+			continue
+		}
+		if vals := r.Header.Values(from); len(vals) > 0 {
+			r.Header.Del(from)
+			r.Header[http.CanonicalHeaderKey(to)] = vals
+		}
+	}
+	return nil
+}
+
+func removeHeaders(r *http.Request, rt *gateway.RequestTransform, _ func(string) string) error {
+	for _, k := range rt.RemoveHeaders {
+		if !reserved(k) {
+			r.Header.Del(k)
+		}
+	}
+	return nil
+}
+
+func setHeaders(r *http.Request, rt *gateway.RequestTransform, vars func(string) string) error {
+	for k, v := range rt.SetHeaders {
+		if reserved(k) {
+			continue
+		}
+		val, err := expand(v, vars)
+		if err != nil {
+			return err
+		}
+		r.Header.Set(k, val)
+	}
+	return nil
+}
+
+// rewritePath sets the path the runtime sees. proxyToRuntime strips the
+// /lambda/{project}/{name} prefix, so the rewritten path is appended to it.
+func rewritePath(r *http.Request, rt *gateway.RequestTransform, vars func(string) string) error {
+	if rt.Path == "" {
+		return nil
+	}
+	path, err := expand(rt.Path, vars)
+	if err != nil {
+		return err
+	}
+	project, name := parsePath(r.URL.Path)
+	r.URL.Path = "/lambda/" + project + "/" + name + "/" + strings.TrimPrefix(path, "/")
+	r.URL.RawPath = ""
+	return nil
+}
+
+func queryToBody(r *http.Request, rt *gateway.RequestTransform, _ func(string) string) error {
+	if len(rt.QueryToBody) == 0 {
+		return nil
+	}
+	raw, err := io.ReadAll(io.LimitReader(r.Body, maxTransformBody+1))
+	if err != nil {
+		return err
+	}
+	if len(raw) > maxTransformBody {
+		return fmt.Errorf("%w: body larger than %d bytes", errTransform, maxTransformBody)
+	}
+	body := map[string]any{}
+	if len(bytes.TrimSpace(raw)) > 0 {
+		if err := json.Unmarshal(raw, &body); err != nil {
+			return fmt.Errorf("%w: body is not a JSON object", errTransform)
+		}
+	}
+	query := r.URL.Query()
+	for param, field := range rt.QueryToBody {
+		if query.Has(param) {
+			body[field] = query.Get(param)
+		}
+	}
+	out, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(out))
+	r.ContentLength = int64(len(out))
+	r.Header.Set("Content-Length", fmt.Sprint(len(out)))
+	r.Header.Set("Content-Type", "application/json")
+	return nil
+}
+
+// transformVars resolves ${...} references against the original request.
+// An empty result for a secret means it isn't mounted.
+func transformVars(r *http.Request, project, name string) func(string) string {
+	query := r.URL.Query()
+	header := r.Header.Clone()
+	return func(key string) string {
+		switch {
+		case key == "project":
+			return project
+		case key == "function":
+			return name
+		case key == "method":
+			return r.Method
+		case strings.HasPrefix(key, "header."):
+			return header.Get(strings.TrimPrefix(key, "header."))
+		case strings.HasPrefix(key, "query."):
+			return query.Get(strings.TrimPrefix(key, "query."))
+		case strings.HasPrefix(key, "secret."):
+			return readSecret(project, strings.TrimPrefix(key, "secret."))
+		}
+		return ""
+	}
+}
+
+// secretTTL is how long a secret is served from memory. Mounted secrets are
+// updated in place, so rotations are picked up once it passes.
+const secretTTL = 30 * time.Second
+
+type cachedSecret struct {
+	value string
+	read  time.Time
+}
+
+// secrets caches readSecret by file path, missing secrets included, so
+// requests don't each hit the disk.
+var secrets sync.Map
+
+// readSecret reads {project}.{name} from the mounted secrets directory, so a
+// project can only reference its own secrets.
+func readSecret(project, name string) string {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return ""
+	}
+	path := filepath.Join(pkg.Settings.TransformSecretsDir, project+"."+name)
+	if v, ok := secrets.Load(path); ok && time.Since(v.(cachedSecret).read) < secretTTL {
+		return v.(cachedSecret).value
+	}
+	var value string
+	if data, err := os.ReadFile(path); err == nil {
+		value = strings.TrimSpace(string(data))
+	}
+	secrets.Store(path, cachedSecret{value: value, read: time.Now()})
+	return value
+}
+
+// expand substitutes ${...} references. Unknown or missing secrets fail the
+// request rather than forwarding an empty credential.
+func expand(s string, vars func(string) string) (string, error) {
+	var missing string
+	out := os.Expand(s, func(key string) string {
+		v := vars(key)
+		if v == "" && strings.HasPrefix(key, "secret.") && missing == "" {
+			missing = strings.TrimPrefix(key, "secret.")
+		}
+		return v
+	})
+	if missing != "" {
+		return "", fmt.Errorf("transform secret %q is not available", missing)
+	}
+	return out, nil
+}
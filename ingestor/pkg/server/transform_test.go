@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/middleware"
+)
+
+func TestRequestPipeline(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/lambda/shop/orders?tenant=acme", strings.NewReader(`{"id":1}`))
+	r.Header.Set("X-Old", "v")
+	r.Header.Set("X-Litefunction-Subject", "alice")
+	rt := &gateway.RequestTransform{
+		RenameHeaders: map[string]string{"X-Old": "X-New"},
+		RemoveHeaders: []string{"X-Litefunction-Subject"},
+		SetHeaders:    map[string]string{"X-Tenant": "${query.tenant}", "X-Litefunction-Project": "spoofed"},
+		Path:          "/v2/${function}",
+		QueryToBody:   map[string]string{"tenant": "tenant"},
+	}
+	vars := transformVars(r, "shop", "orders")
+	for _, step := range requestPipeline {
+		if err := step(r, rt, vars); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if r.Header.Get("X-New") != "v" || r.Header.Get("X-Old") != "" {
+		t.Errorf("rename not applied: %v", r.Header)
+	}
+	if r.Header.Get("X-Litefunction-Subject") != "alice" || r.Header.Get("X-Litefunction-Project") != "" {
+		t.Errorf("reserved headers were touched: %v", r.Header)
+	}
+	if r.Header.Get("X-Tenant") != "acme" {
+		t.Errorf("X-Tenant = %q", r.Header.Get("X-Tenant"))
+	}
+	if r.URL.Path != "/lambda/shop/orders/v2/orders" {
+		t.Errorf("path = %q", r.URL.Path)
+	}
+	body, _ := io.ReadAll(r.Body)
+	if string(body) != `{"id":1,"tenant":"acme"}` {
+		t.Errorf("body = %s", body)
+	}
+}
+
+func TestMissingSecretFails(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/lambda/shop/orders", nil)
+	rt := &gateway.RequestTransform{SetHeaders: map[string]string{"Authorization": "Bearer ${secret.token}"}}
+	if err := setHeaders(r, rt, func(string) string { return "" }); err == nil {
+		t.Fatal("expected an error for an unavailable secret")
+	}
+}
+
+func TestTransformResponse(t *testing.T) {
+	h := http.Header{"Server": {"x"}, "Content-Type": {"text/plain"}, "X-Debug": {"1"}}
+	rt := &gateway.ResponseTransform{
+		RemoveHeaders: []string{"X-Debug"},
+		AllowHeaders:  []string{"content-type"},
+		SetHeaders:    map[string]string{"X-Served-By": "${project}"},
+	}
+	if err := transformResponse(h, rt, transformVars(httptest.NewRequest(http.MethodGet, "/", nil), "shop", "orders")); err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 2 || h.Get("Content-Type") != "text/plain" || h.Get("X-Served-By") != "shop" {
+		t.Errorf("unexpected headers: %v", h)
+	}
+}
+
+func TestResponseSecretsAreNotExpanded(t *testing.T) {
+	vars := func(key string) string { return "value of " + key }
+	rt := &gateway.ResponseTransform{SetHeaders: map[string]string{"X-Token": "${secret.token}"}}
+	h := http.Header{}
+	if err := transformResponse(h, rt, vars); err == nil || h.Get("X-Token") != "" {
+		t.Fatalf("secret written to the response: %v, %v", h, err)
+	}
+}
+
+func TestReadSecretIsCached(t *testing.T) {
+	dir := t.TempDir()
+	pkg.Settings = &pkg.IngestorConf{TransformSecretsDir: dir}
+	path := filepath.Join(dir, "shop.token")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if v := readSecret("shop", "token"); v != "s3cret" {
+		t.Fatalf("readSecret = %q", v)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if v := readSecret("shop", "token"); v != "s3cret" {
+		t.Errorf("secret read again from disk, got %q", v)
+	}
+	secrets.Store(path, cachedSecret{value: "s3cret", read: time.Now().Add(-secretTTL)})
+	if v := readSecret("shop", "token"); v != "" {
+		t.Errorf("expired secret served: %q", v)
+	}
+}
+
+func TestTransformStage(t *testing.T) {
+	ep := &gateway.Endpoint{Transform: &gateway.TransformConfig{Request: &gateway.RequestTransform{
+		SetHeaders:  map[string]string{"X-Tenant": "${query.tenant}"},
+		QueryToBody: map[string]string{"tenant": "tenant"},
+	}}}
+	named, ok := middleware.Lookup("transform")
+	if !ok {
+		t.Fatal("transform middleware is not registered")
+	}
+	mw, err := named(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got *http.Request
+	final := func(w http.ResponseWriter, r *http.Request, c *middleware.Call) { got = r }
+	// listed on the endpoint as well as run by the pipeline
+	chain := middleware.Chain(final, mw, transformStage)
+	call := &middleware.Call{Project: "shop", Function: "orders", Mode: middleware.Sync, Endpoint: ep}
+
+	r := httptest.NewRequest(http.MethodPost, "/lambda/shop/orders?tenant=acme", strings.NewReader(`{"id":1}`))
+	chain(httptest.NewRecorder(), r, call)
+	if got == nil || got.Header.Get("X-Tenant") != "acme" || got.Header.Get("X-Litefunction-Name") != "orders" {
+		t.Fatalf("request not transformed: %v", got)
+	}
+	if body, _ := io.ReadAll(got.Body); string(body) != `{"id":1,"tenant":"acme"}` {
+		t.Errorf("body = %s", body)
+	}
+
+	got = nil
+	r = httptest.NewRequest(http.MethodPost, "/lambda/shop/orders?tenant=acme", strings.NewReader(`{"id":1}`))
+	r = r.WithContext(context.WithValue(r.Context(), runAtKey{}, time.Now().Add(time.Hour)))
+	chain(httptest.NewRecorder(), r, call)
+	if got == nil || got.Header.Get("X-Tenant") != "" {
+		t.Errorf("scheduled request transformed before it runs: %v", got.Header)
+	}
+
+	got = nil
+	w := httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/lambda/shop/orders?tenant=acme", strings.NewReader(`[1]`))
+	chain(w, r, call)
+	if got != nil || w.Code != http.StatusBadRequest {
+		t.Errorf("untransformable body got %d, reached final %v", w.Code, got != nil)
+	}
+}
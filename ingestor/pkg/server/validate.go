@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/common/schema"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/problem"
+)
+
+// validateRequest rejects bodies and query strings that do not match the
+// endpoint's schemas with a structured 400, before the function is activated.
+// Bodies longer than VALIDATE_MAX_BODY are refused with a 413.
+func (h *IngestHandler) validateRequest(w http.ResponseWriter, r *http.Request, project, name string) bool {
+	key := gateway.EndpointKey(project, name, r.Method)
+	ep, ok := h.server.endpoints.Get(key)
+	if !ok || ep.Schema == nil {
+		return true
+	}
+	v, err := h.server.schemas.get(key, ep.Schema)
+	if err != nil {
+		h.logger.Error("ignoring invalid endpoint schema", "project", project, "name", name, "error", err)
+		return true
+	}
+
+	violations := v.ValidateQuery(r.URL.Query())
+	if v.HasBody() {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(pkg.Settings.ValidateMaxBody)))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				problem.Write(w, http.StatusRequestEntityTooLarge, problem.PayloadTooLarge, err.Error())
+				return false
+			}
+			problem.Write(w, http.StatusBadRequest, problem.BadRequest, "error reading request body")
+			return false
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		violations = append(violations, v.ValidateBody(body)...)
+	}
+	if len(violations) == 0 {
+		return true
+	}
+
+	h.logger.Info("request failed schema validation", "project", project, "name", name, "violations", len(violations))
+	p := problem.New(http.StatusBadRequest, problem.ValidationFailed, "request validation failed")
+	p.Violations = violations
+	p.Write(w)
+	return false
+}
+
+// schemaCache keeps one compiled validator per endpoint along with a digest
+// of the schema documents it was compiled from, so an endpoint update
+// recompiles and replaces the old validator instead of piling up beside it.
+type schemaCache struct {
+	mu         sync.Mutex
+	validators map[string]cachedSchema
+}
+
+type cachedSchema struct {
+	sum       [sha256.Size]byte
+	validator *schema.Validator
+}
+
+func newSchemaCache() *schemaCache {
+	return &schemaCache{validators: map[string]cachedSchema{}}
+}
+
+func (c *schemaCache) get(endpoint string, cfg *gateway.SchemaConfig) (*schema.Validator, error) {
+	sum := sha256.Sum256(append(append([]byte{}, cfg.Body...), append([]byte{0}, cfg.Query...)...))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.validators[endpoint]; ok && cached.sum == sum {
+		return cached.validator, nil
+	}
+	v, err := schema.Compile(cfg)
+	if err != nil {
+		return nil, err
+	}
+	c.validators[endpoint] = cachedSchema{sum: sum, validator: v}
+	return v, nil
+}
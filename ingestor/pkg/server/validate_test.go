@@ -0,0 +1,81 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg"
+	"github.com/ashupednekar/litefunctions/ingestor/pkg/registry"
+)
+
+func TestValidateRequest(t *testing.T) {
+	pkg.Settings = &pkg.IngestorConf{ValidateMaxBody: 64}
+	h := &IngestHandler{logger: slog.Default(), server: &Server{
+		schemas: newSchemaCache(),
+		endpoints: registry.Of(gateway.EndpointsBucket, map[string]*gateway.Endpoint{
+			gateway.EndpointKey("shop", "orders", "POST"): {Schema: &gateway.SchemaConfig{
+				Body:  []byte(`{"type":"object","required":["sku"],"properties":{"sku":{"type":"string"}}}`),
+				Query: []byte(`{"type":"object","properties":{"dry":{"enum":["true","false"]}}}`),
+			}},
+		}),
+	}}
+
+	cases := []struct {
+		name   string
+		query  string
+		body   string
+		ok     bool
+		status int
+	}{
+		{"valid", "?dry=true", `{"sku":"a1"}`, true, 0},
+		{"invalid body", "", `{"qty":1}`, false, http.StatusBadRequest},
+		{"invalid query", "?dry=maybe", `{"sku":"a1"}`, false, http.StatusBadRequest},
+		{"body over the cap", "", `{"sku":"` + strings.Repeat("x", 64) + `"}`, false, http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/lambda/shop/orders"+tc.query, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			ok := h.validateRequest(w, r, "shop", "orders")
+			if ok != tc.ok || (!ok && w.Code != tc.status) {
+				t.Fatalf("validateRequest = %v, status %d, want %v, %d", ok, w.Code, tc.ok, tc.status)
+			}
+			if ok {
+				if body, _ := io.ReadAll(r.Body); string(body) != tc.body {
+					t.Errorf("body handed on = %q", body)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaCacheReplacesPerEndpoint(t *testing.T) {
+	c := newSchemaCache()
+	v1 := &gateway.SchemaConfig{Body: []byte(`{"type":"object"}`)}
+	v2 := &gateway.SchemaConfig{Body: []byte(`{"type":"array"}`)}
+
+	first, err := c.get("shop.orders.POST", v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := c.get("shop.orders.POST", v1); again != first {
+		t.Error("unchanged schema was recompiled")
+	}
+	if replaced, _ := c.get("shop.orders.POST", v2); replaced == first {
+		t.Error("updated schema served the old validator")
+	}
+	if _, err := c.get("shop.orders.PUT", v1); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.validators) != 2 {
+		t.Errorf("cache holds %d validators, want one per endpoint", len(c.validators))
+	}
+	if _, err := c.get("shop.orders.PATCH", &gateway.SchemaConfig{Body: []byte(`{"type":1}`)}); err == nil {
+		t.Error("invalid schema compiled")
+	}
+}
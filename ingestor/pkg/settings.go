@@ -8,10 +8,93 @@ import (
 )
 
 type IngestorConf struct {
-	ListenPort   int    `env:"LISTEN_PORT" default:"3000"`
-	NatsUrl      string `env:"NATS_URL" default:"nats://litefunctions-nats:4222"`
-	ReplyTimeout string `env:"REPLY_TIMEOUT" default:"500ms"`
-	OperatorUrl  string `env:"OPERATOR_URL" default:"litefunctions-operator:50051"`
+	ListenPort     int    `env:"LISTEN_PORT" default:"3000"`
+	GrpcListenPort int    `env:"GRPC_LISTEN_PORT" default:"50052"`
+	NatsUrl        string `env:"NATS_URL" default:"nats://litefunctions-nats:4222"`
+	ReplyTimeout   string `env:"REPLY_TIMEOUT" default:"500ms"`
+	OperatorUrl    string `env:"OPERATOR_URL" default:"litefunctions-operator:50051"`
+	// RuntimeHost is where runtime services are reached, {service} and
+	// {namespace} are filled in from the activation.
+	RuntimeHost string `env:"RUNTIME_HOST" default:"{service}.{namespace}.svc.cluster.local"`
+
+	H2C           bool   `env:"H2C_ENABLED" default:"true"`
+	TLSListenPort int    `env:"TLS_LISTEN_PORT" default:"3443"`
+	TLSCertFile   string `env:"TLS_CERT_FILE"`
+	TLSKeyFile    string `env:"TLS_KEY_FILE"`
+
+	IdempotencyBucket      string `env:"IDEMPOTENCY_BUCKET" default:"litefunctions-idempotency"`
+	IdempotencyTTL         string `env:"IDEMPOTENCY_TTL" default:"24h"`
+	IdempotencyLockTimeout string `env:"IDEMPOTENCY_LOCK_TIMEOUT" default:"30s"`
+
+	ResponseCacheMaxTTL string `env:"RESPONSE_CACHE_MAX_TTL" default:"1h"`
+
+	// ValidateMaxBody caps the bodies read for schema validation.
+	ValidateMaxBody int `env:"VALIDATE_MAX_BODY" default:"1048576"`
+
+	PayloadInlineLimit int    `env:"PAYLOAD_INLINE_LIMIT" default:"524288"`
+	PayloadTTL         string `env:"PAYLOAD_TTL" default:"1h"`
+	// PayloadLanguages are the runtimes that resolve claim checks, larger
+	// bodies for the rest are refused with 413.
+	PayloadLanguages string `env:"PAYLOAD_LANGUAGES" default:"go"`
+
+	CallbackResultTimeout string `env:"CALLBACK_RESULT_TIMEOUT" default:"15m"`
+	CallbackMaxAttempts   int    `env:"CALLBACK_MAX_ATTEMPTS" default:"6"`
+	CallbackBackoff       string `env:"CALLBACK_BACKOFF" default:"2s"`
+	CallbackMaxBackoff    string `env:"CALLBACK_MAX_BACKOFF" default:"2m"`
+	CallbackRecordTTL     string `env:"CALLBACK_RECORD_TTL" default:"72h"`
+
+	BatchParallelism    int    `env:"BATCH_PARALLELISM" default:"8"`
+	BatchMaxParallelism int    `env:"BATCH_MAX_PARALLELISM" default:"64"`
+	BatchMaxItems       int    `env:"BATCH_MAX_ITEMS" default:"10000"`
+	BatchItemTimeout    string `env:"BATCH_ITEM_TIMEOUT" default:"5m"`
+	BatchMaxResult      int    `env:"BATCH_MAX_RESULT" default:"65536"`
+	BatchRecordTTL      string `env:"BATCH_RECORD_TTL" default:"72h"`
+
+	ScheduleMaxDelay        string `env:"SCHEDULE_MAX_DELAY" default:"720h"`
+	ScheduleMaxBody         int    `env:"SCHEDULE_MAX_BODY" default:"262144"`
+	SchedulePollInterval    string `env:"SCHEDULE_POLL_INTERVAL" default:"1s"`
+	ScheduleDispatchTimeout string `env:"SCHEDULE_DISPATCH_TIMEOUT" default:"30s"`
+	ScheduleRetention       string `env:"SCHEDULE_RETENTION" default:"72h"`
+
+	UpstreamMaxRetries    int     `env:"UPSTREAM_MAX_RETRIES" default:"2"`
+	UpstreamRetryBackoff  string  `env:"UPSTREAM_RETRY_BACKOFF" default:"100ms"`
+	UpstreamDialTimeout   string  `env:"UPSTREAM_DIAL_TIMEOUT" default:"2s"`
+	UpstreamIdleTimeout   string  `env:"UPSTREAM_IDLE_TIMEOUT" default:"90s"`
+	UpstreamMaxIdleConns  int     `env:"UPSTREAM_MAX_IDLE_CONNS" default:"64"`
+	UpstreamH2CLanguages  string  `env:"UPSTREAM_H2C_LANGUAGES" default:"go"`
+	BreakerErrorThreshold float64 `env:"BREAKER_ERROR_THRESHOLD" default:"0.5"`
+	BreakerMinRequests    int     `env:"BREAKER_MIN_REQUESTS" default:"20"`
+	BreakerWindow         string  `env:"BREAKER_WINDOW" default:"30s"`
+	BreakerCooldown       string  `env:"BREAKER_COOLDOWN" default:"15s"`
+
+	TransformSecretsDir string `env:"TRANSFORM_SECRETS_DIR" default:"/etc/litefunctions/secrets"`
+
+	// Middleware compiled into the build that runs for every endpoint, e.g.
+	// "ratelimit?rps=50,audit"
+	Middleware string `env:"MIDDLEWARE"`
+	// MetricsListenPort serves what the "metrics" middleware collects, 0
+	// turns the listener off.
+	MetricsListenPort int `env:"METRICS_LISTEN_PORT" default:"9090"`
+
+	MaxCallDepth        int    `env:"MAX_CALL_DEPTH" default:"8"`
+	InternalCallTimeout string `env:"INTERNAL_CALL_TIMEOUT" default:"30s"`
+	// CallTokenSecret signs the identity functions pass on to the functions
+	// they call. Every ingestor replica needs the same one.
+	CallTokenSecret string `env:"CALL_TOKEN_SECRET"`
+	// CallTokenTTL bounds how long a function can make calls on behalf of a
+	// request that has no deadline of its own.
+	CallTokenTTL string `env:"CALL_TOKEN_TTL" default:"1h"`
+
+	JwksRefreshInterval string `env:"JWKS_REFRESH_INTERVAL" default:"15m"`
+	// JwksMaxStale is how long cached signing keys are still trusted while
+	// the identity provider can't be reached.
+	JwksMaxStale string `env:"JWKS_MAX_STALE" default:"1h"`
+	// TrustForwardedFor is set when a proxy in front of the ingestor appends
+	// the address it was connected from to X-Forwarded-For.
+	TrustForwardedFor bool `env:"TRUST_FORWARDED_FOR" default:"false"`
+	// TrustedProxies are comma separated CIDRs of further proxies between
+	// callers and that one, whose hops are skipped too.
+	TrustedProxies string `env:"TRUSTED_PROXIES"`
 }
 
 var (
@@ -0,0 +1,114 @@
+package upstream
+
+import (
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	closed breakerState = iota
+	open
+	halfOpen
+)
+
+// Breaker trips once the failure rate within a window crosses the threshold.
+// While open every call fails fast; after the cooldown a single probe is let
+// through and its outcome closes or reopens the breaker.
+type Breaker struct {
+	threshold   float64
+	minRequests int
+	window      time.Duration
+	cooldown    time.Duration
+
+	mu          sync.Mutex
+	state       breakerState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probing     bool
+}
+
+func NewBreaker(threshold float64, minRequests int, window, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: threshold, minRequests: minRequests, window: window, cooldown: cooldown}
+}
+
+// Allow reports whether a call may proceed and whether it is the half-open
+// probe, and if not how long until the breaker will let a probe through.
+func (b *Breaker) Allow(now time.Time) (ok, probe bool, wait time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case open:
+		if wait := b.openedAt.Add(b.cooldown).Sub(now); wait > 0 {
+			return false, false, wait
+		}
+		b.state = halfOpen
+		b.probing = true
+		return true, true, 0
+	case halfOpen:
+		if b.probing {
+			return false, false, b.cooldown
+		}
+		b.probing = true
+		return true, true, 0
+	}
+	return true, false, 0
+}
+
+// Record feeds the outcome of an allowed call back into the breaker. While
+// half open only the probe counts; calls let through before the breaker
+// opened may still be finishing and say nothing about the runtime now.
+func (b *Breaker) Record(now time.Time, probe, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == halfOpen {
+		if !probe {
+			return
+		}
+		b.probing = false
+		if ok {
+			b.reset(now, closed)
+		} else {
+			b.trip(now)
+		}
+		return
+	}
+	if b.state == open {
+		return
+	}
+	if now.Sub(b.windowStart) > b.window {
+		b.reset(now, closed)
+	}
+	b.requests++
+	if !ok {
+		b.failures++
+	}
+	if b.requests >= b.minRequests && float64(b.failures)/float64(b.requests) >= b.threshold {
+		b.trip(now)
+	}
+}
+
+// Abandon releases a probe whose outcome is unknown, so the next call can
+// probe instead. Other calls hold nothing to release.
+func (b *Breaker) Abandon(probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe && b.state == halfOpen {
+		b.probing = false
+	}
+}
+
+func (b *Breaker) trip(now time.Time) {
+	b.state = open
+	b.openedAt = now
+}
+
+func (b *Breaker) reset(now time.Time, state breakerState) {
+	b.state = state
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+}
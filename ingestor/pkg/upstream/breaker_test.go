@@ -0,0 +1,68 @@
+package upstream
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(0.5, 4, time.Minute, 10*time.Second)
+
+	for _, ok := range []bool{true, false, true} {
+		b.Record(now, false, ok)
+	}
+	if ok, _, _ := b.Allow(now); !ok {
+		t.Fatal("breaker opened below min requests")
+	}
+	b.Record(now, false, false)
+	if ok, _, wait := b.Allow(now); ok || wait != 10*time.Second {
+		t.Fatalf("expected open breaker, got allow=%v wait=%v", ok, wait)
+	}
+
+	now = now.Add(11 * time.Second)
+	if ok, probe, _ := b.Allow(now); !ok || !probe {
+		t.Fatal("expected a probe after cooldown")
+	}
+	if ok, _, _ := b.Allow(now); ok {
+		t.Fatal("only one probe may run while half open")
+	}
+	b.Record(now, true, false)
+	if ok, _, _ := b.Allow(now); ok {
+		t.Fatal("failed probe should reopen the breaker")
+	}
+
+	now = now.Add(11 * time.Second)
+	b.Allow(now)
+	b.Record(now, true, true)
+	if ok, probe, _ := b.Allow(now); !ok || probe {
+		t.Fatal("successful probe should close the breaker")
+	}
+}
+
+func TestBreakerAdmitsOneProbe(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(0.5, 2, time.Minute, 10*time.Second)
+
+	// two calls are in flight when the breaker trips
+	b.Allow(now)
+	b.Allow(now)
+	b.Record(now, false, false)
+	b.Record(now, false, false)
+
+	now = now.Add(11 * time.Second)
+	if ok, probe, _ := b.Allow(now); !ok || !probe {
+		t.Fatal("expected a probe after cooldown")
+	}
+	// late outcomes of calls from before the breaker opened
+	b.Record(now, false, true)
+	b.Abandon(false)
+	if ok, _, _ := b.Allow(now); ok {
+		t.Fatal("a call that wasn't the probe decided the half open breaker")
+	}
+
+	b.Record(now, true, true)
+	if ok, _, _ := b.Allow(now); !ok {
+		t.Fatal("successful probe should close the breaker")
+	}
+}
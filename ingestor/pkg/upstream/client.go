@@ -0,0 +1,171 @@
+// Package upstream calls runtime services over HTTP with a tuned transport
+// per service, retries for idempotent requests and per-function circuit
+// breakers.
+package upstream
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitOpenError is returned without contacting the runtime while its breaker
+// is open.
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return "runtime is failing, circuit open"
+}
+
+// Protocol is how a runtime service is spoken to.
+type Protocol int
+
+const (
+	HTTP1 Protocol = iota
+	// H2C is cleartext HTTP/2 with prior knowledge, for runtimes that serve
+	// it. Requests to the service share multiplexed connections.
+	H2C
+)
+
+type Options struct {
+	MaxRetries   int
+	RetryBackoff time.Duration
+
+	BreakerThreshold   float64
+	BreakerMinRequests int
+	BreakerWindow      time.Duration
+	BreakerCooldown    time.Duration
+
+	DialTimeout         time.Duration
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+}
+
+type Client struct {
+	opts Options
+
+	mu       sync.Mutex
+	clients  map[clientKey]*http.Client
+	breakers map[string]*Breaker
+}
+
+func NewClient(opts Options) *Client {
+	return &Client{
+		opts:     opts,
+		clients:  make(map[clientKey]*http.Client),
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// Do sends req for the function identified by key. Requests that are safe to
+// repeat and carry a replayable body are retried on connection errors and
+// 503s. Connection errors and 502/503/504 responses count against the
+// function's breaker.
+func (c *Client) Do(req *http.Request, key string, proto Protocol) (*http.Response, error) {
+	client, breaker := c.get(clientKey{req.URL.Host, proto}, key)
+	retryable := Idempotent(req) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	backoff := c.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		ok, probe, wait := breaker.Allow(time.Now())
+		if !ok {
+			return nil, &CircuitOpenError{RetryAfter: wait}
+		}
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := client.Do(req)
+		failed := err != nil || resp.StatusCode == http.StatusBadGateway ||
+			resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout
+		// a caller going away says nothing about the runtime's health
+		if err != nil && req.Context().Err() != nil {
+			breaker.Abandon(probe)
+			return nil, err
+		}
+		breaker.Record(time.Now(), probe, !failed)
+
+		retry := err != nil || resp.StatusCode == http.StatusServiceUnavailable
+		if !retryable || !retry || attempt >= c.opts.MaxRetries {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		slog.Warn("retrying runtime request", "upstream", req.URL.Host, "attempt", attempt+1, "error", err)
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// Idempotent reports whether req may be sent more than once.
+func Idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+type clientKey struct {
+	host  string
+	proto Protocol
+}
+
+func (c *Client) get(ck clientKey, key string) (*http.Client, *Breaker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	client, ok := c.clients[ck]
+	if !ok {
+		client = &http.Client{Transport: c.transport(ck.proto)}
+		c.clients[ck] = client
+	}
+	breaker, ok := c.breakers[key]
+	if !ok {
+		breaker = NewBreaker(c.opts.BreakerThreshold, c.opts.BreakerMinRequests, c.opts.BreakerWindow, c.opts.BreakerCooldown)
+		c.breakers[key] = breaker
+	}
+	return client, breaker
+}
+
+func (c *Client) transport(proto Protocol) *http.Transport {
+	t := &http.Transport{
+		DialContext:           (&net.Dialer{Timeout: c.opts.DialTimeout, KeepAlive: 30 * time.Second}).DialContext,
+		MaxIdleConns:          c.opts.MaxIdleConnsPerHost,
+		MaxIdleConnsPerHost:   c.opts.MaxIdleConnsPerHost,
+		IdleConnTimeout:       c.opts.IdleConnTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+	if proto == H2C {
+		// without HTTP1 in the set, http:// requests go out as HTTP/2
+		t.Protocols = new(http.Protocols)
+		t.Protocols.SetUnencryptedHTTP2(true)
+		t.HTTP2 = &http.HTTP2Config{
+			SendPingTimeout: 15 * time.Second,
+			PingTimeout:     5 * time.Second,
+		}
+	}
+	return t
+}
+
+// IsCircuitOpen unwraps err into an *CircuitOpenError.
+func IsCircuitOpen(err error) (*CircuitOpenError, bool) {
+	var open *CircuitOpenError
+	ok := errors.As(err, &open)
+	return open, ok
+}
@@ -0,0 +1,32 @@
+package upstream
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientProtocols(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Proto", r.Proto)
+	}))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetHTTP1(true)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	c := NewClient(Options{BreakerThreshold: 0.5, BreakerMinRequests: 20, BreakerWindow: time.Minute, BreakerCooldown: time.Second, DialTimeout: time.Second})
+	for proto, want := range map[Protocol]string{HTTP1: "HTTP/1.1", H2C: "HTTP/2.0"} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		resp, err := c.Do(req, "p/f", proto)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if got := resp.Header.Get("X-Proto"); got != want {
+			t.Errorf("protocol %d: server saw %s, want %s", proto, got, want)
+		}
+	}
+}
@@ -61,23 +61,26 @@ func (c *Client) IsFunctionActive(ctx context.Context, namespace, name string) (
 	return function.Spec.IsActive, nil
 }
 
-func (c *Client) MarkFunctionActive(ctx context.Context, namespace, name string, keepWarmDuration time.Duration) (*apiv1.Function, error) {
+// MarkFunctionActive also reports whether the function was already active,
+// so callers can tell cold starts apart.
+func (c *Client) MarkFunctionActive(ctx context.Context, namespace, name string, keepWarmDuration time.Duration) (*apiv1.Function, bool, error) {
 	function, err := c.GetFunction(ctx, namespace, name)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
+	wasActive := function.Spec.IsActive
 	function.Spec.IsActive = true
 	now := time.Now()
 	deprovisionTime := now.Add(keepWarmDuration)
 	function.Spec.DeProvisionTime = deprovisionTime.Format(time.RFC3339)
 
 	if err := c.Client.Update(ctx, function); err != nil {
-		return nil, fmt.Errorf("failed to update function: %w", err)
+		return nil, false, fmt.Errorf("failed to update function: %w", err)
 	}
 
 	c.Log.Info("Marked function as active", "namespace", namespace, "name", name, "deprovisionTime", deprovisionTime)
-	return function, nil
+	return function, wasActive, nil
 }
 
 func (c *Client) ExtendFunctionLease(ctx context.Context, namespace, name string, keepWarmDuration time.Duration) (bool, error) {
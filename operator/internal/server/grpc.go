@@ -9,6 +9,7 @@ import (
 	"github.com/go-logr/logr"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 type FunctionServer struct {
@@ -51,19 +52,23 @@ func (s *FunctionServer) Activate(ctx context.Context, req *functionproto.Activa
 	if keepWarm <= 0 {
 		keepWarm = 5 * time.Minute
 	}
-	fn, err := s.Client.MarkFunctionActive(ctx, req.Namespace, req.Name, keepWarm)
+	fn, wasActive, err := s.Client.MarkFunctionActive(ctx, req.Namespace, req.Name, keepWarm)
+	if apierrors.IsNotFound(err) {
+		return nil, status.Errorf(codes.NotFound, "function %s/%s not found", req.Namespace, req.Name)
+	}
 	if err != nil {
 		s.Log.Error(err, "Failed to mark function as active", "namespace", req.Namespace, "name", req.Name)
 		return nil, status.Error(codes.Internal, "Failed to activate function: "+err.Error())
 	}
 
 	resp := &functionproto.ActivateResponse{
-		IsActive: true,
-		Language: fn.Spec.Language,
-		IsAsync:  fn.Spec.IsAsync,
-		Project:  fn.Spec.Project,
-		Name:     fn.Spec.Name,
-		Method:   fn.Spec.Method,
+		IsActive:  true,
+		Language:  fn.Spec.Language,
+		IsAsync:   fn.Spec.IsAsync,
+		Project:   fn.Spec.Project,
+		Name:      fn.Spec.Name,
+		Method:    fn.Spec.Method,
+		ColdStart: !wasActive,
 	}
 	resp.ServiceName = client.GetServiceName(fn)
 	resp.ServicePort = 8080
@@ -0,0 +1,29 @@
+-- name: CreateApiKey :one
+INSERT INTO project_api_keys (project_id, name, prefix, key_hash, endpoint_ids, expires_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: ListApiKeysForProject :many
+SELECT *
+FROM project_api_keys
+WHERE project_id = $1
+ORDER BY created_at DESC;
+
+-- name: RevokeApiKey :one
+UPDATE project_api_keys
+SET revoked_at = now()
+WHERE id = $1 AND project_id = $2 AND revoked_at IS NULL
+RETURNING *;
+
+-- name: TouchApiKeyLastUsed :exec
+UPDATE project_api_keys
+SET last_used_at = $2
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < $2);
+
+-- name: ListActiveApiKeys :many
+SELECT k.*, p.name as project_name
+FROM project_api_keys k
+JOIN projects p ON k.project_id = p.id
+WHERE k.revoked_at IS NULL
+  AND (k.expires_at IS NULL OR k.expires_at > now());
@@ -0,0 +1,150 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/portal/internal/apikey/adaptors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	keyPrefix     = "lf_"
+	displayLength = 10
+)
+
+// Generate returns a new plaintext key and the prefix shown in listings. The
+// plaintext is only ever returned to the user once.
+func Generate() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key := keyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:displayLength], nil
+}
+
+// Registry mirrors active api keys, by hash, into the KV bucket ingestors
+// verify against.
+type Registry struct {
+	kv   jetstream.KeyValue
+	pool *pgxpool.Pool
+}
+
+func NewRegistry(ctx context.Context, js jetstream.JetStream, pool *pgxpool.Pool) (*Registry, error) {
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      gateway.ApiKeysBucket,
+		Description: "litefunctions project api keys",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating api keys bucket: %w", err)
+	}
+	return &Registry{kv: kv, pool: pool}, nil
+}
+
+func (r *Registry) Publish(ctx context.Context, project string, key adaptors.ProjectApiKey) error {
+	spec := gateway.ApiKey{
+		ID:      hex.EncodeToString(key.ID.Bytes[:]),
+		Project: project,
+	}
+	for _, id := range key.EndpointIds {
+		spec.Endpoints = append(spec.Endpoints, hex.EncodeToString(id.Bytes[:]))
+	}
+	if key.ExpiresAt.Valid {
+		expiresAt := key.ExpiresAt.Time
+		spec.ExpiresAt = &expiresAt
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	if _, err := r.kv.Put(ctx, key.KeyHash, data); err != nil {
+		return fmt.Errorf("error publishing api key: %w", err)
+	}
+	return nil
+}
+
+func (r *Registry) Revoke(ctx context.Context, keyHash string) error {
+	err := r.kv.Delete(ctx, keyHash)
+	if err != nil && !errors.Is(err, jetstream.ErrKeyNotFound) {
+		return fmt.Errorf("error removing api key: %w", err)
+	}
+	return nil
+}
+
+// Resync publishes every active key and drops revoked or expired ones.
+func (r *Registry) Resync(ctx context.Context) error {
+	rows, err := adaptors.New(r.pool).ListActiveApiKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing api keys: %w", err)
+	}
+	live := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		live[row.KeyHash] = true
+		key := adaptors.ProjectApiKey{
+			ID:          row.ID,
+			ProjectID:   row.ProjectID,
+			KeyHash:     row.KeyHash,
+			EndpointIds: row.EndpointIds,
+			ExpiresAt:   row.ExpiresAt,
+		}
+		if err := r.Publish(ctx, row.ProjectName, key); err != nil {
+			return err
+		}
+	}
+
+	lister, err := r.kv.ListKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing api key hashes: %w", err)
+	}
+	for hash := range lister.Keys() {
+		if live[hash] {
+			continue
+		}
+		if err := r.kv.Delete(ctx, hash); err != nil {
+			slog.Warn("failed to drop stale api key", "error", err)
+		}
+	}
+	slog.Info("api keys resynced", "count", len(rows))
+	return nil
+}
+
+// ConsumeUsage records last-used timestamps reported by ingestors.
+func (r *Registry) ConsumeUsage(nc *nats.Conn) (*nats.Subscription, error) {
+	q := adaptors.New(r.pool)
+	return nc.QueueSubscribe(gateway.ApiKeyUsedSubject, "litefunctions-portal", func(msg *nats.Msg) {
+		var usage gateway.ApiKeyUsage
+		if err := json.Unmarshal(msg.Data, &usage); err != nil {
+			slog.Warn("invalid api key usage event", "error", err)
+			return
+		}
+		idBytes, err := hex.DecodeString(usage.ID)
+		if err != nil || len(idBytes) != 16 {
+			slog.Warn("invalid api key id in usage event", "id", usage.ID)
+			return
+		}
+		var id pgtype.UUID
+		copy(id.Bytes[:], idBytes)
+		id.Valid = true
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := q.TouchApiKeyLastUsed(ctx, adaptors.TouchApiKeyLastUsedParams{
+			ID:         id,
+			LastUsedAt: pgtype.Timestamptz{Time: usage.UsedAt, Valid: true},
+		}); err != nil {
+			slog.Error("failed to record api key usage", "id", usage.ID, "error", err)
+		}
+	})
+}
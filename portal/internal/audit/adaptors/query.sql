@@ -0,0 +1,11 @@
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (project_id, actor, action, target, detail)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListAuditEventsForProject :many
+SELECT a.*, u.name as actor_name
+FROM audit_events a
+LEFT JOIN users u ON a.actor = u.id
+WHERE a.project_id = $1
+ORDER BY a.created_at DESC
+LIMIT $2;
@@ -0,0 +1,33 @@
+// Package audit records who changed what in a project.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ashupednekar/litefunctions/portal/internal/audit/adaptors"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Record stores an audit event. db may be a transaction so the event commits
+// together with the change it describes.
+func Record(ctx context.Context, db adaptors.DBTX, project pgtype.UUID, actor []byte, action, target string, detail any) error {
+	data, err := json.Marshal(detail)
+	if err != nil {
+		return err
+	}
+	if detail == nil {
+		data = []byte("{}")
+	}
+	if err := adaptors.New(db).CreateAuditEvent(ctx, adaptors.CreateAuditEventParams{
+		ProjectID: project,
+		Actor:     actor,
+		Action:    action,
+		Target:    target,
+		Detail:    data,
+	}); err != nil {
+		return fmt.Errorf("error recording audit event: %w", err)
+	}
+	return nil
+}
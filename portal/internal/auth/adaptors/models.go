@@ -54,6 +54,16 @@ func (ns NullProjectRole) Value() (driver.Value, error) {
 	return string(ns.ProjectRole), nil
 }
 
+type AuditEvent struct {
+	ID        pgtype.UUID
+	ProjectID pgtype.UUID
+	Actor     []byte
+	Action    string
+	Target    string
+	Detail    []byte
+	CreatedAt pgtype.Timestamptz
+}
+
 type Credential struct {
 	ID              []byte
 	UserID          []byte
@@ -77,6 +87,87 @@ type Endpoint struct {
 	CreatedAt  pgtype.Timestamptz
 }
 
+type EndpointCacheConfig struct {
+	EndpointID  pgtype.UUID
+	TtlSeconds  int32
+	VaryHeaders []string
+	VaryQuery   []string
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type EndpointCallback struct {
+	EndpointID     pgtype.UUID
+	Url            string
+	Secret         string
+	AllowCallerUrl bool
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type EndpointCorsConfig struct {
+	EndpointID       pgtype.UUID
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           int32
+	UpdatedAt        pgtype.Timestamptz
+}
+
+type EndpointFault struct {
+	EndpointID  pgtype.UUID
+	Percentage  float64
+	DelayMs     int32
+	ErrorStatus int32
+	DropAsync   bool
+	Header      string
+	ExpiresAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type EndpointJwtConfig struct {
+	EndpointID     pgtype.UUID
+	JwksUrl        string
+	Issuer         string
+	Audiences      []string
+	RequiredClaims []byte
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type EndpointMiddleware struct {
+	EndpointID pgtype.UUID
+	Chain      []byte
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type EndpointPolicy struct {
+	EndpointID    pgtype.UUID
+	Mode          string
+	DefaultEffect string
+	Rules         []byte
+	UpdatedAt     pgtype.Timestamptz
+}
+
+type EndpointPriority struct {
+	EndpointID pgtype.UUID
+	Priority   string
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type EndpointSchema struct {
+	EndpointID  pgtype.UUID
+	BodySchema  []byte
+	QuerySchema []byte
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type EndpointTransform struct {
+	EndpointID    pgtype.UUID
+	RequestRules  []byte
+	ResponseRules []byte
+	UpdatedAt     pgtype.Timestamptz
+}
+
 type Function struct {
 	ID        pgtype.UUID
 	ProjectID pgtype.UUID
@@ -88,6 +179,32 @@ type Function struct {
 	CreatedAt pgtype.Timestamptz
 }
 
+type Invocation struct {
+	ProjectID     pgtype.UUID
+	RequestID     string
+	FunctionName  string
+	Endpoint      string
+	Method        string
+	Source        string
+	Status        int32
+	LatencyMs     int64
+	ColdStart     bool
+	Async         bool
+	RequestBytes  int64
+	ResponseBytes int64
+	Error         string
+	StartedAt     pgtype.Timestamptz
+}
+
+type MaintenanceMode struct {
+	ID         pgtype.UUID
+	ProjectID  pgtype.UUID
+	FunctionID pgtype.UUID
+	Message    string
+	EnabledBy  []byte
+	EnabledAt  pgtype.Timestamptz
+}
+
 type Project struct {
 	ID          pgtype.UUID
 	Name        string
@@ -96,6 +213,28 @@ type Project struct {
 	CreatedAt   pgtype.Timestamptz
 }
 
+type ProjectApiKey struct {
+	ID          pgtype.UUID
+	ProjectID   pgtype.UUID
+	Name        string
+	Prefix      string
+	KeyHash     string
+	EndpointIds []pgtype.UUID
+	ExpiresAt   pgtype.Timestamptz
+	RevokedAt   pgtype.Timestamptz
+	LastUsedAt  pgtype.Timestamptz
+	CreatedBy   []byte
+	CreatedAt   pgtype.Timestamptz
+}
+
+type ProjectDomain struct {
+	Host      string
+	ProjectID pgtype.UUID
+	BasePath  string
+	CreatedBy []byte
+	CreatedAt pgtype.Timestamptz
+}
+
 type ProjectInvite struct {
 	ID         pgtype.UUID
 	ProjectID  pgtype.UUID
@@ -106,6 +245,16 @@ type ProjectInvite struct {
 	CreatedAt  pgtype.Timestamptz
 }
 
+type ProjectQuota struct {
+	ID           pgtype.UUID
+	ProjectID    pgtype.UUID
+	ApiKeyID     pgtype.UUID
+	DailyLimit   int64
+	MonthlyLimit int64
+	UpdatedBy    []byte
+	UpdatedAt    pgtype.Timestamptz
+}
+
 type User struct {
 	ID          []byte
 	Name        string
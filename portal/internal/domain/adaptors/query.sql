@@ -0,0 +1,18 @@
+-- name: CreateProjectDomain :one
+INSERT INTO project_domains (host, project_id, base_path, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: DeleteProjectDomain :execrows
+DELETE FROM project_domains
+WHERE host = $1 AND project_id = $2;
+
+-- name: ListProjectDomainsForProject :many
+SELECT * FROM project_domains
+WHERE project_id = $1
+ORDER BY host;
+
+-- name: ListProjectDomains :many
+SELECT d.*, p.name as project_name
+FROM project_domains d
+JOIN projects p ON d.project_id = p.id;
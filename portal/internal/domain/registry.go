@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/portal/internal/domain/adaptors"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Registry mirrors custom domain mappings into the KV bucket ingestors use
+// to route requests by Host.
+type Registry struct {
+	kv   jetstream.KeyValue
+	pool *pgxpool.Pool
+}
+
+func NewRegistry(ctx context.Context, js jetstream.JetStream, pool *pgxpool.Pool) (*Registry, error) {
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      gateway.DomainsBucket,
+		Description: "litefunctions custom domains",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating domains bucket: %w", err)
+	}
+	return &Registry{kv: kv, pool: pool}, nil
+}
+
+func (r *Registry) Publish(ctx context.Context, spec gateway.Domain) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	if _, err := r.kv.Put(ctx, spec.Host, data); err != nil {
+		return fmt.Errorf("error publishing domain: %w", err)
+	}
+	return nil
+}
+
+func (r *Registry) Delete(ctx context.Context, host string) error {
+	err := r.kv.Delete(ctx, host)
+	if err != nil && !errors.Is(err, jetstream.ErrKeyNotFound) {
+		return fmt.Errorf("error removing domain: %w", err)
+	}
+	return nil
+}
+
+// Resync publishes every mapping and drops hosts that were removed while the
+// portal was away.
+func (r *Registry) Resync(ctx context.Context) error {
+	rows, err := adaptors.New(r.pool).ListProjectDomains(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing domains: %w", err)
+	}
+	live := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		live[row.Host] = true
+		if err := r.Publish(ctx, gateway.Domain{Host: row.Host, Project: row.ProjectName, BasePath: row.BasePath}); err != nil {
+			return err
+		}
+	}
+
+	lister, err := r.kv.ListKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing domain keys: %w", err)
+	}
+	for key := range lister.Keys() {
+		if live[key] {
+			continue
+		}
+		if err := r.kv.Delete(ctx, key); err != nil {
+			slog.Warn("failed to drop stale domain", "host", key, "error", err)
+		}
+	}
+	slog.Info("domains resynced", "count", len(rows))
+	return nil
+}
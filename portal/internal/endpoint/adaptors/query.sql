@@ -42,3 +42,304 @@ WHERE e.project_id = $1
       )
 ORDER BY e.name ASC, e.method ASC
 LIMIT $3 OFFSET $4;
+
+-- name: GetEndpointSpec :one
+SELECT e.*, p.name as project_name, f.name as function_name
+FROM endpoints e
+JOIN projects p ON e.project_id = p.id
+JOIN functions f ON e.function_id = f.id
+WHERE e.id = $1;
+
+-- name: ListEndpointSpecs :many
+SELECT e.*, p.name as project_name, f.name as function_name
+FROM endpoints e
+JOIN projects p ON e.project_id = p.id
+JOIN functions f ON e.function_id = f.id
+ORDER BY p.name ASC, e.name ASC;
+
+-- name: GetEndpointJwtConfig :one
+SELECT *
+FROM endpoint_jwt_configs
+WHERE endpoint_id = $1;
+
+-- name: ListEndpointJwtConfigs :many
+SELECT *
+FROM endpoint_jwt_configs;
+
+-- name: UpsertEndpointJwtConfig :one
+INSERT INTO endpoint_jwt_configs (endpoint_id, jwks_url, issuer, audiences, required_claims)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET jwks_url = EXCLUDED.jwks_url,
+    issuer = EXCLUDED.issuer,
+    audiences = EXCLUDED.audiences,
+    required_claims = EXCLUDED.required_claims,
+    updated_at = now()
+RETURNING *;
+
+-- name: ListEndpointJwtConfigsForProject :many
+SELECT c.*
+FROM endpoint_jwt_configs c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1;
+
+-- name: GetEndpointPolicy :one
+SELECT *
+FROM endpoint_policies
+WHERE endpoint_id = $1;
+
+-- name: ListEndpointPolicies :many
+SELECT *
+FROM endpoint_policies;
+
+-- name: ListEndpointPoliciesForProject :many
+SELECT p.*
+FROM endpoint_policies p
+JOIN endpoints e ON p.endpoint_id = e.id
+WHERE e.project_id = $1;
+
+-- name: UpsertEndpointPolicy :one
+INSERT INTO endpoint_policies (endpoint_id, mode, default_effect, rules)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET mode = EXCLUDED.mode,
+    default_effect = EXCLUDED.default_effect,
+    rules = EXCLUDED.rules,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteEndpointPolicy :exec
+DELETE FROM endpoint_policies
+WHERE endpoint_id = $1;
+
+-- name: GetEndpointCorsConfig :one
+SELECT *
+FROM endpoint_cors_configs
+WHERE endpoint_id = $1;
+
+-- name: ListEndpointCorsConfigs :many
+SELECT *
+FROM endpoint_cors_configs;
+
+-- name: ListEndpointCorsConfigsForProject :many
+SELECT c.*
+FROM endpoint_cors_configs c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1;
+
+-- name: UpsertEndpointCorsConfig :one
+INSERT INTO endpoint_cors_configs (endpoint_id, allowed_origins, allowed_methods, allowed_headers, exposed_headers, allow_credentials, max_age)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET allowed_origins = EXCLUDED.allowed_origins,
+    allowed_methods = EXCLUDED.allowed_methods,
+    allowed_headers = EXCLUDED.allowed_headers,
+    exposed_headers = EXCLUDED.exposed_headers,
+    allow_credentials = EXCLUDED.allow_credentials,
+    max_age = EXCLUDED.max_age,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteEndpointCorsConfig :exec
+DELETE FROM endpoint_cors_configs
+WHERE endpoint_id = $1;
+
+-- name: GetEndpointCacheConfig :one
+SELECT *
+FROM endpoint_cache_configs
+WHERE endpoint_id = $1;
+
+-- name: ListEndpointCacheConfigs :many
+SELECT *
+FROM endpoint_cache_configs;
+
+-- name: ListEndpointCacheConfigsForProject :many
+SELECT c.*
+FROM endpoint_cache_configs c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1;
+
+-- name: UpsertEndpointCacheConfig :one
+INSERT INTO endpoint_cache_configs (endpoint_id, ttl_seconds, vary_headers, vary_query)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET ttl_seconds = EXCLUDED.ttl_seconds,
+    vary_headers = EXCLUDED.vary_headers,
+    vary_query = EXCLUDED.vary_query,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteEndpointCacheConfig :exec
+DELETE FROM endpoint_cache_configs
+WHERE endpoint_id = $1;
+
+-- name: GetEndpointSchema :one
+SELECT *
+FROM endpoint_schemas
+WHERE endpoint_id = $1;
+
+-- name: ListEndpointSchemas :many
+SELECT *
+FROM endpoint_schemas;
+
+-- name: ListEndpointSchemasForProject :many
+SELECT c.*
+FROM endpoint_schemas c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1;
+
+-- name: UpsertEndpointSchema :one
+INSERT INTO endpoint_schemas (endpoint_id, body_schema, query_schema)
+VALUES ($1, $2, $3)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET body_schema = EXCLUDED.body_schema,
+    query_schema = EXCLUDED.query_schema,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteEndpointSchema :exec
+DELETE FROM endpoint_schemas
+WHERE endpoint_id = $1;
+
+-- name: GetEndpointCallback :one
+SELECT *
+FROM endpoint_callbacks
+WHERE endpoint_id = $1;
+
+-- name: ListEndpointCallbacks :many
+SELECT *
+FROM endpoint_callbacks;
+
+-- name: ListEndpointCallbacksForProject :many
+SELECT c.*
+FROM endpoint_callbacks c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1;
+
+-- name: UpsertEndpointCallback :one
+INSERT INTO endpoint_callbacks (endpoint_id, url, secret, allow_caller_url)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET url = EXCLUDED.url,
+    secret = EXCLUDED.secret,
+    allow_caller_url = EXCLUDED.allow_caller_url,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteEndpointCallback :exec
+DELETE FROM endpoint_callbacks
+WHERE endpoint_id = $1;
+
+-- name: GetEndpointFault :one
+SELECT *
+FROM endpoint_faults
+WHERE endpoint_id = $1;
+
+-- name: ListEndpointFaults :many
+SELECT *
+FROM endpoint_faults;
+
+-- name: ListEndpointFaultsForProject :many
+SELECT c.*
+FROM endpoint_faults c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1;
+
+-- name: UpsertEndpointFault :one
+INSERT INTO endpoint_faults (endpoint_id, percentage, delay_ms, error_status, drop_async, header, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET percentage = EXCLUDED.percentage,
+    delay_ms = EXCLUDED.delay_ms,
+    error_status = EXCLUDED.error_status,
+    drop_async = EXCLUDED.drop_async,
+    header = EXCLUDED.header,
+    expires_at = EXCLUDED.expires_at,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteEndpointFault :exec
+DELETE FROM endpoint_faults
+WHERE endpoint_id = $1;
+
+-- name: GetEndpointTransform :one
+SELECT *
+FROM endpoint_transforms
+WHERE endpoint_id = $1;
+
+-- name: ListEndpointTransforms :many
+SELECT *
+FROM endpoint_transforms;
+
+-- name: ListEndpointTransformsForProject :many
+SELECT c.*
+FROM endpoint_transforms c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1;
+
+-- name: UpsertEndpointTransform :one
+INSERT INTO endpoint_transforms (endpoint_id, request_rules, response_rules)
+VALUES ($1, $2, $3)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET request_rules = EXCLUDED.request_rules,
+    response_rules = EXCLUDED.response_rules,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteEndpointTransform :exec
+DELETE FROM endpoint_transforms
+WHERE endpoint_id = $1;
+
+-- name: GetEndpointPriority :one
+SELECT *
+FROM endpoint_priorities
+WHERE endpoint_id = $1;
+
+-- name: ListEndpointPriorities :many
+SELECT *
+FROM endpoint_priorities;
+
+-- name: ListEndpointPrioritiesForProject :many
+SELECT c.*
+FROM endpoint_priorities c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1;
+
+-- name: UpsertEndpointPriority :one
+INSERT INTO endpoint_priorities (endpoint_id, priority)
+VALUES ($1, $2)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET priority = EXCLUDED.priority,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteEndpointPriority :exec
+DELETE FROM endpoint_priorities
+WHERE endpoint_id = $1;
+
+-- name: GetEndpointMiddleware :one
+SELECT *
+FROM endpoint_middleware
+WHERE endpoint_id = $1;
+
+-- name: ListEndpointMiddleware :many
+SELECT *
+FROM endpoint_middleware;
+
+-- name: ListEndpointMiddlewareForProject :many
+SELECT c.*
+FROM endpoint_middleware c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1;
+
+-- name: UpsertEndpointMiddleware :one
+INSERT INTO endpoint_middleware (endpoint_id, chain)
+VALUES ($1, $2)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET chain = EXCLUDED.chain,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteEndpointMiddleware :exec
+DELETE FROM endpoint_middleware
+WHERE endpoint_id = $1;
@@ -56,25 +56,961 @@ func (q *Queries) DeleteEndpoint(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const deleteEndpointCacheConfig = `-- name: DeleteEndpointCacheConfig :exec
+DELETE FROM endpoint_cache_configs
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) DeleteEndpointCacheConfig(ctx context.Context, endpointID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteEndpointCacheConfig, endpointID)
+	return err
+}
+
+const deleteEndpointCallback = `-- name: DeleteEndpointCallback :exec
+DELETE FROM endpoint_callbacks
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) DeleteEndpointCallback(ctx context.Context, endpointID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteEndpointCallback, endpointID)
+	return err
+}
+
+const deleteEndpointCorsConfig = `-- name: DeleteEndpointCorsConfig :exec
+DELETE FROM endpoint_cors_configs
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) DeleteEndpointCorsConfig(ctx context.Context, endpointID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteEndpointCorsConfig, endpointID)
+	return err
+}
+
+const deleteEndpointFault = `-- name: DeleteEndpointFault :exec
+DELETE FROM endpoint_faults
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) DeleteEndpointFault(ctx context.Context, endpointID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteEndpointFault, endpointID)
+	return err
+}
+
+const deleteEndpointMiddleware = `-- name: DeleteEndpointMiddleware :exec
+DELETE FROM endpoint_middleware
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) DeleteEndpointMiddleware(ctx context.Context, endpointID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteEndpointMiddleware, endpointID)
+	return err
+}
+
+const deleteEndpointPolicy = `-- name: DeleteEndpointPolicy :exec
+DELETE FROM endpoint_policies
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) DeleteEndpointPolicy(ctx context.Context, endpointID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteEndpointPolicy, endpointID)
+	return err
+}
+
+const deleteEndpointPriority = `-- name: DeleteEndpointPriority :exec
+DELETE FROM endpoint_priorities
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) DeleteEndpointPriority(ctx context.Context, endpointID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteEndpointPriority, endpointID)
+	return err
+}
+
+const deleteEndpointSchema = `-- name: DeleteEndpointSchema :exec
+DELETE FROM endpoint_schemas
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) DeleteEndpointSchema(ctx context.Context, endpointID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteEndpointSchema, endpointID)
+	return err
+}
+
+const deleteEndpointTransform = `-- name: DeleteEndpointTransform :exec
+DELETE FROM endpoint_transforms
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) DeleteEndpointTransform(ctx context.Context, endpointID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteEndpointTransform, endpointID)
+	return err
+}
+
 const getEndpointByID = `-- name: GetEndpointByID :one
 SELECT id, project_id, name, method, scope, function_id, created_at
 FROM endpoints
 WHERE id = $1
 `
 
-func (q *Queries) GetEndpointByID(ctx context.Context, id pgtype.UUID) (Endpoint, error) {
-	row := q.db.QueryRow(ctx, getEndpointByID, id)
-	var i Endpoint
-	err := row.Scan(
-		&i.ID,
-		&i.ProjectID,
-		&i.Name,
-		&i.Method,
-		&i.Scope,
-		&i.FunctionID,
-		&i.CreatedAt,
-	)
-	return i, err
+func (q *Queries) GetEndpointByID(ctx context.Context, id pgtype.UUID) (Endpoint, error) {
+	row := q.db.QueryRow(ctx, getEndpointByID, id)
+	var i Endpoint
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Method,
+		&i.Scope,
+		&i.FunctionID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getEndpointCacheConfig = `-- name: GetEndpointCacheConfig :one
+SELECT endpoint_id, ttl_seconds, vary_headers, vary_query, updated_at
+FROM endpoint_cache_configs
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) GetEndpointCacheConfig(ctx context.Context, endpointID pgtype.UUID) (EndpointCacheConfig, error) {
+	row := q.db.QueryRow(ctx, getEndpointCacheConfig, endpointID)
+	var i EndpointCacheConfig
+	err := row.Scan(
+		&i.EndpointID,
+		&i.TtlSeconds,
+		&i.VaryHeaders,
+		&i.VaryQuery,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getEndpointCallback = `-- name: GetEndpointCallback :one
+SELECT endpoint_id, url, secret, allow_caller_url, updated_at
+FROM endpoint_callbacks
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) GetEndpointCallback(ctx context.Context, endpointID pgtype.UUID) (EndpointCallback, error) {
+	row := q.db.QueryRow(ctx, getEndpointCallback, endpointID)
+	var i EndpointCallback
+	err := row.Scan(
+		&i.EndpointID,
+		&i.Url,
+		&i.Secret,
+		&i.AllowCallerUrl,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getEndpointCorsConfig = `-- name: GetEndpointCorsConfig :one
+SELECT endpoint_id, allowed_origins, allowed_methods, allowed_headers, exposed_headers, allow_credentials, max_age, updated_at
+FROM endpoint_cors_configs
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) GetEndpointCorsConfig(ctx context.Context, endpointID pgtype.UUID) (EndpointCorsConfig, error) {
+	row := q.db.QueryRow(ctx, getEndpointCorsConfig, endpointID)
+	var i EndpointCorsConfig
+	err := row.Scan(
+		&i.EndpointID,
+		&i.AllowedOrigins,
+		&i.AllowedMethods,
+		&i.AllowedHeaders,
+		&i.ExposedHeaders,
+		&i.AllowCredentials,
+		&i.MaxAge,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getEndpointFault = `-- name: GetEndpointFault :one
+SELECT endpoint_id, percentage, delay_ms, error_status, drop_async, header, expires_at, updated_at
+FROM endpoint_faults
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) GetEndpointFault(ctx context.Context, endpointID pgtype.UUID) (EndpointFault, error) {
+	row := q.db.QueryRow(ctx, getEndpointFault, endpointID)
+	var i EndpointFault
+	err := row.Scan(
+		&i.EndpointID,
+		&i.Percentage,
+		&i.DelayMs,
+		&i.ErrorStatus,
+		&i.DropAsync,
+		&i.Header,
+		&i.ExpiresAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getEndpointJwtConfig = `-- name: GetEndpointJwtConfig :one
+SELECT endpoint_id, jwks_url, issuer, audiences, required_claims, updated_at
+FROM endpoint_jwt_configs
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) GetEndpointJwtConfig(ctx context.Context, endpointID pgtype.UUID) (EndpointJwtConfig, error) {
+	row := q.db.QueryRow(ctx, getEndpointJwtConfig, endpointID)
+	var i EndpointJwtConfig
+	err := row.Scan(
+		&i.EndpointID,
+		&i.JwksUrl,
+		&i.Issuer,
+		&i.Audiences,
+		&i.RequiredClaims,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getEndpointMiddleware = `-- name: GetEndpointMiddleware :one
+SELECT endpoint_id, chain, updated_at
+FROM endpoint_middleware
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) GetEndpointMiddleware(ctx context.Context, endpointID pgtype.UUID) (EndpointMiddleware, error) {
+	row := q.db.QueryRow(ctx, getEndpointMiddleware, endpointID)
+	var i EndpointMiddleware
+	err := row.Scan(
+		&i.EndpointID,
+		&i.Chain,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getEndpointPolicy = `-- name: GetEndpointPolicy :one
+SELECT endpoint_id, mode, default_effect, rules, updated_at
+FROM endpoint_policies
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) GetEndpointPolicy(ctx context.Context, endpointID pgtype.UUID) (EndpointPolicy, error) {
+	row := q.db.QueryRow(ctx, getEndpointPolicy, endpointID)
+	var i EndpointPolicy
+	err := row.Scan(
+		&i.EndpointID,
+		&i.Mode,
+		&i.DefaultEffect,
+		&i.Rules,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getEndpointPriority = `-- name: GetEndpointPriority :one
+SELECT endpoint_id, priority, updated_at
+FROM endpoint_priorities
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) GetEndpointPriority(ctx context.Context, endpointID pgtype.UUID) (EndpointPriority, error) {
+	row := q.db.QueryRow(ctx, getEndpointPriority, endpointID)
+	var i EndpointPriority
+	err := row.Scan(&i.EndpointID, &i.Priority, &i.UpdatedAt)
+	return i, err
+}
+
+const getEndpointSchema = `-- name: GetEndpointSchema :one
+SELECT endpoint_id, body_schema, query_schema, updated_at
+FROM endpoint_schemas
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) GetEndpointSchema(ctx context.Context, endpointID pgtype.UUID) (EndpointSchema, error) {
+	row := q.db.QueryRow(ctx, getEndpointSchema, endpointID)
+	var i EndpointSchema
+	err := row.Scan(
+		&i.EndpointID,
+		&i.BodySchema,
+		&i.QuerySchema,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getEndpointSpec = `-- name: GetEndpointSpec :one
+SELECT e.id, e.project_id, e.name, e.method, e.scope, e.function_id, e.created_at, p.name as project_name, f.name as function_name
+FROM endpoints e
+JOIN projects p ON e.project_id = p.id
+JOIN functions f ON e.function_id = f.id
+WHERE e.id = $1
+`
+
+type GetEndpointSpecRow struct {
+	ID           pgtype.UUID
+	ProjectID    pgtype.UUID
+	Name         string
+	Method       string
+	Scope        string
+	FunctionID   pgtype.UUID
+	CreatedAt    pgtype.Timestamptz
+	ProjectName  string
+	FunctionName string
+}
+
+func (q *Queries) GetEndpointSpec(ctx context.Context, id pgtype.UUID) (GetEndpointSpecRow, error) {
+	row := q.db.QueryRow(ctx, getEndpointSpec, id)
+	var i GetEndpointSpecRow
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Method,
+		&i.Scope,
+		&i.FunctionID,
+		&i.CreatedAt,
+		&i.ProjectName,
+		&i.FunctionName,
+	)
+	return i, err
+}
+
+const getEndpointTransform = `-- name: GetEndpointTransform :one
+SELECT endpoint_id, request_rules, response_rules, updated_at
+FROM endpoint_transforms
+WHERE endpoint_id = $1
+`
+
+func (q *Queries) GetEndpointTransform(ctx context.Context, endpointID pgtype.UUID) (EndpointTransform, error) {
+	row := q.db.QueryRow(ctx, getEndpointTransform, endpointID)
+	var i EndpointTransform
+	err := row.Scan(
+		&i.EndpointID,
+		&i.RequestRules,
+		&i.ResponseRules,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listEndpointCacheConfigs = `-- name: ListEndpointCacheConfigs :many
+SELECT endpoint_id, ttl_seconds, vary_headers, vary_query, updated_at
+FROM endpoint_cache_configs
+`
+
+func (q *Queries) ListEndpointCacheConfigs(ctx context.Context) ([]EndpointCacheConfig, error) {
+	rows, err := q.db.Query(ctx, listEndpointCacheConfigs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointCacheConfig
+	for rows.Next() {
+		var i EndpointCacheConfig
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.TtlSeconds,
+			&i.VaryHeaders,
+			&i.VaryQuery,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointCacheConfigsForProject = `-- name: ListEndpointCacheConfigsForProject :many
+SELECT c.endpoint_id, c.ttl_seconds, c.vary_headers, c.vary_query, c.updated_at
+FROM endpoint_cache_configs c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1
+`
+
+func (q *Queries) ListEndpointCacheConfigsForProject(ctx context.Context, projectID pgtype.UUID) ([]EndpointCacheConfig, error) {
+	rows, err := q.db.Query(ctx, listEndpointCacheConfigsForProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointCacheConfig
+	for rows.Next() {
+		var i EndpointCacheConfig
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.TtlSeconds,
+			&i.VaryHeaders,
+			&i.VaryQuery,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointCallbacks = `-- name: ListEndpointCallbacks :many
+SELECT endpoint_id, url, secret, allow_caller_url, updated_at
+FROM endpoint_callbacks
+`
+
+func (q *Queries) ListEndpointCallbacks(ctx context.Context) ([]EndpointCallback, error) {
+	rows, err := q.db.Query(ctx, listEndpointCallbacks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointCallback
+	for rows.Next() {
+		var i EndpointCallback
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.Url,
+			&i.Secret,
+			&i.AllowCallerUrl,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointCallbacksForProject = `-- name: ListEndpointCallbacksForProject :many
+SELECT c.endpoint_id, c.url, c.secret, c.allow_caller_url, c.updated_at
+FROM endpoint_callbacks c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1
+`
+
+func (q *Queries) ListEndpointCallbacksForProject(ctx context.Context, projectID pgtype.UUID) ([]EndpointCallback, error) {
+	rows, err := q.db.Query(ctx, listEndpointCallbacksForProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointCallback
+	for rows.Next() {
+		var i EndpointCallback
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.Url,
+			&i.Secret,
+			&i.AllowCallerUrl,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointCorsConfigs = `-- name: ListEndpointCorsConfigs :many
+SELECT endpoint_id, allowed_origins, allowed_methods, allowed_headers, exposed_headers, allow_credentials, max_age, updated_at
+FROM endpoint_cors_configs
+`
+
+func (q *Queries) ListEndpointCorsConfigs(ctx context.Context) ([]EndpointCorsConfig, error) {
+	rows, err := q.db.Query(ctx, listEndpointCorsConfigs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointCorsConfig
+	for rows.Next() {
+		var i EndpointCorsConfig
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.AllowedOrigins,
+			&i.AllowedMethods,
+			&i.AllowedHeaders,
+			&i.ExposedHeaders,
+			&i.AllowCredentials,
+			&i.MaxAge,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointCorsConfigsForProject = `-- name: ListEndpointCorsConfigsForProject :many
+SELECT c.endpoint_id, c.allowed_origins, c.allowed_methods, c.allowed_headers, c.exposed_headers, c.allow_credentials, c.max_age, c.updated_at
+FROM endpoint_cors_configs c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1
+`
+
+func (q *Queries) ListEndpointCorsConfigsForProject(ctx context.Context, projectID pgtype.UUID) ([]EndpointCorsConfig, error) {
+	rows, err := q.db.Query(ctx, listEndpointCorsConfigsForProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointCorsConfig
+	for rows.Next() {
+		var i EndpointCorsConfig
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.AllowedOrigins,
+			&i.AllowedMethods,
+			&i.AllowedHeaders,
+			&i.ExposedHeaders,
+			&i.AllowCredentials,
+			&i.MaxAge,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointFaults = `-- name: ListEndpointFaults :many
+SELECT endpoint_id, percentage, delay_ms, error_status, drop_async, header, expires_at, updated_at
+FROM endpoint_faults
+`
+
+func (q *Queries) ListEndpointFaults(ctx context.Context) ([]EndpointFault, error) {
+	rows, err := q.db.Query(ctx, listEndpointFaults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointFault
+	for rows.Next() {
+		var i EndpointFault
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.Percentage,
+			&i.DelayMs,
+			&i.ErrorStatus,
+			&i.DropAsync,
+			&i.Header,
+			&i.ExpiresAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointFaultsForProject = `-- name: ListEndpointFaultsForProject :many
+SELECT c.endpoint_id, c.percentage, c.delay_ms, c.error_status, c.drop_async, c.header, c.expires_at, c.updated_at
+FROM endpoint_faults c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1
+`
+
+func (q *Queries) ListEndpointFaultsForProject(ctx context.Context, projectID pgtype.UUID) ([]EndpointFault, error) {
+	rows, err := q.db.Query(ctx, listEndpointFaultsForProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointFault
+	for rows.Next() {
+		var i EndpointFault
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.Percentage,
+			&i.DelayMs,
+			&i.ErrorStatus,
+			&i.DropAsync,
+			&i.Header,
+			&i.ExpiresAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointJwtConfigs = `-- name: ListEndpointJwtConfigs :many
+SELECT endpoint_id, jwks_url, issuer, audiences, required_claims, updated_at
+FROM endpoint_jwt_configs
+`
+
+func (q *Queries) ListEndpointJwtConfigs(ctx context.Context) ([]EndpointJwtConfig, error) {
+	rows, err := q.db.Query(ctx, listEndpointJwtConfigs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointJwtConfig
+	for rows.Next() {
+		var i EndpointJwtConfig
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.JwksUrl,
+			&i.Issuer,
+			&i.Audiences,
+			&i.RequiredClaims,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointJwtConfigsForProject = `-- name: ListEndpointJwtConfigsForProject :many
+SELECT c.endpoint_id, c.jwks_url, c.issuer, c.audiences, c.required_claims, c.updated_at
+FROM endpoint_jwt_configs c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1
+`
+
+func (q *Queries) ListEndpointJwtConfigsForProject(ctx context.Context, projectID pgtype.UUID) ([]EndpointJwtConfig, error) {
+	rows, err := q.db.Query(ctx, listEndpointJwtConfigsForProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointJwtConfig
+	for rows.Next() {
+		var i EndpointJwtConfig
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.JwksUrl,
+			&i.Issuer,
+			&i.Audiences,
+			&i.RequiredClaims,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointMiddleware = `-- name: ListEndpointMiddleware :many
+SELECT endpoint_id, chain, updated_at
+FROM endpoint_middleware
+`
+
+func (q *Queries) ListEndpointMiddleware(ctx context.Context) ([]EndpointMiddleware, error) {
+	rows, err := q.db.Query(ctx, listEndpointMiddleware)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointMiddleware
+	for rows.Next() {
+		var i EndpointMiddleware
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.Chain,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointMiddlewareForProject = `-- name: ListEndpointMiddlewareForProject :many
+SELECT c.endpoint_id, c.chain, c.updated_at
+FROM endpoint_middleware c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1
+`
+
+func (q *Queries) ListEndpointMiddlewareForProject(ctx context.Context, projectID pgtype.UUID) ([]EndpointMiddleware, error) {
+	rows, err := q.db.Query(ctx, listEndpointMiddlewareForProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointMiddleware
+	for rows.Next() {
+		var i EndpointMiddleware
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.Chain,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointPolicies = `-- name: ListEndpointPolicies :many
+SELECT endpoint_id, mode, default_effect, rules, updated_at
+FROM endpoint_policies
+`
+
+func (q *Queries) ListEndpointPolicies(ctx context.Context) ([]EndpointPolicy, error) {
+	rows, err := q.db.Query(ctx, listEndpointPolicies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointPolicy
+	for rows.Next() {
+		var i EndpointPolicy
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.Mode,
+			&i.DefaultEffect,
+			&i.Rules,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointPoliciesForProject = `-- name: ListEndpointPoliciesForProject :many
+SELECT p.endpoint_id, p.mode, p.default_effect, p.rules, p.updated_at
+FROM endpoint_policies p
+JOIN endpoints e ON p.endpoint_id = e.id
+WHERE e.project_id = $1
+`
+
+func (q *Queries) ListEndpointPoliciesForProject(ctx context.Context, projectID pgtype.UUID) ([]EndpointPolicy, error) {
+	rows, err := q.db.Query(ctx, listEndpointPoliciesForProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointPolicy
+	for rows.Next() {
+		var i EndpointPolicy
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.Mode,
+			&i.DefaultEffect,
+			&i.Rules,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointPriorities = `-- name: ListEndpointPriorities :many
+SELECT endpoint_id, priority, updated_at
+FROM endpoint_priorities
+`
+
+func (q *Queries) ListEndpointPriorities(ctx context.Context) ([]EndpointPriority, error) {
+	rows, err := q.db.Query(ctx, listEndpointPriorities)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointPriority
+	for rows.Next() {
+		var i EndpointPriority
+		if err := rows.Scan(&i.EndpointID, &i.Priority, &i.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointPrioritiesForProject = `-- name: ListEndpointPrioritiesForProject :many
+SELECT c.endpoint_id, c.priority, c.updated_at
+FROM endpoint_priorities c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1
+`
+
+func (q *Queries) ListEndpointPrioritiesForProject(ctx context.Context, projectID pgtype.UUID) ([]EndpointPriority, error) {
+	rows, err := q.db.Query(ctx, listEndpointPrioritiesForProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointPriority
+	for rows.Next() {
+		var i EndpointPriority
+		if err := rows.Scan(&i.EndpointID, &i.Priority, &i.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointSchemas = `-- name: ListEndpointSchemas :many
+SELECT endpoint_id, body_schema, query_schema, updated_at
+FROM endpoint_schemas
+`
+
+func (q *Queries) ListEndpointSchemas(ctx context.Context) ([]EndpointSchema, error) {
+	rows, err := q.db.Query(ctx, listEndpointSchemas)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointSchema
+	for rows.Next() {
+		var i EndpointSchema
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.BodySchema,
+			&i.QuerySchema,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointSchemasForProject = `-- name: ListEndpointSchemasForProject :many
+SELECT c.endpoint_id, c.body_schema, c.query_schema, c.updated_at
+FROM endpoint_schemas c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1
+`
+
+func (q *Queries) ListEndpointSchemasForProject(ctx context.Context, projectID pgtype.UUID) ([]EndpointSchema, error) {
+	rows, err := q.db.Query(ctx, listEndpointSchemasForProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointSchema
+	for rows.Next() {
+		var i EndpointSchema
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.BodySchema,
+			&i.QuerySchema,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointSpecs = `-- name: ListEndpointSpecs :many
+SELECT e.id, e.project_id, e.name, e.method, e.scope, e.function_id, e.created_at, p.name as project_name, f.name as function_name
+FROM endpoints e
+JOIN projects p ON e.project_id = p.id
+JOIN functions f ON e.function_id = f.id
+ORDER BY p.name ASC, e.name ASC
+`
+
+type ListEndpointSpecsRow struct {
+	ID           pgtype.UUID
+	ProjectID    pgtype.UUID
+	Name         string
+	Method       string
+	Scope        string
+	FunctionID   pgtype.UUID
+	CreatedAt    pgtype.Timestamptz
+	ProjectName  string
+	FunctionName string
+}
+
+func (q *Queries) ListEndpointSpecs(ctx context.Context) ([]ListEndpointSpecsRow, error) {
+	rows, err := q.db.Query(ctx, listEndpointSpecs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEndpointSpecsRow
+	for rows.Next() {
+		var i ListEndpointSpecsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Method,
+			&i.Scope,
+			&i.FunctionID,
+			&i.CreatedAt,
+			&i.ProjectName,
+			&i.FunctionName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const listEndpointsForProject = `-- name: ListEndpointsForProject :many
@@ -198,6 +1134,68 @@ func (q *Queries) ListEndpointsSearch(ctx context.Context, arg ListEndpointsSear
 	return items, nil
 }
 
+const listEndpointTransforms = `-- name: ListEndpointTransforms :many
+SELECT endpoint_id, request_rules, response_rules, updated_at
+FROM endpoint_transforms
+`
+
+func (q *Queries) ListEndpointTransforms(ctx context.Context) ([]EndpointTransform, error) {
+	rows, err := q.db.Query(ctx, listEndpointTransforms)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointTransform
+	for rows.Next() {
+		var i EndpointTransform
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.RequestRules,
+			&i.ResponseRules,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndpointTransformsForProject = `-- name: ListEndpointTransformsForProject :many
+SELECT c.endpoint_id, c.request_rules, c.response_rules, c.updated_at
+FROM endpoint_transforms c
+JOIN endpoints e ON c.endpoint_id = e.id
+WHERE e.project_id = $1
+`
+
+func (q *Queries) ListEndpointTransformsForProject(ctx context.Context, projectID pgtype.UUID) ([]EndpointTransform, error) {
+	rows, err := q.db.Query(ctx, listEndpointTransformsForProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndpointTransform
+	for rows.Next() {
+		var i EndpointTransform
+		if err := rows.Scan(
+			&i.EndpointID,
+			&i.RequestRules,
+			&i.ResponseRules,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateEndpoint = `-- name: UpdateEndpoint :one
 UPDATE endpoints
 SET name = $2, method = $3, scope = $4, function_id = $5
@@ -261,3 +1259,360 @@ func (q *Queries) UpdateEndpointMethodScope(ctx context.Context, arg UpdateEndpo
 	)
 	return i, err
 }
+
+const upsertEndpointCacheConfig = `-- name: UpsertEndpointCacheConfig :one
+INSERT INTO endpoint_cache_configs (endpoint_id, ttl_seconds, vary_headers, vary_query)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET ttl_seconds = EXCLUDED.ttl_seconds,
+    vary_headers = EXCLUDED.vary_headers,
+    vary_query = EXCLUDED.vary_query,
+    updated_at = now()
+RETURNING endpoint_id, ttl_seconds, vary_headers, vary_query, updated_at
+`
+
+type UpsertEndpointCacheConfigParams struct {
+	EndpointID  pgtype.UUID
+	TtlSeconds  int32
+	VaryHeaders []string
+	VaryQuery   []string
+}
+
+func (q *Queries) UpsertEndpointCacheConfig(ctx context.Context, arg UpsertEndpointCacheConfigParams) (EndpointCacheConfig, error) {
+	row := q.db.QueryRow(ctx, upsertEndpointCacheConfig,
+		arg.EndpointID,
+		arg.TtlSeconds,
+		arg.VaryHeaders,
+		arg.VaryQuery,
+	)
+	var i EndpointCacheConfig
+	err := row.Scan(
+		&i.EndpointID,
+		&i.TtlSeconds,
+		&i.VaryHeaders,
+		&i.VaryQuery,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertEndpointCallback = `-- name: UpsertEndpointCallback :one
+INSERT INTO endpoint_callbacks (endpoint_id, url, secret, allow_caller_url)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET url = EXCLUDED.url,
+    secret = EXCLUDED.secret,
+    allow_caller_url = EXCLUDED.allow_caller_url,
+    updated_at = now()
+RETURNING endpoint_id, url, secret, allow_caller_url, updated_at
+`
+
+type UpsertEndpointCallbackParams struct {
+	EndpointID     pgtype.UUID
+	Url            string
+	Secret         string
+	AllowCallerUrl bool
+}
+
+func (q *Queries) UpsertEndpointCallback(ctx context.Context, arg UpsertEndpointCallbackParams) (EndpointCallback, error) {
+	row := q.db.QueryRow(ctx, upsertEndpointCallback,
+		arg.EndpointID,
+		arg.Url,
+		arg.Secret,
+		arg.AllowCallerUrl,
+	)
+	var i EndpointCallback
+	err := row.Scan(
+		&i.EndpointID,
+		&i.Url,
+		&i.Secret,
+		&i.AllowCallerUrl,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertEndpointCorsConfig = `-- name: UpsertEndpointCorsConfig :one
+INSERT INTO endpoint_cors_configs (endpoint_id, allowed_origins, allowed_methods, allowed_headers, exposed_headers, allow_credentials, max_age)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET allowed_origins = EXCLUDED.allowed_origins,
+    allowed_methods = EXCLUDED.allowed_methods,
+    allowed_headers = EXCLUDED.allowed_headers,
+    exposed_headers = EXCLUDED.exposed_headers,
+    allow_credentials = EXCLUDED.allow_credentials,
+    max_age = EXCLUDED.max_age,
+    updated_at = now()
+RETURNING endpoint_id, allowed_origins, allowed_methods, allowed_headers, exposed_headers, allow_credentials, max_age, updated_at
+`
+
+type UpsertEndpointCorsConfigParams struct {
+	EndpointID       pgtype.UUID
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           int32
+}
+
+func (q *Queries) UpsertEndpointCorsConfig(ctx context.Context, arg UpsertEndpointCorsConfigParams) (EndpointCorsConfig, error) {
+	row := q.db.QueryRow(ctx, upsertEndpointCorsConfig,
+		arg.EndpointID,
+		arg.AllowedOrigins,
+		arg.AllowedMethods,
+		arg.AllowedHeaders,
+		arg.ExposedHeaders,
+		arg.AllowCredentials,
+		arg.MaxAge,
+	)
+	var i EndpointCorsConfig
+	err := row.Scan(
+		&i.EndpointID,
+		&i.AllowedOrigins,
+		&i.AllowedMethods,
+		&i.AllowedHeaders,
+		&i.ExposedHeaders,
+		&i.AllowCredentials,
+		&i.MaxAge,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertEndpointFault = `-- name: UpsertEndpointFault :one
+INSERT INTO endpoint_faults (endpoint_id, percentage, delay_ms, error_status, drop_async, header, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET percentage = EXCLUDED.percentage,
+    delay_ms = EXCLUDED.delay_ms,
+    error_status = EXCLUDED.error_status,
+    drop_async = EXCLUDED.drop_async,
+    header = EXCLUDED.header,
+    expires_at = EXCLUDED.expires_at,
+    updated_at = now()
+RETURNING endpoint_id, percentage, delay_ms, error_status, drop_async, header, expires_at, updated_at
+`
+
+type UpsertEndpointFaultParams struct {
+	EndpointID  pgtype.UUID
+	Percentage  float64
+	DelayMs     int32
+	ErrorStatus int32
+	DropAsync   bool
+	Header      string
+	ExpiresAt   pgtype.Timestamptz
+}
+
+func (q *Queries) UpsertEndpointFault(ctx context.Context, arg UpsertEndpointFaultParams) (EndpointFault, error) {
+	row := q.db.QueryRow(ctx, upsertEndpointFault,
+		arg.EndpointID,
+		arg.Percentage,
+		arg.DelayMs,
+		arg.ErrorStatus,
+		arg.DropAsync,
+		arg.Header,
+		arg.ExpiresAt,
+	)
+	var i EndpointFault
+	err := row.Scan(
+		&i.EndpointID,
+		&i.Percentage,
+		&i.DelayMs,
+		&i.ErrorStatus,
+		&i.DropAsync,
+		&i.Header,
+		&i.ExpiresAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertEndpointJwtConfig = `-- name: UpsertEndpointJwtConfig :one
+INSERT INTO endpoint_jwt_configs (endpoint_id, jwks_url, issuer, audiences, required_claims)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET jwks_url = EXCLUDED.jwks_url,
+    issuer = EXCLUDED.issuer,
+    audiences = EXCLUDED.audiences,
+    required_claims = EXCLUDED.required_claims,
+    updated_at = now()
+RETURNING endpoint_id, jwks_url, issuer, audiences, required_claims, updated_at
+`
+
+type UpsertEndpointJwtConfigParams struct {
+	EndpointID     pgtype.UUID
+	JwksUrl        string
+	Issuer         string
+	Audiences      []string
+	RequiredClaims []byte
+}
+
+func (q *Queries) UpsertEndpointJwtConfig(ctx context.Context, arg UpsertEndpointJwtConfigParams) (EndpointJwtConfig, error) {
+	row := q.db.QueryRow(ctx, upsertEndpointJwtConfig,
+		arg.EndpointID,
+		arg.JwksUrl,
+		arg.Issuer,
+		arg.Audiences,
+		arg.RequiredClaims,
+	)
+	var i EndpointJwtConfig
+	err := row.Scan(
+		&i.EndpointID,
+		&i.JwksUrl,
+		&i.Issuer,
+		&i.Audiences,
+		&i.RequiredClaims,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertEndpointMiddleware = `-- name: UpsertEndpointMiddleware :one
+INSERT INTO endpoint_middleware (endpoint_id, chain)
+VALUES ($1, $2)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET chain = EXCLUDED.chain,
+    updated_at = now()
+RETURNING endpoint_id, chain, updated_at
+`
+
+type UpsertEndpointMiddlewareParams struct {
+	EndpointID pgtype.UUID
+	Chain      []byte
+}
+
+func (q *Queries) UpsertEndpointMiddleware(ctx context.Context, arg UpsertEndpointMiddlewareParams) (EndpointMiddleware, error) {
+	row := q.db.QueryRow(ctx, upsertEndpointMiddleware,
+		arg.EndpointID,
+		arg.Chain,
+	)
+	var i EndpointMiddleware
+	err := row.Scan(
+		&i.EndpointID,
+		&i.Chain,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertEndpointPolicy = `-- name: UpsertEndpointPolicy :one
+INSERT INTO endpoint_policies (endpoint_id, mode, default_effect, rules)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET mode = EXCLUDED.mode,
+    default_effect = EXCLUDED.default_effect,
+    rules = EXCLUDED.rules,
+    updated_at = now()
+RETURNING endpoint_id, mode, default_effect, rules, updated_at
+`
+
+type UpsertEndpointPolicyParams struct {
+	EndpointID    pgtype.UUID
+	Mode          string
+	DefaultEffect string
+	Rules         []byte
+}
+
+func (q *Queries) UpsertEndpointPolicy(ctx context.Context, arg UpsertEndpointPolicyParams) (EndpointPolicy, error) {
+	row := q.db.QueryRow(ctx, upsertEndpointPolicy,
+		arg.EndpointID,
+		arg.Mode,
+		arg.DefaultEffect,
+		arg.Rules,
+	)
+	var i EndpointPolicy
+	err := row.Scan(
+		&i.EndpointID,
+		&i.Mode,
+		&i.DefaultEffect,
+		&i.Rules,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertEndpointPriority = `-- name: UpsertEndpointPriority :one
+INSERT INTO endpoint_priorities (endpoint_id, priority)
+VALUES ($1, $2)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET priority = EXCLUDED.priority,
+    updated_at = now()
+RETURNING endpoint_id, priority, updated_at
+`
+
+type UpsertEndpointPriorityParams struct {
+	EndpointID pgtype.UUID
+	Priority   string
+}
+
+func (q *Queries) UpsertEndpointPriority(ctx context.Context, arg UpsertEndpointPriorityParams) (EndpointPriority, error) {
+	row := q.db.QueryRow(ctx, upsertEndpointPriority, arg.EndpointID, arg.Priority)
+	var i EndpointPriority
+	err := row.Scan(&i.EndpointID, &i.Priority, &i.UpdatedAt)
+	return i, err
+}
+
+const upsertEndpointSchema = `-- name: UpsertEndpointSchema :one
+INSERT INTO endpoint_schemas (endpoint_id, body_schema, query_schema)
+VALUES ($1, $2, $3)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET body_schema = EXCLUDED.body_schema,
+    query_schema = EXCLUDED.query_schema,
+    updated_at = now()
+RETURNING endpoint_id, body_schema, query_schema, updated_at
+`
+
+type UpsertEndpointSchemaParams struct {
+	EndpointID  pgtype.UUID
+	BodySchema  []byte
+	QuerySchema []byte
+}
+
+func (q *Queries) UpsertEndpointSchema(ctx context.Context, arg UpsertEndpointSchemaParams) (EndpointSchema, error) {
+	row := q.db.QueryRow(ctx, upsertEndpointSchema,
+		arg.EndpointID,
+		arg.BodySchema,
+		arg.QuerySchema,
+	)
+	var i EndpointSchema
+	err := row.Scan(
+		&i.EndpointID,
+		&i.BodySchema,
+		&i.QuerySchema,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertEndpointTransform = `-- name: UpsertEndpointTransform :one
+INSERT INTO endpoint_transforms (endpoint_id, request_rules, response_rules)
+VALUES ($1, $2, $3)
+ON CONFLICT (endpoint_id) DO UPDATE
+SET request_rules = EXCLUDED.request_rules,
+    response_rules = EXCLUDED.response_rules,
+    updated_at = now()
+RETURNING endpoint_id, request_rules, response_rules, updated_at
+`
+
+type UpsertEndpointTransformParams struct {
+	EndpointID    pgtype.UUID
+	RequestRules  []byte
+	ResponseRules []byte
+}
+
+func (q *Queries) UpsertEndpointTransform(ctx context.Context, arg UpsertEndpointTransformParams) (EndpointTransform, error) {
+	row := q.db.QueryRow(ctx, upsertEndpointTransform,
+		arg.EndpointID,
+		arg.RequestRules,
+		arg.ResponseRules,
+	)
+	var i EndpointTransform
+	err := row.Scan(
+		&i.EndpointID,
+		&i.RequestRules,
+		&i.ResponseRules,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
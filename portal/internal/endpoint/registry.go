@@ -0,0 +1,436 @@
+package endpoint
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/portal/internal/endpoint/adaptors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Registry mirrors endpoint configuration into the KV bucket ingestors watch.
+type Registry struct {
+	js   jetstream.JetStream
+	kv   jetstream.KeyValue
+	pool *pgxpool.Pool
+}
+
+func NewRegistry(ctx context.Context, js jetstream.JetStream, pool *pgxpool.Pool) (*Registry, error) {
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      gateway.EndpointsBucket,
+		Description: "litefunctions endpoint configuration",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating endpoints bucket: %w", err)
+	}
+	return &Registry{js: js, kv: kv, pool: pool}, nil
+}
+
+// Publish loads the endpoint and pushes its current configuration.
+func (r *Registry) Publish(ctx context.Context, id pgtype.UUID) error {
+	q := adaptors.New(r.pool)
+	row, err := q.GetEndpointSpec(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error loading endpoint: %w", err)
+	}
+	spec := r.spec(row)
+	if spec.Scope == gateway.ScopeJWT {
+		cfg, err := q.GetEndpointJwtConfig(ctx, id)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("error loading endpoint jwt config: %w", err)
+		}
+		if err == nil {
+			spec.JWT = jwtConfig(cfg)
+		}
+	}
+	pol, err := q.GetEndpointPolicy(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("error loading endpoint policy: %w", err)
+	}
+	if err == nil {
+		spec.Policy = policySpec(pol)
+	}
+	cors, err := q.GetEndpointCorsConfig(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("error loading endpoint cors config: %w", err)
+	}
+	if err == nil {
+		spec.CORS = corsConfig(cors)
+	}
+	cache, err := q.GetEndpointCacheConfig(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("error loading endpoint cache config: %w", err)
+	}
+	if err == nil {
+		spec.Cache = cacheConfig(cache)
+	}
+	sch, err := q.GetEndpointSchema(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("error loading endpoint schema: %w", err)
+	}
+	if err == nil {
+		spec.Schema = schemaConfig(sch)
+	}
+	cb, err := q.GetEndpointCallback(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("error loading endpoint callback: %w", err)
+	}
+	if err == nil {
+		spec.Callback = callbackConfig(cb)
+	}
+	fault, err := q.GetEndpointFault(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("error loading endpoint fault: %w", err)
+	}
+	if err == nil {
+		spec.Fault = faultConfig(fault)
+	}
+	tr, err := q.GetEndpointTransform(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("error loading endpoint transform: %w", err)
+	}
+	if err == nil {
+		spec.Transform = transformConfig(tr)
+	}
+	prio, err := q.GetEndpointPriority(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("error loading endpoint priority: %w", err)
+	}
+	if err == nil {
+		spec.Priority = prio.Priority
+	}
+	mw, err := q.GetEndpointMiddleware(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("error loading endpoint middleware: %w", err)
+	}
+	if err == nil {
+		spec.Middleware = middlewareChain(mw)
+	}
+	return r.put(ctx, spec)
+}
+
+func (r *Registry) Delete(ctx context.Context, project, function, method string) error {
+	err := r.kv.Delete(ctx, gateway.EndpointKey(project, function, method))
+	if err != nil && !errors.Is(err, jetstream.ErrKeyNotFound) {
+		return fmt.Errorf("error removing endpoint: %w", err)
+	}
+	return nil
+}
+
+// Resync publishes every endpoint and drops keys that no longer exist, so
+// ingestors converge even if an earlier publish was missed.
+func (r *Registry) Resync(ctx context.Context) error {
+	q := adaptors.New(r.pool)
+	rows, err := q.ListEndpointSpecs(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing endpoints: %w", err)
+	}
+	jwtConfigs, err := q.ListEndpointJwtConfigs(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing endpoint jwt configs: %w", err)
+	}
+	jwtByEndpoint := make(map[pgtype.UUID]adaptors.EndpointJwtConfig, len(jwtConfigs))
+	for _, cfg := range jwtConfigs {
+		jwtByEndpoint[cfg.EndpointID] = cfg
+	}
+	policies, err := q.ListEndpointPolicies(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing endpoint policies: %w", err)
+	}
+	policyByEndpoint := make(map[pgtype.UUID]adaptors.EndpointPolicy, len(policies))
+	for _, pol := range policies {
+		policyByEndpoint[pol.EndpointID] = pol
+	}
+	corsConfigs, err := q.ListEndpointCorsConfigs(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing endpoint cors configs: %w", err)
+	}
+	corsByEndpoint := make(map[pgtype.UUID]adaptors.EndpointCorsConfig, len(corsConfigs))
+	for _, cfg := range corsConfigs {
+		corsByEndpoint[cfg.EndpointID] = cfg
+	}
+	cacheConfigs, err := q.ListEndpointCacheConfigs(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing endpoint cache configs: %w", err)
+	}
+	cacheByEndpoint := make(map[pgtype.UUID]adaptors.EndpointCacheConfig, len(cacheConfigs))
+	for _, cfg := range cacheConfigs {
+		cacheByEndpoint[cfg.EndpointID] = cfg
+	}
+	schemas, err := q.ListEndpointSchemas(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing endpoint schemas: %w", err)
+	}
+	schemaByEndpoint := make(map[pgtype.UUID]adaptors.EndpointSchema, len(schemas))
+	for _, sch := range schemas {
+		schemaByEndpoint[sch.EndpointID] = sch
+	}
+	callbacks, err := q.ListEndpointCallbacks(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing endpoint callbacks: %w", err)
+	}
+	callbackByEndpoint := make(map[pgtype.UUID]adaptors.EndpointCallback, len(callbacks))
+	for _, cb := range callbacks {
+		callbackByEndpoint[cb.EndpointID] = cb
+	}
+	faults, err := q.ListEndpointFaults(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing endpoint faults: %w", err)
+	}
+	faultByEndpoint := make(map[pgtype.UUID]adaptors.EndpointFault, len(faults))
+	for _, f := range faults {
+		faultByEndpoint[f.EndpointID] = f
+	}
+	transforms, err := q.ListEndpointTransforms(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing endpoint transforms: %w", err)
+	}
+	transformByEndpoint := make(map[pgtype.UUID]adaptors.EndpointTransform, len(transforms))
+	for _, tr := range transforms {
+		transformByEndpoint[tr.EndpointID] = tr
+	}
+	priorities, err := q.ListEndpointPriorities(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing endpoint priorities: %w", err)
+	}
+	priorityByEndpoint := make(map[pgtype.UUID]string, len(priorities))
+	for _, p := range priorities {
+		priorityByEndpoint[p.EndpointID] = p.Priority
+	}
+	middleware, err := q.ListEndpointMiddleware(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing endpoint middleware: %w", err)
+	}
+	middlewareByEndpoint := make(map[pgtype.UUID]adaptors.EndpointMiddleware, len(middleware))
+	for _, mw := range middleware {
+		middlewareByEndpoint[mw.EndpointID] = mw
+	}
+
+	live := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		spec := r.spec(adaptors.GetEndpointSpecRow(row))
+		if cfg, ok := jwtByEndpoint[row.ID]; ok && spec.Scope == gateway.ScopeJWT {
+			spec.JWT = jwtConfig(cfg)
+		}
+		if pol, ok := policyByEndpoint[row.ID]; ok {
+			spec.Policy = policySpec(pol)
+		}
+		if cfg, ok := corsByEndpoint[row.ID]; ok {
+			spec.CORS = corsConfig(cfg)
+		}
+		if cfg, ok := cacheByEndpoint[row.ID]; ok {
+			spec.Cache = cacheConfig(cfg)
+		}
+		if sch, ok := schemaByEndpoint[row.ID]; ok {
+			spec.Schema = schemaConfig(sch)
+		}
+		if cb, ok := callbackByEndpoint[row.ID]; ok {
+			spec.Callback = callbackConfig(cb)
+		}
+		if f, ok := faultByEndpoint[row.ID]; ok {
+			spec.Fault = faultConfig(f)
+		}
+		if tr, ok := transformByEndpoint[row.ID]; ok {
+			spec.Transform = transformConfig(tr)
+		}
+		spec.Priority = priorityByEndpoint[row.ID]
+		if mw, ok := middlewareByEndpoint[row.ID]; ok {
+			spec.Middleware = middlewareChain(mw)
+		}
+		live[gateway.EndpointKey(spec.Project, spec.Function, spec.Method)] = true
+		if err := r.put(ctx, spec); err != nil {
+			return err
+		}
+	}
+
+	lister, err := r.kv.ListKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing endpoint keys: %w", err)
+	}
+	for key := range lister.Keys() {
+		if live[key] {
+			continue
+		}
+		if err := r.kv.Delete(ctx, key); err != nil {
+			slog.Warn("failed to drop stale endpoint", "key", key, "error", err)
+		}
+	}
+	slog.Info("endpoints resynced", "count", len(rows))
+	return nil
+}
+
+func (r *Registry) spec(row adaptors.GetEndpointSpecRow) gateway.Endpoint {
+	return gateway.Endpoint{
+		ID:       hex.EncodeToString(row.ID.Bytes[:]),
+		Project:  row.ProjectName,
+		Function: row.FunctionName,
+		Name:     row.Name,
+		Method:   row.Method,
+		Scope:    row.Scope,
+	}
+}
+
+// jwtConfig converts the stored settings into what the ingestor verifies
+// against. A malformed claims document is dropped rather than failing the
+// publish; the portal validates it on save.
+func jwtConfig(cfg adaptors.EndpointJwtConfig) *gateway.JWTConfig {
+	out := &gateway.JWTConfig{
+		JwksURL:   cfg.JwksUrl,
+		Issuer:    cfg.Issuer,
+		Audiences: cfg.Audiences,
+	}
+	if len(cfg.RequiredClaims) > 0 {
+		if err := json.Unmarshal(cfg.RequiredClaims, &out.RequiredClaims); err != nil {
+			slog.Warn("ignoring malformed required claims", "endpoint", hex.EncodeToString(cfg.EndpointID.Bytes[:]), "error", err)
+		}
+	}
+	return out
+}
+
+func policySpec(pol adaptors.EndpointPolicy) *gateway.Policy {
+	out := &gateway.Policy{Mode: pol.Mode, Default: pol.DefaultEffect}
+	if err := json.Unmarshal(pol.Rules, &out.Rules); err != nil {
+		slog.Warn("ignoring malformed policy rules", "endpoint", hex.EncodeToString(pol.EndpointID.Bytes[:]), "error", err)
+	}
+	return out
+}
+
+func corsConfig(cfg adaptors.EndpointCorsConfig) *gateway.CORSConfig {
+	return &gateway.CORSConfig{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   cfg.AllowedMethods,
+		AllowedHeaders:   cfg.AllowedHeaders,
+		ExposedHeaders:   cfg.ExposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           int(cfg.MaxAge),
+	}
+}
+
+func cacheConfig(cfg adaptors.EndpointCacheConfig) *gateway.CacheConfig {
+	return &gateway.CacheConfig{
+		TTL:         int(cfg.TtlSeconds),
+		VaryHeaders: cfg.VaryHeaders,
+		VaryQuery:   cfg.VaryQuery,
+	}
+}
+
+func schemaConfig(sch adaptors.EndpointSchema) *gateway.SchemaConfig {
+	return &gateway.SchemaConfig{Body: sch.BodySchema, Query: sch.QuerySchema}
+}
+
+func callbackConfig(cb adaptors.EndpointCallback) *gateway.CallbackConfig {
+	return &gateway.CallbackConfig{URL: cb.Url, Secret: cb.Secret, AllowCallerURL: cb.AllowCallerUrl}
+}
+
+func faultConfig(f adaptors.EndpointFault) *gateway.FaultConfig {
+	return &gateway.FaultConfig{
+		Percentage:  f.Percentage,
+		DelayMs:     int(f.DelayMs),
+		ErrorStatus: int(f.ErrorStatus),
+		DropAsync:   f.DropAsync,
+		Header:      f.Header,
+		ExpiresAt:   f.ExpiresAt.Time,
+	}
+}
+
+// transformConfig decodes the stored rules, which were validated when saved.
+func transformConfig(tr adaptors.EndpointTransform) *gateway.TransformConfig {
+	cfg := &gateway.TransformConfig{}
+	if len(tr.RequestRules) > 0 {
+		if err := json.Unmarshal(tr.RequestRules, &cfg.Request); err != nil {
+			slog.Error("ignoring invalid request transform", "endpoint", tr.EndpointID, "error", err)
+		}
+	}
+	if len(tr.ResponseRules) > 0 {
+		if err := json.Unmarshal(tr.ResponseRules, &cfg.Response); err != nil {
+			slog.Error("ignoring invalid response transform", "endpoint", tr.EndpointID, "error", err)
+		}
+	}
+	return cfg
+}
+
+func middlewareChain(mw adaptors.EndpointMiddleware) []gateway.MiddlewareConfig {
+	var chain []gateway.MiddlewareConfig
+	if err := json.Unmarshal(mw.Chain, &chain); err != nil {
+		slog.Error("ignoring invalid endpoint middleware", "endpoint", mw.EndpointID, "error", err)
+	}
+	return chain
+}
+
+// CallbackDeliveries returns the delivery records the ingestors kept for a
+// function, most recent first.
+func (r *Registry) CallbackDeliveries(ctx context.Context, project, function string, limit int) ([]gateway.CallbackDelivery, error) {
+	kv, err := r.js.KeyValue(ctx, gateway.CallbacksBucket)
+	if errors.Is(err, jetstream.ErrBucketNotFound) {
+		return []gateway.CallbackDelivery{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening callbacks bucket: %w", err)
+	}
+	lister, err := kv.ListKeysFiltered(ctx, gateway.CallbackKeyPrefix(project, function)+">")
+	if err != nil {
+		return nil, fmt.Errorf("error listing callback deliveries: %w", err)
+	}
+	out := []gateway.CallbackDelivery{}
+	for key := range lister.Keys() {
+		entry, err := kv.Get(ctx, key)
+		if err != nil {
+			continue
+		}
+		var d gateway.CallbackDelivery
+		if err := json.Unmarshal(entry.Value(), &d); err == nil {
+			out = append(out, d)
+		}
+	}
+	slices.SortFunc(out, func(a, b gateway.CallbackDelivery) int {
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// PurgeCache drops every cached response of a function from the bucket the
+// ingestors share. It returns the number of entries removed.
+func (r *Registry) PurgeCache(ctx context.Context, project, function string) (int, error) {
+	kv, err := r.js.KeyValue(ctx, gateway.CacheBucket)
+	if errors.Is(err, jetstream.ErrBucketNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error opening response cache: %w", err)
+	}
+	lister, err := kv.ListKeysFiltered(ctx, gateway.CacheKeyPrefix(project, function)+">")
+	if err != nil {
+		return 0, fmt.Errorf("error listing cached responses: %w", err)
+	}
+	purged := 0
+	for key := range lister.Keys() {
+		if err := kv.Purge(ctx, key); err != nil {
+			return purged, fmt.Errorf("error purging cached response: %w", err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (r *Registry) put(ctx context.Context, spec gateway.Endpoint) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	if _, err := r.kv.Put(ctx, gateway.EndpointKey(spec.Project, spec.Function, spec.Method), data); err != nil {
+		return fmt.Errorf("error publishing endpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+-- name: RecordInvocation :execrows
+INSERT INTO invocations (
+    project_id, request_id, function_name, endpoint, method, source, status,
+    latency_ms, cold_start, async, request_bytes, response_bytes, error, started_at
+)
+SELECT p.id, @request_id, @function_name, @endpoint, @method, @source, @status,
+    @latency_ms, @cold_start, @async, @request_bytes, @response_bytes, @error, @started_at
+FROM projects p
+WHERE p.name = @project_name
+ON CONFLICT (project_id, request_id) DO NOTHING;
+
+-- name: ListInvocations :many
+SELECT * FROM invocations
+WHERE project_id = @project_id
+  AND function_name = @function_name
+  AND started_at >= @since
+  AND (sqlc.narg('before')::timestamptz IS NULL OR started_at < sqlc.narg('before')::timestamptz)
+  AND (sqlc.narg('status_class')::int IS NULL OR status / 100 = sqlc.narg('status_class')::int)
+  AND (sqlc.narg('source')::text IS NULL OR source = sqlc.narg('source')::text)
+  AND (sqlc.narg('cold_start')::boolean IS NULL OR cold_start = sqlc.narg('cold_start')::boolean)
+  AND (@search::text = '' OR request_id = @search::text OR error ILIKE '%' || @search::text || '%')
+ORDER BY started_at DESC
+LIMIT @row_limit;
+
+-- name: SummarizeInvocations :one
+SELECT
+    count(*)::bigint AS total,
+    count(*) FILTER (WHERE status >= 500)::bigint AS server_errors,
+    count(*) FILTER (WHERE status >= 400 AND status < 500)::bigint AS client_errors,
+    count(*) FILTER (WHERE cold_start)::bigint AS cold_starts,
+    coalesce(avg(latency_ms), 0)::bigint AS avg_latency_ms,
+    coalesce(percentile_cont(0.95) WITHIN GROUP (ORDER BY latency_ms), 0)::bigint AS p95_latency_ms
+FROM invocations
+WHERE project_id = @project_id
+  AND function_name = @function_name
+  AND started_at >= @since;
+
+-- name: PruneInvocations :execrows
+DELETE FROM invocations
+WHERE started_at < $1;
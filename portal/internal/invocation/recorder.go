@@ -0,0 +1,108 @@
+package invocation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/portal/internal/invocation/adaptors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// streamMaxAge bounds how long records wait in the stream for a portal to
+// store them, e.g. across a portal outage.
+const streamMaxAge = 24 * time.Hour
+
+// Recorder stores the invocation records ingestors publish. Portal replicas
+// share one durable consumer, so each record is stored once.
+type Recorder struct {
+	stream    jetstream.Stream
+	pool      *pgxpool.Pool
+	retention time.Duration
+}
+
+func NewRecorder(ctx context.Context, js jetstream.JetStream, pool *pgxpool.Pool, retention time.Duration) (*Recorder, error) {
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:        gateway.InvocationsStream,
+		Description: "litefunctions invocation records",
+		Subjects:    []string{gateway.InvocationsSubject + ".>"},
+		MaxAge:      streamMaxAge,
+		Discard:     jetstream.DiscardOld,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating invocations stream: %w", err)
+	}
+	return &Recorder{stream: stream, pool: pool, retention: retention}, nil
+}
+
+// Consume stores records until the returned context is stopped. Records the
+// database rejects are redelivered.
+func (r *Recorder) Consume(ctx context.Context) (jetstream.ConsumeContext, error) {
+	cons, err := r.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:   "litefunctions-portal",
+		AckPolicy: jetstream.AckExplicitPolicy,
+		AckWait:   30 * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating invocations consumer: %w", err)
+	}
+	q := adaptors.New(r.pool)
+	return cons.Consume(func(msg jetstream.Msg) {
+		var rec gateway.Invocation
+		if err := json.Unmarshal(msg.Data(), &rec); err != nil {
+			slog.Warn("invalid invocation record", "subject", msg.Subject(), "error", err)
+			_ = msg.Term()
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := q.RecordInvocation(ctx, adaptors.RecordInvocationParams{
+			RequestID:     rec.RequestID,
+			FunctionName:  rec.Function,
+			Endpoint:      rec.Endpoint,
+			Method:        rec.Method,
+			Source:        rec.Source,
+			Status:        int32(rec.Status),
+			LatencyMs:     rec.LatencyMs,
+			ColdStart:     rec.ColdStart,
+			Async:         rec.Async,
+			RequestBytes:  rec.RequestBytes,
+			ResponseBytes: rec.ResponseBytes,
+			Error:         rec.Error,
+			StartedAt:     pgtype.Timestamptz{Time: rec.StartedAt, Valid: true},
+			ProjectName:   rec.Project,
+		}); err != nil {
+			slog.Error("failed to store invocation", "project", rec.Project, "name", rec.Function, "error", err)
+			_ = msg.NakWithDelay(5 * time.Second)
+			return
+		}
+		_ = msg.Ack()
+	})
+}
+
+// Prune deletes records older than the retention every interval until ctx
+// is done. Deletes are idempotent, so every replica may run it.
+func (r *Recorder) Prune(ctx context.Context, interval time.Duration) {
+	q := adaptors.New(r.pool)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		cutoff := time.Now().Add(-r.retention)
+		removed, err := q.PruneInvocations(ctx, pgtype.Timestamptz{Time: cutoff, Valid: true})
+		if err != nil {
+			slog.Error("failed to prune invocations", "error", err)
+		} else if removed > 0 {
+			slog.Info("pruned invocations", "count", removed, "before", cutoff)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,22 @@
+-- name: CreateMaintenanceMode :one
+INSERT INTO maintenance_modes (project_id, function_id, message, enabled_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: DeleteMaintenanceMode :execrows
+DELETE FROM maintenance_modes
+WHERE project_id = $1
+  AND function_id IS NOT DISTINCT FROM $2;
+
+-- name: ListMaintenanceModesForProject :many
+SELECT m.*, f.name as function_name
+FROM maintenance_modes m
+LEFT JOIN functions f ON m.function_id = f.id
+WHERE m.project_id = $1
+ORDER BY m.enabled_at DESC;
+
+-- name: ListMaintenanceModes :many
+SELECT m.*, p.name as project_name, f.name as function_name
+FROM maintenance_modes m
+JOIN projects p ON m.project_id = p.id
+LEFT JOIN functions f ON m.function_id = f.id;
@@ -0,0 +1,88 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/portal/internal/maintenance/adaptors"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Registry mirrors maintenance flags into the KV bucket ingestors check
+// before activating a function.
+type Registry struct {
+	kv   jetstream.KeyValue
+	pool *pgxpool.Pool
+}
+
+func NewRegistry(ctx context.Context, js jetstream.JetStream, pool *pgxpool.Pool) (*Registry, error) {
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      gateway.MaintenanceBucket,
+		Description: "litefunctions maintenance flags",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating maintenance bucket: %w", err)
+	}
+	return &Registry{kv: kv, pool: pool}, nil
+}
+
+func (r *Registry) Publish(ctx context.Context, spec gateway.Maintenance) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	if _, err := r.kv.Put(ctx, gateway.MaintenanceKey(spec.Project, spec.Function), data); err != nil {
+		return fmt.Errorf("error publishing maintenance flag: %w", err)
+	}
+	return nil
+}
+
+func (r *Registry) Delete(ctx context.Context, project, function string) error {
+	err := r.kv.Delete(ctx, gateway.MaintenanceKey(project, function))
+	if err != nil && !errors.Is(err, jetstream.ErrKeyNotFound) {
+		return fmt.Errorf("error removing maintenance flag: %w", err)
+	}
+	return nil
+}
+
+// Resync publishes every flag and drops keys that were cleared while the
+// portal was away.
+func (r *Registry) Resync(ctx context.Context) error {
+	rows, err := adaptors.New(r.pool).ListMaintenanceModes(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing maintenance modes: %w", err)
+	}
+	live := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		spec := gateway.Maintenance{
+			Project:  row.ProjectName,
+			Function: row.FunctionName.String,
+			Message:  row.Message,
+			Since:    row.EnabledAt.Time,
+		}
+		live[gateway.MaintenanceKey(spec.Project, spec.Function)] = true
+		if err := r.Publish(ctx, spec); err != nil {
+			return err
+		}
+	}
+
+	lister, err := r.kv.ListKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing maintenance keys: %w", err)
+	}
+	for key := range lister.Keys() {
+		if live[key] {
+			continue
+		}
+		if err := r.kv.Delete(ctx, key); err != nil {
+			slog.Warn("failed to drop stale maintenance flag", "key", key, "error", err)
+		}
+	}
+	slog.Info("maintenance flags resynced", "count", len(rows))
+	return nil
+}
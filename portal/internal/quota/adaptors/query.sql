@@ -0,0 +1,30 @@
+-- name: UpsertProjectQuota :one
+INSERT INTO project_quotas (project_id, daily_limit, monthly_limit, updated_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (project_id) WHERE api_key_id IS NULL
+DO UPDATE SET daily_limit = EXCLUDED.daily_limit,
+              monthly_limit = EXCLUDED.monthly_limit,
+              updated_by = EXCLUDED.updated_by,
+              updated_at = now()
+RETURNING *;
+
+-- name: UpsertApiKeyQuota :one
+INSERT INTO project_quotas (project_id, api_key_id, daily_limit, monthly_limit, updated_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (api_key_id) WHERE api_key_id IS NOT NULL
+DO UPDATE SET daily_limit = EXCLUDED.daily_limit,
+              monthly_limit = EXCLUDED.monthly_limit,
+              updated_by = EXCLUDED.updated_by,
+              updated_at = now()
+RETURNING *;
+
+-- name: ListQuotasForProject :many
+SELECT * FROM project_quotas
+WHERE project_id = $1;
+
+-- name: ListQuotas :many
+SELECT q.*, p.name as project_name
+FROM project_quotas q
+JOIN projects p ON q.project_id = p.id
+LEFT JOIN project_api_keys k ON q.api_key_id = k.id
+WHERE q.api_key_id IS NULL OR k.revoked_at IS NULL;
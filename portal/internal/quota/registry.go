@@ -0,0 +1,137 @@
+package quota
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/portal/internal/quota/adaptors"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Registry mirrors invocation quotas into the KV bucket ingestors enforce
+// them from, and reads back what ingestors counted against them.
+type Registry struct {
+	js   jetstream.JetStream
+	kv   jetstream.KeyValue
+	pool *pgxpool.Pool
+}
+
+func NewRegistry(ctx context.Context, js jetstream.JetStream, pool *pgxpool.Pool) (*Registry, error) {
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      gateway.QuotasBucket,
+		Description: "litefunctions usage quotas",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating quotas bucket: %w", err)
+	}
+	return &Registry{js: js, kv: kv, pool: pool}, nil
+}
+
+// Spec builds the quota published for a row; an api key's quota is keyed by
+// the same hex id ingestors see on authenticated requests.
+func Spec(project string, row adaptors.ProjectQuota) gateway.Quota {
+	spec := gateway.Quota{Project: project, Daily: row.DailyLimit, Monthly: row.MonthlyLimit}
+	if row.ApiKeyID.Valid {
+		spec.ApiKeyID = hex.EncodeToString(row.ApiKeyID.Bytes[:])
+	}
+	return spec
+}
+
+// Publish makes spec enforced, or lifts it when it has no limit left.
+func (r *Registry) Publish(ctx context.Context, spec gateway.Quota) error {
+	key := gateway.QuotaKey(spec.Project, spec.ApiKeyID)
+	if spec.Daily <= 0 && spec.Monthly <= 0 {
+		return r.Delete(ctx, key)
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	if _, err := r.kv.Put(ctx, key, data); err != nil {
+		return fmt.Errorf("error publishing quota: %w", err)
+	}
+	return nil
+}
+
+func (r *Registry) Delete(ctx context.Context, key string) error {
+	err := r.kv.Delete(ctx, key)
+	if err != nil && !errors.Is(err, jetstream.ErrKeyNotFound) {
+		return fmt.Errorf("error removing quota: %w", err)
+	}
+	return nil
+}
+
+// Resync publishes every quota and drops ones that were lifted, or whose api
+// key was revoked, while the portal was away.
+func (r *Registry) Resync(ctx context.Context) error {
+	rows, err := adaptors.New(r.pool).ListQuotas(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing quotas: %w", err)
+	}
+	live := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		spec := Spec(row.ProjectName, adaptors.ProjectQuota{
+			ApiKeyID:     row.ApiKeyID,
+			DailyLimit:   row.DailyLimit,
+			MonthlyLimit: row.MonthlyLimit,
+		})
+		if spec.Daily > 0 || spec.Monthly > 0 {
+			live[gateway.QuotaKey(spec.Project, spec.ApiKeyID)] = true
+		}
+		if err := r.Publish(ctx, spec); err != nil {
+			return err
+		}
+	}
+
+	lister, err := r.kv.ListKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing quota keys: %w", err)
+	}
+	for key := range lister.Keys() {
+		if live[key] {
+			continue
+		}
+		if err := r.kv.Delete(ctx, key); err != nil {
+			slog.Warn("failed to drop stale quota", "key", key, "error", err)
+		}
+	}
+	slog.Info("quotas resynced", "count", len(rows))
+	return nil
+}
+
+// Usage returns the invocations counted against key in the current day and
+// month. Nothing has been counted until an ingestor enforced a quota, so a
+// missing bucket or counter reads as zero.
+func (r *Registry) Usage(ctx context.Context, key string, now time.Time) (map[string]int64, error) {
+	usage := make(map[string]int64, len(gateway.QuotaPeriods))
+	kv, err := r.js.KeyValue(ctx, gateway.QuotaUsageBucket)
+	if errors.Is(err, jetstream.ErrBucketNotFound) {
+		return usage, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening quota usage bucket: %w", err)
+	}
+	for _, period := range gateway.QuotaPeriods {
+		entry, err := kv.Get(ctx, gateway.QuotaUsageKey(key, period, now))
+		if errors.Is(err, jetstream.ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading quota usage: %w", err)
+		}
+		count, err := strconv.ParseInt(string(entry.Value()), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding quota usage %s: %w", entry.Key(), err)
+		}
+		usage[period] = count
+	}
+	return usage, nil
+}
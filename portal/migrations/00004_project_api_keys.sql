@@ -0,0 +1,25 @@
+-- +goose Up
+
+-------------------------------------------------------------------------------
+-- PROJECT API KEYS (only the sha256 of the key is stored)
+-------------------------------------------------------------------------------
+CREATE TABLE project_api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,                      -- first chars of the key, for display
+    key_hash TEXT NOT NULL,
+    endpoint_ids UUID[] NOT NULL DEFAULT '{}', -- empty = every endpoint in the project
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_by BYTEA NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT uq_project_api_key_hash UNIQUE (key_hash)
+);
+
+CREATE INDEX idx_project_api_keys_project ON project_api_keys(project_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS project_api_keys;
@@ -0,0 +1,22 @@
+-- +goose Up
+
+ALTER TABLE endpoints DROP CONSTRAINT endpoints_scope_check;
+ALTER TABLE endpoints ADD CONSTRAINT endpoints_scope_check CHECK (scope IN ('public', 'authn', 'jwt'));
+
+-------------------------------------------------------------------------------
+-- ENDPOINT JWT CONFIGS (verification settings for scope = 'jwt')
+-------------------------------------------------------------------------------
+CREATE TABLE endpoint_jwt_configs (
+    endpoint_id UUID PRIMARY KEY REFERENCES endpoints(id) ON DELETE CASCADE,
+    jwks_url TEXT NOT NULL,
+    issuer TEXT NOT NULL DEFAULT '',
+    audiences TEXT[] NOT NULL DEFAULT '{}',         -- any match is accepted, empty = unchecked
+    required_claims JSONB NOT NULL DEFAULT '{}',    -- claim -> expected value, '' = present
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS endpoint_jwt_configs;
+UPDATE endpoints SET scope = 'authn' WHERE scope = 'jwt';
+ALTER TABLE endpoints DROP CONSTRAINT endpoints_scope_check;
+ALTER TABLE endpoints ADD CONSTRAINT endpoints_scope_check CHECK (scope IN ('public', 'authn'));
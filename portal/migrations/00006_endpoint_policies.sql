@@ -0,0 +1,15 @@
+-- +goose Up
+
+-------------------------------------------------------------------------------
+-- ENDPOINT POLICIES (ordered CEL rules, first match decides)
+-------------------------------------------------------------------------------
+CREATE TABLE endpoint_policies (
+    endpoint_id UUID PRIMARY KEY REFERENCES endpoints(id) ON DELETE CASCADE,
+    mode TEXT NOT NULL CHECK (mode IN ('enforce', 'audit')),
+    default_effect TEXT NOT NULL CHECK (default_effect IN ('allow', 'deny')),
+    rules JSONB NOT NULL DEFAULT '[]',   -- [{"name", "effect", "expr"}]
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS endpoint_policies;
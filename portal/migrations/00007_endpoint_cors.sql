@@ -0,0 +1,18 @@
+-- +goose Up
+
+-------------------------------------------------------------------------------
+-- ENDPOINT CORS CONFIGS (applied by the ingestor, incl. preflight)
+-------------------------------------------------------------------------------
+CREATE TABLE endpoint_cors_configs (
+    endpoint_id UUID PRIMARY KEY REFERENCES endpoints(id) ON DELETE CASCADE,
+    allowed_origins TEXT[] NOT NULL,
+    allowed_methods TEXT[] NOT NULL DEFAULT '{}',   -- empty = the endpoint's method
+    allowed_headers TEXT[] NOT NULL DEFAULT '{}',
+    exposed_headers TEXT[] NOT NULL DEFAULT '{}',
+    allow_credentials BOOLEAN NOT NULL DEFAULT false,
+    max_age INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS endpoint_cors_configs;
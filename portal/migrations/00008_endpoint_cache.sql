@@ -0,0 +1,15 @@
+-- +goose Up
+
+-------------------------------------------------------------------------------
+-- ENDPOINT CACHE CONFIGS (ingestor response cache for GET endpoints)
+-------------------------------------------------------------------------------
+CREATE TABLE endpoint_cache_configs (
+    endpoint_id UUID PRIMARY KEY REFERENCES endpoints(id) ON DELETE CASCADE,
+    ttl_seconds INTEGER NOT NULL CHECK (ttl_seconds > 0),
+    vary_headers TEXT[] NOT NULL DEFAULT '{}',
+    vary_query TEXT[] NOT NULL DEFAULT '{}',        -- empty = the whole query string
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS endpoint_cache_configs;
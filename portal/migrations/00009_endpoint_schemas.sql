@@ -0,0 +1,14 @@
+-- +goose Up
+
+-------------------------------------------------------------------------------
+-- ENDPOINT SCHEMAS (JSON Schemas the ingestor validates requests against)
+-------------------------------------------------------------------------------
+CREATE TABLE endpoint_schemas (
+    endpoint_id UUID PRIMARY KEY REFERENCES endpoints(id) ON DELETE CASCADE,
+    body_schema JSONB,
+    query_schema JSONB,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS endpoint_schemas;
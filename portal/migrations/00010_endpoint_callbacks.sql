@@ -0,0 +1,15 @@
+-- +goose Up
+
+-------------------------------------------------------------------------------
+-- ENDPOINT CALLBACKS (webhooks for async invocation results)
+-------------------------------------------------------------------------------
+CREATE TABLE endpoint_callbacks (
+    endpoint_id UUID PRIMARY KEY REFERENCES endpoints(id) ON DELETE CASCADE,
+    url TEXT NOT NULL DEFAULT '',                   -- empty = only caller supplied urls
+    secret TEXT NOT NULL,                           -- HMAC signing secret
+    allow_caller_url BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS endpoint_callbacks;
@@ -0,0 +1,35 @@
+-- +goose Up
+
+-------------------------------------------------------------------------------
+-- MAINTENANCE MODES (kill switch for a function, or a whole project)
+-------------------------------------------------------------------------------
+CREATE TABLE maintenance_modes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    function_id UUID REFERENCES functions(id) ON DELETE CASCADE,  -- NULL = every function in the project
+    message TEXT NOT NULL DEFAULT '',
+    enabled_by BYTEA REFERENCES users(id) ON DELETE SET NULL,
+    enabled_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX uq_maintenance_modes_project ON maintenance_modes(project_id) WHERE function_id IS NULL;
+CREATE UNIQUE INDEX uq_maintenance_modes_function ON maintenance_modes(function_id) WHERE function_id IS NOT NULL;
+
+-------------------------------------------------------------------------------
+-- AUDIT EVENTS
+-------------------------------------------------------------------------------
+CREATE TABLE audit_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    actor BYTEA REFERENCES users(id) ON DELETE SET NULL,
+    action TEXT NOT NULL,                      -- e.g. maintenance.enable
+    target TEXT NOT NULL,
+    detail JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_events_project_created ON audit_events(project_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS maintenance_modes;
@@ -0,0 +1,18 @@
+-- +goose Up
+
+-------------------------------------------------------------------------------
+-- ENDPOINT FAULTS (time boxed fault injection at the ingestor)
+-------------------------------------------------------------------------------
+CREATE TABLE endpoint_faults (
+    endpoint_id UUID PRIMARY KEY REFERENCES endpoints(id) ON DELETE CASCADE,
+    percentage DOUBLE PRECISION NOT NULL CHECK (percentage > 0 AND percentage <= 100),
+    delay_ms INTEGER NOT NULL DEFAULT 0 CHECK (delay_ms >= 0),
+    error_status INTEGER NOT NULL DEFAULT 0,        -- 0 = no forced error
+    drop_async BOOLEAN NOT NULL DEFAULT false,
+    header TEXT NOT NULL DEFAULT '',                -- only requests carrying this header, empty = all
+    expires_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS endpoint_faults;
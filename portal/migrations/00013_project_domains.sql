@@ -0,0 +1,17 @@
+-- +goose Up
+
+-------------------------------------------------------------------------------
+-- PROJECT DOMAINS (custom hostnames routed to a project by the ingestor)
+-------------------------------------------------------------------------------
+CREATE TABLE project_domains (
+    host TEXT PRIMARY KEY,                      -- lowercase, no port
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    base_path TEXT NOT NULL DEFAULT '',         -- e.g. /v1, '' = served from /
+    created_by BYTEA REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_project_domains_project ON project_domains(project_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS project_domains;
@@ -0,0 +1,14 @@
+-- +goose Up
+
+-------------------------------------------------------------------------------
+-- ENDPOINT TRANSFORMS (header, path and body rewrites applied by the ingestor)
+-------------------------------------------------------------------------------
+CREATE TABLE endpoint_transforms (
+    endpoint_id UUID PRIMARY KEY REFERENCES endpoints(id) ON DELETE CASCADE,
+    request_rules JSONB,
+    response_rules JSONB,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS endpoint_transforms;
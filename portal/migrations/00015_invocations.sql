@@ -0,0 +1,29 @@
+-- +goose Up
+
+-------------------------------------------------------------------------------
+-- INVOCATIONS (one row per request answered by an ingestor, for the runs view)
+-------------------------------------------------------------------------------
+CREATE TABLE invocations (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    request_id TEXT NOT NULL,
+    function_name TEXT NOT NULL,
+    endpoint TEXT NOT NULL DEFAULT '',          -- request path at the ingestor
+    method TEXT NOT NULL,
+    source TEXT NOT NULL,                       -- http | grpc | internal
+    status INT NOT NULL,
+    latency_ms BIGINT NOT NULL,
+    cold_start BOOLEAN NOT NULL DEFAULT false,
+    async BOOLEAN NOT NULL DEFAULT false,
+    request_bytes BIGINT NOT NULL DEFAULT 0,
+    response_bytes BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',             -- start of the error response
+    started_at TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (project_id, request_id)
+);
+
+CREATE INDEX idx_invocations_function ON invocations(project_id, function_name, started_at DESC);
+CREATE INDEX idx_invocations_started_at ON invocations(started_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS invocations;
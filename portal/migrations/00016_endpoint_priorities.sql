@@ -0,0 +1,13 @@
+-- +goose Up
+
+-------------------------------------------------------------------------------
+-- ENDPOINT PRIORITIES (lane async invocations are queued in)
+-------------------------------------------------------------------------------
+CREATE TABLE endpoint_priorities (
+    endpoint_id UUID PRIMARY KEY REFERENCES endpoints(id) ON DELETE CASCADE,
+    priority TEXT NOT NULL CHECK (priority IN ('high', 'low')),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS endpoint_priorities;
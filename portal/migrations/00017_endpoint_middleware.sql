@@ -0,0 +1,13 @@
+-- +goose Up
+
+-------------------------------------------------------------------------------
+-- ENDPOINT MIDDLEWARE (middleware compiled into the ingestor, run per endpoint)
+-------------------------------------------------------------------------------
+CREATE TABLE endpoint_middleware (
+    endpoint_id UUID PRIMARY KEY REFERENCES endpoints(id) ON DELETE CASCADE,
+    chain JSONB NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS endpoint_middleware;
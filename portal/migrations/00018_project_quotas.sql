@@ -0,0 +1,20 @@
+-- +goose Up
+
+-------------------------------------------------------------------------------
+-- PROJECT QUOTAS (daily/monthly invocation caps enforced by the ingestor)
+-------------------------------------------------------------------------------
+CREATE TABLE project_quotas (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    api_key_id UUID REFERENCES project_api_keys(id) ON DELETE CASCADE, -- NULL = the whole project
+    daily_limit BIGINT NOT NULL DEFAULT 0,                             -- 0 = unlimited
+    monthly_limit BIGINT NOT NULL DEFAULT 0,
+    updated_by BYTEA REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX idx_project_quotas_project ON project_quotas(project_id) WHERE api_key_id IS NULL;
+CREATE UNIQUE INDEX idx_project_quotas_api_key ON project_quotas(api_key_id) WHERE api_key_id IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS project_quotas;
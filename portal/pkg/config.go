@@ -10,6 +10,7 @@ import (
 type Settings struct {
 	Port                    int    `env:"LISTEN_PORT" default:"3000"`
 	Fqdn                    string `env:"FQDN" default:"localhost"`
+	ReservedHosts           string `env:"RESERVED_HOSTS"`
 	DatabaseUrl             string `env:"DATABASE_URL,required"`
 	DatabaseSchema          string `env:"DATABASE_SCHEMA" default:"litefunctions"`
 	DatabaseConnTimeout     string `env:"DATABASE_CONN_TIMEOUT" default:"10s"`
@@ -29,6 +30,8 @@ type Settings struct {
 	VcsPublicBaseUrl        string `env:"VCS_PUBLIC_BASE_URL"`
 	OperatorUrl             string `env:"OPERATOR_URL" default:"litefunctions-operator:50051"`
 	IngestorUrl             string `env:"INGESTOR_URL" default:"http://litefunctions-ingestor:3000"`
+	NatsUrl                 string `env:"NATS_URL" default:"nats://litefunctions-nats:4222"`
+	InvocationRetention     string `env:"INVOCATION_RETENTION" default:"168h"`
 }
 
 var (
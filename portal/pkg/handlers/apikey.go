@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/portal/internal/apikey"
+	apikeyadaptors "github.com/ashupednekar/litefunctions/portal/internal/apikey/adaptors"
+	"github.com/ashupednekar/litefunctions/portal/pkg/state"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ApiKeyHandlers struct {
+	state *state.AppState
+}
+
+func NewApiKeyHandlers(s *state.AppState) *ApiKeyHandlers {
+	return &ApiKeyHandlers{state: s}
+}
+
+type apiKeyResponse struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	EndpointIDs []string   `json:"endpoint_ids"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func newApiKeyResponse(k apikeyadaptors.ProjectApiKey) apiKeyResponse {
+	res := apiKeyResponse{
+		ID:          hex.EncodeToString(k.ID.Bytes[:]),
+		Name:        k.Name,
+		Prefix:      k.Prefix,
+		EndpointIDs: make([]string, 0, len(k.EndpointIds)),
+		ExpiresAt:   timePtr(k.ExpiresAt),
+		RevokedAt:   timePtr(k.RevokedAt),
+		LastUsedAt:  timePtr(k.LastUsedAt),
+		CreatedAt:   k.CreatedAt.Time,
+	}
+	for _, id := range k.EndpointIds {
+		res.EndpointIDs = append(res.EndpointIDs, hex.EncodeToString(id.Bytes[:]))
+	}
+	return res
+}
+
+func timePtr(ts pgtype.Timestamptz) *time.Time {
+	if !ts.Valid {
+		return nil
+	}
+	t := ts.Time
+	return &t
+}
+
+func (h *ApiKeyHandlers) CreateApiKey(c *gin.Context) {
+	userID := c.MustGet("userID").([]byte)
+	projectUUID := c.MustGet("projectUUID").(pgtype.UUID)
+	projectName := c.MustGet("projectName").(string)
+
+	var req struct {
+		Name        string   `json:"name"`
+		EndpointIDs []string `json:"endpoint_ids"`
+		ExpiresIn   string   `json:"expires_in"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Name == "" {
+		c.JSON(400, gin.H{"error": "invalid request"})
+		return
+	}
+
+	var expiresAt pgtype.Timestamptz
+	if req.ExpiresIn != "" {
+		d, err := time.ParseDuration(req.ExpiresIn)
+		if err != nil || d <= 0 {
+			c.JSON(400, gin.H{"error": "invalid expires_in"})
+			return
+		}
+		expiresAt = pgtype.Timestamptz{Time: time.Now().Add(d), Valid: true}
+	}
+
+	endpointIDs := make([]pgtype.UUID, 0, len(req.EndpointIDs))
+	for _, idHex := range req.EndpointIDs {
+		idBytes, err := hex.DecodeString(idHex)
+		if err != nil || len(idBytes) != 16 {
+			c.JSON(400, gin.H{"error": "invalid endpoint id"})
+			return
+		}
+		id := pgtype.UUID{Valid: true}
+		copy(id.Bytes[:], idBytes)
+		endpointIDs = append(endpointIDs, id)
+	}
+
+	plaintext, prefix, err := apikey.Generate()
+	if err != nil {
+		slog.Error("api key generation failed", "error", err)
+		c.JSON(500, gin.H{"error": "key generation error"})
+		return
+	}
+
+	q := apikeyadaptors.New(h.state.DBPool)
+	key, err := q.CreateApiKey(c.Request.Context(), apikeyadaptors.CreateApiKeyParams{
+		ProjectID:   projectUUID,
+		Name:        req.Name,
+		Prefix:      prefix,
+		KeyHash:     gateway.HashApiKey(plaintext),
+		EndpointIds: endpointIDs,
+		ExpiresAt:   expiresAt,
+		CreatedBy:   userID,
+	})
+	if err != nil {
+		slog.Error("CreateApiKey DB failed", "error", err)
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	if err := h.state.ApiKeys.Publish(c.Request.Context(), projectName, key); err != nil {
+		slog.Error("failed to publish api key", "error", err)
+		c.JSON(500, gin.H{"error": "failed to publish api key"})
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"key":     plaintext,
+		"api_key": newApiKeyResponse(key),
+	})
+}
+
+func (h *ApiKeyHandlers) ListApiKeys(c *gin.Context) {
+	projectUUID := c.MustGet("projectUUID").(pgtype.UUID)
+
+	q := apikeyadaptors.New(h.state.DBPool)
+	keys, err := q.ListApiKeysForProject(c.Request.Context(), projectUUID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+
+	out := make([]apiKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, newApiKeyResponse(k))
+	}
+	c.JSON(200, out)
+}
+
+func (h *ApiKeyHandlers) RevokeApiKey(c *gin.Context) {
+	projectUUID := c.MustGet("projectUUID").(pgtype.UUID)
+
+	keyIDBytes, err := hex.DecodeString(c.Param("keyID"))
+	if err != nil || len(keyIDBytes) != 16 {
+		c.JSON(400, gin.H{"error": "invalid api key id"})
+		return
+	}
+	keyID := pgtype.UUID{Valid: true}
+	copy(keyID.Bytes[:], keyIDBytes)
+
+	q := apikeyadaptors.New(h.state.DBPool)
+	key, err := q.RevokeApiKey(c.Request.Context(), apikeyadaptors.RevokeApiKeyParams{
+		ID:        keyID,
+		ProjectID: projectUUID,
+	})
+	if err != nil {
+		c.JSON(404, gin.H{"error": "not found"})
+		return
+	}
+	if err := h.state.ApiKeys.Revoke(c.Request.Context(), key.KeyHash); err != nil {
+		slog.Error("failed to revoke api key at gateway", "error", err)
+		c.JSON(500, gin.H{"error": "failed to revoke api key"})
+		return
+	}
+
+	c.JSON(200, newApiKeyResponse(key))
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	accessAdaptors "github.com/ashupednekar/litefunctions/portal/internal/access/adaptors"
+	"github.com/ashupednekar/litefunctions/portal/internal/audit"
+	domainadaptors "github.com/ashupednekar/litefunctions/portal/internal/domain/adaptors"
+	"github.com/ashupednekar/litefunctions/portal/pkg"
+	"github.com/ashupednekar/litefunctions/portal/pkg/state"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	hostnamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+	basePathPattern = regexp.MustCompile(`^(/[A-Za-z0-9._~-]+)*$`)
+)
+
+type DomainHandlers struct {
+	state *state.AppState
+}
+
+func NewDomainHandlers(s *state.AppState) *DomainHandlers {
+	return &DomainHandlers{state: s}
+}
+
+type domainResponse struct {
+	Host      string    `json:"host"`
+	BasePath  string    `json:"base_path"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (h *DomainHandlers) ListDomains(c *gin.Context) {
+	projectUUID := c.MustGet("projectUUID").(pgtype.UUID)
+	rows, err := domainadaptors.New(h.state.DBPool).ListProjectDomainsForProject(c.Request.Context(), projectUUID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	res := make([]domainResponse, 0, len(rows))
+	for _, row := range rows {
+		res = append(res, domainResponse{
+			Host:      row.Host,
+			BasePath:  row.BasePath,
+			URL:       "https://" + row.Host + row.BasePath + "/",
+			CreatedAt: row.CreatedAt.Time,
+		})
+	}
+	c.JSON(200, res)
+}
+
+// AddDomain maps a hostname onto the project. The gateway still needs a
+// listener and certificate for it, which the chart renders from
+// gatewayApi.hosts.functions.
+func (h *DomainHandlers) AddDomain(c *gin.Context) {
+	var req struct {
+		Host     string `json:"host"`
+		BasePath string `json:"base_path"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid request"})
+		return
+	}
+	host := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(req.Host), "."))
+	if !hostnamePattern.MatchString(host) || len(host) > 253 {
+		c.JSON(400, gin.H{"error": "host must be a fully qualified hostname, without scheme, port or wildcards"})
+		return
+	}
+	if reservedHost(host, pkg.Cfg.Fqdn, pkg.Cfg.ReservedHosts) {
+		c.JSON(400, gin.H{"error": "host is reserved for the platform"})
+		return
+	}
+	basePath := strings.TrimSuffix(strings.TrimSpace(req.BasePath), "/")
+	if !basePathPattern.MatchString(basePath) {
+		c.JSON(400, gin.H{"error": "base_path must look like /v1"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.MustGet("userID").([]byte)
+	projectUUID := c.MustGet("projectUUID").(pgtype.UUID)
+	projectName := c.MustGet("projectName").(string)
+
+	tx, err := h.state.DBPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "error starting transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if !canManageDomains(c, tx, userID, projectUUID) {
+		return
+	}
+	row, err := domainadaptors.New(tx).CreateProjectDomain(ctx, domainadaptors.CreateProjectDomainParams{
+		Host:      host,
+		ProjectID: projectUUID,
+		BasePath:  basePath,
+		CreatedBy: userID,
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		c.JSON(409, gin.H{"error": "host is already mapped"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	if err := audit.Record(ctx, tx, projectUUID, userID, "domain.add", "domain:"+host, gin.H{"base_path": basePath}); err != nil {
+		slog.Error("Failed to record audit event", "action", "domain.add", "error", err)
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "failed to commit"})
+		return
+	}
+
+	if err := h.state.Domains.Publish(ctx, gateway.Domain{Host: host, Project: projectName, BasePath: basePath}); err != nil {
+		slog.Error("Failed to publish domain", "host", host, "error", err)
+		c.JSON(500, gin.H{"error": "failed to publish domain"})
+		return
+	}
+	slog.Info("Custom domain added", "project", projectName, "host", host)
+	c.JSON(201, domainResponse{
+		Host:      row.Host,
+		BasePath:  row.BasePath,
+		URL:       "https://" + row.Host + row.BasePath + "/",
+		CreatedAt: row.CreatedAt.Time,
+	})
+}
+
+func (h *DomainHandlers) RemoveDomain(c *gin.Context) {
+	ctx := c.Request.Context()
+	host := strings.ToLower(c.Param("host"))
+	userID := c.MustGet("userID").([]byte)
+	projectUUID := c.MustGet("projectUUID").(pgtype.UUID)
+
+	tx, err := h.state.DBPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "error starting transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if !canManageDomains(c, tx, userID, projectUUID) {
+		return
+	}
+	removed, err := domainadaptors.New(tx).DeleteProjectDomain(ctx, domainadaptors.DeleteProjectDomainParams{
+		Host:      host,
+		ProjectID: projectUUID,
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	if removed == 0 {
+		c.JSON(404, gin.H{"error": "not found"})
+		return
+	}
+	if err := audit.Record(ctx, tx, projectUUID, userID, "domain.remove", "domain:"+host, gin.H{}); err != nil {
+		slog.Error("Failed to record audit event", "action", "domain.remove", "error", err)
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "failed to commit"})
+		return
+	}
+
+	if err := h.state.Domains.Delete(ctx, host); err != nil {
+		slog.Error("Failed to remove domain", "host", host, "error", err)
+		c.JSON(500, gin.H{"error": "failed to remove domain"})
+		return
+	}
+	slog.Info("Custom domain removed", "host", host)
+	c.JSON(200, gin.H{"removed": host})
+}
+
+// canManageDomains answers 403 for viewers, who may not change how the
+// project is exposed.
+func canManageDomains(c *gin.Context, db accessAdaptors.DBTX, userID []byte, projectUUID pgtype.UUID) bool {
+	role, err := accessAdaptors.New(db).GetUserProjectRole(c.Request.Context(), accessAdaptors.GetUserProjectRoleParams{
+		UserID:    userID,
+		ProjectID: projectUUID,
+	})
+	if err != nil || role == string(accessAdaptors.ProjectRoleViewer) {
+		c.JSON(403, gin.H{"error": "only owners and managers can change custom domains"})
+		return false
+	}
+	return true
+}
+
+// reservedHost reports whether host is the portal's own or one of reserved,
+// the comma separated hosts the gateway already routes to the portal,
+// ingestor and gitea. Wildcard entries reserve every host below them.
+func reservedHost(host, fqdn, reserved string) bool {
+	for _, r := range append(strings.Split(reserved, ","), fqdn) {
+		r = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(r), "."))
+		if r == "" {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(r, "*"); ok && strings.HasSuffix(host, suffix) {
+			return true
+		}
+		if host == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import "testing"
+
+func TestReservedHost(t *testing.T) {
+	reserved := "litefunctions.portal, app.example.com,git.example.com.,*.internal.example.com"
+	cases := map[string]bool{
+		"app.example.com":          true,
+		"git.example.com":          true,
+		"litefunctions.portal":     true,
+		"portal.example.com":       true,
+		"ci.internal.example.com":  true,
+		"internal.example.com":     false,
+		"api.example.com":          false,
+		"app.example.com.evil.com": false,
+	}
+	for host, want := range cases {
+		if got := reservedHost(host, "portal.example.com", reserved); got != want {
+			t.Errorf("reservedHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+	if reservedHost("api.example.com", "", "") {
+		t.Error("nothing reserved but host refused")
+	}
+}
@@ -1,12 +1,27 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	"github.com/ashupednekar/litefunctions/common/schema"
 	endpointadaptors "github.com/ashupednekar/litefunctions/portal/internal/endpoint/adaptors"
 	"github.com/ashupednekar/litefunctions/portal/pkg/state"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -74,7 +89,7 @@ func (h *EndpointHandlers) GetEndpoint(c *gin.Context) {
 
 	q := endpointadaptors.New(h.state.DBPool)
 	ep, err := q.GetEndpointByID(c.Request.Context(), epUUID)
-	if err != nil {
+	if err != nil || ep.ProjectID != c.MustGet("projectUUID").(pgtype.UUID) {
 		c.JSON(404, gin.H{"error": "not found"})
 		return
 	}
@@ -96,6 +111,46 @@ func (h *EndpointHandlers) UpdateEndpoint(c *gin.Context) {
 	var req struct {
 		Method string `json:"method"`
 		Scope  string `json:"scope"`
+		JWT    *struct {
+			JwksURL        string            `json:"jwks_url"`
+			Issuer         string            `json:"issuer"`
+			Audiences      []string          `json:"audiences"`
+			RequiredClaims map[string]string `json:"required_claims"`
+		} `json:"jwt"`
+		// Policy is left untouched when omitted and removed when its mode
+		// is "off".
+		Policy *gateway.Policy `json:"policy"`
+		// CORS is left untouched when omitted and removed when it has no
+		// allowed origins.
+		CORS *gateway.CORSConfig `json:"cors"`
+		// Cache is left untouched when omitted and removed when its ttl is 0.
+		Cache *gateway.CacheConfig `json:"cache"`
+		// Schema is left untouched when omitted and removed when it holds
+		// neither a body nor a query schema.
+		Schema *gateway.SchemaConfig `json:"schema"`
+		// Callback is left untouched when omitted and removed when it has
+		// neither a url nor allows caller urls. The signing secret is
+		// generated on first save and kept unless rotated.
+		Callback *struct {
+			URL            string `json:"url"`
+			AllowCallerURL bool   `json:"allow_caller_url"`
+			RotateSecret   bool   `json:"rotate_secret"`
+		} `json:"callback"`
+		// Fault is left untouched when omitted and removed when its
+		// percentage is 0. ExpiresIn is a duration of at most a day.
+		Fault *struct {
+			gateway.FaultConfig
+			ExpiresIn string `json:"expires_in"`
+		} `json:"fault"`
+		// Transform is left untouched when omitted and removed when it
+		// holds no rules.
+		Transform *gateway.TransformConfig `json:"transform"`
+		// Priority is left untouched when omitted, "normal" or empty
+		// removes it.
+		Priority *string `json:"priority"`
+		// Middleware is left untouched when omitted and removed when
+		// empty. Names must be compiled into the ingestor.
+		Middleware *[]gateway.MiddlewareConfig `json:"middleware"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, gin.H{"error": "invalid request"})
@@ -103,6 +158,165 @@ func (h *EndpointHandlers) UpdateEndpoint(c *gin.Context) {
 	}
 
 	q := endpointadaptors.New(h.state.DBPool)
+	prev, err := q.GetEndpointSpec(c.Request.Context(), epUUID)
+	if err != nil || prev.ProjectID != c.MustGet("projectUUID").(pgtype.UUID) {
+		c.JSON(404, gin.H{"error": "not found"})
+		return
+	}
+	req.Method = strings.ToUpper(req.Method)
+	if !gateway.ValidEndpointMethod(req.Method) {
+		c.JSON(400, gin.H{"error": "method must be one of " + strings.Join(gateway.EndpointMethods, ", ")})
+		return
+	}
+	if req.Policy != nil && req.Policy.Mode != "off" {
+		if err := h.state.Policies.Validate(req.Policy); err != nil {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("invalid policy: %s", err)})
+			return
+		}
+	}
+	if req.CORS != nil && (req.CORS.MaxAge < 0 || req.CORS.MaxAge > 86400) {
+		c.JSON(400, gin.H{"error": "cors max age must be between 0 and 86400 seconds"})
+		return
+	}
+	if req.Cache != nil && req.Cache.TTL < 0 {
+		c.JSON(400, gin.H{"error": "cache ttl must not be negative"})
+		return
+	}
+	if req.Cache != nil && req.Cache.TTL > 0 && req.Method != "GET" {
+		c.JSON(400, gin.H{"error": "response caching is only available for GET endpoints"})
+		return
+	}
+	if req.Schema != nil {
+		req.Schema.Body, req.Schema.Query = nullJSON(req.Schema.Body), nullJSON(req.Schema.Query)
+		if _, err := schema.Compile(req.Schema); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Callback != nil && req.Callback.URL != "" {
+		u, err := url.Parse(req.Callback.URL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			c.JSON(400, gin.H{"error": "callback url must be an absolute https url"})
+			return
+		}
+	}
+	if req.Fault != nil && req.Fault.Percentage != 0 {
+		if err := validateFault(req.Fault.FaultConfig); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		d, err := time.ParseDuration(req.Fault.ExpiresIn)
+		if err != nil || d <= 0 || d > maxFaultDuration {
+			c.JSON(400, gin.H{"error": "fault expires_in must be a duration of at most 24h"})
+			return
+		}
+		req.Fault.ExpiresAt = time.Now().Add(d)
+	}
+	if req.Transform != nil {
+		if err := validateTransform(req.Transform); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Priority != nil && *req.Priority != "" && !gateway.ValidPriority(*req.Priority) {
+		c.JSON(400, gin.H{"error": "priority must be one of high, normal, low"})
+		return
+	}
+	if req.Middleware != nil {
+		for _, mw := range *req.Middleware {
+			if strings.TrimSpace(mw.Name) == "" {
+				c.JSON(400, gin.H{"error": "every middleware needs a name"})
+				return
+			}
+		}
+	}
+	if req.Scope == gateway.ScopeJWT {
+		if req.JWT == nil {
+			if _, err := q.GetEndpointJwtConfig(c.Request.Context(), epUUID); err != nil {
+				c.JSON(400, gin.H{"error": "jwt settings required"})
+				return
+			}
+		} else {
+			u, err := url.Parse(req.JWT.JwksURL)
+			if err != nil || u.Scheme != "https" || u.Host == "" {
+				c.JSON(400, gin.H{"error": "jwks url must be an absolute https url"})
+				return
+			}
+			claims, err := json.Marshal(req.JWT.RequiredClaims)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "invalid required claims"})
+				return
+			}
+			if req.JWT.Audiences == nil {
+				req.JWT.Audiences = []string{}
+			}
+			if _, err := q.UpsertEndpointJwtConfig(c.Request.Context(), endpointadaptors.UpsertEndpointJwtConfigParams{
+				EndpointID:     epUUID,
+				JwksUrl:        req.JWT.JwksURL,
+				Issuer:         req.JWT.Issuer,
+				Audiences:      req.JWT.Audiences,
+				RequiredClaims: claims,
+			}); err != nil {
+				c.JSON(500, gin.H{"error": "database error"})
+				return
+			}
+		}
+	}
+
+	if req.Policy != nil {
+		if err := h.savePolicy(c.Request.Context(), q, epUUID, req.Policy); err != nil {
+			c.JSON(500, gin.H{"error": "database error"})
+			return
+		}
+	}
+	if req.CORS != nil {
+		if err := h.saveCORS(c.Request.Context(), q, epUUID, req.CORS); err != nil {
+			c.JSON(500, gin.H{"error": "database error"})
+			return
+		}
+	}
+	if req.Cache != nil {
+		if err := h.saveCache(c.Request.Context(), q, epUUID, req.Cache); err != nil {
+			c.JSON(500, gin.H{"error": "database error"})
+			return
+		}
+	}
+	if req.Schema != nil {
+		if err := h.saveSchema(c.Request.Context(), q, epUUID, req.Schema); err != nil {
+			c.JSON(500, gin.H{"error": "database error"})
+			return
+		}
+	}
+	if req.Callback != nil {
+		if err := h.saveCallback(c.Request.Context(), q, epUUID, req.Callback.URL, req.Callback.AllowCallerURL, req.Callback.RotateSecret); err != nil {
+			c.JSON(500, gin.H{"error": "database error"})
+			return
+		}
+	}
+	if req.Fault != nil {
+		if err := h.saveFault(c.Request.Context(), q, epUUID, &req.Fault.FaultConfig); err != nil {
+			c.JSON(500, gin.H{"error": "database error"})
+			return
+		}
+	}
+	if req.Transform != nil {
+		if err := h.saveTransform(c.Request.Context(), q, epUUID, req.Transform); err != nil {
+			c.JSON(500, gin.H{"error": "database error"})
+			return
+		}
+	}
+	if req.Priority != nil {
+		if err := h.savePriority(c.Request.Context(), q, epUUID, *req.Priority); err != nil {
+			c.JSON(500, gin.H{"error": "database error"})
+			return
+		}
+	}
+	if req.Middleware != nil {
+		if err := h.saveMiddleware(c.Request.Context(), q, epUUID, *req.Middleware); err != nil {
+			c.JSON(500, gin.H{"error": "database error"})
+			return
+		}
+	}
 	ep, err := q.UpdateEndpointMethodScope(c.Request.Context(), endpointadaptors.UpdateEndpointMethodScopeParams{
 		ID:     epUUID,
 		Method: req.Method,
@@ -113,6 +327,324 @@ func (h *EndpointHandlers) UpdateEndpoint(c *gin.Context) {
 		return
 	}
 
+	if prev.Method != ep.Method {
+		if err := h.state.Endpoints.Delete(c.Request.Context(), prev.ProjectName, prev.FunctionName, prev.Method); err != nil {
+			slog.Warn("Failed to remove endpoint", "name", prev.Name, "error", err)
+		}
+	}
+	if err := h.state.Endpoints.Publish(c.Request.Context(), ep.ID); err != nil {
+		slog.Error("Failed to publish endpoint", "name", ep.Name, "error", err)
+		c.JSON(500, gin.H{"error": "failed to publish endpoint"})
+		return
+	}
+
 	c.JSON(200, ep)
 }
 
+func (h *EndpointHandlers) savePolicy(ctx context.Context, q *endpointadaptors.Queries, id pgtype.UUID, pol *gateway.Policy) error {
+	if pol.Mode == "off" {
+		return q.DeleteEndpointPolicy(ctx, id)
+	}
+	if pol.Rules == nil {
+		pol.Rules = []gateway.PolicyRule{}
+	}
+	rules, err := json.Marshal(pol.Rules)
+	if err != nil {
+		return err
+	}
+	_, err = q.UpsertEndpointPolicy(ctx, endpointadaptors.UpsertEndpointPolicyParams{
+		EndpointID:    id,
+		Mode:          pol.Mode,
+		DefaultEffect: pol.Default,
+		Rules:         rules,
+	})
+	return err
+}
+
+func (h *EndpointHandlers) saveCORS(ctx context.Context, q *endpointadaptors.Queries, id pgtype.UUID, cfg *gateway.CORSConfig) error {
+	if len(cfg.AllowedOrigins) == 0 {
+		return q.DeleteEndpointCorsConfig(ctx, id)
+	}
+	for _, list := range []*[]string{&cfg.AllowedMethods, &cfg.AllowedHeaders, &cfg.ExposedHeaders} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	_, err := q.UpsertEndpointCorsConfig(ctx, endpointadaptors.UpsertEndpointCorsConfigParams{
+		EndpointID:       id,
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   cfg.AllowedMethods,
+		AllowedHeaders:   cfg.AllowedHeaders,
+		ExposedHeaders:   cfg.ExposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           int32(cfg.MaxAge),
+	})
+	return err
+}
+
+func (h *EndpointHandlers) saveCache(ctx context.Context, q *endpointadaptors.Queries, id pgtype.UUID, cfg *gateway.CacheConfig) error {
+	if cfg.TTL == 0 {
+		return q.DeleteEndpointCacheConfig(ctx, id)
+	}
+	if cfg.VaryHeaders == nil {
+		cfg.VaryHeaders = []string{}
+	}
+	if cfg.VaryQuery == nil {
+		cfg.VaryQuery = []string{}
+	}
+	_, err := q.UpsertEndpointCacheConfig(ctx, endpointadaptors.UpsertEndpointCacheConfigParams{
+		EndpointID:  id,
+		TtlSeconds:  int32(cfg.TTL),
+		VaryHeaders: cfg.VaryHeaders,
+		VaryQuery:   cfg.VaryQuery,
+	})
+	return err
+}
+
+func (h *EndpointHandlers) saveSchema(ctx context.Context, q *endpointadaptors.Queries, id pgtype.UUID, cfg *gateway.SchemaConfig) error {
+	if cfg.Body == nil && cfg.Query == nil {
+		return q.DeleteEndpointSchema(ctx, id)
+	}
+	_, err := q.UpsertEndpointSchema(ctx, endpointadaptors.UpsertEndpointSchemaParams{
+		EndpointID:  id,
+		BodySchema:  cfg.Body,
+		QuerySchema: cfg.Query,
+	})
+	return err
+}
+
+func (h *EndpointHandlers) saveCallback(ctx context.Context, q *endpointadaptors.Queries, id pgtype.UUID, target string, allowCaller, rotate bool) error {
+	if target == "" && !allowCaller {
+		return q.DeleteEndpointCallback(ctx, id)
+	}
+	prev, err := q.GetEndpointCallback(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	secret := prev.Secret
+	if secret == "" || rotate {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return err
+		}
+		secret = "whsec_" + hex.EncodeToString(buf)
+	}
+	_, err = q.UpsertEndpointCallback(ctx, endpointadaptors.UpsertEndpointCallbackParams{
+		EndpointID:     id,
+		Url:            target,
+		Secret:         secret,
+		AllowCallerUrl: allowCaller,
+	})
+	return err
+}
+
+// maxFaultDuration bounds fault injection so a forgotten experiment can't
+// degrade an endpoint indefinitely.
+const maxFaultDuration = 24 * time.Hour
+
+func validateFault(f gateway.FaultConfig) error {
+	switch {
+	case f.Percentage < 0 || f.Percentage > 100:
+		return errors.New("fault percentage must be between 0 and 100")
+	case f.DelayMs < 0 || f.DelayMs > 60000:
+		return errors.New("fault delay must be between 0 and 60000 ms")
+	case f.ErrorStatus != 0 && (f.ErrorStatus < 400 || f.ErrorStatus > 599):
+		return errors.New("fault error status must be a 4xx or 5xx code")
+	case f.DelayMs == 0 && f.ErrorStatus == 0 && !f.DropAsync:
+		return errors.New("fault must add latency, force an error or drop async requests")
+	case strings.HasPrefix(http.CanonicalHeaderKey(f.Header), "X-Litefunction-"):
+		return errors.New("fault header can't be a reserved X-Litefunction- header")
+	}
+	return nil
+}
+
+func (h *EndpointHandlers) saveFault(ctx context.Context, q *endpointadaptors.Queries, id pgtype.UUID, f *gateway.FaultConfig) error {
+	if f.Percentage == 0 {
+		return q.DeleteEndpointFault(ctx, id)
+	}
+	_, err := q.UpsertEndpointFault(ctx, endpointadaptors.UpsertEndpointFaultParams{
+		EndpointID:  id,
+		Percentage:  f.Percentage,
+		DelayMs:     int32(f.DelayMs),
+		ErrorStatus: int32(f.ErrorStatus),
+		DropAsync:   f.DropAsync,
+		Header:      strings.TrimSpace(f.Header),
+		ExpiresAt:   pgtype.Timestamptz{Time: f.ExpiresAt, Valid: true},
+	})
+	return err
+}
+
+// nullJSON maps an absent or null document to SQL NULL.
+func nullJSON(raw json.RawMessage) []byte {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil
+	}
+	return trimmed
+}
+
+func (h *EndpointHandlers) PurgeEndpointCache(c *gin.Context) {
+	epIDBytes, err := hex.DecodeString(c.Param("epID"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid endpoint id"})
+		return
+	}
+	var epUUID pgtype.UUID
+	copy(epUUID.Bytes[:], epIDBytes)
+	epUUID.Valid = true
+
+	ep, err := endpointadaptors.New(h.state.DBPool).GetEndpointSpec(c.Request.Context(), epUUID)
+	if err != nil || ep.ProjectID != c.MustGet("projectUUID").(pgtype.UUID) {
+		c.JSON(404, gin.H{"error": "not found"})
+		return
+	}
+	purged, err := h.state.Endpoints.PurgeCache(c.Request.Context(), ep.ProjectName, ep.FunctionName)
+	if err != nil {
+		slog.Error("Failed to purge response cache", "name", ep.Name, "error", err)
+		c.JSON(500, gin.H{"error": "failed to purge cache"})
+		return
+	}
+	c.JSON(200, gin.H{"purged": purged})
+}
+
+// ListCallbackDeliveries shows the recent webhook deliveries of an async
+// endpoint, with every attempt the ingestors made.
+func (h *EndpointHandlers) ListCallbackDeliveries(c *gin.Context) {
+	epIDBytes, err := hex.DecodeString(c.Param("epID"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid endpoint id"})
+		return
+	}
+	var epUUID pgtype.UUID
+	copy(epUUID.Bytes[:], epIDBytes)
+	epUUID.Valid = true
+
+	ep, err := endpointadaptors.New(h.state.DBPool).GetEndpointSpec(c.Request.Context(), epUUID)
+	if err != nil || ep.ProjectID != c.MustGet("projectUUID").(pgtype.UUID) {
+		c.JSON(404, gin.H{"error": "not found"})
+		return
+	}
+	deliveries, err := h.state.Endpoints.CallbackDeliveries(c.Request.Context(), ep.ProjectName, ep.FunctionName, 50)
+	if err != nil {
+		slog.Error("Failed to list callback deliveries", "name", ep.Name, "error", err)
+		c.JSON(500, gin.H{"error": "failed to list callback deliveries"})
+		return
+	}
+	c.JSON(200, deliveries)
+}
+
+var (
+	headerNamePattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+	transformVarRef   = regexp.MustCompile(`\$\{([^}]*)\}`)
+)
+
+// validateTransform rejects rules the ingestor would skip or fail on, so
+// mistakes surface when saving rather than on live traffic.
+func validateTransform(t *gateway.TransformConfig) error {
+	var names []string
+	var values []string
+	if rt := t.Request; rt != nil {
+		for from, to := range rt.RenameHeaders {
+			names = append(names, from, to)
+		}
+		names = append(names, rt.RemoveHeaders...)
+		for k, v := range rt.SetHeaders {
+			names = append(names, k)
+			values = append(values, v)
+		}
+		if rt.Path != "" {
+			if !strings.HasPrefix(rt.Path, "/") {
+				return errors.New("transform path must start with /")
+			}
+			values = append(values, rt.Path)
+		}
+		for param, field := range rt.QueryToBody {
+			if param == "" || field == "" {
+				return errors.New("transform query_to_body needs a parameter and a field name")
+			}
+		}
+	}
+	if rt := t.Response; rt != nil {
+		names = append(names, rt.RemoveHeaders...)
+		names = append(names, rt.AllowHeaders...)
+		for k, v := range rt.SetHeaders {
+			names = append(names, k)
+			values = append(values, v)
+			for _, m := range transformVarRef.FindAllStringSubmatch(v, -1) {
+				if strings.HasPrefix(m[1], "secret.") {
+					return fmt.Errorf("response header %q can't reference a secret", k)
+				}
+			}
+		}
+	}
+	for _, name := range names {
+		if !headerNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if strings.HasPrefix(http.CanonicalHeaderKey(name), "X-Litefunction-") {
+			return fmt.Errorf("transform can't change reserved header %q", name)
+		}
+	}
+	for _, v := range values {
+		for _, m := range transformVarRef.FindAllStringSubmatch(v, -1) {
+			key := m[1]
+			switch {
+			case key == "project" || key == "function" || key == "method":
+			case strings.HasPrefix(key, "header.") || strings.HasPrefix(key, "query."):
+			case strings.HasPrefix(key, "secret.") && !strings.ContainsAny(key, `/\`):
+			default:
+				return fmt.Errorf("unknown transform variable ${%s}", key)
+			}
+		}
+	}
+	return nil
+}
+
+func (h *EndpointHandlers) saveTransform(ctx context.Context, q *endpointadaptors.Queries, id pgtype.UUID, t *gateway.TransformConfig) error {
+	if t.Request == nil && t.Response == nil {
+		return q.DeleteEndpointTransform(ctx, id)
+	}
+	params := endpointadaptors.UpsertEndpointTransformParams{EndpointID: id}
+	if t.Request != nil {
+		rules, err := json.Marshal(t.Request)
+		if err != nil {
+			return err
+		}
+		params.RequestRules = rules
+	}
+	if t.Response != nil {
+		rules, err := json.Marshal(t.Response)
+		if err != nil {
+			return err
+		}
+		params.ResponseRules = rules
+	}
+	_, err := q.UpsertEndpointTransform(ctx, params)
+	return err
+}
+
+func (h *EndpointHandlers) savePriority(ctx context.Context, q *endpointadaptors.Queries, id pgtype.UUID, priority string) error {
+	if priority == "" || priority == gateway.PriorityNormal {
+		return q.DeleteEndpointPriority(ctx, id)
+	}
+	_, err := q.UpsertEndpointPriority(ctx, endpointadaptors.UpsertEndpointPriorityParams{
+		EndpointID: id,
+		Priority:   priority,
+	})
+	return err
+}
+
+func (h *EndpointHandlers) saveMiddleware(ctx context.Context, q *endpointadaptors.Queries, id pgtype.UUID, chain []gateway.MiddlewareConfig) error {
+	if len(chain) == 0 {
+		return q.DeleteEndpointMiddleware(ctx, id)
+	}
+	data, err := json.Marshal(chain)
+	if err != nil {
+		return err
+	}
+	_, err = q.UpsertEndpointMiddleware(ctx, endpointadaptors.UpsertEndpointMiddlewareParams{
+		EndpointID: id,
+		Chain:      data,
+	})
+	return err
+}
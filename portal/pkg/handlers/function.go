@@ -139,7 +139,7 @@ func (h *FunctionHandlers) CreateFunction(c *gin.Context) {
 
 	// Automatically create endpoint: /project/name
 	epPath := fmt.Sprintf("/%s/%s", projectName, req.Name)
-	_, err = eq.CreateEndpoint(c.Request.Context(), endpointadaptors.CreateEndpointParams{
+	ep, err := eq.CreateEndpoint(c.Request.Context(), endpointadaptors.CreateEndpointParams{
 		ProjectID:  projectUUID,
 		Name:       epPath,
 		Method:     "GET",
@@ -148,6 +148,8 @@ func (h *FunctionHandlers) CreateFunction(c *gin.Context) {
 	})
 	if err != nil {
 		slog.Warn("Failed to create automatic endpoint", "name", req.Name, "error", err)
+	} else if err := h.state.Endpoints.Publish(c.Request.Context(), ep.ID); err != nil {
+		slog.Warn("Failed to publish endpoint", "name", req.Name, "error", err)
 	}
 	_, err = functionadaptors.CreateFunctionCRD(
 		c.Request.Context(),
@@ -405,12 +407,29 @@ func (h *FunctionHandlers) DeleteFunction(c *gin.Context) {
 		return
 	}
 
+	eps, err := endpointadaptors.New(h.state.DBPool).ListEndpointsForProject(c.Request.Context(), f.ProjectID)
+	if err != nil {
+		slog.Error("ListEndpointsForProject failed", "error", err)
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+
 	if err := q.DeleteFunction(c.Request.Context(), pgFnId); err != nil {
 		slog.Error("db delete failed", "error", err)
 		c.JSON(500, gin.H{"error": "db delete error"})
 		return
 	}
 
+	projectName := c.MustGet("projectName").(string)
+	for _, ep := range eps {
+		if ep.FunctionID != f.ID {
+			continue
+		}
+		if err := h.state.Endpoints.Delete(c.Request.Context(), projectName, f.Name, ep.Method); err != nil {
+			slog.Warn("Failed to remove endpoint", "name", ep.Name, "error", err)
+		}
+	}
+
 	r := c.MustGet("repo").(*repo.GitRepo)
 
 	if err := r.Fs.Remove(f.Path); err != nil {
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	functionadaptors "github.com/ashupednekar/litefunctions/portal/internal/function/adaptors"
+	invocationadaptors "github.com/ashupednekar/litefunctions/portal/internal/invocation/adaptors"
+	"github.com/ashupednekar/litefunctions/portal/pkg/state"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// invocationWindows are the time ranges runs can be filtered to.
+var invocationWindows = map[string]time.Duration{
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+type InvocationHandlers struct {
+	state *state.AppState
+}
+
+func NewInvocationHandlers(s *state.AppState) *InvocationHandlers {
+	return &InvocationHandlers{state: s}
+}
+
+type invocationResponse struct {
+	RequestID     string    `json:"request_id"`
+	Endpoint      string    `json:"endpoint"`
+	Method        string    `json:"method"`
+	Source        string    `json:"source"`
+	Status        int32     `json:"status"`
+	LatencyMs     int64     `json:"latency_ms"`
+	ColdStart     bool      `json:"cold_start"`
+	Async         bool      `json:"async"`
+	RequestBytes  int64     `json:"request_bytes"`
+	ResponseBytes int64     `json:"response_bytes"`
+	Error         string    `json:"error,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+}
+
+type invocationSummary struct {
+	Total        int64 `json:"total"`
+	ServerErrors int64 `json:"server_errors"`
+	ClientErrors int64 `json:"client_errors"`
+	ColdStarts   int64 `json:"cold_starts"`
+	AvgLatencyMs int64 `json:"avg_latency_ms"`
+	P95LatencyMs int64 `json:"p95_latency_ms"`
+}
+
+// ListInvocations returns the function's recent runs, newest first, with a
+// summary over the selected window. Older pages are fetched by passing the
+// last started_at as ?before=.
+func (h *InvocationHandlers) ListInvocations(c *gin.Context) {
+	fn, ok := projectFunction(c, h.state)
+	if !ok {
+		return
+	}
+	params, err := invocationQuery(c, fn)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	runs, summary, err := listInvocations(c, h.state, params)
+	if err != nil {
+		slog.Error("Failed to list invocations", "name", fn.Name, "error", err)
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	c.JSON(200, gin.H{"summary": summary, "invocations": runs})
+}
+
+// projectFunction resolves :fnID within the current project, answering 404
+// otherwise.
+func projectFunction(c *gin.Context, s *state.AppState) (functionadaptors.Function, bool) {
+	fnID, err := hex.DecodeString(c.Param("fnID"))
+	if err != nil || len(fnID) != 16 {
+		c.JSON(404, gin.H{"error": "not found"})
+		return functionadaptors.Function{}, false
+	}
+	id := pgtype.UUID{Valid: true}
+	copy(id.Bytes[:], fnID)
+	fn, err := functionadaptors.New(s.DBPool).GetFunctionByID(c.Request.Context(), id)
+	if err != nil || fn.ProjectID != c.MustGet("projectUUID").(pgtype.UUID) {
+		c.JSON(404, gin.H{"error": "not found"})
+		return functionadaptors.Function{}, false
+	}
+	return fn, true
+}
+
+// invocationQuery reads the filters shared by the API and the runs page:
+// window, status (2xx, 4xx, 5xx), source, cold, q (request id or error
+// text), before and limit.
+func invocationQuery(c *gin.Context, fn functionadaptors.Function) (invocationadaptors.ListInvocationsParams, error) {
+	params := invocationadaptors.ListInvocationsParams{
+		ProjectID:    fn.ProjectID,
+		FunctionName: fn.Name,
+		Search:       strings.TrimSpace(c.Query("q")),
+		RowLimit:     100,
+	}
+	window, ok := invocationWindows[c.DefaultQuery("window", "24h")]
+	if !ok {
+		return params, errors.New("window must be one of 1h, 24h, 7d, 30d")
+	}
+	params.Since = pgtype.Timestamptz{Time: time.Now().Add(-window), Valid: true}
+
+	if raw := c.Query("before"); raw != "" {
+		before, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return params, errors.New("before must be an RFC 3339 timestamp")
+		}
+		params.Before = pgtype.Timestamptz{Time: before, Valid: true}
+	}
+	switch status := c.Query("status"); status {
+	case "":
+	case "2xx", "3xx", "4xx", "5xx":
+		params.StatusClass = pgtype.Int4{Int32: int32(status[0] - '0'), Valid: true}
+	default:
+		return params, errors.New("status must be one of 2xx, 3xx, 4xx, 5xx")
+	}
+	if source := c.Query("source"); source != "" {
+		params.Source = pgtype.Text{String: source, Valid: true}
+	}
+	if raw := c.Query("cold"); raw != "" {
+		cold, err := strconv.ParseBool(raw)
+		if err != nil {
+			return params, errors.New("cold must be true or false")
+		}
+		params.ColdStart = pgtype.Bool{Bool: cold, Valid: true}
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return params, errors.New("limit must be a positive number")
+		}
+		params.RowLimit = int32(min(limit, 500))
+	}
+	return params, nil
+}
+
+func listInvocations(c *gin.Context, s *state.AppState, params invocationadaptors.ListInvocationsParams) ([]invocationResponse, invocationSummary, error) {
+	q := invocationadaptors.New(s.DBPool)
+	rows, err := q.ListInvocations(c.Request.Context(), params)
+	if err != nil {
+		return nil, invocationSummary{}, err
+	}
+	sum, err := q.SummarizeInvocations(c.Request.Context(), invocationadaptors.SummarizeInvocationsParams{
+		ProjectID:    params.ProjectID,
+		FunctionName: params.FunctionName,
+		Since:        params.Since,
+	})
+	if err != nil {
+		return nil, invocationSummary{}, err
+	}
+	runs := make([]invocationResponse, 0, len(rows))
+	for _, row := range rows {
+		runs = append(runs, invocationResponse{
+			RequestID:     row.RequestID,
+			Endpoint:      row.Endpoint,
+			Method:        row.Method,
+			Source:        row.Source,
+			Status:        row.Status,
+			LatencyMs:     row.LatencyMs,
+			ColdStart:     row.ColdStart,
+			Async:         row.Async,
+			RequestBytes:  row.RequestBytes,
+			ResponseBytes: row.ResponseBytes,
+			Error:         row.Error,
+			StartedAt:     row.StartedAt.Time,
+		})
+	}
+	return runs, invocationSummary(sum), nil
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	accessAdaptors "github.com/ashupednekar/litefunctions/portal/internal/access/adaptors"
+	"github.com/ashupednekar/litefunctions/portal/internal/audit"
+	auditadaptors "github.com/ashupednekar/litefunctions/portal/internal/audit/adaptors"
+	functionadaptors "github.com/ashupednekar/litefunctions/portal/internal/function/adaptors"
+	maintenanceadaptors "github.com/ashupednekar/litefunctions/portal/internal/maintenance/adaptors"
+	"github.com/ashupednekar/litefunctions/portal/pkg/state"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type MaintenanceHandlers struct {
+	state *state.AppState
+}
+
+func NewMaintenanceHandlers(s *state.AppState) *MaintenanceHandlers {
+	return &MaintenanceHandlers{state: s}
+}
+
+type maintenanceResponse struct {
+	FunctionID string    `json:"function_id,omitempty"`
+	Function   string    `json:"function,omitempty"`
+	Message    string    `json:"message"`
+	EnabledAt  time.Time `json:"enabled_at"`
+}
+
+func (h *MaintenanceHandlers) ListMaintenance(c *gin.Context) {
+	projectUUID := c.MustGet("projectUUID").(pgtype.UUID)
+	rows, err := maintenanceadaptors.New(h.state.DBPool).ListMaintenanceModesForProject(c.Request.Context(), projectUUID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	res := make([]maintenanceResponse, 0, len(rows))
+	for _, row := range rows {
+		m := maintenanceResponse{Function: row.FunctionName.String, Message: row.Message, EnabledAt: row.EnabledAt.Time}
+		if row.FunctionID.Valid {
+			m.FunctionID = hex.EncodeToString(row.FunctionID.Bytes[:])
+		}
+		res = append(res, m)
+	}
+	c.JSON(200, res)
+}
+
+// SetMaintenance takes a function, or the whole project when no function_id
+// is given, out of service. Ingestors answer 503 with the message until it
+// is cleared.
+func (h *MaintenanceHandlers) SetMaintenance(c *gin.Context) {
+	var req struct {
+		FunctionID string `json:"function_id"`
+		Message    string `json:"message"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Message) > 512 {
+		c.JSON(400, gin.H{"error": "invalid request"})
+		return
+	}
+	h.toggle(c, req.FunctionID, strings.TrimSpace(req.Message), true)
+}
+
+func (h *MaintenanceHandlers) ClearMaintenance(c *gin.Context) {
+	h.toggle(c, c.Query("function_id"), "", false)
+}
+
+func (h *MaintenanceHandlers) toggle(c *gin.Context, fnHex, message string, enable bool) {
+	ctx := c.Request.Context()
+	userID := c.MustGet("userID").([]byte)
+	projectUUID := c.MustGet("projectUUID").(pgtype.UUID)
+	projectName := c.MustGet("projectName").(string)
+
+	var fnUUID pgtype.UUID
+	fnName := ""
+	if fnHex != "" {
+		fnID, err := hex.DecodeString(fnHex)
+		if err != nil || len(fnID) != 16 {
+			c.JSON(400, gin.H{"error": "invalid function id"})
+			return
+		}
+		copy(fnUUID.Bytes[:], fnID)
+		fnUUID.Valid = true
+		fn, err := functionadaptors.New(h.state.DBPool).GetFunctionByID(ctx, fnUUID)
+		if err != nil || fn.ProjectID != projectUUID {
+			c.JSON(404, gin.H{"error": "not found"})
+			return
+		}
+		fnName = fn.Name
+	}
+
+	tx, err := h.state.DBPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "error starting transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	role, err := accessAdaptors.New(tx).GetUserProjectRole(ctx, accessAdaptors.GetUserProjectRoleParams{
+		UserID:    userID,
+		ProjectID: projectUUID,
+	})
+	if err != nil || role == string(accessAdaptors.ProjectRoleViewer) {
+		c.JSON(403, gin.H{"error": "only owners and managers can change maintenance mode"})
+		return
+	}
+
+	q := maintenanceadaptors.New(tx)
+	removed, err := q.DeleteMaintenanceMode(ctx, maintenanceadaptors.DeleteMaintenanceModeParams{
+		ProjectID:  projectUUID,
+		FunctionID: fnUUID,
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	var row maintenanceadaptors.MaintenanceMode
+	if enable {
+		row, err = q.CreateMaintenanceMode(ctx, maintenanceadaptors.CreateMaintenanceModeParams{
+			ProjectID:  projectUUID,
+			FunctionID: fnUUID,
+			Message:    message,
+			EnabledBy:  userID,
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": "database error"})
+			return
+		}
+	} else if removed == 0 {
+		c.JSON(404, gin.H{"error": "not in maintenance"})
+		return
+	}
+
+	target, action := "project", "maintenance.disable"
+	if fnName != "" {
+		target = "function:" + fnName
+	}
+	if enable {
+		action = "maintenance.enable"
+	}
+	if err := audit.Record(ctx, tx, projectUUID, userID, action, target, gin.H{"message": message}); err != nil {
+		slog.Error("Failed to record audit event", "action", action, "error", err)
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "failed to commit"})
+		return
+	}
+
+	if enable {
+		err = h.state.Maintenance.Publish(ctx, gateway.Maintenance{
+			Project:  projectName,
+			Function: fnName,
+			Message:  message,
+			Since:    row.EnabledAt.Time,
+		})
+	} else {
+		err = h.state.Maintenance.Delete(ctx, projectName, fnName)
+	}
+	if err != nil {
+		slog.Error("Failed to publish maintenance flag", "project", projectName, "function", fnName, "error", err)
+		c.JSON(500, gin.H{"error": "failed to publish maintenance flag"})
+		return
+	}
+	slog.Info("Maintenance mode changed", "project", projectName, "function", fnName, "enabled", enable)
+	c.JSON(200, gin.H{"enabled": enable})
+}
+
+type auditEventResponse struct {
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Target    string          `json:"target"`
+	Detail    json.RawMessage `json:"detail"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func (h *MaintenanceHandlers) ListAuditEvents(c *gin.Context) {
+	projectUUID := c.MustGet("projectUUID").(pgtype.UUID)
+	rows, err := auditadaptors.New(h.state.DBPool).ListAuditEventsForProject(c.Request.Context(), auditadaptors.ListAuditEventsForProjectParams{
+		ProjectID: projectUUID,
+		Limit:     100,
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	res := make([]auditEventResponse, 0, len(rows))
+	for _, row := range rows {
+		res = append(res, auditEventResponse{
+			Actor:     row.ActorName.String,
+			Action:    row.Action,
+			Target:    row.Target,
+			Detail:    row.Detail,
+			CreatedAt: row.CreatedAt.Time,
+		})
+	}
+	c.JSON(200, res)
+}
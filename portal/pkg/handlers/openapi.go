@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"strings"
+
+	endpointadaptors "github.com/ashupednekar/litefunctions/portal/internal/endpoint/adaptors"
+	"github.com/ashupednekar/litefunctions/portal/pkg"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// OpenAPI describes the project's endpoints as an OpenAPI 3.1 document, using
+// the same schemas the ingestor validates requests against, so client docs
+// and SDKs can be generated from it.
+func (h *EndpointHandlers) OpenAPI(c *gin.Context) {
+	projectUUID := c.MustGet("projectUUID").(pgtype.UUID)
+	projectName := c.GetString("projectName")
+
+	q := endpointadaptors.New(h.state.DBPool)
+	eps, err := q.ListEndpointsForProject(c.Request.Context(), projectUUID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	schemas, err := q.ListEndpointSchemasForProject(c.Request.Context(), projectUUID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	schemaByEndpoint := make(map[pgtype.UUID]endpointadaptors.EndpointSchema, len(schemas))
+	for _, sch := range schemas {
+		schemaByEndpoint[sch.EndpointID] = sch
+	}
+
+	paths := map[string]map[string]any{}
+	for _, e := range eps {
+		op := map[string]any{
+			"operationId": e.FunctionName + strings.ToUpper(e.Method[:1]) + strings.ToLower(e.Method[1:]),
+			"summary":     e.FunctionName,
+			"responses": map[string]any{
+				"200": map[string]any{"description": "Function response"},
+			},
+		}
+		if sch, ok := schemaByEndpoint[e.ID]; ok {
+			if sch.BodySchema != nil {
+				op["requestBody"] = map[string]any{
+					"required": true,
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": json.RawMessage(sch.BodySchema)},
+					},
+				}
+			}
+			if sch.QuerySchema != nil {
+				op["parameters"] = queryParameters(sch.QuerySchema)
+			}
+			op["responses"].(map[string]any)["400"] = map[string]any{"description": "Request failed schema validation"}
+		}
+		if paths[e.Name] == nil {
+			paths[e.Name] = map[string]any{}
+		}
+		paths[e.Name][strings.ToLower(e.Method)] = op
+	}
+
+	c.JSON(200, gin.H{
+		"openapi": "3.1.0",
+		"info":    gin.H{"title": projectName, "version": "1.0.0"},
+		"servers": []gin.H{{"url": strings.TrimRight(pkg.Cfg.IngestorUrl, "/")}},
+		"paths":   paths,
+	})
+}
+
+// queryParameters turns an object schema over query parameters into OpenAPI
+// parameter objects.
+func queryParameters(raw []byte) []map[string]any {
+	var sch struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Required   []string                   `json:"required"`
+	}
+	if err := json.Unmarshal(raw, &sch); err != nil {
+		slog.Warn("ignoring malformed query schema", "error", err)
+		return nil
+	}
+	required := map[string]bool{}
+	for _, name := range sch.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(sch.Properties))
+	for name := range sch.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		params = append(params, map[string]any{
+			"name":     name,
+			"in":       "query",
+			"required": required[name],
+			"schema":   sch.Properties[name],
+		})
+	}
+	return params
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/ashupednekar/litefunctions/common/gateway"
+	accessAdaptors "github.com/ashupednekar/litefunctions/portal/internal/access/adaptors"
+	apikeyadaptors "github.com/ashupednekar/litefunctions/portal/internal/apikey/adaptors"
+	"github.com/ashupednekar/litefunctions/portal/internal/audit"
+	"github.com/ashupednekar/litefunctions/portal/internal/quota"
+	quotaadaptors "github.com/ashupednekar/litefunctions/portal/internal/quota/adaptors"
+	"github.com/ashupednekar/litefunctions/portal/pkg/state"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type QuotaHandlers struct {
+	state *state.AppState
+}
+
+func NewQuotaHandlers(s *state.AppState) *QuotaHandlers {
+	return &QuotaHandlers{state: s}
+}
+
+type quotaPeriodResponse struct {
+	Limit int64     `json:"limit"`
+	Used  int64     `json:"used"`
+	Reset time.Time `json:"reset"`
+}
+
+type quotaResponse struct {
+	ApiKeyID string              `json:"api_key_id,omitempty"`
+	Name     string              `json:"name"`
+	Daily    quotaPeriodResponse `json:"daily"`
+	Monthly  quotaPeriodResponse `json:"monthly"`
+}
+
+// ListQuotas returns the project's quota and each active api key's, with
+// what was consumed in the current day and month. A limit of 0 means the
+// period is not limited.
+func (h *QuotaHandlers) ListQuotas(c *gin.Context) {
+	ctx := c.Request.Context()
+	projectUUID := c.MustGet("projectUUID").(pgtype.UUID)
+	projectName := c.MustGet("projectName").(string)
+
+	rows, err := quotaadaptors.New(h.state.DBPool).ListQuotasForProject(ctx, projectUUID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	keys, err := apikeyadaptors.New(h.state.DBPool).ListApiKeysForProject(ctx, projectUUID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	limits := make(map[string]gateway.Quota, len(rows))
+	for _, row := range rows {
+		spec := quota.Spec(projectName, row)
+		limits[spec.ApiKeyID] = spec
+	}
+
+	now := time.Now()
+	res := make([]quotaResponse, 0, len(keys)+1)
+	add := func(apiKeyID, name string) bool {
+		usage, err := h.state.Quotas.Usage(ctx, gateway.QuotaKey(projectName, apiKeyID), now)
+		if err != nil {
+			slog.Error("Failed to read quota usage", "project", projectName, "error", err)
+			c.JSON(500, gin.H{"error": "failed to read quota usage"})
+			return false
+		}
+		spec := limits[apiKeyID]
+		_, dailyReset := gateway.QuotaWindow(gateway.QuotaDaily, now)
+		_, monthlyReset := gateway.QuotaWindow(gateway.QuotaMonthly, now)
+		res = append(res, quotaResponse{
+			ApiKeyID: apiKeyID,
+			Name:     name,
+			Daily:    quotaPeriodResponse{Limit: spec.Daily, Used: usage[gateway.QuotaDaily], Reset: dailyReset},
+			Monthly:  quotaPeriodResponse{Limit: spec.Monthly, Used: usage[gateway.QuotaMonthly], Reset: monthlyReset},
+		})
+		return true
+	}
+	if !add("", projectName) {
+		return
+	}
+	for _, k := range keys {
+		if k.RevokedAt.Valid {
+			continue
+		}
+		if !add(hex.EncodeToString(k.ID.Bytes[:]), k.Name) {
+			return
+		}
+	}
+	c.JSON(200, res)
+}
+
+// SetQuota sets the daily and monthly invocation limits of the project, or
+// of one of its api keys when api_key_id is given. 0 lifts a limit.
+func (h *QuotaHandlers) SetQuota(c *gin.Context) {
+	var req struct {
+		ApiKeyID string `json:"api_key_id"`
+		Daily    int64  `json:"daily"`
+		Monthly  int64  `json:"monthly"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid request"})
+		return
+	}
+	if req.Daily < 0 || req.Monthly < 0 {
+		c.JSON(400, gin.H{"error": "limits must be 0 or more"})
+		return
+	}
+	if req.Daily > 0 && req.Monthly > 0 && req.Daily > req.Monthly {
+		c.JSON(400, gin.H{"error": "the daily limit can't exceed the monthly limit"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.MustGet("userID").([]byte)
+	projectUUID := c.MustGet("projectUUID").(pgtype.UUID)
+	projectName := c.MustGet("projectName").(string)
+
+	tx, err := h.state.DBPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "error starting transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	role, err := accessAdaptors.New(tx).GetUserProjectRole(ctx, accessAdaptors.GetUserProjectRoleParams{
+		UserID:    userID,
+		ProjectID: projectUUID,
+	})
+	if err != nil || role != string(accessAdaptors.ProjectRoleOwner) {
+		c.JSON(403, gin.H{"error": "only owners can change quotas"})
+		return
+	}
+
+	q := quotaadaptors.New(tx)
+	var row quotaadaptors.ProjectQuota
+	target := "quota:" + projectName
+	if req.ApiKeyID == "" {
+		row, err = q.UpsertProjectQuota(ctx, quotaadaptors.UpsertProjectQuotaParams{
+			ProjectID:    projectUUID,
+			DailyLimit:   req.Daily,
+			MonthlyLimit: req.Monthly,
+			UpdatedBy:    userID,
+		})
+	} else {
+		keyID, ok := h.activeApiKey(c, tx, projectUUID, req.ApiKeyID)
+		if !ok {
+			return
+		}
+		target = "quota:apikey:" + req.ApiKeyID
+		row, err = q.UpsertApiKeyQuota(ctx, quotaadaptors.UpsertApiKeyQuotaParams{
+			ProjectID:    projectUUID,
+			ApiKeyID:     keyID,
+			DailyLimit:   req.Daily,
+			MonthlyLimit: req.Monthly,
+			UpdatedBy:    userID,
+		})
+	}
+	if err != nil {
+		slog.Error("Failed to save quota", "project", projectName, "error", err)
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	if err := audit.Record(ctx, tx, projectUUID, userID, "quota.update", target, gin.H{"daily": req.Daily, "monthly": req.Monthly}); err != nil {
+		slog.Error("Failed to record audit event", "action", "quota.update", "error", err)
+		c.JSON(500, gin.H{"error": "database error"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "failed to commit"})
+		return
+	}
+
+	spec := quota.Spec(projectName, row)
+	if err := h.state.Quotas.Publish(ctx, spec); err != nil {
+		slog.Error("Failed to publish quota", "project", projectName, "error", err)
+		c.JSON(500, gin.H{"error": "failed to publish quota"})
+		return
+	}
+	slog.Info("Quota updated", "project", projectName, "api_key_id", spec.ApiKeyID, "daily", spec.Daily, "monthly", spec.Monthly)
+	c.JSON(200, spec)
+}
+
+// activeApiKey resolves a hex api key id, answering 404 unless it is an
+// unrevoked key of the project.
+func (h *QuotaHandlers) activeApiKey(c *gin.Context, db apikeyadaptors.DBTX, projectUUID pgtype.UUID, idHex string) (pgtype.UUID, bool) {
+	keys, err := apikeyadaptors.New(db).ListApiKeysForProject(c.Request.Context(), projectUUID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "database error"})
+		return pgtype.UUID{}, false
+	}
+	for _, k := range keys {
+		if hex.EncodeToString(k.ID.Bytes[:]) == idHex && !k.RevokedAt.Valid {
+			return k.ID, true
+		}
+	}
+	c.JSON(404, gin.H{"error": "api key not found"})
+	return pgtype.UUID{}, false
+}
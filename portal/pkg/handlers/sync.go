@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/ashupednekar/litefunctions/portal/internal/endpoint"
 	endpointadaptors "github.com/ashupednekar/litefunctions/portal/internal/endpoint/adaptors"
 	functionadaptors "github.com/ashupednekar/litefunctions/portal/internal/function/adaptors"
 	"github.com/ashupednekar/litefunctions/portal/internal/project/repo"
@@ -23,7 +24,7 @@ var extLang = map[string]string{
 	".lua": "lua",
 }
 
-func SyncRepoFunctionsToDb(c *gin.Context, pool *pgxpool.Pool, projectUUID pgtype.UUID, projectName string, userID []byte) error {
+func SyncRepoFunctionsToDb(c *gin.Context, pool *pgxpool.Pool, endpoints *endpoint.Registry, projectUUID pgtype.UUID, projectName string, userID []byte) error {
 	r, err := repo.NewGitRepo(projectName, nil)
 	if err != nil {
 		return fmt.Errorf("failed to clone repo: %w", err)
@@ -89,7 +90,7 @@ func SyncRepoFunctionsToDb(c *gin.Context, pool *pgxpool.Pool, projectUUID pgtyp
 		// Ensure automatic endpoint: /project/name (GET)
 		if methods, ok := existingFnEps[fnID]; !ok || !methods["GET"] {
 			epPath := fmt.Sprintf("/%s/%s", projectName, fnName)
-			_, err = eq.CreateEndpoint(c.Request.Context(), endpointadaptors.CreateEndpointParams{
+			ep, err := eq.CreateEndpoint(c.Request.Context(), endpointadaptors.CreateEndpointParams{
 				ProjectID:  projectUUID,
 				Name:       epPath,
 				Method:     "GET",
@@ -100,6 +101,9 @@ func SyncRepoFunctionsToDb(c *gin.Context, pool *pgxpool.Pool, projectUUID pgtyp
 				slog.Warn("Failed to create automatic endpoint", "name", fnName, "error", err)
 			} else {
 				slog.Debug("Created missing automatic endpoint", "name", fnName, "endpoint", epPath)
+				if err := endpoints.Publish(c.Request.Context(), ep.ID); err != nil {
+					slog.Warn("Failed to publish endpoint", "name", fnName, "error", err)
+				}
 			}
 		}
 
@@ -1,13 +1,19 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ashupednekar/litefunctions/common/gateway"
 	accessAdaptors "github.com/ashupednekar/litefunctions/portal/internal/access/adaptors"
 	authAdaptors "github.com/ashupednekar/litefunctions/portal/internal/auth/adaptors"
 	endpointAdaptors "github.com/ashupednekar/litefunctions/portal/internal/endpoint/adaptors"
@@ -138,6 +144,70 @@ func (h *UIHandlers) Functions(ctx *gin.Context) {
 	}
 }
 
+// Runs lists a function's recent invocations with the filters of the
+// invocations API.
+func (h *UIHandlers) Runs(ctx *gin.Context) {
+	fn, ok := projectFunction(ctx, h.state)
+	if !ok {
+		return
+	}
+	filter := templates.RunsFilter{
+		Window: ctx.DefaultQuery("window", "24h"),
+		Status: ctx.Query("status"),
+		Source: ctx.Query("source"),
+		Cold:   ctx.Query("cold"),
+		Query:  ctx.Query("q"),
+	}
+	var runs []templates.Run
+	var summary templates.RunsSummary
+	params, err := invocationQuery(ctx, fn)
+	if err != nil {
+		filter.Error = err.Error()
+	} else if rows, sum, err := listInvocations(ctx, h.state, params); err != nil {
+		slog.Error("Failed to list invocations", "name", fn.Name, "error", err)
+		filter.Error = "failed to load runs"
+	} else {
+		summary = templates.RunsSummary(sum)
+		for _, row := range rows {
+			runs = append(runs, templates.Run{
+				RequestID: row.RequestID,
+				Method:    row.Method,
+				Endpoint:  row.Endpoint,
+				Source:    row.Source,
+				Status:    row.Status,
+				LatencyMs: row.LatencyMs,
+				ColdStart: row.ColdStart,
+				Async:     row.Async,
+				Bytes:     humanBytes(row.RequestBytes) + " / " + humanBytes(row.ResponseBytes),
+				Error:     row.Error,
+				StartedAt: row.StartedAt.Local().Format("Jan 2 15:04:05"),
+			})
+		}
+		if len(rows) == int(params.RowLimit) {
+			older := ctx.Request.URL.Query()
+			older.Set("before", rows[len(rows)-1].StartedAt.Format(time.RFC3339Nano))
+			filter.Older = ctx.Request.URL.Path + "?" + older.Encode()
+		}
+	}
+
+	page := templates.BaseLayout(
+		templates.RunsContent(hex.EncodeToString(fn.ID.Bytes[:]), fn.Name, filter, summary, runs),
+	)
+	if err := page.Render(ctx, ctx.Writer); err != nil {
+		slog.Error("page render failed", "error", err)
+	}
+}
+
+func humanBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
 func (h *UIHandlers) Data(ctx *gin.Context) {
 	page := templates.BaseLayout(
 		templates.DataContent(),
@@ -155,6 +225,87 @@ func (h *UIHandlers) Endpoints(ctx *gin.Context) {
 	var endpoints []templates.Endpoint
 	dbEps, err := q.ListEndpointsForProject(ctx.Request.Context(), projUUID)
 	if err == nil {
+		jwtConfigs, err := q.ListEndpointJwtConfigsForProject(ctx.Request.Context(), projUUID)
+		if err != nil {
+			slog.Error("failed to list endpoint jwt configs", "project", projUUID, "error", err)
+		}
+		jwtByEndpoint := make(map[pgtype.UUID]endpointAdaptors.EndpointJwtConfig, len(jwtConfigs))
+		for _, cfg := range jwtConfigs {
+			jwtByEndpoint[cfg.EndpointID] = cfg
+		}
+		policies, err := q.ListEndpointPoliciesForProject(ctx.Request.Context(), projUUID)
+		if err != nil {
+			slog.Error("failed to list endpoint policies", "project", projUUID, "error", err)
+		}
+		policyByEndpoint := make(map[pgtype.UUID]endpointAdaptors.EndpointPolicy, len(policies))
+		for _, pol := range policies {
+			policyByEndpoint[pol.EndpointID] = pol
+		}
+		corsConfigs, err := q.ListEndpointCorsConfigsForProject(ctx.Request.Context(), projUUID)
+		if err != nil {
+			slog.Error("failed to list endpoint cors configs", "project", projUUID, "error", err)
+		}
+		corsByEndpoint := make(map[pgtype.UUID]endpointAdaptors.EndpointCorsConfig, len(corsConfigs))
+		for _, cfg := range corsConfigs {
+			corsByEndpoint[cfg.EndpointID] = cfg
+		}
+		cacheConfigs, err := q.ListEndpointCacheConfigsForProject(ctx.Request.Context(), projUUID)
+		if err != nil {
+			slog.Error("failed to list endpoint cache configs", "project", projUUID, "error", err)
+		}
+		cacheByEndpoint := make(map[pgtype.UUID]endpointAdaptors.EndpointCacheConfig, len(cacheConfigs))
+		for _, cfg := range cacheConfigs {
+			cacheByEndpoint[cfg.EndpointID] = cfg
+		}
+		schemas, err := q.ListEndpointSchemasForProject(ctx.Request.Context(), projUUID)
+		if err != nil {
+			slog.Error("failed to list endpoint schemas", "project", projUUID, "error", err)
+		}
+		schemaByEndpoint := make(map[pgtype.UUID]endpointAdaptors.EndpointSchema, len(schemas))
+		for _, sch := range schemas {
+			schemaByEndpoint[sch.EndpointID] = sch
+		}
+		callbacks, err := q.ListEndpointCallbacksForProject(ctx.Request.Context(), projUUID)
+		if err != nil {
+			slog.Error("failed to list endpoint callbacks", "project", projUUID, "error", err)
+		}
+		callbackByEndpoint := make(map[pgtype.UUID]endpointAdaptors.EndpointCallback, len(callbacks))
+		for _, cb := range callbacks {
+			callbackByEndpoint[cb.EndpointID] = cb
+		}
+		faults, err := q.ListEndpointFaultsForProject(ctx.Request.Context(), projUUID)
+		if err != nil {
+			slog.Error("failed to list endpoint faults", "project", projUUID, "error", err)
+		}
+		faultByEndpoint := make(map[pgtype.UUID]endpointAdaptors.EndpointFault, len(faults))
+		for _, f := range faults {
+			faultByEndpoint[f.EndpointID] = f
+		}
+		transforms, err := q.ListEndpointTransformsForProject(ctx.Request.Context(), projUUID)
+		if err != nil {
+			slog.Error("failed to list endpoint transforms", "project", projUUID, "error", err)
+		}
+		transformByEndpoint := make(map[pgtype.UUID]endpointAdaptors.EndpointTransform, len(transforms))
+		for _, tr := range transforms {
+			transformByEndpoint[tr.EndpointID] = tr
+		}
+		priorities, err := q.ListEndpointPrioritiesForProject(ctx.Request.Context(), projUUID)
+		if err != nil {
+			slog.Error("failed to list endpoint priorities", "project", projUUID, "error", err)
+		}
+		priorityByEndpoint := make(map[pgtype.UUID]string, len(priorities))
+		for _, p := range priorities {
+			priorityByEndpoint[p.EndpointID] = p.Priority
+		}
+		middleware, err := q.ListEndpointMiddlewareForProject(ctx.Request.Context(), projUUID)
+		if err != nil {
+			slog.Error("failed to list endpoint middleware", "project", projUUID, "error", err)
+		}
+		middlewareByEndpoint := make(map[pgtype.UUID][]byte, len(middleware))
+		for _, mw := range middleware {
+			middlewareByEndpoint[mw.EndpointID] = mw.Chain
+		}
+
 		baseURL := strings.TrimRight(pkg.Cfg.IngestorUrl, "/")
 		for _, e := range dbEps {
 			// e.ID is available, e.Name is available, e.Method is available, e.Scope is available, e.FunctionName is available from join
@@ -167,6 +318,23 @@ func (h *UIHandlers) Endpoints(ctx *gin.Context) {
 				Language:     e.FunctionLanguage,
 				URL:          baseURL + e.Name,
 				IsAsync:      e.IsAsync,
+				JWT:          templateJwtConfig(jwtByEndpoint[e.ID]),
+				Policy:       templatePolicy(policyByEndpoint[e.ID]),
+				CORS:         templateCORS(corsByEndpoint[e.ID]),
+				Cache:        templateCache(cacheByEndpoint[e.ID]),
+				Schema:       templateSchema(schemaByEndpoint[e.ID]),
+				Callback: templates.EndpointCallback{
+					URL:            callbackByEndpoint[e.ID].Url,
+					AllowCallerURL: callbackByEndpoint[e.ID].AllowCallerUrl,
+					Secret:         callbackByEndpoint[e.ID].Secret,
+				},
+				Fault: templateFault(faultByEndpoint[e.ID]),
+				Transform: templates.EndpointTransform{
+					Request:  indentJSON(transformByEndpoint[e.ID].RequestRules),
+					Response: indentJSON(transformByEndpoint[e.ID].ResponseRules),
+				},
+				Priority:   priorityByEndpoint[e.ID],
+				Middleware: indentJSON(middlewareByEndpoint[e.ID]),
 			})
 		}
 	} else {
@@ -182,6 +350,100 @@ func (h *UIHandlers) Endpoints(ctx *gin.Context) {
 	}
 }
 
+// templateJwtConfig flattens stored JWT settings into the form fields shown
+// on the endpoints page.
+func templateJwtConfig(cfg endpointAdaptors.EndpointJwtConfig) templates.EndpointJWT {
+	var claims map[string]string
+	_ = json.Unmarshal(cfg.RequiredClaims, &claims)
+	lines := make([]string, 0, len(claims))
+	for k, v := range claims {
+		lines = append(lines, k+"="+v)
+	}
+	sort.Strings(lines)
+	return templates.EndpointJWT{
+		JwksURL:        cfg.JwksUrl,
+		Issuer:         cfg.Issuer,
+		Audiences:      strings.Join(cfg.Audiences, ", "),
+		RequiredClaims: strings.Join(lines, "\n"),
+	}
+}
+
+// templatePolicy renders policy rules one per line as "<effect> <expr>".
+func templatePolicy(pol endpointAdaptors.EndpointPolicy) templates.EndpointPolicy {
+	if !pol.EndpointID.Valid {
+		return templates.EndpointPolicy{Mode: "off", Default: gateway.EffectDeny}
+	}
+	var rules []gateway.PolicyRule
+	_ = json.Unmarshal(pol.Rules, &rules)
+	lines := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		lines = append(lines, rule.Effect+" "+rule.Expr)
+	}
+	return templates.EndpointPolicy{
+		Mode:    pol.Mode,
+		Default: pol.DefaultEffect,
+		Rules:   strings.Join(lines, "\n"),
+	}
+}
+
+func templateCORS(cfg endpointAdaptors.EndpointCorsConfig) templates.EndpointCORS {
+	out := templates.EndpointCORS{
+		Origins:     strings.Join(cfg.AllowedOrigins, ", "),
+		Methods:     strings.Join(cfg.AllowedMethods, ", "),
+		Headers:     strings.Join(cfg.AllowedHeaders, ", "),
+		Exposed:     strings.Join(cfg.ExposedHeaders, ", "),
+		Credentials: cfg.AllowCredentials,
+	}
+	if cfg.MaxAge > 0 {
+		out.MaxAge = fmt.Sprint(cfg.MaxAge)
+	}
+	return out
+}
+
+func templateCache(cfg endpointAdaptors.EndpointCacheConfig) templates.EndpointCache {
+	out := templates.EndpointCache{
+		VaryHeaders: strings.Join(cfg.VaryHeaders, ", "),
+		VaryQuery:   strings.Join(cfg.VaryQuery, ", "),
+	}
+	if cfg.TtlSeconds > 0 {
+		out.TTL = fmt.Sprint(cfg.TtlSeconds)
+	}
+	return out
+}
+
+// templateFault leaves the form empty once a fault has expired; the ingestor
+// no longer applies it.
+func templateFault(f endpointAdaptors.EndpointFault) templates.EndpointFault {
+	if !f.ExpiresAt.Valid || time.Now().After(f.ExpiresAt.Time) {
+		return templates.EndpointFault{}
+	}
+	out := templates.EndpointFault{
+		Percentage: strconv.FormatFloat(f.Percentage, 'f', -1, 64),
+		DropAsync:  f.DropAsync,
+		Header:     f.Header,
+		ExpiresAt:  f.ExpiresAt.Time.UTC().Format("2006-01-02 15:04 MST"),
+	}
+	if f.DelayMs > 0 {
+		out.DelayMs = fmt.Sprint(f.DelayMs)
+	}
+	if f.ErrorStatus > 0 {
+		out.ErrorStatus = fmt.Sprint(f.ErrorStatus)
+	}
+	return out
+}
+
+func templateSchema(sch endpointAdaptors.EndpointSchema) templates.EndpointSchema {
+	return templates.EndpointSchema{Body: indentJSON(sch.BodySchema), Query: indentJSON(sch.QuerySchema)}
+}
+
+func indentJSON(raw []byte) string {
+	var buf bytes.Buffer
+	if len(raw) == 0 || json.Indent(&buf, raw, "", "  ") != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
 func (h *UIHandlers) Configuration(ctx *gin.Context) {
 	page := templates.BaseLayout(
 		templates.ConfigurationContent(),
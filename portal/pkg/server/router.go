@@ -53,6 +53,7 @@ func (s *Server) BuildRoutes() {
 	{
 		protected.GET("/configuration/", ui.Configuration)
 		protected.GET("/functions/", ui.Functions)
+		protected.GET("/functions/:fnID/runs/", ui.Runs)
 		protected.GET("/endpoints/", ui.Endpoints)
 		protected.GET("/data/", ui.Data)
 	}
@@ -74,6 +75,11 @@ func (s *Server) BuildRoutes() {
 	{
 		functionHandlers := handlers.NewFunctionHandlers(s.state)
 		endpointHandlers := handlers.NewEndpointHandlers(s.state)
+		apiKeyHandlers := handlers.NewApiKeyHandlers(s.state)
+		maintenanceHandlers := handlers.NewMaintenanceHandlers(s.state)
+		domainHandlers := handlers.NewDomainHandlers(s.state)
+		quotaHandlers := handlers.NewQuotaHandlers(s.state)
+		invocationHandlers := handlers.NewInvocationHandlers(s.state)
 		actionHandlers := handlers.NewActionHandlers()
 
 		api.GET("/projects/", projectHandlers.ListProjects)
@@ -91,10 +97,30 @@ func (s *Server) BuildRoutes() {
 		api.GET("/functions/:fnID/", functionHandlers.GetFunction)
 		api.PUT("/functions/:fnID/", functionHandlers.UpdateFunction)
 		api.DELETE("/functions/:fnID/", functionHandlers.DeleteFunction)
+		api.GET("/functions/:fnID/invocations/", invocationHandlers.ListInvocations)
 
 		api.GET("/endpoints/", endpointHandlers.ListEndpoints)
 		api.GET("/endpoints/:epID/", endpointHandlers.GetEndpoint)
 		api.PUT("/endpoints/:epID/", endpointHandlers.UpdateEndpoint)
+		api.POST("/endpoints/:epID/cache/purge/", endpointHandlers.PurgeEndpointCache)
+		api.GET("/endpoints/:epID/callbacks/", endpointHandlers.ListCallbackDeliveries)
+		api.GET("/openapi.json", endpointHandlers.OpenAPI)
+
+		api.GET("/apikeys/", apiKeyHandlers.ListApiKeys)
+		api.POST("/apikeys/", apiKeyHandlers.CreateApiKey)
+		api.DELETE("/apikeys/:keyID/", apiKeyHandlers.RevokeApiKey)
+
+		api.GET("/maintenance/", maintenanceHandlers.ListMaintenance)
+		api.PUT("/maintenance/", maintenanceHandlers.SetMaintenance)
+		api.DELETE("/maintenance/", maintenanceHandlers.ClearMaintenance)
+		api.GET("/audit/", maintenanceHandlers.ListAuditEvents)
+
+		api.GET("/domains/", domainHandlers.ListDomains)
+		api.POST("/domains/", domainHandlers.AddDomain)
+		api.DELETE("/domains/:host/", domainHandlers.RemoveDomain)
+
+		api.GET("/quotas/", quotaHandlers.ListQuotas)
+		api.PUT("/quotas/", quotaHandlers.SetQuota)
 
 		api.GET("/actions/status/", actionHandlers.Status)
 
@@ -1,7 +1,10 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/ashupednekar/litefunctions/portal/pkg"
 	"github.com/ashupednekar/litefunctions/portal/pkg/state"
@@ -25,9 +28,40 @@ func NewServer() (*Server, error) {
 		state:  state,
 	}
 	s.BuildRoutes()
+	s.syncGateway()
 	return s, nil
 }
 
+// syncGateway brings the ingestor-facing KV buckets in line with the database
+// and starts recording api key usage and invocations reported back by
+// ingestors.
+func (s *Server) syncGateway() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.state.Endpoints.Resync(ctx); err != nil {
+		slog.Error("failed to resync endpoints", "error", err)
+	}
+	if err := s.state.ApiKeys.Resync(ctx); err != nil {
+		slog.Error("failed to resync api keys", "error", err)
+	}
+	if err := s.state.Maintenance.Resync(ctx); err != nil {
+		slog.Error("failed to resync maintenance flags", "error", err)
+	}
+	if err := s.state.Domains.Resync(ctx); err != nil {
+		slog.Error("failed to resync domains", "error", err)
+	}
+	if err := s.state.Quotas.Resync(ctx); err != nil {
+		slog.Error("failed to resync quotas", "error", err)
+	}
+	if _, err := s.state.ApiKeys.ConsumeUsage(s.state.Nc); err != nil {
+		slog.Error("failed to subscribe to api key usage", "error", err)
+	}
+	if _, err := s.state.Invocations.Consume(context.Background()); err != nil {
+		slog.Error("failed to consume invocations", "error", err)
+	}
+	go s.state.Invocations.Prune(context.Background(), time.Hour)
+}
+
 func (s *Server) Start() {
 	s.router.Run(fmt.Sprintf("0.0.0.0:%d", s.Port))
 }
@@ -0,0 +1,29 @@
+package connections
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/ashupednekar/litefunctions/portal/pkg"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+var (
+	Nc       *nats.Conn
+	Js       jetstream.JetStream
+	natsOnce sync.Once
+	natsErr  error
+)
+
+func ConnectNats() error {
+	slog.Info("NATS connecting", "url", pkg.Cfg.NatsUrl)
+	natsOnce.Do(func() {
+		Nc, natsErr = nats.Connect(pkg.Cfg.NatsUrl, nats.Name("litefunctions-portal"))
+		if natsErr != nil {
+			return
+		}
+		Js, natsErr = jetstream.New(Nc)
+	})
+	return natsErr
+}
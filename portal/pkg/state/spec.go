@@ -1,17 +1,36 @@
 package state
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"github.com/ashupednekar/litefunctions/common/policy"
+	"github.com/ashupednekar/litefunctions/portal/internal/apikey"
 	"github.com/ashupednekar/litefunctions/portal/internal/auth"
+	"github.com/ashupednekar/litefunctions/portal/internal/domain"
+	"github.com/ashupednekar/litefunctions/portal/internal/endpoint"
+	"github.com/ashupednekar/litefunctions/portal/internal/invocation"
+	"github.com/ashupednekar/litefunctions/portal/internal/maintenance"
+	"github.com/ashupednekar/litefunctions/portal/internal/quota"
+	"github.com/ashupednekar/litefunctions/portal/pkg"
 	"github.com/ashupednekar/litefunctions/portal/pkg/state/connections"
 	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go"
 )
 
 type AppState struct {
-	Authn  *webauthn.WebAuthn
-	DBPool *pgxpool.Pool
+	Authn       *webauthn.WebAuthn
+	DBPool      *pgxpool.Pool
+	Nc          *nats.Conn
+	Endpoints   *endpoint.Registry
+	ApiKeys     *apikey.Registry
+	Maintenance *maintenance.Registry
+	Domains     *domain.Registry
+	Quotas      *quota.Registry
+	Invocations *invocation.Recorder
+	Policies    *policy.Engine
 }
 
 func NewState() (*AppState, error) {
@@ -20,5 +39,54 @@ func NewState() (*AppState, error) {
 		return nil, fmt.Errorf("couldn't initialize state - webauthn: %s", err)
 	}
 	connections.ConnectDB()
-	return &AppState{Authn: authn, DBPool: connections.DBPool}, nil
+	if err := connections.ConnectNats(); err != nil {
+		return nil, fmt.Errorf("couldn't initialize state - nats: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	endpoints, err := endpoint.NewRegistry(ctx, connections.Js, connections.DBPool)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialize state - endpoints: %s", err)
+	}
+	apiKeys, err := apikey.NewRegistry(ctx, connections.Js, connections.DBPool)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialize state - api keys: %s", err)
+	}
+	maint, err := maintenance.NewRegistry(ctx, connections.Js, connections.DBPool)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialize state - maintenance: %s", err)
+	}
+	domains, err := domain.NewRegistry(ctx, connections.Js, connections.DBPool)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialize state - domains: %s", err)
+	}
+	quotas, err := quota.NewRegistry(ctx, connections.Js, connections.DBPool)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialize state - quotas: %s", err)
+	}
+	retention, err := time.ParseDuration(pkg.Cfg.InvocationRetention)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialize state - invocation retention: %s", err)
+	}
+	invocations, err := invocation.NewRecorder(ctx, connections.Js, connections.DBPool, retention)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialize state - invocations: %s", err)
+	}
+	policies, err := policy.NewEngine()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialize state - policies: %s", err)
+	}
+	return &AppState{
+		Authn:       authn,
+		DBPool:      connections.DBPool,
+		Nc:          connections.Nc,
+		Endpoints:   endpoints,
+		ApiKeys:     apiKeys,
+		Maintenance: maint,
+		Domains:     domains,
+		Quotas:      quotas,
+		Invocations: invocations,
+		Policies:    policies,
+	}, nil
 }
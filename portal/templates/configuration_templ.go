@@ -29,7 +29,7 @@ func ConfigurationContent() templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"w-full px-6 md:px-14 py-12 space-y-10\"><!-- HEADER --><div class=\"flex items-center justify-between\"><div class=\"flex items-center gap-4\"><a href=\"/dashboard/\" class=\"p-2 hover:bg-neutral-800 rounded-lg transition\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"w-5 h-5 text-neutral-400\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M15 19l-7-7 7-7\"></path></svg></a><h1 class=\"text-4xl font-semibold text-white tracking-tight\">Configuration</h1></div><button onclick=\"addConfigRow()\" class=\"bg-blue-600 hover:bg-blue-700 text-white font-semibold px-4 py-2 rounded-xl transition\">New Config</button></div><p class=\"text-neutral-400 text-sm -mt-4\">Project-level configuration & encrypted secrets.</p><!-- CONFIG LIST --><div id=\"config-list\" class=\"space-y-3\"><!-- Example Row --><div class=\"config-item border border-neutral-800 bg-[#0e0e0f] rounded-2xl p-4\"><div class=\"flex items-center justify-between cursor-pointer\" onclick=\"toggleConfig(this)\"><div><h3 class=\"text-white font-semibold text-lg\">APP_MODE</h3><p class=\"text-neutral-500 text-sm\">production</p></div><img src=\"/static/imgs/arrow-down.svg\" class=\"w-5 h-5 opacity-60 rotate-0 transition-transform\"></div><!-- EXPANDED EDITOR --><div class=\"config-body hidden mt-4 space-y-3\"><!-- Key --><div><label class=\"text-neutral-400 text-sm\">Key</label> <input class=\"cfg-key w-full mt-1 p-2 bg-[#0c0c0d] border border-neutral-700 rounded-xl text-white\"></div><!-- Value --><div><label class=\"text-neutral-400 text-sm\">Value</label> <textarea class=\"cfg-value w-full h-32 mt-1 p-2 bg-[#0c0c0d] border border-neutral-700 rounded-xl text-white\"></textarea></div><!-- Options --><label class=\"flex items-center gap-2 text-neutral-300 text-sm\"><input type=\"checkbox\" class=\"cfg-encrypted\"> Encrypted</label><!-- Save/Cancel --><div class=\"flex justify-end gap-3 pt-2\"><button onclick=\"cancelConfigEdit(this)\" class=\"px-4 py-2 rounded-lg border border-neutral-700 text-neutral-300 hover:bg-neutral-800\">Cancel</button> <button onclick=\"saveConfig(this)\" class=\"px-4 py-2 rounded-lg bg-blue-600 hover:bg-blue-700 text-white\">Save</button></div></div></div></div></div><script>\n  /* Toggle expand/collapse */\n  function toggleConfig(el) {\n    const body = el.parentElement.querySelector(\".config-body\");\n    const arrow = el.querySelector(\"img\");\n\n    if (body.classList.contains(\"hidden\")) {\n      body.classList.remove(\"hidden\");\n      arrow.style.transform = \"rotate(180deg)\";\n    } else {\n      body.classList.add(\"hidden\");\n      arrow.style.transform = \"rotate(0deg)\";\n    }\n  }\n\n  /* Add a new empty row */\n  function addConfigRow() {\n    const list = document.getElementById(\"config-list\");\n\n    const div = document.createElement(\"div\");\n    div.className = \"config-item border border-neutral-800 bg-[#0e0e0f] rounded-2xl p-4\";\n\n    div.innerHTML = `\n\t\t<div class=\"flex items-center justify-between cursor-pointer\" onclick=\"toggleConfig(this)\">\n\t\t\t<div>\n\t\t\t\t<h3 class=\"text-white font-semibold text-lg\">New Key</h3>\n\t\t\t\t<p class=\"text-neutral-500 text-sm\">Click to edit…</p>\n\t\t\t</div>\n\t\t\t<img src=\"/static/imgs/arrow-down.svg\" class=\"w-5 h-5 opacity-60 rotate-0 transition-transform\"/>\n\t\t</div>\n\n\t\t<div class=\"config-body mt-4 space-y-3\">\n\t\t\t<div>\n\t\t\t\t<label class=\"text-neutral-400 text-sm\">Key</label>\n\t\t\t\t<input class=\"cfg-key w-full mt-1 p-2 bg-[#0c0c0d] border border-neutral-700 rounded-xl text-white\"/>\n\t\t\t</div>\n\n\t\t\t<div>\n\t\t\t\t<label class=\"text-neutral-400 text-sm\">Value</label>\n\t\t\t\t<textarea class=\"cfg-value w-full h-32 mt-1 p-2 bg-[#0c0c0d] border border-neutral-700 rounded-xl text-white\"></textarea>\n\t\t\t</div>\n\n\t\t\t<label class=\"flex items-center gap-2 text-neutral-300 text-sm\">\n\t\t\t\t<input type=\"checkbox\" class=\"cfg-encrypted\"/>\n\t\t\t\tEncrypted\n\t\t\t</label>\n\n\t\t\t<div class=\"flex justify-end gap-3 pt-2\">\n\t\t\t\t<button onclick=\"cancelConfigEdit(this)\" \n\t\t\t\t\tclass=\"px-4 py-2 rounded-lg border border-neutral-700 text-neutral-300 hover:bg-neutral-800\">\n\t\t\t\t\tCancel\n\t\t\t\t</button>\n\t\t\t\t<button onclick=\"saveConfig(this)\" \n\t\t\t\t\tclass=\"px-4 py-2 rounded-lg bg-blue-600 hover:bg-blue-700 text-white\">\n\t\t\t\t\tSave\n\t\t\t\t</button>\n\t\t\t</div>\n\t\t</div>`;\n\n    list.prepend(div);\n  }\n\n  /* Cancel editing */\n  function cancelConfigEdit(btn) {\n    const body = btn.closest(\".config-body\");\n    body.classList.add(\"hidden\");\n\n    const arrow = body.parentElement.querySelector(\"img\");\n    arrow.style.transform = \"rotate(0deg)\";\n  }\n\n  /* Save logic placeholder */\n  function saveConfig(btn) {\n    const container = btn.closest(\".config-item\");\n    const key = container.querySelector(\".cfg-key\").value;\n    const val = container.querySelector(\".cfg-value\").value;\n    const enc = container.querySelector(\".cfg-encrypted\").checked;\n\n    console.log(\"TODO: Send save to backend\", {key, val, enc});\n\n    // update collapsed summary UI\n    container.querySelector(\"h3\").textContent = key || \"Unnamed\";\n    container.querySelector(\"p\").textContent = enc ? \"(encrypted)\" : val.slice(0, 50);\n\n    cancelConfigEdit(btn);\n  }\n</script><style>\n  .config-item {\n    transition: border-color 0.2s, background-color 0.2s;\n  }\n\n  .config-item:hover {\n    border-color: #666;\n    background-color: #141416;\n  }\n</style>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"w-full px-6 md:px-14 py-12 space-y-10\"><!-- HEADER --><div class=\"flex items-center justify-between\"><div class=\"flex items-center gap-4\"><a href=\"/dashboard/\" class=\"p-2 hover:bg-neutral-800 rounded-lg transition\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"w-5 h-5 text-neutral-400\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M15 19l-7-7 7-7\"></path></svg></a><h1 class=\"text-4xl font-semibold text-white tracking-tight\">Configuration</h1></div><button onclick=\"addConfigRow()\" class=\"bg-blue-600 hover:bg-blue-700 text-white font-semibold px-4 py-2 rounded-xl transition\">New Config</button></div><p class=\"text-neutral-400 text-sm -mt-4\">Project-level configuration & encrypted secrets.</p><!-- CONFIG LIST --><div id=\"config-list\" class=\"space-y-3\"><!-- Example Row --><div class=\"config-item border border-neutral-800 bg-[#0e0e0f] rounded-2xl p-4\"><div class=\"flex items-center justify-between cursor-pointer\" onclick=\"toggleConfig(this)\"><div><h3 class=\"text-white font-semibold text-lg\">APP_MODE</h3><p class=\"text-neutral-500 text-sm\">production</p></div><img src=\"/static/imgs/arrow-down.svg\" class=\"w-5 h-5 opacity-60 rotate-0 transition-transform\"></div><!-- EXPANDED EDITOR --><div class=\"config-body hidden mt-4 space-y-3\"><!-- Key --><div><label class=\"text-neutral-400 text-sm\">Key</label> <input class=\"cfg-key w-full mt-1 p-2 bg-[#0c0c0d] border border-neutral-700 rounded-xl text-white\"></div><!-- Value --><div><label class=\"text-neutral-400 text-sm\">Value</label> <textarea class=\"cfg-value w-full h-32 mt-1 p-2 bg-[#0c0c0d] border border-neutral-700 rounded-xl text-white\"></textarea></div><!-- Options --><label class=\"flex items-center gap-2 text-neutral-300 text-sm\"><input type=\"checkbox\" class=\"cfg-encrypted\"> Encrypted</label><!-- Save/Cancel --><div class=\"flex justify-end gap-3 pt-2\"><button onclick=\"cancelConfigEdit(this)\" class=\"px-4 py-2 rounded-lg border border-neutral-700 text-neutral-300 hover:bg-neutral-800\">Cancel</button> <button onclick=\"saveConfig(this)\" class=\"px-4 py-2 rounded-lg bg-blue-600 hover:bg-blue-700 text-white\">Save</button></div></div></div></div><!-- API KEYS --><div class=\"space-y-4\"><div class=\"flex items-center justify-between\"><div><h2 class=\"text-2xl font-semibold text-white tracking-tight\">API Keys</h2><p class=\"text-neutral-400 text-sm\">Keys for calling authn endpoints programmatically via the <span class=\"font-mono\">X-Api-Key</span> header.</p></div><button onclick=\"toggleApiKeyForm()\" class=\"bg-blue-600 hover:bg-blue-700 text-white font-semibold px-4 py-2 rounded-xl transition\">New Key</button></div><div id=\"apikey-form\" class=\"hidden border border-neutral-800 bg-[#0e0e0f] rounded-2xl p-4 space-y-3\"><div><label class=\"text-neutral-400 text-sm\">Name</label> <input id=\"apikey-name\" class=\"w-full mt-1 p-2 bg-[#0c0c0d] border border-neutral-700 rounded-xl text-white\" placeholder=\"ci-deployer\"></div><div><label class=\"text-neutral-400 text-sm\">Expires in</label> <select id=\"apikey-expiry\" class=\"w-full mt-1 p-2 bg-[#0c0c0d] border border-neutral-700 rounded-xl text-white\"><option value=\"\">Never</option> <option value=\"720h\">30 days</option> <option value=\"2160h\">90 days</option> <option value=\"8760h\">1 year</option></select></div><div><label class=\"text-neutral-400 text-sm\">Endpoints (none selected = all endpoints)</label><div id=\"apikey-endpoints\" class=\"mt-1 grid grid-cols-1 md:grid-cols-2 gap-2\"></div></div><div class=\"flex justify-end gap-3 pt-2\"><button onclick=\"toggleApiKeyForm()\" class=\"px-4 py-2 rounded-lg border border-neutral-700 text-neutral-300 hover:bg-neutral-800\">Cancel</button> <button onclick=\"createApiKey()\" class=\"px-4 py-2 rounded-lg bg-blue-600 hover:bg-blue-700 text-white\">Create</button></div></div><div id=\"apikey-created\" class=\"hidden border border-green-700/60 bg-green-700/10 rounded-2xl p-4\"><p class=\"text-green-300 text-sm mb-2\">Copy this key now, it will not be shown again.</p><code id=\"apikey-plaintext\" class=\"block text-white font-mono text-sm break-all\"></code></div><div id=\"apikey-list\" class=\"space-y-3\"></div></div><!-- MAINTENANCE --><div class=\"space-y-4\"><div><h2 class=\"text-2xl font-semibold text-white tracking-tight\">Maintenance</h2><p class=\"text-neutral-400 text-sm\">Stop traffic to a function, or the whole project, without deleting it. Callers get a 503 with the message below and nothing is activated.</p></div><div class=\"border border-neutral-800 bg-[#0e0e0f] rounded-2xl p-4 space-y-3\"><input id=\"maintenance-message\" class=\"w-full p-2 bg-[#0c0c0d] border border-neutral-700 rounded-xl text-white\" placeholder=\"Message shown to callers (optional)\"><div id=\"maintenance-list\" class=\"space-y-2\"></div></div></div><!-- CUSTOM DOMAINS --><div class=\"space-y-4\"><div><h2 class=\"text-2xl font-semibold text-white tracking-tight\">Custom Domains</h2><p class=\"text-neutral-400 text-sm\">Serve this project's functions from your own hostname, e.g. <code class=\"text-neutral-300\">https://api.example.com/v1/orders</code> instead of <code class=\"text-neutral-300\">/lambda/&lt;project&gt;/orders</code>. Point the host at the gateway and add it to <code class=\"text-neutral-300\">gatewayApi.hosts.functions</code> so it gets a listener and certificate.</p></div><div class=\"border border-neutral-800 bg-[#0e0e0f] rounded-2xl p-4 flex flex-col md:flex-row gap-3\"><input id=\"domain-host\" class=\"flex-1 p-2 bg-[#0c0c0d] border border-neutral-700 rounded-xl text-white\" placeholder=\"api.example.com\"> <input id=\"domain-base-path\" class=\"md:w-48 p-2 bg-[#0c0c0d] border border-neutral-700 rounded-xl text-white\" placeholder=\"Base path (optional)\"> <button onclick=\"addDomain()\" class=\"px-4 py-2 rounded-xl bg-blue-600 hover:bg-blue-500 text-white text-sm font-semibold\">Add Domain</button></div><div id=\"domain-list\" class=\"space-y-2\"></div></div><!-- QUOTAS --><div class=\"space-y-4\"><div><h2 class=\"text-2xl font-semibold text-white tracking-tight\">Quotas</h2><p class=\"text-neutral-400 text-sm\">Cap how many invocations the project, or a single api key, may make per day and per month. Callers see what is left in <code class=\"text-neutral-300\">X-Quota-Remaining</code> and get a 429 once it runs out. Leave a limit at 0 for no cap; only owners can change them.</p></div><div id=\"quota-list\" class=\"space-y-2\"></div></div><!-- AUDIT LOG --><div class=\"space-y-4\"><div><h2 class=\"text-2xl font-semibold text-white tracking-tight\">Audit Log</h2><p class=\"text-neutral-400 text-sm\">Recent changes to this project.</p></div><div id=\"audit-list\" class=\"space-y-2\"></div></div></div><script>\n  /* API keys */\n  function toggleApiKeyForm() {\n    const form = document.getElementById(\"apikey-form\");\n    form.classList.toggle(\"hidden\");\n    if (!form.classList.contains(\"hidden\")) loadApiKeyEndpoints();\n  }\n\n  function loadApiKeyEndpoints() {\n    fetch(\"/api/endpoints/\").then(res => res.json()).then(eps => {\n      const box = document.getElementById(\"apikey-endpoints\");\n      box.innerHTML = \"\";\n      (eps || []).forEach(ep => {\n        const label = document.createElement(\"label\");\n        label.className = \"flex items-center gap-2 text-neutral-300 text-sm\";\n        const cb = document.createElement(\"input\");\n        cb.type = \"checkbox\";\n        cb.value = ep.ID;\n        cb.className = \"apikey-endpoint\";\n        label.appendChild(cb);\n        label.appendChild(document.createTextNode(ep.Method + \" \" + ep.Name));\n        box.appendChild(label);\n      });\n    });\n  }\n\n  function createApiKey() {\n    const name = document.getElementById(\"apikey-name\").value.trim();\n    if (!name) {\n      toast(\"Name is required\", \"error\");\n      return;\n    }\n    const endpointIDs = Array.from(document.querySelectorAll(\".apikey-endpoint:checked\")).map(cb => cb.value);\n    fetch(\"/api/apikeys/\", {\n      method: \"POST\",\n      headers: {\"Content-Type\": \"application/json\"},\n      body: JSON.stringify({\n        name: name,\n        endpoint_ids: endpointIDs,\n        expires_in: document.getElementById(\"apikey-expiry\").value\n      })\n    }).then(res => res.json().then(data => ({ok: res.ok, data}))).then(({ok, data}) => {\n      if (!ok) {\n        toast(data.error || \"Failed to create key\", \"error\");\n        return;\n      }\n      document.getElementById(\"apikey-plaintext\").textContent = data.key;\n      document.getElementById(\"apikey-created\").classList.remove(\"hidden\");\n      document.getElementById(\"apikey-form\").classList.add(\"hidden\");\n      loadApiKeys();\n    });\n  }\n\n  function revokeApiKey(id) {\n    if (!confirm(\"Revoke this key? Clients using it will be rejected immediately.\")) return;\n    fetch(\"/api/apikeys/\" + id + \"/\", {method: \"DELETE\"}).then(res => {\n      if (res.ok) {\n        toast(\"Key revoked\", \"success\");\n        loadApiKeys();\n      } else {\n        toast(\"Revoke failed\", \"error\");\n      }\n    });\n  }\n\n  function formatDate(value) {\n    return value ? new Date(value).toLocaleString() : \"never\";\n  }\n\n  function loadApiKeys() {\n    fetch(\"/api/apikeys/\").then(res => res.json()).then(keys => {\n      const list = document.getElementById(\"apikey-list\");\n      list.innerHTML = \"\";\n      if (!keys || keys.length === 0) {\n        list.innerHTML = `<p class=\"text-neutral-500 text-sm\">No API keys yet.</p>`;\n        return;\n      }\n      keys.forEach(k => {\n        const row = document.createElement(\"div\");\n        row.className = \"config-item border border-neutral-800 bg-[#0e0e0f] rounded-2xl p-4 flex items-center justify-between\";\n        const info = document.createElement(\"div\");\n        const title = document.createElement(\"h3\");\n        title.className = \"text-white font-semibold text-lg\";\n        title.textContent = k.name;\n        const meta = document.createElement(\"p\");\n        meta.className = \"text-neutral-500 text-sm font-mono\";\n        const scope = k.endpoint_ids.length ? k.endpoint_ids.length + \" endpoint(s)\" : \"all endpoints\";\n        meta.textContent = k.prefix + \"… · \" + scope + \" · expires \" + formatDate(k.expires_at) + \" · last used \" + formatDate(k.last_used_at);\n        info.appendChild(title);\n        info.appendChild(meta);\n        row.appendChild(info);\n        if (k.revoked_at) {\n          const badge = document.createElement(\"span\");\n          badge.className = \"text-xs text-red-400 font-semibold\";\n          badge.textContent = \"revoked\";\n          row.appendChild(badge);\n        } else {\n          const btn = document.createElement(\"button\");\n          btn.className = \"px-3 py-1.5 rounded-lg border border-red-700/60 text-red-400 hover:bg-red-700/10 text-xs font-semibold\";\n          btn.textContent = \"Revoke\";\n          btn.onclick = () => revokeApiKey(k.id);\n          row.appendChild(btn);\n        }\n        list.appendChild(row);\n      });\n    });\n  }\n\n  document.addEventListener(\"DOMContentLoaded\", loadApiKeys);\n\n  /* Maintenance */\n  function setMaintenance(functionID, enable) {\n    const req = enable\n      ? fetch(\"/api/maintenance/\", {\n          method: \"PUT\",\n          headers: {\"Content-Type\": \"application/json\"},\n          body: JSON.stringify({function_id: functionID, message: document.getElementById(\"maintenance-message\").value.trim()})\n        })\n      : fetch(\"/api/maintenance/?function_id=\" + encodeURIComponent(functionID), {method: \"DELETE\"});\n    req.then(res => {\n      if (res.ok) {\n        toast(enable ? \"Maintenance enabled\" : \"Maintenance cleared\", \"success\");\n        loadMaintenance();\n        loadAuditLog();\n      } else {\n        res.json().then(body => toast(body.error || \"Update failed\", \"error\")).catch(() => toast(\"Update failed\", \"error\"));\n      }\n    });\n  }\n\n  function maintenanceRow(label, functionID, flag) {\n    const row = document.createElement(\"div\");\n    row.className = \"flex items-center justify-between border border-neutral-800 rounded-xl p-3\";\n    const info = document.createElement(\"div\");\n    const title = document.createElement(\"p\");\n    title.className = \"text-white text-sm font-semibold\";\n    title.textContent = label;\n    const meta = document.createElement(\"p\");\n    meta.className = \"text-neutral-500 text-xs\";\n    meta.textContent = flag ? \"in maintenance since \" + formatDate(flag.enabled_at) + (flag.message ? \" · \" + flag.message : \"\") : \"serving traffic\";\n    info.appendChild(title);\n    info.appendChild(meta);\n    const btn = document.createElement(\"button\");\n    btn.className = flag\n      ? \"px-3 py-1.5 rounded-lg border border-green-700/60 text-green-400 hover:bg-green-700/10 text-xs font-semibold\"\n      : \"px-3 py-1.5 rounded-lg border border-red-700/60 text-red-400 hover:bg-red-700/10 text-xs font-semibold\";\n    btn.textContent = flag ? \"Resume\" : \"Disable\";\n    btn.onclick = () => setMaintenance(functionID, !flag);\n    row.appendChild(info);\n    row.appendChild(btn);\n    return row;\n  }\n\n  function loadMaintenance() {\n    Promise.all([\n      fetch(\"/api/maintenance/\").then(res => res.json()),\n      fetch(\"/api/functions/\").then(res => res.json())\n    ]).then(([flags, fns]) => {\n      const byFunction = {};\n      (flags || []).forEach(f => byFunction[f.function_id || \"\"] = f);\n      const list = document.getElementById(\"maintenance-list\");\n      list.innerHTML = \"\";\n      list.appendChild(maintenanceRow(\"Entire project\", \"\", byFunction[\"\"]));\n      (fns || []).forEach(fn => list.appendChild(maintenanceRow(fn.name, fn.id, byFunction[fn.id])));\n    });\n  }\n\n  function loadAuditLog() {\n    fetch(\"/api/audit/\").then(res => res.json()).then(events => {\n      const list = document.getElementById(\"audit-list\");\n      list.innerHTML = \"\";\n      if (!events || events.length === 0) {\n        list.innerHTML = `<p class=\"text-neutral-500 text-sm\">No changes recorded yet.</p>`;\n        return;\n      }\n      events.forEach(e => {\n        const row = document.createElement(\"p\");\n        row.className = \"text-neutral-400 text-sm font-mono\";\n        row.textContent = formatDate(e.created_at) + \" · \" + (e.actor || \"unknown\") + \" · \" + e.action + \" · \" + e.target;\n        list.appendChild(row);\n      });\n    });\n  }\n\n  document.addEventListener(\"DOMContentLoaded\", loadMaintenance);\n\n  /* Custom domains */\n  function addDomain() {\n    fetch(\"/api/domains/\", {\n      method: \"POST\",\n      headers: {\"Content-Type\": \"application/json\"},\n      body: JSON.stringify({\n        host: document.getElementById(\"domain-host\").value.trim(),\n        base_path: document.getElementById(\"domain-base-path\").value.trim()\n      })\n    }).then(res => {\n      if (res.ok) {\n        toast(\"Domain added\", \"success\");\n        document.getElementById(\"domain-host\").value = \"\";\n        document.getElementById(\"domain-base-path\").value = \"\";\n        loadDomains();\n        loadAuditLog();\n      } else {\n        res.json().then(body => toast(body.error || \"Failed to add domain\", \"error\")).catch(() => toast(\"Failed to add domain\", \"error\"));\n      }\n    });\n  }\n\n  function removeDomain(host) {\n    if (!confirm(\"Stop serving this project from \" + host + \"?\")) return;\n    fetch(\"/api/domains/\" + encodeURIComponent(host) + \"/\", {method: \"DELETE\"}).then(res => {\n      if (res.ok) {\n        toast(\"Domain removed\", \"success\");\n        loadDomains();\n        loadAuditLog();\n      } else {\n        res.json().then(body => toast(body.error || \"Failed to remove domain\", \"error\")).catch(() => toast(\"Failed to remove domain\", \"error\"));\n      }\n    });\n  }\n\n  function loadDomains() {\n    fetch(\"/api/domains/\").then(res => res.json()).then(domains => {\n      const list = document.getElementById(\"domain-list\");\n      list.innerHTML = \"\";\n      if (!domains || domains.length === 0) {\n        list.innerHTML = `<p class=\"text-neutral-500 text-sm\">No custom domains yet.</p>`;\n        return;\n      }\n      domains.forEach(d => {\n        const row = document.createElement(\"div\");\n        row.className = \"flex items-center justify-between border border-neutral-800 rounded-xl p-3\";\n        const url = document.createElement(\"p\");\n        url.className = \"text-white text-sm font-mono\";\n        url.textContent = d.url;\n        const btn = document.createElement(\"button\");\n        btn.className = \"px-3 py-1.5 rounded-lg border border-red-700/60 text-red-400 hover:bg-red-700/10 text-xs font-semibold\";\n        btn.textContent = \"Remove\";\n        btn.onclick = () => removeDomain(d.host);\n        row.appendChild(url);\n        row.appendChild(btn);\n        list.appendChild(row);\n      });\n    });\n  }\n\n  /* Quotas */\n  function quotaUsage(period) {\n    if (!period.limit) return period.used + \" used, no limit\";\n    return period.used + \" / \" + period.limit + \" used\";\n  }\n\n  function saveQuota(row, apiKeyID) {\n    fetch(\"/api/quotas/\", {\n      method: \"PUT\",\n      headers: {\"Content-Type\": \"application/json\"},\n      body: JSON.stringify({\n        api_key_id: apiKeyID,\n        daily: parseInt(row.querySelector(\".quota-daily\").value || \"0\", 10),\n        monthly: parseInt(row.querySelector(\".quota-monthly\").value || \"0\", 10)\n      })\n    }).then(res => {\n      if (res.ok) {\n        toast(\"Quota saved\", \"success\");\n        loadQuotas();\n        loadAuditLog();\n      } else {\n        res.json().then(body => toast(body.error || \"Failed to save quota\", \"error\")).catch(() => toast(\"Failed to save quota\", \"error\"));\n      }\n    });\n  }\n\n  function loadQuotas() {\n    fetch(\"/api/quotas/\").then(res => res.json()).then(quotas => {\n      const list = document.getElementById(\"quota-list\");\n      list.innerHTML = \"\";\n      (quotas || []).forEach(q => {\n        const row = document.createElement(\"div\");\n        row.className = \"flex flex-col md:flex-row md:items-center justify-between gap-3 border border-neutral-800 rounded-xl p-3\";\n        const info = document.createElement(\"div\");\n        const name = document.createElement(\"p\");\n        name.className = \"text-white text-sm font-semibold\";\n        name.textContent = q.api_key_id ? \"API key: \" + q.name : \"Project: \" + q.name;\n        const usage = document.createElement(\"p\");\n        usage.className = \"text-neutral-500 text-xs\";\n        usage.textContent = \"Today \" + quotaUsage(q.daily) + \" · This month \" + quotaUsage(q.monthly) + \" · Daily resets \" + formatDate(q.daily.reset);\n        info.appendChild(name);\n        info.appendChild(usage);\n\n        const form = document.createElement(\"div\");\n        form.className = \"flex items-center gap-2\";\n        [\"daily\", \"monthly\"].forEach(period => {\n          const input = document.createElement(\"input\");\n          input.type = \"number\";\n          input.min = \"0\";\n          input.value = q[period].limit;\n          input.title = period === \"daily\" ? \"Daily limit\" : \"Monthly limit\";\n          input.className = \"quota-\" + period + \" w-28 p-1.5 bg-[#0c0c0d] border border-neutral-700 rounded-lg text-white text-sm\";\n          form.appendChild(input);\n        });\n        const btn = document.createElement(\"button\");\n        btn.className = \"px-3 py-1.5 rounded-lg bg-blue-600 hover:bg-blue-500 text-white text-xs font-semibold\";\n        btn.textContent = \"Save\";\n        btn.onclick = () => saveQuota(row, q.api_key_id || \"\");\n        form.appendChild(btn);\n\n        row.appendChild(info);\n        row.appendChild(form);\n        list.appendChild(row);\n      });\n    });\n  }\n\n  document.addEventListener(\"DOMContentLoaded\", loadDomains);\n  document.addEventListener(\"DOMContentLoaded\", loadQuotas);\n  document.addEventListener(\"DOMContentLoaded\", loadAuditLog);\n\n  /* Toggle expand/collapse */\n  function toggleConfig(el) {\n    const body = el.parentElement.querySelector(\".config-body\");\n    const arrow = el.querySelector(\"img\");\n\n    if (body.classList.contains(\"hidden\")) {\n      body.classList.remove(\"hidden\");\n      arrow.style.transform = \"rotate(180deg)\";\n    } else {\n      body.classList.add(\"hidden\");\n      arrow.style.transform = \"rotate(0deg)\";\n    }\n  }\n\n  /* Add a new empty row */\n  function addConfigRow() {\n    const list = document.getElementById(\"config-list\");\n\n    const div = document.createElement(\"div\");\n    div.className = \"config-item border border-neutral-800 bg-[#0e0e0f] rounded-2xl p-4\";\n\n    div.innerHTML = `\n\t\t<div class=\"flex items-center justify-between cursor-pointer\" onclick=\"toggleConfig(this)\">\n\t\t\t<div>\n\t\t\t\t<h3 class=\"text-white font-semibold text-lg\">New Key</h3>\n\t\t\t\t<p class=\"text-neutral-500 text-sm\">Click to edit…</p>\n\t\t\t</div>\n\t\t\t<img src=\"/static/imgs/arrow-down.svg\" class=\"w-5 h-5 opacity-60 rotate-0 transition-transform\"/>\n\t\t</div>\n\n\t\t<div class=\"config-body mt-4 space-y-3\">\n\t\t\t<div>\n\t\t\t\t<label class=\"text-neutral-400 text-sm\">Key</label>\n\t\t\t\t<input class=\"cfg-key w-full mt-1 p-2 bg-[#0c0c0d] border border-neutral-700 rounded-xl text-white\"/>\n\t\t\t</div>\n\n\t\t\t<div>\n\t\t\t\t<label class=\"text-neutral-400 text-sm\">Value</label>\n\t\t\t\t<textarea class=\"cfg-value w-full h-32 mt-1 p-2 bg-[#0c0c0d] border border-neutral-700 rounded-xl text-white\"></textarea>\n\t\t\t</div>\n\n\t\t\t<label class=\"flex items-center gap-2 text-neutral-300 text-sm\">\n\t\t\t\t<input type=\"checkbox\" class=\"cfg-encrypted\"/>\n\t\t\t\tEncrypted\n\t\t\t</label>\n\n\t\t\t<div class=\"flex justify-end gap-3 pt-2\">\n\t\t\t\t<button onclick=\"cancelConfigEdit(this)\" \n\t\t\t\t\tclass=\"px-4 py-2 rounded-lg border border-neutral-700 text-neutral-300 hover:bg-neutral-800\">\n\t\t\t\t\tCancel\n\t\t\t\t</button>\n\t\t\t\t<button onclick=\"saveConfig(this)\" \n\t\t\t\t\tclass=\"px-4 py-2 rounded-lg bg-blue-600 hover:bg-blue-700 text-white\">\n\t\t\t\t\tSave\n\t\t\t\t</button>\n\t\t\t</div>\n\t\t</div>`;\n\n    list.prepend(div);\n  }\n\n  /* Cancel editing */\n  function cancelConfigEdit(btn) {\n    const body = btn.closest(\".config-body\");\n    body.classList.add(\"hidden\");\n\n    const arrow = body.parentElement.querySelector(\"img\");\n    arrow.style.transform = \"rotate(0deg)\";\n  }\n\n  /* Save logic placeholder */\n  function saveConfig(btn) {\n    const container = btn.closest(\".config-item\");\n    const key = container.querySelector(\".cfg-key\").value;\n    const val = container.querySelector(\".cfg-value\").value;\n    const enc = container.querySelector(\".cfg-encrypted\").checked;\n\n    console.log(\"TODO: Send save to backend\", {key, val, enc});\n\n    // update collapsed summary UI\n    container.querySelector(\"h3\").textContent = key || \"Unnamed\";\n    container.querySelector(\"p\").textContent = enc ? \"(encrypted)\" : val.slice(0, 50);\n\n    cancelConfigEdit(btn);\n  }\n</script><style>\n  .config-item {\n    transition: border-color 0.2s, background-color 0.2s;\n  }\n\n  .config-item:hover {\n    border-color: #666;\n    background-color: #141416;\n  }\n</style>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -17,6 +17,69 @@ type Endpoint struct {
 	Language     string
 	URL          string
 	IsAsync      bool
+	JWT          EndpointJWT
+	Policy       EndpointPolicy
+	CORS         EndpointCORS
+	Cache        EndpointCache
+	Schema       EndpointSchema
+	Callback     EndpointCallback
+	Fault        EndpointFault
+	Transform    EndpointTransform
+	Priority     string
+	Middleware   string
+}
+
+type EndpointJWT struct {
+	JwksURL        string
+	Issuer         string
+	Audiences      string
+	RequiredClaims string
+}
+
+type EndpointCORS struct {
+	Origins     string
+	Methods     string
+	Headers     string
+	Exposed     string
+	Credentials bool
+	MaxAge      string
+}
+
+type EndpointCache struct {
+	TTL         string
+	VaryHeaders string
+	VaryQuery   string
+}
+
+type EndpointSchema struct {
+	Body  string
+	Query string
+}
+
+type EndpointCallback struct {
+	URL            string
+	AllowCallerURL bool
+	Secret         string
+}
+
+type EndpointFault struct {
+	Percentage  string
+	DelayMs     string
+	ErrorStatus string
+	DropAsync   bool
+	Header      string
+	ExpiresAt   string
+}
+
+type EndpointTransform struct {
+	Request  string
+	Response string
+}
+
+type EndpointPolicy struct {
+	Mode    string
+	Default string
+	Rules   string
 }
 
 func toggleManageEndpoint(id string) templ.ComponentScript {
@@ -106,30 +169,141 @@ if(c) c.classList.add("border-blue-500", "bg-blue-500/10");
 
 func saveEndpointSettings(id string, scope string) templ.ComponentScript {
 	return templ.ComponentScript{
-		Name: `__templ_saveEndpointSettings_7528`,
-		Function: `function __templ_saveEndpointSettings_7528(id, scope){const method = document.getElementById("selected-method-" + id).value;
+		Name: `__templ_saveEndpointSettings_847c`,
+		Function: `function __templ_saveEndpointSettings_847c(id, scope){const method = document.getElementById("selected-method-" + id).value;
 const authEl = document.getElementById("auth-" + id);
 let newScope = scope;
 if (authEl) {
 const authVal = authEl.value;
 if (authVal === "No Auth") newScope = "public";
 else if (authVal.includes("API Key")) newScope = "authn";
+else if (authVal === "JWT") newScope = "jwt";
+}
+const payload = {method: method, scope: newScope};
+if (newScope === "jwt") {
+const claims = {};
+document.getElementById("jwt-claims-" + id).value.split("\n").forEach(line => {
+line = line.trim();
+if (!line) return;
+const idx = line.indexOf("=");
+if (idx < 0) claims[line] = "";
+else claims[line.slice(0, idx).trim()] = line.slice(idx + 1).trim();
+});
+payload.jwt = {
+jwks_url: document.getElementById("jwt-jwks-" + id).value.trim(),
+issuer: document.getElementById("jwt-issuer-" + id).value.trim(),
+audiences: document.getElementById("jwt-aud-" + id).value.split(",").map(a => a.trim()).filter(a => a),
+required_claims: claims
+};
+}
+const splitList = (el) => document.getElementById(el + id).value.split(",").map(v => v.trim()).filter(v => v);
+payload.cors = {
+allowed_origins: splitList("cors-origins-"),
+allowed_methods: splitList("cors-methods-").map(m => m.toUpperCase()),
+allowed_headers: splitList("cors-headers-"),
+exposed_headers: splitList("cors-exposed-"),
+allow_credentials: document.getElementById("cors-credentials-" + id).checked,
+max_age: parseInt(document.getElementById("cors-maxage-" + id).value || "0", 10)
+};
+payload.cache = {
+ttl: parseInt(document.getElementById("cache-ttl-" + id).value || "0", 10),
+vary_headers: splitList("cache-headers-"),
+vary_query: splitList("cache-query-")
+};
+if (document.getElementById("callback-url-" + id)) {
+payload.callback = {
+url: document.getElementById("callback-url-" + id).value.trim(),
+allow_caller_url: document.getElementById("callback-caller-" + id).checked,
+rotate_secret: document.getElementById("callback-rotate-" + id).checked
+};
+}
+if (document.getElementById("priority-" + id)) {
+payload.priority = document.getElementById("priority-" + id).value;
+}
+const faultPct = parseFloat(document.getElementById("fault-pct-" + id).value || "0");
+payload.fault = {percentage: faultPct};
+if (faultPct > 0) {
+const dropEl = document.getElementById("fault-drop-" + id);
+payload.fault.delay_ms = parseInt(document.getElementById("fault-delay-" + id).value || "0", 10);
+payload.fault.error_status = parseInt(document.getElementById("fault-status-" + id).value || "0", 10);
+payload.fault.drop_async = dropEl ? dropEl.checked : false;
+payload.fault.header = document.getElementById("fault-header-" + id).value.trim();
+payload.fault.expires_in = document.getElementById("fault-expiry-" + id).value;
+}
+const parseSchema = (el) => {
+const raw = document.getElementById(el + id).value.trim();
+return raw ? JSON.parse(raw) : null;
+};
+try {
+payload.schema = {body: parseSchema("schema-body-"), query: parseSchema("schema-query-")};
+} catch (e) {
+toast("Schema is not valid JSON", "error");
+return;
+}
+try {
+payload.transform = {request: parseSchema("transform-request-"), response: parseSchema("transform-response-")};
+} catch (e) {
+toast("Transform rules are not valid JSON", "error");
+return;
+}
+try {
+payload.middleware = parseSchema("middleware-") || [];
+} catch (e) {
+toast("Middleware is not valid JSON", "error");
+return;
+}
+const policyMode = document.getElementById("policy-mode-" + id).value;
+payload.policy = {mode: policyMode};
+if (policyMode !== "off") {
+payload.policy.default = document.getElementById("policy-default-" + id).value;
+payload.policy.rules = document.getElementById("policy-rules-" + id).value.split("\n").map(line => line.trim()).filter(line => line).map(line => {
+const idx = line.indexOf(" ");
+return idx < 0 ? {effect: line, expr: ""} : {effect: line.slice(0, idx), expr: line.slice(idx + 1).trim()};
+});
 }
 fetch("/api/endpoints/" + id + "/", {
 method: "PUT",
 headers: {"Content-Type": "application/json"},
-body: JSON.stringify({method: method, scope: newScope})
+body: JSON.stringify(payload)
 }).then(res => {
 if (res.ok) {
 toast("Endpoint updated!", "success");
 setTimeout(() => location.reload(), 500);
 } else {
-toast("Update failed", "error");
+res.json().then(body => toast(body.error || "Update failed", "error")).catch(() => toast("Update failed", "error"));
+}
+});
+}`,
+		Call:       templ.SafeScript(`__templ_saveEndpointSettings_847c`, id, scope),
+		CallInline: templ.SafeScriptInline(`__templ_saveEndpointSettings_847c`, id, scope),
+	}
+}
+
+func purgeEndpointCache(id string) templ.ComponentScript {
+	return templ.ComponentScript{
+		Name: `__templ_purgeEndpointCache_5310`,
+		Function: `function __templ_purgeEndpointCache_5310(id){fetch("/api/endpoints/" + id + "/cache/purge/", {method: "POST"}).then(res => {
+if (!res.ok) {
+toast("Purge failed", "error");
+return;
 }
+res.json().then(body => toast("Purged " + body.purged + " cached responses", "success"));
 });
 }`,
-		Call:       templ.SafeScript(`__templ_saveEndpointSettings_7528`, id, scope),
-		CallInline: templ.SafeScriptInline(`__templ_saveEndpointSettings_7528`, id, scope),
+		Call:       templ.SafeScript(`__templ_purgeEndpointCache_5310`, id),
+		CallInline: templ.SafeScriptInline(`__templ_purgeEndpointCache_5310`, id),
+	}
+}
+
+func toggleJwtSettings(id string) templ.ComponentScript {
+	return templ.ComponentScript{
+		Name: `__templ_toggleJwtSettings_a340`,
+		Function: `function __templ_toggleJwtSettings_a340(id){const authEl = document.getElementById("auth-" + id);
+const panel = document.getElementById("jwt-settings-" + id);
+if (authEl && panel) panel.classList.toggle("hidden", authEl.value !== "JWT");
+}`,
+		Call:       templ.SafeScript(`__templ_toggleJwtSettings_a340`, id),
+		CallInline: templ.SafeScriptInline(`__templ_toggleJwtSettings_a340`, id),
 	}
 }
 
@@ -190,7 +364,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var2 string
 			templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(ep.ID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 240, Col: 29}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 402, Col: 29}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
 			if templ_7745c5c3_Err != nil {
@@ -203,7 +377,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var3 string
 			templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Name)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 241, Col: 33}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 403, Col: 33}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
 			if templ_7745c5c3_Err != nil {
@@ -216,7 +390,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var4 string
 			templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Method)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 242, Col: 37}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 404, Col: 37}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 			if templ_7745c5c3_Err != nil {
@@ -229,7 +403,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var5 string
 			templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(ep.FunctionName)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 243, Col: 45}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 405, Col: 45}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
 			if templ_7745c5c3_Err != nil {
@@ -242,7 +416,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var6 string
 			templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Language)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 244, Col: 41}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 406, Col: 41}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
 			if templ_7745c5c3_Err != nil {
@@ -255,7 +429,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var7 string
 			templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(ep.IsAsync)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 245, Col: 37}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 407, Col: 37}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
 			if templ_7745c5c3_Err != nil {
@@ -274,7 +448,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 				templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(
 					ep.Method)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 252, Col: 21}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 414, Col: 21}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
 				if templ_7745c5c3_Err != nil {
@@ -293,7 +467,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 				templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(
 					ep.Method)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 257, Col: 21}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 419, Col: 21}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
 				if templ_7745c5c3_Err != nil {
@@ -311,7 +485,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 				var templ_7745c5c3_Var10 string
 				templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Method)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 261, Col: 20}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 423, Col: 20}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
 				if templ_7745c5c3_Err != nil {
@@ -329,7 +503,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 				var templ_7745c5c3_Var11 string
 				templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Method)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 265, Col: 20}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 427, Col: 20}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
 				if templ_7745c5c3_Err != nil {
@@ -347,7 +521,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var12 string
 			templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Name)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 268, Col: 61}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 430, Col: 61}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
 			if templ_7745c5c3_Err != nil {
@@ -360,7 +534,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var13 string
 			templ_7745c5c3_Var13, templ_7745c5c3_Err = templ.JoinStringErrs("/static/imgs/" + ep.Language + "-svgrepo-com.svg")
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 269, Col: 68}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 431, Col: 68}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var13))
 			if templ_7745c5c3_Err != nil {
@@ -373,7 +547,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var14 string
 			templ_7745c5c3_Var14, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Language)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 269, Col: 117}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 431, Col: 117}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var14))
 			if templ_7745c5c3_Err != nil {
@@ -386,7 +560,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var15 string
 			templ_7745c5c3_Var15, templ_7745c5c3_Err = templ.JoinStringErrs(ep.FunctionName)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 271, Col: 25}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 433, Col: 25}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var15))
 			if templ_7745c5c3_Err != nil {
@@ -407,7 +581,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var16 string
 			templ_7745c5c3_Var16, templ_7745c5c3_Err = templ.JoinStringErrs("ws-test-btn-" + ep.ID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 276, Col: 35}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 438, Col: 35}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var16))
 			if templ_7745c5c3_Err != nil {
@@ -437,7 +611,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var18 string
 			templ_7745c5c3_Var18, templ_7745c5c3_Err = templ.JoinStringErrs("test-btn-" + ep.ID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 287, Col: 32}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 449, Col: 32}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var18))
 			if templ_7745c5c3_Err != nil {
@@ -459,7 +633,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var20 string
 			templ_7745c5c3_Var20, templ_7745c5c3_Err = templ.JoinStringErrs("build-status-" + ep.ID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 297, Col: 36}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 459, Col: 36}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var20))
 			if templ_7745c5c3_Err != nil {
@@ -472,7 +646,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var21 string
 			templ_7745c5c3_Var21, templ_7745c5c3_Err = templ.JoinStringErrs("build-step-" + ep.ID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 306, Col: 34}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 468, Col: 34}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var21))
 			if templ_7745c5c3_Err != nil {
@@ -493,7 +667,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var22 string
 			templ_7745c5c3_Var22, templ_7745c5c3_Err = templ.JoinStringErrs("test-btn-" + ep.ID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 310, Col: 32}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 472, Col: 32}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var22))
 			if templ_7745c5c3_Err != nil {
@@ -532,7 +706,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var25 string
 			templ_7745c5c3_Var25, templ_7745c5c3_Err = templ.JoinStringErrs("endpoint-" + ep.ID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 329, Col: 34}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 491, Col: 34}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var25))
 			if templ_7745c5c3_Err != nil {
@@ -545,7 +719,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var26 string
 			templ_7745c5c3_Var26, templ_7745c5c3_Err = templ.JoinStringErrs("selected-method-" + ep.ID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 331, Col: 58}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 493, Col: 58}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var26))
 			if templ_7745c5c3_Err != nil {
@@ -558,7 +732,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var27 string
 			templ_7745c5c3_Var27, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Method)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 331, Col: 78}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 493, Col: 78}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var27))
 			if templ_7745c5c3_Err != nil {
@@ -600,7 +774,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 						var templ_7745c5c3_Var29 string
 						templ_7745c5c3_Var29, templ_7745c5c3_Err = templ.JoinStringErrs("method-" + m + "-" + ep.ID)
 						if templ_7745c5c3_Err != nil {
-							return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 346, Col: 44}
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 508, Col: 44}
 						}
 						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var29))
 						if templ_7745c5c3_Err != nil {
@@ -614,7 +788,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 						templ_7745c5c3_Var30, templ_7745c5c3_Err = templ.JoinStringErrs(
 							m)
 						if templ_7745c5c3_Err != nil {
-							return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 350, Col: 16}
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 512, Col: 16}
 						}
 						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var30))
 						if templ_7745c5c3_Err != nil {
@@ -645,7 +819,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 						var templ_7745c5c3_Var32 string
 						templ_7745c5c3_Var32, templ_7745c5c3_Err = templ.JoinStringErrs("method-" + m + "-" + ep.ID)
 						if templ_7745c5c3_Err != nil {
-							return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 355, Col: 44}
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 517, Col: 44}
 						}
 						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var32))
 						if templ_7745c5c3_Err != nil {
@@ -659,7 +833,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 						templ_7745c5c3_Var33, templ_7745c5c3_Err = templ.JoinStringErrs(
 							m)
 						if templ_7745c5c3_Err != nil {
-							return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 359, Col: 16}
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 521, Col: 16}
 						}
 						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var33))
 						if templ_7745c5c3_Err != nil {
@@ -700,7 +874,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var35 string
 			templ_7745c5c3_Var35, templ_7745c5c3_Err = templ.JoinStringErrs("gateway-liteginx-" + ep.ID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 373, Col: 41}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 535, Col: 41}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var35))
 			if templ_7745c5c3_Err != nil {
@@ -730,7 +904,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var37 string
 			templ_7745c5c3_Var37, templ_7745c5c3_Err = templ.JoinStringErrs("gateway-nginx-" + ep.ID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 381, Col: 38}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 543, Col: 38}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var37))
 			if templ_7745c5c3_Err != nil {
@@ -760,7 +934,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var39 string
 			templ_7745c5c3_Var39, templ_7745c5c3_Err = templ.JoinStringErrs("gateway-envoy-" + ep.ID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 389, Col: 38}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 551, Col: 38}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var39))
 			if templ_7745c5c3_Err != nil {
@@ -790,7 +964,7 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var41 string
 			templ_7745c5c3_Var41, templ_7745c5c3_Err = templ.JoinStringErrs("gateway-traefik-" + ep.ID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 397, Col: 40}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 559, Col: 40}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var41))
 			if templ_7745c5c3_Err != nil {
@@ -803,74 +977,1168 @@ func EndpointsContent(endpoints []Endpoint) templ.Component {
 			var templ_7745c5c3_Var42 string
 			templ_7745c5c3_Var42, templ_7745c5c3_Err = templ.JoinStringErrs("rl-" + ep.ID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 413, Col: 27}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 575, Col: 27}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var42))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 62, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-40 focus:border-blue-500 outline-none transition\" placeholder=\"req/min\"> <button class=\"bg-blue-600 hover:bg-blue-700 text-white px-5 py-2.5 rounded-xl font-semibold transition shadow-lg shadow-blue-500/20\">Save</button></div></div><!-- AUTH CONTROLS --><div><h4 class=\"text-white font-semibold mb-2\">Authentication</h4><select id=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 62, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-40 focus:border-blue-500 outline-none transition\" placeholder=\"req/min\"> <button class=\"bg-blue-600 hover:bg-blue-700 text-white px-5 py-2.5 rounded-xl font-semibold transition shadow-lg shadow-blue-500/20\">Save</button></div></div><!-- AUTH CONTROLS --><div><h4 class=\"text-white font-semibold mb-2\">Authentication</h4>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderScriptItems(ctx, templ_7745c5c3_Buffer, toggleJwtSettings(ep.ID))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 63, "<select id=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			var templ_7745c5c3_Var43 string
 			templ_7745c5c3_Var43, templ_7745c5c3_Err = templ.JoinStringErrs("auth-" + ep.ID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 428, Col: 28}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 590, Col: 28}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var43))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 63, "\" class=\"bg-[#0b0b0c] p-3 border border-neutral-800 rounded-xl text-white w-full focus:border-blue-500 outline-none transition appearance-none\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 64, "\" onchange=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var44 templ.ComponentScript = toggleJwtSettings(ep.ID)
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var44.Call)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 65, "\" class=\"bg-[#0b0b0c] p-3 border border-neutral-800 rounded-xl text-white w-full focus:border-blue-500 outline-none transition appearance-none\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			if ep.Scope == "public" {
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 64, "<option selected>No Auth</option> ")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 66, "<option selected>No Auth</option> ")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 			} else {
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 65, "<option>No Auth</option> ")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 67, "<option>No Auth</option> ")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 			}
 			if ep.Scope == "authn" {
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 66, "<option selected>API Key (LWS Auth)</option> ")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 68, "<option selected>API Key (LWS Auth)</option> ")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			} else {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 69, "<option>API Key (LWS Auth)</option> ")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			if ep.Scope == "jwt" {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 70, "<option selected>JWT</option> ")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 			} else {
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 67, "<option>API Key (LWS Auth)</option> ")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 71, "<option>JWT</option> ")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 68, "<option>JWT</option> <option>Session</option></select></div><!-- SAVE BUTTON --><div class=\"pt-4 border-t border-neutral-800/50 flex justify-end\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 72, "<option>Session</option></select>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templ.RenderScriptItems(ctx, templ_7745c5c3_Buffer, saveEndpointSettings(ep.ID, ep.Scope))
+			var templ_7745c5c3_Var45 = []any{"mt-4 space-y-3", templ.KV("hidden", ep.Scope != "jwt")}
+			templ_7745c5c3_Err = templ.RenderCSSItems(ctx, templ_7745c5c3_Buffer, templ_7745c5c3_Var45...)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 69, "<button onclick=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 73, "<div id=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var44 templ.ComponentScript = saveEndpointSettings(ep.ID, ep.Scope)
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var44.Call)
+			var templ_7745c5c3_Var46 string
+			templ_7745c5c3_Var46, templ_7745c5c3_Err = templ.JoinStringErrs("jwt-settings-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 611, Col: 40}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var46))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 74, "\" class=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var47 string
+			templ_7745c5c3_Var47, templ_7745c5c3_Err = templ.JoinStringErrs(templ.CSSClasses(templ_7745c5c3_Var45).String())
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 1, Col: 0}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var47))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 75, "\"><p class=\"text-neutral-500 text-sm\">Bearer tokens are verified at the ingestor before the function is activated.</p><input type=\"url\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var48 string
+			templ_7745c5c3_Var48, templ_7745c5c3_Err = templ.JoinStringErrs("jwt-jwks-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 615, Col: 33}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var48))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 76, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var49 string
+			templ_7745c5c3_Var49, templ_7745c5c3_Err = templ.JoinStringErrs(ep.JWT.JwksURL)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 616, Col: 31}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var49))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 77, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"JWKS URL (https://issuer/.well-known/jwks.json)\"> <input type=\"text\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var50 string
+			templ_7745c5c3_Var50, templ_7745c5c3_Err = templ.JoinStringErrs("jwt-issuer-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 622, Col: 35}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var50))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 78, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var51 string
+			templ_7745c5c3_Var51, templ_7745c5c3_Err = templ.JoinStringErrs(ep.JWT.Issuer)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 623, Col: 30}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var51))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 79, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"Issuer (optional)\"> <input type=\"text\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var52 string
+			templ_7745c5c3_Var52, templ_7745c5c3_Err = templ.JoinStringErrs("jwt-aud-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 629, Col: 32}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var52))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 80, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var53 string
+			templ_7745c5c3_Var53, templ_7745c5c3_Err = templ.JoinStringErrs(ep.JWT.Audiences)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 630, Col: 33}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var53))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 81, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"Accepted audiences, comma separated (optional)\"> <textarea id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var54 string
+			templ_7745c5c3_Var54, templ_7745c5c3_Err = templ.JoinStringErrs("jwt-claims-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 635, Col: 35}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var54))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 82, "\" rows=\"3\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full font-mono text-sm focus:border-blue-500 outline-none transition\" placeholder=\"Required claims, one per line: claim=value (or just claim)\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var55 string
+			templ_7745c5c3_Var55, templ_7745c5c3_Err = templ.JoinStringErrs(ep.JWT.RequiredClaims)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 639, Col: 32}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var55))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 83, "</textarea></div></div><!-- CORS --><div><h4 class=\"text-white font-semibold mb-2\">CORS</h4><p class=\"text-neutral-500 text-sm mb-3\">Applied by the ingestor, preflight requests are answered without waking the function. Leave origins empty to disable.</p><div class=\"grid grid-cols-1 md:grid-cols-2 gap-3\"><input type=\"text\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var56 string
+			templ_7745c5c3_Var56, templ_7745c5c3_Err = templ.JoinStringErrs("cors-origins-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 649, Col: 37}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var56))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 84, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var57 string
+			templ_7745c5c3_Var57, templ_7745c5c3_Err = templ.JoinStringErrs(ep.CORS.Origins)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 650, Col: 32}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var57))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 85, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"Allowed origins (https://app.example.com, https://*.example.com)\"> <input type=\"text\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var58 string
+			templ_7745c5c3_Var58, templ_7745c5c3_Err = templ.JoinStringErrs("cors-methods-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 656, Col: 37}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var58))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 86, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var59 string
+			templ_7745c5c3_Var59, templ_7745c5c3_Err = templ.JoinStringErrs(ep.CORS.Methods)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 657, Col: 32}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var59))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 87, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"Allowed methods (defaults to the endpoint method)\"> <input type=\"text\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var60 string
+			templ_7745c5c3_Var60, templ_7745c5c3_Err = templ.JoinStringErrs("cors-headers-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 663, Col: 37}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var60))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 88, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var61 string
+			templ_7745c5c3_Var61, templ_7745c5c3_Err = templ.JoinStringErrs(ep.CORS.Headers)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 664, Col: 32}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var61))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 89, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"Allowed request headers (Content-Type, Authorization or *)\"> <input type=\"text\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var62 string
+			templ_7745c5c3_Var62, templ_7745c5c3_Err = templ.JoinStringErrs("cors-exposed-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 670, Col: 37}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var62))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 90, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var63 string
+			templ_7745c5c3_Var63, templ_7745c5c3_Err = templ.JoinStringErrs(ep.CORS.Exposed)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 671, Col: 32}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var63))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 91, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"Exposed response headers\"> <input type=\"number\" min=\"0\" max=\"86400\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var64 string
+			templ_7745c5c3_Var64, templ_7745c5c3_Err = templ.JoinStringErrs("cors-maxage-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 679, Col: 36}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var64))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 92, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var65 string
+			templ_7745c5c3_Var65, templ_7745c5c3_Err = templ.JoinStringErrs(ep.CORS.MaxAge)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 680, Col: 31}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var65))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 93, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"Preflight max age (seconds)\"> <label class=\"flex items-center gap-2 text-neutral-300 text-sm\"><input type=\"checkbox\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var66 string
+			templ_7745c5c3_Var66, templ_7745c5c3_Err = templ.JoinStringErrs("cors-credentials-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 685, Col: 64}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var66))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 94, "\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if ep.CORS.Credentials {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 95, " checked")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 96, "> Allow credentials</label></div></div><!-- RESPONSE CACHE --><div><h4 class=\"text-white font-semibold mb-2\">Response Cache</h4><p class=\"text-neutral-500 text-sm mb-3\">Cache GET responses at the ingestor, shared by every replica. A shorter <code class=\"text-neutral-300\">Cache-Control</code> max-age from the function wins. Leave the TTL empty to disable.</p><div class=\"grid grid-cols-1 md:grid-cols-3 gap-3\"><input type=\"number\" min=\"0\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var67 string
+			templ_7745c5c3_Var67, templ_7745c5c3_Err = templ.JoinStringErrs("cache-ttl-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 698, Col: 34}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var67))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 97, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var68 string
+			templ_7745c5c3_Var68, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Cache.TTL)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 699, Col: 29}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var68))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 98, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"TTL (seconds)\"> <input type=\"text\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var69 string
+			templ_7745c5c3_Var69, templ_7745c5c3_Err = templ.JoinStringErrs("cache-headers-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 705, Col: 38}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var69))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 99, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var70 string
+			templ_7745c5c3_Var70, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Cache.VaryHeaders)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 706, Col: 37}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var70))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 100, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"Vary by headers (Accept-Language)\"> <input type=\"text\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var71 string
+			templ_7745c5c3_Var71, templ_7745c5c3_Err = templ.JoinStringErrs("cache-query-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 712, Col: 36}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var71))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 101, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var72 string
+			templ_7745c5c3_Var72, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Cache.VaryQuery)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 713, Col: 35}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var72))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 102, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"Vary by query params (default: all)\"></div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderScriptItems(ctx, templ_7745c5c3_Buffer, purgeEndpointCache(ep.ID))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 103, "<button onclick=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var73 templ.ComponentScript = purgeEndpointCache(ep.ID)
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var73.Call)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 104, "\" class=\"mt-3 border border-neutral-700 hover:border-neutral-500 text-neutral-300 hover:text-white px-4 py-2 rounded-xl text-sm transition\">Purge cache</button></div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if ep.IsAsync {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 105, "<!-- PRIORITY --> <div><h4 class=\"text-white font-semibold mb-2\">Async Priority</h4><p class=\"text-neutral-500 text-sm mb-3\">Lane async invocations are queued in. Runtimes drain higher lanes first, weighted so lower ones still make progress. Callers can override it per request with <code class=\"text-neutral-300\">X-Async-Priority</code>; batches default to low.</p><select id=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var74 string
+				templ_7745c5c3_Var74, templ_7745c5c3_Err = templ.JoinStringErrs("priority-" + ep.ID)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 733, Col: 33}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var74))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 106, "\" class=\"bg-[#0b0b0c] p-2.5 border border-neutral-800 rounded-xl text-white focus:border-blue-500 outline-none transition\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				for _, p := range []string{"high", "normal", "low"} {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 107, "<option value=\"")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var75 string
+					templ_7745c5c3_Var75, templ_7745c5c3_Err = templ.JoinStringErrs(p)
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 737, Col: 27}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var75))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 108, "\"")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					if ep.Priority == p || (ep.Priority == "" && p == "normal") {
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 109, " selected")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 110, ">")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var76 string
+					templ_7745c5c3_Var76, templ_7745c5c3_Err = templ.JoinStringErrs(p)
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 737, Col: 104}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var76))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 111, "</option>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 112, "</select></div><!-- CALLBACK --> <div><h4 class=\"text-white font-semibold mb-2\">Completion Callback</h4><p class=\"text-neutral-500 text-sm mb-3\">POST the result of each async invocation to an https url. Requests carry <code class=\"text-neutral-300\">X-Litefunction-Signature</code>, an HMAC-SHA256 of <code class=\"text-neutral-300\">timestamp.body</code>, and are retried with backoff. Recent deliveries are listed at <code class=\"text-neutral-300\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var77 string
+				templ_7745c5c3_Var77, templ_7745c5c3_Err = templ.JoinStringErrs("/api/endpoints/" + ep.ID + "/callbacks/")
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 745, Col: 354}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var77))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 113, "</code>.</p><div class=\"grid grid-cols-1 gap-3\"><input type=\"text\" id=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var78 string
+				templ_7745c5c3_Var78, templ_7745c5c3_Err = templ.JoinStringErrs("callback-url-" + ep.ID)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 750, Col: 38}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var78))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 114, "\" value=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var79 string
+				templ_7745c5c3_Var79, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Callback.URL)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 751, Col: 33}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var79))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 115, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"https://example.com/hooks/litefunctions\"> <label class=\"flex items-center gap-2 text-neutral-300 text-sm\"><input type=\"checkbox\" id=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var80 string
+				templ_7745c5c3_Var80, templ_7745c5c3_Err = templ.JoinStringErrs("callback-caller-" + ep.ID)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 756, Col: 64}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var80))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 116, "\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				if ep.Callback.AllowCallerURL {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 117, " checked")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 118, "> Allow callers to set their own url with <code class=\"text-neutral-300\">X-Callback-Url</code></label> ")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				if ep.Callback.Secret != "" {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 119, "<div class=\"text-neutral-400 text-sm\">Signing secret <code class=\"text-neutral-300 break-all\">")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var81 string
+					templ_7745c5c3_Var81, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Callback.Secret)
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 761, Col: 87}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var81))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 120, "</code></div>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 121, "<label class=\"flex items-center gap-2 text-neutral-300 text-sm\"><input type=\"checkbox\" id=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var82 string
+				templ_7745c5c3_Var82, templ_7745c5c3_Err = templ.JoinStringErrs("callback-rotate-" + ep.ID)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 765, Col: 64}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var82))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 122, "\"> Rotate signing secret on save</label></div></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 123, "<!-- REQUEST SCHEMA --><div><h4 class=\"text-white font-semibold mb-2\">Request Schema</h4><p class=\"text-neutral-500 text-sm mb-3\">JSON Schemas checked by the ingestor before the function runs. Query parameters are validated as an object of strings. Schemas are also published at <code class=\"text-neutral-300\">/api/openapi.json</code>.</p><div class=\"grid grid-cols-1 md:grid-cols-2 gap-3\"><textarea id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var83 string
+			templ_7745c5c3_Var83, templ_7745c5c3_Err = templ.JoinStringErrs("schema-body-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 777, Col: 36}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var83))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 124, "\" rows=\"6\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full font-mono text-sm focus:border-blue-500 outline-none transition\" placeholder=\"Body schema\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var84 string
+			templ_7745c5c3_Var84, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Schema.Body)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 781, Col: 25}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var84))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 125, "</textarea> <textarea id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var85 string
+			templ_7745c5c3_Var85, templ_7745c5c3_Err = templ.JoinStringErrs("schema-query-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 783, Col: 37}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var85))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 126, "\" rows=\"6\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full font-mono text-sm focus:border-blue-500 outline-none transition\" placeholder=\"Query schema\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var86 string
+			templ_7745c5c3_Var86, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Schema.Query)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 787, Col: 26}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var86))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 127, "</textarea></div></div><!-- TRANSFORMS --><div><h4 class=\"text-white font-semibold mb-2\">Transformations</h4><p class=\"text-neutral-500 text-sm mb-3\">Rewrite requests before they reach the runtime and filter its response headers. Request rules: <code class=\"text-neutral-300\">rename_headers</code>, <code class=\"text-neutral-300\">remove_headers</code>, <code class=\"text-neutral-300\">set_headers</code>, <code class=\"text-neutral-300\">path</code>, <code class=\"text-neutral-300\">query_to_body</code>. Response rules: <code class=\"text-neutral-300\">remove_headers</code>, <code class=\"text-neutral-300\">allow_headers</code>, <code class=\"text-neutral-300\">set_headers</code>. Values may use <code class=\"text-neutral-300\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var87 string
+			templ_7745c5c3_Var87, templ_7745c5c3_Err = templ.JoinStringErrs("${project}")
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 794, Col: 593}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var87))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 128, "</code>, <code class=\"text-neutral-300\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var88 string
+			templ_7745c5c3_Var88, templ_7745c5c3_Err = templ.JoinStringErrs("${header.Name}")
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 794, Col: 653}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var88))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 129, "</code>, <code class=\"text-neutral-300\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var89 string
+			templ_7745c5c3_Var89, templ_7745c5c3_Err = templ.JoinStringErrs("${query.name}")
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 794, Col: 712}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var89))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 130, "</code> and <code class=\"text-neutral-300\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var90 string
+			templ_7745c5c3_Var90, templ_7745c5c3_Err = templ.JoinStringErrs("${secret.name}")
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 794, Col: 775}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var90))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 131, "</code>, read from secrets mounted into the ingestor as <code class=\"text-neutral-300\">&lt;project&gt;.&lt;name&gt;</code>.</p><div class=\"grid grid-cols-1 md:grid-cols-2 gap-3\"><textarea id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var91 string
+			templ_7745c5c3_Var91, templ_7745c5c3_Err = templ.JoinStringErrs("transform-request-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 798, Col: 42}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var91))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 132, "\" rows=\"6\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full font-mono text-sm focus:border-blue-500 outline-none transition\" placeholder='Request rules, e.g. {\"set_headers\": {\"Authorization\": \"Bearer ${secret.upstream-token}\"}}'>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var92 string
+			templ_7745c5c3_Var92, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Transform.Request)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 802, Col: 31}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var92))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 133, "</textarea> <textarea id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var93 string
+			templ_7745c5c3_Var93, templ_7745c5c3_Err = templ.JoinStringErrs("transform-response-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 804, Col: 43}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var93))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 134, "\" rows=\"6\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full font-mono text-sm focus:border-blue-500 outline-none transition\" placeholder='Response rules, e.g. {\"remove_headers\": [\"Server\"]}'>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var94 string
+			templ_7745c5c3_Var94, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Transform.Response)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 808, Col: 32}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var94))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 135, "</textarea></div></div><!-- MIDDLEWARE --><div><h4 class=\"text-white font-semibold mb-2\">Middleware</h4><p class=\"text-neutral-500 text-sm mb-3\">Middleware compiled into the ingestor, run in order after authentication and validation for every invocation mode (HTTP, SSE, websocket, gRPC, batch and function calls). Each entry has a <code class=\"text-neutral-300\">name</code> and an optional string <code class=\"text-neutral-300\">config</code> map. Requests fail with 500 while a listed middleware isn't available.</p><textarea id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var95 string
+			templ_7745c5c3_Var95, templ_7745c5c3_Err = templ.JoinStringErrs("middleware-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 818, Col: 34}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var95))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 136, "\" rows=\"4\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full font-mono text-sm focus:border-blue-500 outline-none transition\" placeholder='e.g. [{\"name\": \"ratelimit\", \"config\": {\"rps\": \"50\"}}]'>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var96 string
+			templ_7745c5c3_Var96, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Middleware)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 822, Col: 23}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var96))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 137, "</textarea></div><!-- FAULT INJECTION --><div><h4 class=\"text-white font-semibold mb-2\">Fault Injection</h4><p class=\"text-neutral-500 text-sm mb-3\">Add latency, force an error status or drop async requests for a share of traffic, optionally only when a header is present. Affected responses carry <code class=\"text-neutral-300\">X-Litefunction-Fault</code>. Faults expire automatically; leave the percentage empty to disable. ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if ep.Fault.ExpiresAt != "" {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 138, "<span class=\"text-amber-400\">Active until ")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var97 string
+				templ_7745c5c3_Var97, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Fault.ExpiresAt)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 830, Col: 71}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var97))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 139, ".</span>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 140, "</p><div class=\"grid grid-cols-1 md:grid-cols-3 gap-3\"><input type=\"number\" min=\"0\" max=\"100\" step=\"0.1\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var98 string
+			templ_7745c5c3_Var98, templ_7745c5c3_Err = templ.JoinStringErrs("fault-pct-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 839, Col: 34}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var98))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 141, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var99 string
+			templ_7745c5c3_Var99, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Fault.Percentage)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 840, Col: 36}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var99))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 142, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"Percentage of requests\"> <input type=\"number\" min=\"0\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var100 string
+			templ_7745c5c3_Var100, templ_7745c5c3_Err = templ.JoinStringErrs("fault-delay-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 847, Col: 36}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var100))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 143, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var101 string
+			templ_7745c5c3_Var101, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Fault.DelayMs)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 848, Col: 33}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var101))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 144, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"Added latency (ms)\"> <input type=\"number\" min=\"400\" max=\"599\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var102 string
+			templ_7745c5c3_Var102, templ_7745c5c3_Err = templ.JoinStringErrs("fault-status-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 856, Col: 37}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var102))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 145, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var103 string
+			templ_7745c5c3_Var103, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Fault.ErrorStatus)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 857, Col: 37}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var103))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 146, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"Forced status (e.g. 503)\"> <input type=\"text\" id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var104 string
+			templ_7745c5c3_Var104, templ_7745c5c3_Err = templ.JoinStringErrs("fault-header-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 863, Col: 37}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var104))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 147, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var105 string
+			templ_7745c5c3_Var105, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Fault.Header)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 864, Col: 32}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var105))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 148, "\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full focus:border-blue-500 outline-none transition\" placeholder=\"Only with header (optional)\"> <select id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var106 string
+			templ_7745c5c3_Var106, templ_7745c5c3_Err = templ.JoinStringErrs("fault-expiry-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 869, Col: 37}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var106))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 149, "\" class=\"bg-[#0b0b0c] p-2.5 border border-neutral-800 rounded-xl text-white focus:border-blue-500 outline-none transition\"><option value=\"15m\">Expire in 15 minutes</option> <option value=\"1h\">Expire in 1 hour</option> <option value=\"4h\">Expire in 4 hours</option> <option value=\"24h\">Expire in 24 hours</option></select> ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if ep.IsAsync {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 150, "<label class=\"flex items-center gap-2 text-neutral-300 text-sm\"><input type=\"checkbox\" id=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var107 string
+				templ_7745c5c3_Var107, templ_7745c5c3_Err = templ.JoinStringErrs("fault-drop-" + ep.ID)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 879, Col: 59}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var107))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 151, "\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				if ep.Fault.DropAsync {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 152, " checked")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 153, "> Drop async requests</label>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 154, "</div></div><!-- AUTHORIZATION POLICY --><div><h4 class=\"text-white font-semibold mb-2\">Authorization Policy</h4><p class=\"text-neutral-500 text-sm mb-3\">CEL rules, one per line as <code class=\"text-neutral-300\">allow|deny expression</code>. The first matching rule decides, e.g. <code class=\"text-neutral-300\">allow inCidr(request.ip, \"10.0.0.0/8\")</code> or <code class=\"text-neutral-300\">deny !(\"admin\" in claims.roles)</code>. Audit mode only logs decisions.</p><div class=\"flex gap-3 mb-3\"><select id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var108 string
+			templ_7745c5c3_Var108, templ_7745c5c3_Err = templ.JoinStringErrs("policy-mode-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 893, Col: 36}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var108))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 155, "\" class=\"bg-[#0b0b0c] p-2.5 border border-neutral-800 rounded-xl text-white focus:border-blue-500 outline-none transition\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			for _, m := range []string{"off", "audit", "enforce"} {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 156, "<option value=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var109 string
+				templ_7745c5c3_Var109, templ_7745c5c3_Err = templ.JoinStringErrs(m)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 897, Col: 27}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var109))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 157, "\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				if ep.Policy.Mode == m {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 158, " selected")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 159, ">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var110 string
+				templ_7745c5c3_Var110, templ_7745c5c3_Err = templ.JoinStringErrs(m)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 897, Col: 67}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var110))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 160, "</option>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 161, "</select> <select id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var111 string
+			templ_7745c5c3_Var111, templ_7745c5c3_Err = templ.JoinStringErrs("policy-default-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 901, Col: 39}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var111))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 162, "\" class=\"bg-[#0b0b0c] p-2.5 border border-neutral-800 rounded-xl text-white focus:border-blue-500 outline-none transition\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			for _, d := range []string{"deny", "allow"} {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 163, "<option value=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var112 string
+				templ_7745c5c3_Var112, templ_7745c5c3_Err = templ.JoinStringErrs(d)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 905, Col: 27}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var112))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 164, "\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				if ep.Policy.Default == d {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 165, " selected")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 166, ">default ")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var113 string
+				templ_7745c5c3_Var113, templ_7745c5c3_Err = templ.JoinStringErrs(d)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 905, Col: 78}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var113))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 167, "</option>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 168, "</select></div><textarea id=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var114 string
+			templ_7745c5c3_Var114, templ_7745c5c3_Err = templ.JoinStringErrs("policy-rules-" + ep.ID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 910, Col: 36}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var114))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 169, "\" rows=\"4\" class=\"bg-[#0b0b0c] border border-neutral-800 rounded-xl p-2.5 text-white w-full font-mono text-sm focus:border-blue-500 outline-none transition\" placeholder=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var115 string
+			templ_7745c5c3_Var115, templ_7745c5c3_Err = templ.JoinStringErrs("allow request.method == \"GET\"")
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 913, Col: 55}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var115))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 170, "\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var116 string
+			templ_7745c5c3_Var116, templ_7745c5c3_Err = templ.JoinStringErrs(ep.Policy.Rules)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/endpoints.templ`, Line: 914, Col: 25}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var116))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 171, "</textarea></div><!-- SAVE BUTTON --><div class=\"pt-4 border-t border-neutral-800/50 flex justify-end\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderScriptItems(ctx, templ_7745c5c3_Buffer, saveEndpointSettings(ep.ID, ep.Scope))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 172, "<button onclick=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var117 templ.ComponentScript = saveEndpointSettings(ep.ID, ep.Scope)
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var117.Call)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 70, "\" class=\"bg-blue-600 hover:bg-blue-700 text-white px-6 py-2.5 rounded-xl font-semibold transition shadow-lg shadow-blue-500/20 flex items-center gap-2\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"w-4 h-4\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M5 13l4 4L19 7\"></path></svg> Save Changes</button></div></div></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 173, "\" class=\"bg-blue-600 hover:bg-blue-700 text-white px-6 py-2.5 rounded-xl font-semibold transition shadow-lg shadow-blue-500/20 flex items-center gap-2\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"w-4 h-4\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M5 13l4 4L19 7\"></path></svg> Save Changes</button></div></div></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 71, "</div><!-- TEST MODAL --><div id=\"test-modal\" class=\"hidden fixed inset-0 bg-black/80 backdrop-blur-md z-50 flex items-center justify-center p-4\" onclick=\"closeTestModal()\"><div class=\"w-full max-w-6xl h-[85vh] bg-[#0f0f10] border border-neutral-800 rounded-2xl flex flex-col shadow-2xl\" onclick=\"event.stopPropagation()\"><!-- MODAL HEADER --><div class=\"flex items-center justify-between p-4 border-b border-neutral-800\"><div class=\"flex items-center gap-2\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"w-5 h-5 text-blue-500\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M13 10V3L4 14h7v7l9-11h-7z\"></path></svg></div><button onclick=\"closeTestModal()\" class=\"p-2 text-neutral-400 hover:text-white rounded-lg hover:bg-neutral-800 transition\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"w-6 h-6\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M6 18L18 6M6 6l12 12\"></path></svg></button></div><!-- MODAL CONTENT --><div class=\"flex-1 grid grid-cols-1 lg:grid-cols-3 divide-y lg:divide-y-0 lg:divide-x divide-neutral-800 overflow-hidden\"><!-- LEFT COLUMN: SETTINGS --><div class=\"p-4 space-y-4 overflow-y-auto bg-[#0b0b0c]/50\"><div><label class=\"text-xs font-semibold text-neutral-500 uppercase tracking-wider mb-2 block\">Endpoint</label> <select id=\"test-endpoint-select\" class=\"w-full bg-[#151516] border border-neutral-800 text-white rounded-lg p-3 text-sm focus:border-blue-500 focus:ring-1 focus:ring-blue-500 outline-none transition\"></select></div><div><label class=\"text-xs font-semibold text-neutral-500 uppercase tracking-wider mb-2 block\">Headers</label><div class=\"space-y-2\"><div class=\"grid grid-cols-2 gap-2 text-[10px] text-neutral-600 uppercase tracking-wider\"><span>Key</span> <span>Value</span></div><div id=\"test-headers-list\" class=\"space-y-2\"></div><button onclick=\"addHeaderRow('', '')\" class=\"w-full px-3 py-2 rounded-lg border border-neutral-800 text-neutral-300 hover:text-white hover:border-neutral-600 transition text-xs font-semibold\">+ Add Header</button></div></div></div><!-- RIGHT COLUMN: BODY & RESPONSE --><div class=\"lg:col-span-2 flex flex-col h-full overflow-hidden\"><!-- REQUEST BODY --><div class=\"flex-1 p-4 border-b border-neutral-800 flex flex-col min-h-0\"><div class=\"flex items-center justify-between mb-2\"><label class=\"text-xs font-semibold text-neutral-500 uppercase tracking-wider\">Request Body</label><div class=\"flex items-center gap-2\"><select id=\"test-method-select\" class=\"bg-[#151516] border border-neutral-800 text-white rounded px-2 py-1 text-xs outline-none focus:border-blue-500\"><option value=\"GET\">GET</option> <option value=\"POST\">POST</option> <option value=\"PUT\">PUT</option> <option value=\"PATCH\">PATCH</option> <option value=\"DELETE\">DELETE</option></select> <span class=\"text-[10px] text-neutral-600 font-mono\">JSON</span> <button onclick=\"runEndpointTest()\" class=\"px-4 py-1.5 rounded-lg bg-blue-600 hover:bg-blue-700 text-white text-xs font-semibold transition flex items-center gap-2 shadow-lg shadow-blue-500/10\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"w-3.5 h-3.5\" viewBox=\"0 0 20 20\" fill=\"currentColor\"><path fill-rule=\"evenodd\" d=\"M10 18a8 8 0 100-16 8 8 0 000 16zM9.555 7.168A1 1 0 008 8v4a1 1 0 001.555.832l3-2a1 1 0 000-1.664l-3-2z\" clip-rule=\"evenodd\"></path></svg> Send Request</button></div></div><div id=\"test-body-ace\" class=\"w-full flex-1 rounded-lg border border-neutral-800\"></div><textarea id=\"test-body\" class=\"hidden\" placeholder='{&#10;  \"key\": \"value\"&#10;}'></textarea></div><!-- RESPONSE --><div class=\"flex-1 p-4 flex flex-col min-h-0 bg-[#0b0b0c]/30\"><div class=\"flex items-center justify-between mb-2\"><label class=\"text-xs font-semibold text-neutral-500 uppercase tracking-wider\">Response</label><div id=\"test-status\" class=\"text-xs font-mono font-bold text-neutral-400 bg-neutral-900 px-2 py-1 rounded\">Waiting...</div></div><textarea id=\"test-response\" class=\"w-full flex-1 bg-[#0e0e0f] border border-neutral-800 text-green-400 rounded-lg p-3 font-mono text-xs outline-none resize-none\" readonly placeholder=\"Response will appear here...\"></textarea></div></div></div></div></div><script>\n    document.addEventListener('DOMContentLoaded', function () {\n      const params = new URLSearchParams(window.location.search);\n      const expandId = params.get('expand');\n      if (expandId) {\n        document.querySelectorAll('[id^=\"endpoint-\"]').forEach(el => el.classList.add('hidden'));\n        let target = document.getElementById(\"endpoint-\" + expandId);\n        if (target) {\n          target.classList.remove('hidden');\n          setTimeout(() => target.scrollIntoView({behavior: 'smooth', block: 'center'}), 100);\n        }\n      }\n    });\n  </script><script>\n    (function () {\n      const cdn = \"https://cdnjs.cloudflare.com/ajax/libs/ace/1.32.3/\";\n      const loadAce = (cb) => {\n        if (window.ace) return cb();\n        const s1 = document.createElement(\"script\");\n        s1.src = cdn + \"ace.js\";\n        s1.onload = () => {\n          ace.config.set(\"basePath\", cdn);\n          ace.config.set(\"modePath\", cdn);\n          ace.config.set(\"themePath\", cdn);\n          cb();\n        };\n        document.head.appendChild(s1);\n      };\n\n      function initTestBodyEditor() {\n        if (window.__testBodyEditor || !window.ace) return;\n        const el = document.getElementById(\"test-body-ace\");\n        if (!el) return;\n        window.__testBodyEditor = ace.edit(el);\n        window.__testBodyEditor.setTheme(\"ace/theme/dracula\");\n        window.__testBodyEditor.session.setMode(\"ace/mode/json\");\n        window.__testBodyEditor.setValue('{\\n  \"key\": \"value\"\\n}', -1);\n        window.__testBodyEditor.session.setUseWorker(false);\n      }\n\n      window.__initTestBodyEditor = initTestBodyEditor;\n\n      document.addEventListener(\"DOMContentLoaded\", () => {\n        const list = document.getElementById(\"test-headers-list\");\n        if (list && list.children.length === 0) {\n          addHeaderRow(\"Content-Type\", \"application/json\");\n          addHeaderRow(\"Authorization\", \"Bearer ...\");\n        }\n        loadAce(initTestBodyEditor);\n      });\n    })();\n  </script><script>\n    window.ensureTestModalOptions = function () {\n      const select = document.getElementById(\"test-endpoint-select\");\n      if (!select) return null;\n      let list = [];\n      if (window.__endpointList && window.__endpointList.length > 0) {\n        list = window.__endpointList;\n      } else {\n        list = Array.from(document.querySelectorAll(\"[data-endpoint-id]\")).map(el => ({\n          id: el.dataset.endpointId,\n          name: el.dataset.endpointName,\n          method: el.dataset.endpointMethod,\n        }));\n      }\n      if (list.length > 0 && select.options.length === 0) {\n        list.forEach(ep => {\n          const opt = document.createElement(\"option\");\n          opt.value = ep.id;\n          opt.textContent = `${ep.method} ${ep.name}`;\n          select.appendChild(opt);\n        });\n      }\n      return select;\n    };\n\n    window.openTestModal = function () {\n      const modal = document.getElementById(\"test-modal\");\n      if (!modal) return;\n      modal.classList.remove(\"hidden\");\n      const select = window.ensureTestModalOptions();\n      if (select) select.dispatchEvent(new Event(\"change\"));\n      if (window.__initTestBodyEditor) window.__initTestBodyEditor();\n      if (window.__testBodyEditor) setTimeout(() => window.__testBodyEditor.resize(), 60);\n    };\n\n    window.openTestModalForEndpoint = function (id) {\n      window.openTestModal();\n      const select = window.ensureTestModalOptions();\n      if (!select) return;\n      select.value = id;\n      select.dispatchEvent(new Event(\"change\"));\n    };\n\n    window.closeTestModal = function () {\n      const modal = document.getElementById(\"test-modal\");\n      if (modal) modal.classList.add(\"hidden\");\n    };\n\n    window.addHeaderRow = function (key, val) {\n      const list = document.getElementById(\"test-headers-list\");\n      if (!list) return;\n      const row = document.createElement(\"div\");\n      row.className = \"header-row flex items-center gap-2\";\n      row.innerHTML = `\n\t\t\t\t\t<input class=\"header-key flex-1 min-w-0 bg-[#151516] border border-neutral-800 text-white rounded-lg px-2.5 py-2 text-xs font-mono focus:border-blue-500 focus:ring-1 focus:ring-blue-500 outline-none transition\" placeholder=\"Header\" value=\"${key || \"\"}\">\n\t\t\t\t\t<span class=\"text-neutral-600 text-xs\">:</span>\n\t\t\t\t\t<input class=\"header-val flex-1 min-w-0 bg-[#151516] border border-neutral-800 text-white rounded-lg px-2.5 py-2 text-xs font-mono focus:border-blue-500 focus:ring-1 focus:ring-blue-500 outline-none transition\" placeholder=\"Value\" value=\"${val || \"\"}\">\n\t\t\t\t\t<button class=\"remove-header px-2 py-2 rounded-lg border border-neutral-800 text-neutral-400 hover:text-white hover:border-neutral-600 transition\" title=\"Remove header\">\n\t\t\t\t\t\t<svg xmlns=\"http://www.w3.org/2000/svg\" class=\"w-4 h-4\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\">\n\t\t\t\t\t\t\t<path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M6 18L18 6M6 6l12 12\" />\n\t\t\t\t\t\t</svg>\n\t\t\t\t\t</button>\n\t\t\t\t`;\n      row.querySelector(\".remove-header\")?.addEventListener(\"click\", () => {\n        row.remove();\n        if (list.querySelectorAll(\".header-row\").length === 0) {\n          window.addHeaderRow(\"\", \"\");\n        }\n      });\n      list.appendChild(row);\n    };\n\n    window.getHeadersFromUI = function () {\n      const headers = {};\n      document.querySelectorAll(\"#test-headers-list .header-row\").forEach(row => {\n        const key = row.querySelector(\".header-key\")?.value?.trim();\n        const val = row.querySelector(\".header-val\")?.value?.trim();\n        if (key) headers[key] = val || \"\";\n      });\n      return headers;\n    };\n\n    function setTestBodyEnabled(method) {\n      const isGetLike = method === \"GET\" || method === \"HEAD\";\n      const aceWrap = document.getElementById(\"test-body-ace\");\n      if (window.__testBodyEditor) {\n        window.__testBodyEditor.setReadOnly(isGetLike);\n      }\n      if (aceWrap) {\n        if (isGetLike) aceWrap.classList.add(\"opacity-60\");\n        else aceWrap.classList.remove(\"opacity-60\");\n      }\n    }\n\n    window.runEndpointTest = function () {\n      const select = document.getElementById(\"test-endpoint-select\");\n      const epId = select?.value;\n      if (!epId) return;\n      const endpointList = (window.__endpointList && window.__endpointList.length > 0)\n        ? window.__endpointList\n        : Array.from(document.querySelectorAll(\"[data-endpoint-id]\")).map(el => ({\n            id: el.dataset.endpointId,\n            name: el.dataset.endpointName,\n            method: el.dataset.endpointMethod,\n          }));\n      const endpoint = endpointList.find(ep => ep.id === epId);\n      if (!endpoint || !endpoint.name) return;\n\n      const methodSelect = document.getElementById(\"test-method-select\");\n      const methodInput = document.getElementById(\"selected-method-\" + epId);\n      let method = String(methodSelect?.value || methodInput?.value || endpoint.method || \"GET\").toUpperCase();\n      \n      const url = `/lambda${endpoint.name}`;\n      const bodyRaw = window.__testBodyEditor ? window.__testBodyEditor.getValue() : (document.getElementById(\"test-body\")?.value || \"\");\n      const headers = window.getHeadersFromUI();\n\n      const resEl = document.getElementById(\"test-response\");\n      const statusEl = document.getElementById(\"test-status\");\n      if (resEl) resEl.value = \"Sending...\";\n      if (statusEl) statusEl.textContent = \"\";\n\n      (async () => {\n        try {\n          const request = { method, headers };\n          if (method !== \"GET\" && method !== \"HEAD\" && bodyRaw.trim() !== \"\") {\n            request.body = bodyRaw;\n          }\n          const resp = await fetch(url, request);\n          const contentType = resp.headers.get(\"Content-Type\") || \"\";\n          const textBody = await resp.text();\n          if (statusEl) statusEl.textContent = `Status: ${resp.status}`;\n\n          let bodyStr = textBody || \"\";\n          if (contentType.includes(\"application/json\")) {\n            try {\n              const jsonBody = JSON.parse(bodyStr);\n              bodyStr = JSON.stringify(jsonBody, null, 2);\n            } catch (e) { }\n          }\n\n          if (resEl) resEl.value = bodyStr;\n        } catch (e) {\n          if (statusEl) statusEl.textContent = \"Status: error\";\n          if (resEl) resEl.value = String(e);\n        }\n      })();\n    };\n\n    document.addEventListener(\"DOMContentLoaded\", () => {\n      const select = document.getElementById(\"test-endpoint-select\");\n      const methodSelect = document.getElementById(\"test-method-select\");\n      const syncMethodFromEndpoint = () => {\n        if (!select || !methodSelect) return;\n        const endpointList = (window.__endpointList && window.__endpointList.length > 0)\n          ? window.__endpointList\n          : Array.from(document.querySelectorAll(\"[data-endpoint-id]\")).map(el => ({\n              id: el.dataset.endpointId,\n              name: el.dataset.endpointName,\n              method: el.dataset.endpointMethod,\n            }));\n        const endpoint = endpointList.find(ep => ep.id === select.value);\n        methodSelect.value = String(endpoint?.method || \"GET\").toUpperCase();\n        setTestBodyEnabled(methodSelect.value);\n      };\n\n      select?.addEventListener(\"change\", syncMethodFromEndpoint);\n      methodSelect?.addEventListener(\"change\", () => setTestBodyEnabled(String(methodSelect.value || \"GET\").toUpperCase()));\n      syncMethodFromEndpoint();\n    });\n  </script><script>\n    (function () {\n      function ensureWsModalOptions() {\n        const select = document.getElementById(\"ws-endpoint-select\");\n        if (!select) return null;\n        let list = [];\n        if (window.__endpointList && window.__endpointList.length > 0) {\n          list = window.__endpointList;\n        } else {\n          list = Array.from(document.querySelectorAll(\"[data-endpoint-id]\")).map(el => ({\n            id: el.dataset.endpointId,\n            name: el.dataset.endpointName,\n            method: el.dataset.endpointMethod,\n          }));\n        }\n        if (list.length > 0 && select.options.length === 0) {\n          list.forEach(ep => {\n            const opt = document.createElement(\"option\");\n            opt.value = ep.id;\n            opt.textContent = `${ep.method} ${ep.name}`;\n            select.appendChild(opt);\n          });\n        }\n        return select;\n      }\n\n      function wsBaseUrl() {\n        const proto = window.location.protocol === \"https:\" ? \"wss:\" : \"ws:\";\n        return proto + \"//\" + window.location.host;\n      }\n\n      function toWsPath(path) {\n        if (!path) return \"\";\n        if (path.startsWith(\"/lambda/ws/\")) return path;\n        if (path.startsWith(\"/lambda/\")) {\n          return \"/lambda/ws/\" + path.replace(\"/lambda/\", \"\");\n        }\n        if (path.startsWith(\"/\")) return \"/lambda/ws\" + path;\n        return \"/lambda/ws/\" + path;\n      }\n\n      function setWsUrl() {\n        const select = document.getElementById(\"ws-endpoint-select\");\n        const urlEl = document.getElementById(\"ws-url\");\n        if (!select || !urlEl) return;\n        const epId = select.value;\n        const endpointList = (window.__endpointList && window.__endpointList.length > 0)\n          ? window.__endpointList\n          : Array.from(document.querySelectorAll(\"[data-endpoint-id]\")).map(el => ({\n              id: el.dataset.endpointId,\n              name: el.dataset.endpointName,\n              method: el.dataset.endpointMethod,\n            }));\n        const endpoint = endpointList.find(ep => ep.id === epId);\n        const path = endpoint?.name || \"\";\n        const wsUrl = wsBaseUrl() + toWsPath(path);\n        urlEl.value = wsUrl;\n      }\n\n      function setWsStatus(text, cls) {\n        const el = document.getElementById(\"ws-status\");\n        if (!el) return;\n        el.textContent = text;\n        el.className = \"text-xs font-mono bg-neutral-900 px-2 py-1 rounded \" + (cls || \"text-neutral-400\");\n      }\n\n      function logWsMessage(kind, payload) {\n        const log = document.getElementById(\"ws-log\");\n        if (!log) return;\n        const ts = new Date().toLocaleTimeString();\n        const color = kind === \"sent\" ? \"text-cyan-300\" : (kind === \"recv\" ? \"text-green-400\" : \"text-yellow-400\");\n        const line = document.createElement(\"div\");\n        line.className = \"mb-2\";\n        line.innerHTML = `<span class=\"text-neutral-500\">[${ts}]</span> <span class=\"${color}\">${kind.toUpperCase()}</span> <span class=\"text-neutral-300\">•</span> <span class=\"text-neutral-200 whitespace-pre-wrap break-words\"></span>`;\n        line.querySelector(\"span:last-child\").textContent = payload;\n        log.appendChild(line);\n        log.scrollTop = log.scrollHeight;\n      }\n\n      function closeWs() {\n        if (window.__wsConn) {\n          try { window.__wsConn.close(); } catch (e) {}\n          window.__wsConn = null;\n        }\n      }\n\n      window.openWsTestModal = function () {\n        const modal = document.getElementById(\"ws-test-modal\");\n        if (!modal) return;\n        modal.classList.remove(\"hidden\");\n        const select = ensureWsModalOptions();\n        if (select) {\n          select.removeEventListener(\"change\", setWsUrl);\n          select.addEventListener(\"change\", setWsUrl);\n          setWsUrl();\n        }\n      };\n\n      window.openWsTestModalForEndpoint = function (id) {\n        window.openWsTestModal();\n        const select = ensureWsModalOptions();\n        if (!select) return;\n        select.value = id;\n        setWsUrl();\n      };\n\n      window.closeWsTestModal = function () {\n        closeWs();\n        const modal = document.getElementById(\"ws-test-modal\");\n        if (modal) modal.classList.add(\"hidden\");\n        setWsStatus(\"Disconnected\");\n      };\n\n      window.copyWsUrl = function () {\n        const url = document.getElementById(\"ws-url\")?.value || \"\";\n        if (!url) return;\n        navigator.clipboard.writeText(url);\n      };\n\n      window.connectWs = function () {\n        const url = document.getElementById(\"ws-url\")?.value;\n        if (!url) return;\n        closeWs();\n        setWsStatus(\"Connecting...\", \"text-cyan-300\");\n        try {\n          const ws = new WebSocket(url);\n          ws.binaryType = \"arraybuffer\";\n          ws.onopen = () => setWsStatus(\"Connected\", \"text-green-400\");\n          ws.onclose = () => setWsStatus(\"Disconnected\", \"text-neutral-400\");\n          ws.onerror = () => setWsStatus(\"Error\", \"text-red-400\");\n          ws.onmessage = (ev) => {\n            if (typeof ev.data === \"string\") {\n              logWsMessage(\"recv\", ev.data);\n            } else {\n              const view = new Uint8Array(ev.data);\n              const text = new TextDecoder().decode(view);\n              logWsMessage(\"recv\", text || \"[binary]\");\n            }\n          };\n          window.__wsConn = ws;\n        } catch (e) {\n          setWsStatus(\"Error\", \"text-red-400\");\n          logWsMessage(\"info\", String(e));\n        }\n      };\n\n      window.disconnectWs = function () {\n        closeWs();\n        setWsStatus(\"Disconnected\", \"text-neutral-400\");\n      };\n\n      window.sendWsMessage = function () {\n        const ws = window.__wsConn;\n        const msg = document.getElementById(\"ws-message\")?.value || \"\";\n        if (!ws || ws.readyState !== WebSocket.OPEN) {\n          logWsMessage(\"info\", \"Not connected.\");\n          return;\n        }\n        ws.send(msg);\n        logWsMessage(\"sent\", msg);\n      };\n\n      window.clearWsLog = function () {\n        const log = document.getElementById(\"ws-log\");\n        if (log) log.innerHTML = \"\";\n      };\n    })();\n  </script><script>\n    (function () {\n      function isInProgress(run) {\n        if (!run) return false;\n        const rawStatus = run.status || run.Status;\n        if (!rawStatus) return false;\n        const status = String(rawStatus).toLowerCase();\n        return status === \"in_progress\" || status === \"queued\" || status === \"waiting\" || status === \"running\";\n      }\n\n      function getRuns(progress) {\n        if (!progress) return [];\n        if (Array.isArray(progress.Runs)) return progress.Runs;\n        if (Array.isArray(progress.runs)) return progress.runs;\n        return [];\n      }\n\n      function pickActiveRun(progress) {\n        const runs = getRuns(progress);\n        if (!runs.length) return null;\n        return runs.find(isInProgress) || null;\n      }\n\n      function pickDisplayRun(progress) {\n        const runs = getRuns(progress);\n        if (!runs.length) return null;\n        return runs.find(isInProgress) || runs[0];\n      }\n\n      function normalizeName(val) {\n        return String(val || \"\").trim().toLowerCase();\n      }\n\n      function getRunName(run) {\n        return normalizeName(run?.FunctionName || run?.function_name || run?.WorkflowName || run?.workflow_name || run?.Name || run?.name || \"\");\n      }\n\n      function extractFunctionName(run) {\n        const title = String(run?.DisplayTitle || run?.display_title || \"\").toLowerCase();\n        if (!title) return \"\";\n        const m = title.match(/functions\\/(?:go|rs|rust|py|python|ts|typescript|lua)\\/([^.\\s\\/]+)\\./);\n        return m ? m[1] : \"\";\n      }\n\n      function pickRunForFunction(runs, functionName) {\n        const target = normalizeName(functionName);\n        if (!target) return null;\n        const matching = runs.filter(r => {\n          const byName = getRunName(r) === target;\n          const byDisplay = extractFunctionName(r) === target;\n          return byName || byDisplay;\n        });\n        if (!matching.length) return null;\n        return matching;\n      }\n\n      function isRunSuccess(run) {\n        const { status, conclusion } = normalizeStatus(run);\n        if (conclusion === \"success\" || status === \"success\") return true;\n        if (status === \"completed\" && !conclusion) return true;\n        return false;\n      }\n\n      function jobNameForRun(run) {\n        return normalizeName(run?.CurrentJob || run?.current_job || run?.Name || run?.name || \"\");\n      }\n\n      function isRelevantRunForLanguage(run, language) {\n        const relevant = getRelevantJobs(language);\n        if (!relevant.length) return true;\n        const job = jobNameForRun(run);\n        if (!job) return false;\n        return relevant.some(r => job.includes(r));\n      }\n\n      function pickRunForFunctionAndLanguage(runs, functionName, language) {\n        const matching = pickRunForFunction(runs, functionName);\n        if (!matching || !matching.length) return null;\n\n        const activeRelevant = matching.find(r => isInProgress(r) && isRelevantRunForLanguage(r, language));\n        if (activeRelevant) return activeRelevant;\n\n        const successRelevant = matching.find(r => isRunSuccess(r) && isRelevantRunForLanguage(r, language));\n        if (successRelevant) return successRelevant;\n\n        return matching.find(isInProgress) || matching[0];\n      }\n\n      function normalizeStatus(run) {\n        if (!run) return \"\";\n        const status = String(run.status || run.Status || \"\").toLowerCase();\n        const conclusion = String(run.conclusion || run.Conclusion || \"\").toLowerCase();\n        return { status, conclusion };\n      }\n\n      function statusClass(status, conclusion, running) {\n        if (running) {\n          return \"border-[#6b3f17] bg-[#4a2a0c]/40 text-[#e5b07b] hover:bg-[#4a2a0c]/60\";\n        }\n        if (conclusion === \"success\" || status === \"success\") {\n          return \"border-green-700/60 bg-green-700/15 text-green-300 hover:bg-green-700/30\";\n        }\n        if ([\"failure\", \"failed\", \"cancelled\", \"canceled\", \"error\", \"timed_out\"].includes(conclusion) ||\n            [\"failure\", \"failed\", \"cancelled\", \"canceled\", \"error\", \"timed_out\"].includes(status)) {\n          return \"border-red-700/60 bg-red-700/15 text-red-300 hover:bg-red-700/30\";\n        }\n        return \"border-neutral-700 bg-neutral-800/50 text-neutral-300 hover:bg-neutral-800\";\n      }\n\n      function statusLabel(run, running) {\n        const name = run?.Name || run?.name || \"Build\";\n        if (running) return `Running: ${name}`;\n        const { status, conclusion } = normalizeStatus(run);\n        if (conclusion === \"success\" || status === \"success\") return `Success: ${name}`;\n        if ([\"failure\", \"failed\", \"cancelled\", \"canceled\", \"error\", \"timed_out\"].includes(conclusion) ||\n            [\"failure\", \"failed\", \"cancelled\", \"canceled\", \"error\", \"timed_out\"].includes(status)) {\n          return `Failed: ${name}`;\n        }\n        if (status) return `${status}: ${name}`;\n        return name;\n      }\n\n      function normalizeLanguage(val) {\n        const lang = normalizeName(val);\n        if (lang === \"rs\") return \"rust\";\n        return lang;\n      }\n\n      function getRelevantJobs(language) {\n        switch (normalizeLanguage(language)) {\n          case \"go\":\n            return [\"build-go\"];\n          case \"rust\":\n            return [\"build-rust\"];\n          case \"python\":\n            return [\"hook-python\"];\n          case \"ts\":\n            return [\"hook-ts\"];\n          case \"lua\":\n            return [\"hook-lua\"];\n          default:\n            return [];\n        }\n      }\n\n      function isRelevantCurrentJob(language, currentJob) {\n        const relevant = getRelevantJobs(language);\n        if (!relevant.length) return true;\n        const job = normalizeName(currentJob);\n        if (!job) return true;\n        return relevant.some(r => job.includes(r));\n      }\n\n      function updateBuildButtons(progress) {\n        const runs = getRuns(progress);\n        document.querySelectorAll(\"[data-endpoint-id]\").forEach(card => {\n          const endpointId = card.getAttribute(\"data-endpoint-id\");\n          const fnName = card.getAttribute(\"data-endpoint-function\");\n          const language = card.getAttribute(\"data-endpoint-language\") || \"\";\n          const isAsync = card.getAttribute(\"data-endpoint-async\") === \"true\";\n          const buildLink = document.getElementById(`build-status-${endpointId}`);\n          const stepLabel = document.getElementById(`build-step-${endpointId}`);\n          const testBtn = document.getElementById(`test-btn-${endpointId}`);\n          const wsTestBtn = document.getElementById(`ws-test-btn-${endpointId}`);\n          if (!buildLink) return;\n\n          const display = pickRunForFunctionAndLanguage(runs, fnName, language);\n          const baseRunning = display ? isInProgress(display) : false;\n          const url = (display && (display.HTMLURL || display.htmlurl)) || \"\";\n          const jobName = display?.CurrentJob || display?.current_job || \"\";\n          const stepName = display?.CurrentStep || display?.current_step || \"\";\n          const relevantRunning = baseRunning && isRelevantCurrentJob(language, jobName);\n          const effectiveStatus = (baseRunning && !relevantRunning) ? \"success\" : (display?.status || display?.Status);\n          const effectiveConclusion = (baseRunning && !relevantRunning) ? \"success\" : (display?.conclusion || display?.Conclusion);\n          const cls = statusClass(\n            effectiveStatus,\n            effectiveConclusion,\n            relevantRunning\n          );\n          const label = display\n            ? (baseRunning && !relevantRunning\n                ? `Success: ${jobName || (display?.Name || display?.name || \"Build\")}`\n                : statusLabel(display, relevantRunning))\n            : \"No Actions\";\n          const stepText = stepName ? (jobName ? `${jobName} • ${stepName}` : stepName) : jobName;\n\n          if (url) {\n            buildLink.setAttribute(\"href\", url);\n            buildLink.classList.remove(\"pointer-events-none\", \"opacity-70\");\n          } else {\n            buildLink.setAttribute(\"href\", \"#\");\n            buildLink.classList.add(\"pointer-events-none\", \"opacity-70\");\n          }\n          buildLink.className = `px-3 py-1.5 rounded-lg border text-xs font-semibold tracking-wide transition ${cls}`;\n          buildLink.textContent = label;\n          buildLink.classList.remove(\"hidden\");\n          if (stepLabel) {\n            if (stepText) {\n              stepLabel.textContent = `Step: ${stepText}`;\n              stepLabel.classList.remove(\"hidden\");\n            } else {\n              stepLabel.textContent = \"\";\n              stepLabel.classList.add(\"hidden\");\n            }\n          }\n\n          if (isAsync) {\n            if (wsTestBtn) wsTestBtn.classList.remove(\"hidden\");\n            if (testBtn) testBtn.classList.add(\"hidden\");\n          } else {\n            if (wsTestBtn) wsTestBtn.classList.add(\"hidden\");\n            if (testBtn) {\n              const showTest = !relevantRunning;\n              if (showTest) testBtn.classList.remove(\"hidden\");\n              else testBtn.classList.add(\"hidden\");\n            }\n          }\n        });\n      }\n\n      function startActionsSSE() {\n        if (!window.EventSource) return;\n        const es = new EventSource(\"/api/actions/status/\");\n        es.addEventListener(\"status\", (ev) => {\n          try {\n            const data = JSON.parse(ev.data || \"{}\");\n            updateBuildButtons(data);\n          } catch (e) {\n            // ignore malformed payloads\n          }\n        });\n        es.addEventListener(\"error\", () => {\n          // keep UI usable if stream drops\n          updateBuildButtons(null);\n        });\n      }\n\n      document.addEventListener(\"DOMContentLoaded\", startActionsSSE);\n    })();\n  </script><script>\n    // Provide a global method selector for DOMContentLoaded initialization.\n    // templ component scripts are scoped, so we expose a stable name here.\n    window.selectMethodForEndpoint = function (id, method) {\n      const methodStyles = {\n        GET: [\"border-blue-500\", \"bg-blue-500/20\", \"text-blue-400\"],\n        POST: [\"border-green-500\", \"bg-green-500/20\", \"text-green-400\"],\n        PUT: [\"border-yellow-500\", \"bg-yellow-500/20\", \"text-yellow-400\"],\n        PATCH: [\"border-purple-500\", \"bg-purple-500/20\", \"text-purple-400\"],\n        DELETE: [\"border-red-500\", \"bg-red-500/20\", \"text-red-400\"]\n      };\n      const allMethodClasses = [\n        \"border-blue-500\",\"bg-blue-500/20\",\"text-blue-400\",\n        \"border-green-500\",\"bg-green-500/20\",\"text-green-400\",\n        \"border-yellow-500\",\"bg-yellow-500/20\",\"text-yellow-400\",\n        \"border-purple-500\",\"bg-purple-500/20\",\"text-purple-400\",\n        \"border-red-500\",\"bg-red-500/20\",\"text-red-400\"\n      ];\n      const neutralClasses = [\"border-neutral-700\", \"text-neutral-400\"];\n      [\"GET\", \"POST\", \"PUT\", \"PATCH\", \"DELETE\"].forEach(m => {\n        const btn = document.getElementById(\"method-\" + m + \"-\" + id);\n        if (btn) {\n          btn.classList.remove(...allMethodClasses);\n          btn.classList.remove(...neutralClasses);\n          btn.classList.add(...neutralClasses);\n        }\n      });\n      const selected = document.getElementById(\"method-\" + method + \"-\" + id);\n      if (selected) {\n        selected.classList.remove(...neutralClasses);\n        const style = methodStyles[method] || methodStyles.GET;\n        selected.classList.add(...style);\n      }\n      const input = document.getElementById(\"selected-method-\" + id);\n      if (input) input.value = method;\n    };\n  </script><script>\n    document.addEventListener(\"DOMContentLoaded\", () => {\n      document.querySelectorAll('input[id^=\"selected-method-\"]').forEach(el => {\n        const id = el.id.replace(\"selected-method-\", \"\");\n        const method = el.value || \"GET\";\n        selectMethodForEndpoint(id, method);\n      });\n    });\n  </script><style>\n    .ace_editor,\n    .ace_scroller,\n    .ace_content {\n      background: #0b0b0c !important;\n      color: #eee !important;\n    }\n  </style></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 174, "</div><!-- TEST MODAL --><div id=\"test-modal\" class=\"hidden fixed inset-0 bg-black/80 backdrop-blur-md z-50 flex items-center justify-center p-4\" onclick=\"closeTestModal()\"><div class=\"w-full max-w-6xl h-[85vh] bg-[#0f0f10] border border-neutral-800 rounded-2xl flex flex-col shadow-2xl\" onclick=\"event.stopPropagation()\"><!-- MODAL HEADER --><div class=\"flex items-center justify-between p-4 border-b border-neutral-800\"><div class=\"flex items-center gap-2\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"w-5 h-5 text-blue-500\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M13 10V3L4 14h7v7l9-11h-7z\"></path></svg></div><button onclick=\"closeTestModal()\" class=\"p-2 text-neutral-400 hover:text-white rounded-lg hover:bg-neutral-800 transition\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"w-6 h-6\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M6 18L18 6M6 6l12 12\"></path></svg></button></div><!-- MODAL CONTENT --><div class=\"flex-1 grid grid-cols-1 lg:grid-cols-3 divide-y lg:divide-y-0 lg:divide-x divide-neutral-800 overflow-hidden\"><!-- LEFT COLUMN: SETTINGS --><div class=\"p-4 space-y-4 overflow-y-auto bg-[#0b0b0c]/50\"><div><label class=\"text-xs font-semibold text-neutral-500 uppercase tracking-wider mb-2 block\">Endpoint</label> <select id=\"test-endpoint-select\" class=\"w-full bg-[#151516] border border-neutral-800 text-white rounded-lg p-3 text-sm focus:border-blue-500 focus:ring-1 focus:ring-blue-500 outline-none transition\"></select></div><div><label class=\"text-xs font-semibold text-neutral-500 uppercase tracking-wider mb-2 block\">Headers</label><div class=\"space-y-2\"><div class=\"grid grid-cols-2 gap-2 text-[10px] text-neutral-600 uppercase tracking-wider\"><span>Key</span> <span>Value</span></div><div id=\"test-headers-list\" class=\"space-y-2\"></div><button onclick=\"addHeaderRow('', '')\" class=\"w-full px-3 py-2 rounded-lg border border-neutral-800 text-neutral-300 hover:text-white hover:border-neutral-600 transition text-xs font-semibold\">+ Add Header</button></div></div></div><!-- RIGHT COLUMN: BODY & RESPONSE --><div class=\"lg:col-span-2 flex flex-col h-full overflow-hidden\"><!-- REQUEST BODY --><div class=\"flex-1 p-4 border-b border-neutral-800 flex flex-col min-h-0\"><div class=\"flex items-center justify-between mb-2\"><label class=\"text-xs font-semibold text-neutral-500 uppercase tracking-wider\">Request Body</label><div class=\"flex items-center gap-2\"><select id=\"test-method-select\" class=\"bg-[#151516] border border-neutral-800 text-white rounded px-2 py-1 text-xs outline-none focus:border-blue-500\"><option value=\"GET\">GET</option> <option value=\"POST\">POST</option> <option value=\"PUT\">PUT</option> <option value=\"PATCH\">PATCH</option> <option value=\"DELETE\">DELETE</option></select> <span class=\"text-[10px] text-neutral-600 font-mono\">JSON</span> <button onclick=\"runEndpointTest()\" class=\"px-4 py-1.5 rounded-lg bg-blue-600 hover:bg-blue-700 text-white text-xs font-semibold transition flex items-center gap-2 shadow-lg shadow-blue-500/10\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"w-3.5 h-3.5\" viewBox=\"0 0 20 20\" fill=\"currentColor\"><path fill-rule=\"evenodd\" d=\"M10 18a8 8 0 100-16 8 8 0 000 16zM9.555 7.168A1 1 0 008 8v4a1 1 0 001.555.832l3-2a1 1 0 000-1.664l-3-2z\" clip-rule=\"evenodd\"></path></svg> Send Request</button></div></div><div id=\"test-body-ace\" class=\"w-full flex-1 rounded-lg border border-neutral-800\"></div><textarea id=\"test-body\" class=\"hidden\" placeholder='{&#10;  \"key\": \"value\"&#10;}'></textarea></div><!-- RESPONSE --><div class=\"flex-1 p-4 flex flex-col min-h-0 bg-[#0b0b0c]/30\"><div class=\"flex items-center justify-between mb-2\"><label class=\"text-xs font-semibold text-neutral-500 uppercase tracking-wider\">Response</label><div id=\"test-status\" class=\"text-xs font-mono font-bold text-neutral-400 bg-neutral-900 px-2 py-1 rounded\">Waiting...</div></div><textarea id=\"test-response\" class=\"w-full flex-1 bg-[#0e0e0f] border border-neutral-800 text-green-400 rounded-lg p-3 font-mono text-xs outline-none resize-none\" readonly placeholder=\"Response will appear here...\"></textarea></div></div></div></div></div><script>\n    document.addEventListener('DOMContentLoaded', function () {\n      const params = new URLSearchParams(window.location.search);\n      const expandId = params.get('expand');\n      if (expandId) {\n        document.querySelectorAll('[id^=\"endpoint-\"]').forEach(el => el.classList.add('hidden'));\n        let target = document.getElementById(\"endpoint-\" + expandId);\n        if (target) {\n          target.classList.remove('hidden');\n          setTimeout(() => target.scrollIntoView({behavior: 'smooth', block: 'center'}), 100);\n        }\n      }\n    });\n  </script><script>\n    (function () {\n      const cdn = \"https://cdnjs.cloudflare.com/ajax/libs/ace/1.32.3/\";\n      const loadAce = (cb) => {\n        if (window.ace) return cb();\n        const s1 = document.createElement(\"script\");\n        s1.src = cdn + \"ace.js\";\n        s1.onload = () => {\n          ace.config.set(\"basePath\", cdn);\n          ace.config.set(\"modePath\", cdn);\n          ace.config.set(\"themePath\", cdn);\n          cb();\n        };\n        document.head.appendChild(s1);\n      };\n\n      function initTestBodyEditor() {\n        if (window.__testBodyEditor || !window.ace) return;\n        const el = document.getElementById(\"test-body-ace\");\n        if (!el) return;\n        window.__testBodyEditor = ace.edit(el);\n        window.__testBodyEditor.setTheme(\"ace/theme/dracula\");\n        window.__testBodyEditor.session.setMode(\"ace/mode/json\");\n        window.__testBodyEditor.setValue('{\\n  \"key\": \"value\"\\n}', -1);\n        window.__testBodyEditor.session.setUseWorker(false);\n      }\n\n      window.__initTestBodyEditor = initTestBodyEditor;\n\n      document.addEventListener(\"DOMContentLoaded\", () => {\n        const list = document.getElementById(\"test-headers-list\");\n        if (list && list.children.length === 0) {\n          addHeaderRow(\"Content-Type\", \"application/json\");\n          addHeaderRow(\"Authorization\", \"Bearer ...\");\n        }\n        loadAce(initTestBodyEditor);\n      });\n    })();\n  </script><script>\n    window.ensureTestModalOptions = function () {\n      const select = document.getElementById(\"test-endpoint-select\");\n      if (!select) return null;\n      let list = [];\n      if (window.__endpointList && window.__endpointList.length > 0) {\n        list = window.__endpointList;\n      } else {\n        list = Array.from(document.querySelectorAll(\"[data-endpoint-id]\")).map(el => ({\n          id: el.dataset.endpointId,\n          name: el.dataset.endpointName,\n          method: el.dataset.endpointMethod,\n        }));\n      }\n      if (list.length > 0 && select.options.length === 0) {\n        list.forEach(ep => {\n          const opt = document.createElement(\"option\");\n          opt.value = ep.id;\n          opt.textContent = `${ep.method} ${ep.name}`;\n          select.appendChild(opt);\n        });\n      }\n      return select;\n    };\n\n    window.openTestModal = function () {\n      const modal = document.getElementById(\"test-modal\");\n      if (!modal) return;\n      modal.classList.remove(\"hidden\");\n      const select = window.ensureTestModalOptions();\n      if (select) select.dispatchEvent(new Event(\"change\"));\n      if (window.__initTestBodyEditor) window.__initTestBodyEditor();\n      if (window.__testBodyEditor) setTimeout(() => window.__testBodyEditor.resize(), 60);\n    };\n\n    window.openTestModalForEndpoint = function (id) {\n      window.openTestModal();\n      const select = window.ensureTestModalOptions();\n      if (!select) return;\n      select.value = id;\n      select.dispatchEvent(new Event(\"change\"));\n    };\n\n    window.closeTestModal = function () {\n      const modal = document.getElementById(\"test-modal\");\n      if (modal) modal.classList.add(\"hidden\");\n    };\n\n    window.addHeaderRow = function (key, val) {\n      const list = document.getElementById(\"test-headers-list\");\n      if (!list) return;\n      const row = document.createElement(\"div\");\n      row.className = \"header-row flex items-center gap-2\";\n      row.innerHTML = `\n\t\t\t\t\t<input class=\"header-key flex-1 min-w-0 bg-[#151516] border border-neutral-800 text-white rounded-lg px-2.5 py-2 text-xs font-mono focus:border-blue-500 focus:ring-1 focus:ring-blue-500 outline-none transition\" placeholder=\"Header\" value=\"${key || \"\"}\">\n\t\t\t\t\t<span class=\"text-neutral-600 text-xs\">:</span>\n\t\t\t\t\t<input class=\"header-val flex-1 min-w-0 bg-[#151516] border border-neutral-800 text-white rounded-lg px-2.5 py-2 text-xs font-mono focus:border-blue-500 focus:ring-1 focus:ring-blue-500 outline-none transition\" placeholder=\"Value\" value=\"${val || \"\"}\">\n\t\t\t\t\t<button class=\"remove-header px-2 py-2 rounded-lg border border-neutral-800 text-neutral-400 hover:text-white hover:border-neutral-600 transition\" title=\"Remove header\">\n\t\t\t\t\t\t<svg xmlns=\"http://www.w3.org/2000/svg\" class=\"w-4 h-4\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\">\n\t\t\t\t\t\t\t<path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M6 18L18 6M6 6l12 12\" />\n\t\t\t\t\t\t</svg>\n\t\t\t\t\t</button>\n\t\t\t\t`;\n      row.querySelector(\".remove-header\")?.addEventListener(\"click\", () => {\n        row.remove();\n        if (list.querySelectorAll(\".header-row\").length === 0) {\n          window.addHeaderRow(\"\", \"\");\n        }\n      });\n      list.appendChild(row);\n    };\n\n    window.getHeadersFromUI = function () {\n      const headers = {};\n      document.querySelectorAll(\"#test-headers-list .header-row\").forEach(row => {\n        const key = row.querySelector(\".header-key\")?.value?.trim();\n        const val = row.querySelector(\".header-val\")?.value?.trim();\n        if (key) headers[key] = val || \"\";\n      });\n      return headers;\n    };\n\n    function setTestBodyEnabled(method) {\n      const isGetLike = method === \"GET\" || method === \"HEAD\";\n      const aceWrap = document.getElementById(\"test-body-ace\");\n      if (window.__testBodyEditor) {\n        window.__testBodyEditor.setReadOnly(isGetLike);\n      }\n      if (aceWrap) {\n        if (isGetLike) aceWrap.classList.add(\"opacity-60\");\n        else aceWrap.classList.remove(\"opacity-60\");\n      }\n    }\n\n    window.runEndpointTest = function () {\n      const select = document.getElementById(\"test-endpoint-select\");\n      const epId = select?.value;\n      if (!epId) return;\n      const endpointList = (window.__endpointList && window.__endpointList.length > 0)\n        ? window.__endpointList\n        : Array.from(document.querySelectorAll(\"[data-endpoint-id]\")).map(el => ({\n            id: el.dataset.endpointId,\n            name: el.dataset.endpointName,\n            method: el.dataset.endpointMethod,\n          }));\n      const endpoint = endpointList.find(ep => ep.id === epId);\n      if (!endpoint || !endpoint.name) return;\n\n      const methodSelect = document.getElementById(\"test-method-select\");\n      const methodInput = document.getElementById(\"selected-method-\" + epId);\n      let method = String(methodSelect?.value || methodInput?.value || endpoint.method || \"GET\").toUpperCase();\n      \n      const url = `/lambda${endpoint.name}`;\n      const bodyRaw = window.__testBodyEditor ? window.__testBodyEditor.getValue() : (document.getElementById(\"test-body\")?.value || \"\");\n      const headers = window.getHeadersFromUI();\n\n      const resEl = document.getElementById(\"test-response\");\n      const statusEl = document.getElementById(\"test-status\");\n      if (resEl) resEl.value = \"Sending...\";\n      if (statusEl) statusEl.textContent = \"\";\n\n      (async () => {\n        try {\n          const request = { method, headers };\n          if (method !== \"GET\" && method !== \"HEAD\" && bodyRaw.trim() !== \"\") {\n            request.body = bodyRaw;\n          }\n          const resp = await fetch(url, request);\n          const contentType = resp.headers.get(\"Content-Type\") || \"\";\n          const textBody = await resp.text();\n          if (statusEl) statusEl.textContent = `Status: ${resp.status}`;\n\n          let bodyStr = textBody || \"\";\n          if (contentType.includes(\"application/json\")) {\n            try {\n              const jsonBody = JSON.parse(bodyStr);\n              bodyStr = JSON.stringify(jsonBody, null, 2);\n            } catch (e) { }\n          }\n\n          if (resEl) resEl.value = bodyStr;\n        } catch (e) {\n          if (statusEl) statusEl.textContent = \"Status: error\";\n          if (resEl) resEl.value = String(e);\n        }\n      })();\n    };\n\n    document.addEventListener(\"DOMContentLoaded\", () => {\n      const select = document.getElementById(\"test-endpoint-select\");\n      const methodSelect = document.getElementById(\"test-method-select\");\n      const syncMethodFromEndpoint = () => {\n        if (!select || !methodSelect) return;\n        const endpointList = (window.__endpointList && window.__endpointList.length > 0)\n          ? window.__endpointList\n          : Array.from(document.querySelectorAll(\"[data-endpoint-id]\")).map(el => ({\n              id: el.dataset.endpointId,\n              name: el.dataset.endpointName,\n              method: el.dataset.endpointMethod,\n            }));\n        const endpoint = endpointList.find(ep => ep.id === select.value);\n        methodSelect.value = String(endpoint?.method || \"GET\").toUpperCase();\n        setTestBodyEnabled(methodSelect.value);\n      };\n\n      select?.addEventListener(\"change\", syncMethodFromEndpoint);\n      methodSelect?.addEventListener(\"change\", () => setTestBodyEnabled(String(methodSelect.value || \"GET\").toUpperCase()));\n      syncMethodFromEndpoint();\n    });\n  </script><script>\n    (function () {\n      function ensureWsModalOptions() {\n        const select = document.getElementById(\"ws-endpoint-select\");\n        if (!select) return null;\n        let list = [];\n        if (window.__endpointList && window.__endpointList.length > 0) {\n          list = window.__endpointList;\n        } else {\n          list = Array.from(document.querySelectorAll(\"[data-endpoint-id]\")).map(el => ({\n            id: el.dataset.endpointId,\n            name: el.dataset.endpointName,\n            method: el.dataset.endpointMethod,\n          }));\n        }\n        if (list.length > 0 && select.options.length === 0) {\n          list.forEach(ep => {\n            const opt = document.createElement(\"option\");\n            opt.value = ep.id;\n            opt.textContent = `${ep.method} ${ep.name}`;\n            select.appendChild(opt);\n          });\n        }\n        return select;\n      }\n\n      function wsBaseUrl() {\n        const proto = window.location.protocol === \"https:\" ? \"wss:\" : \"ws:\";\n        return proto + \"//\" + window.location.host;\n      }\n\n      function toWsPath(path) {\n        if (!path) return \"\";\n        if (path.startsWith(\"/lambda/ws/\")) return path;\n        if (path.startsWith(\"/lambda/\")) {\n          return \"/lambda/ws/\" + path.replace(\"/lambda/\", \"\");\n        }\n        if (path.startsWith(\"/\")) return \"/lambda/ws\" + path;\n        return \"/lambda/ws/\" + path;\n      }\n\n      function setWsUrl() {\n        const select = document.getElementById(\"ws-endpoint-select\");\n        const urlEl = document.getElementById(\"ws-url\");\n        if (!select || !urlEl) return;\n        const epId = select.value;\n        const endpointList = (window.__endpointList && window.__endpointList.length > 0)\n          ? window.__endpointList\n          : Array.from(document.querySelectorAll(\"[data-endpoint-id]\")).map(el => ({\n              id: el.dataset.endpointId,\n              name: el.dataset.endpointName,\n              method: el.dataset.endpointMethod,\n            }));\n        const endpoint = endpointList.find(ep => ep.id === epId);\n        const path = endpoint?.name || \"\";\n        const wsUrl = wsBaseUrl() + toWsPath(path);\n        urlEl.value = wsUrl;\n      }\n\n      function setWsStatus(text, cls) {\n        const el = document.getElementById(\"ws-status\");\n        if (!el) return;\n        el.textContent = text;\n        el.className = \"text-xs font-mono bg-neutral-900 px-2 py-1 rounded \" + (cls || \"text-neutral-400\");\n      }\n\n      function logWsMessage(kind, payload) {\n        const log = document.getElementById(\"ws-log\");\n        if (!log) return;\n        const ts = new Date().toLocaleTimeString();\n        const color = kind === \"sent\" ? \"text-cyan-300\" : (kind === \"recv\" ? \"text-green-400\" : \"text-yellow-400\");\n        const line = document.createElement(\"div\");\n        line.className = \"mb-2\";\n        line.innerHTML = `<span class=\"text-neutral-500\">[${ts}]</span> <span class=\"${color}\">${kind.toUpperCase()}</span> <span class=\"text-neutral-300\">•</span> <span class=\"text-neutral-200 whitespace-pre-wrap break-words\"></span>`;\n        line.querySelector(\"span:last-child\").textContent = payload;\n        log.appendChild(line);\n        log.scrollTop = log.scrollHeight;\n      }\n\n      function closeWs() {\n        if (window.__wsConn) {\n          try { window.__wsConn.close(); } catch (e) {}\n          window.__wsConn = null;\n        }\n      }\n\n      window.openWsTestModal = function () {\n        const modal = document.getElementById(\"ws-test-modal\");\n        if (!modal) return;\n        modal.classList.remove(\"hidden\");\n        const select = ensureWsModalOptions();\n        if (select) {\n          select.removeEventListener(\"change\", setWsUrl);\n          select.addEventListener(\"change\", setWsUrl);\n          setWsUrl();\n        }\n      };\n\n      window.openWsTestModalForEndpoint = function (id) {\n        window.openWsTestModal();\n        const select = ensureWsModalOptions();\n        if (!select) return;\n        select.value = id;\n        setWsUrl();\n      };\n\n      window.closeWsTestModal = function () {\n        closeWs();\n        const modal = document.getElementById(\"ws-test-modal\");\n        if (modal) modal.classList.add(\"hidden\");\n        setWsStatus(\"Disconnected\");\n      };\n\n      window.copyWsUrl = function () {\n        const url = document.getElementById(\"ws-url\")?.value || \"\";\n        if (!url) return;\n        navigator.clipboard.writeText(url);\n      };\n\n      window.connectWs = function () {\n        const url = document.getElementById(\"ws-url\")?.value;\n        if (!url) return;\n        closeWs();\n        setWsStatus(\"Connecting...\", \"text-cyan-300\");\n        try {\n          const ws = new WebSocket(url);\n          ws.binaryType = \"arraybuffer\";\n          ws.onopen = () => setWsStatus(\"Connected\", \"text-green-400\");\n          ws.onclose = () => setWsStatus(\"Disconnected\", \"text-neutral-400\");\n          ws.onerror = () => setWsStatus(\"Error\", \"text-red-400\");\n          ws.onmessage = (ev) => {\n            if (typeof ev.data === \"string\") {\n              logWsMessage(\"recv\", ev.data);\n            } else {\n              const view = new Uint8Array(ev.data);\n              const text = new TextDecoder().decode(view);\n              logWsMessage(\"recv\", text || \"[binary]\");\n            }\n          };\n          window.__wsConn = ws;\n        } catch (e) {\n          setWsStatus(\"Error\", \"text-red-400\");\n          logWsMessage(\"info\", String(e));\n        }\n      };\n\n      window.disconnectWs = function () {\n        closeWs();\n        setWsStatus(\"Disconnected\", \"text-neutral-400\");\n      };\n\n      window.sendWsMessage = function () {\n        const ws = window.__wsConn;\n        const msg = document.getElementById(\"ws-message\")?.value || \"\";\n        if (!ws || ws.readyState !== WebSocket.OPEN) {\n          logWsMessage(\"info\", \"Not connected.\");\n          return;\n        }\n        ws.send(msg);\n        logWsMessage(\"sent\", msg);\n      };\n\n      window.clearWsLog = function () {\n        const log = document.getElementById(\"ws-log\");\n        if (log) log.innerHTML = \"\";\n      };\n    })();\n  </script><script>\n    (function () {\n      function isInProgress(run) {\n        if (!run) return false;\n        const rawStatus = run.status || run.Status;\n        if (!rawStatus) return false;\n        const status = String(rawStatus).toLowerCase();\n        return status === \"in_progress\" || status === \"queued\" || status === \"waiting\" || status === \"running\";\n      }\n\n      function getRuns(progress) {\n        if (!progress) return [];\n        if (Array.isArray(progress.Runs)) return progress.Runs;\n        if (Array.isArray(progress.runs)) return progress.runs;\n        return [];\n      }\n\n      function pickActiveRun(progress) {\n        const runs = getRuns(progress);\n        if (!runs.length) return null;\n        return runs.find(isInProgress) || null;\n      }\n\n      function pickDisplayRun(progress) {\n        const runs = getRuns(progress);\n        if (!runs.length) return null;\n        return runs.find(isInProgress) || runs[0];\n      }\n\n      function normalizeName(val) {\n        return String(val || \"\").trim().toLowerCase();\n      }\n\n      function getRunName(run) {\n        return normalizeName(run?.FunctionName || run?.function_name || run?.WorkflowName || run?.workflow_name || run?.Name || run?.name || \"\");\n      }\n\n      function extractFunctionName(run) {\n        const title = String(run?.DisplayTitle || run?.display_title || \"\").toLowerCase();\n        if (!title) return \"\";\n        const m = title.match(/functions\\/(?:go|rs|rust|py|python|ts|typescript|lua)\\/([^.\\s\\/]+)\\./);\n        return m ? m[1] : \"\";\n      }\n\n      function pickRunForFunction(runs, functionName) {\n        const target = normalizeName(functionName);\n        if (!target) return null;\n        const matching = runs.filter(r => {\n          const byName = getRunName(r) === target;\n          const byDisplay = extractFunctionName(r) === target;\n          return byName || byDisplay;\n        });\n        if (!matching.length) return null;\n        return matching;\n      }\n\n      function isRunSuccess(run) {\n        const { status, conclusion } = normalizeStatus(run);\n        if (conclusion === \"success\" || status === \"success\") return true;\n        if (status === \"completed\" && !conclusion) return true;\n        return false;\n      }\n\n      function jobNameForRun(run) {\n        return normalizeName(run?.CurrentJob || run?.current_job || run?.Name || run?.name || \"\");\n      }\n\n      function isRelevantRunForLanguage(run, language) {\n        const relevant = getRelevantJobs(language);\n        if (!relevant.length) return true;\n        const job = jobNameForRun(run);\n        if (!job) return false;\n        return relevant.some(r => job.includes(r));\n      }\n\n      function pickRunForFunctionAndLanguage(runs, functionName, language) {\n        const matching = pickRunForFunction(runs, functionName);\n        if (!matching || !matching.length) return null;\n\n        const activeRelevant = matching.find(r => isInProgress(r) && isRelevantRunForLanguage(r, language));\n        if (activeRelevant) return activeRelevant;\n\n        const successRelevant = matching.find(r => isRunSuccess(r) && isRelevantRunForLanguage(r, language));\n        if (successRelevant) return successRelevant;\n\n        return matching.find(isInProgress) || matching[0];\n      }\n\n      function normalizeStatus(run) {\n        if (!run) return \"\";\n        const status = String(run.status || run.Status || \"\").toLowerCase();\n        const conclusion = String(run.conclusion || run.Conclusion || \"\").toLowerCase();\n        return { status, conclusion };\n      }\n\n      function statusClass(status, conclusion, running) {\n        if (running) {\n          return \"border-[#6b3f17] bg-[#4a2a0c]/40 text-[#e5b07b] hover:bg-[#4a2a0c]/60\";\n        }\n        if (conclusion === \"success\" || status === \"success\") {\n          return \"border-green-700/60 bg-green-700/15 text-green-300 hover:bg-green-700/30\";\n        }\n        if ([\"failure\", \"failed\", \"cancelled\", \"canceled\", \"error\", \"timed_out\"].includes(conclusion) ||\n            [\"failure\", \"failed\", \"cancelled\", \"canceled\", \"error\", \"timed_out\"].includes(status)) {\n          return \"border-red-700/60 bg-red-700/15 text-red-300 hover:bg-red-700/30\";\n        }\n        return \"border-neutral-700 bg-neutral-800/50 text-neutral-300 hover:bg-neutral-800\";\n      }\n\n      function statusLabel(run, running) {\n        const name = run?.Name || run?.name || \"Build\";\n        if (running) return `Running: ${name}`;\n        const { status, conclusion } = normalizeStatus(run);\n        if (conclusion === \"success\" || status === \"success\") return `Success: ${name}`;\n        if ([\"failure\", \"failed\", \"cancelled\", \"canceled\", \"error\", \"timed_out\"].includes(conclusion) ||\n            [\"failure\", \"failed\", \"cancelled\", \"canceled\", \"error\", \"timed_out\"].includes(status)) {\n          return `Failed: ${name}`;\n        }\n        if (status) return `${status}: ${name}`;\n        return name;\n      }\n\n      function normalizeLanguage(val) {\n        const lang = normalizeName(val);\n        if (lang === \"rs\") return \"rust\";\n        return lang;\n      }\n\n      function getRelevantJobs(language) {\n        switch (normalizeLanguage(language)) {\n          case \"go\":\n            return [\"build-go\"];\n          case \"rust\":\n            return [\"build-rust\"];\n          case \"python\":\n            return [\"hook-python\"];\n          case \"ts\":\n            return [\"hook-ts\"];\n          case \"lua\":\n            return [\"hook-lua\"];\n          default:\n            return [];\n        }\n      }\n\n      function isRelevantCurrentJob(language, currentJob) {\n        const relevant = getRelevantJobs(language);\n        if (!relevant.length) return true;\n        const job = normalizeName(currentJob);\n        if (!job) return true;\n        return relevant.some(r => job.includes(r));\n      }\n\n      function updateBuildButtons(progress) {\n        const runs = getRuns(progress);\n        document.querySelectorAll(\"[data-endpoint-id]\").forEach(card => {\n          const endpointId = card.getAttribute(\"data-endpoint-id\");\n          const fnName = card.getAttribute(\"data-endpoint-function\");\n          const language = card.getAttribute(\"data-endpoint-language\") || \"\";\n          const isAsync = card.getAttribute(\"data-endpoint-async\") === \"true\";\n          const buildLink = document.getElementById(`build-status-${endpointId}`);\n          const stepLabel = document.getElementById(`build-step-${endpointId}`);\n          const testBtn = document.getElementById(`test-btn-${endpointId}`);\n          const wsTestBtn = document.getElementById(`ws-test-btn-${endpointId}`);\n          if (!buildLink) return;\n\n          const display = pickRunForFunctionAndLanguage(runs, fnName, language);\n          const baseRunning = display ? isInProgress(display) : false;\n          const url = (display && (display.HTMLURL || display.htmlurl)) || \"\";\n          const jobName = display?.CurrentJob || display?.current_job || \"\";\n          const stepName = display?.CurrentStep || display?.current_step || \"\";\n          const relevantRunning = baseRunning && isRelevantCurrentJob(language, jobName);\n          const effectiveStatus = (baseRunning && !relevantRunning) ? \"success\" : (display?.status || display?.Status);\n          const effectiveConclusion = (baseRunning && !relevantRunning) ? \"success\" : (display?.conclusion || display?.Conclusion);\n          const cls = statusClass(\n            effectiveStatus,\n            effectiveConclusion,\n            relevantRunning\n          );\n          const label = display\n            ? (baseRunning && !relevantRunning\n                ? `Success: ${jobName || (display?.Name || display?.name || \"Build\")}`\n                : statusLabel(display, relevantRunning))\n            : \"No Actions\";\n          const stepText = stepName ? (jobName ? `${jobName} • ${stepName}` : stepName) : jobName;\n\n          if (url) {\n            buildLink.setAttribute(\"href\", url);\n            buildLink.classList.remove(\"pointer-events-none\", \"opacity-70\");\n          } else {\n            buildLink.setAttribute(\"href\", \"#\");\n            buildLink.classList.add(\"pointer-events-none\", \"opacity-70\");\n          }\n          buildLink.className = `px-3 py-1.5 rounded-lg border text-xs font-semibold tracking-wide transition ${cls}`;\n          buildLink.textContent = label;\n          buildLink.classList.remove(\"hidden\");\n          if (stepLabel) {\n            if (stepText) {\n              stepLabel.textContent = `Step: ${stepText}`;\n              stepLabel.classList.remove(\"hidden\");\n            } else {\n              stepLabel.textContent = \"\";\n              stepLabel.classList.add(\"hidden\");\n            }\n          }\n\n          if (isAsync) {\n            if (wsTestBtn) wsTestBtn.classList.remove(\"hidden\");\n            if (testBtn) testBtn.classList.add(\"hidden\");\n          } else {\n            if (wsTestBtn) wsTestBtn.classList.add(\"hidden\");\n            if (testBtn) {\n              const showTest = !relevantRunning;\n              if (showTest) testBtn.classList.remove(\"hidden\");\n              else testBtn.classList.add(\"hidden\");\n            }\n          }\n        });\n      }\n\n      function startActionsSSE() {\n        if (!window.EventSource) return;\n        const es = new EventSource(\"/api/actions/status/\");\n        es.addEventListener(\"status\", (ev) => {\n          try {\n            const data = JSON.parse(ev.data || \"{}\");\n            updateBuildButtons(data);\n          } catch (e) {\n            // ignore malformed payloads\n          }\n        });\n        es.addEventListener(\"error\", () => {\n          // keep UI usable if stream drops\n          updateBuildButtons(null);\n        });\n      }\n\n      document.addEventListener(\"DOMContentLoaded\", startActionsSSE);\n    })();\n  </script><script>\n    // Provide a global method selector for DOMContentLoaded initialization.\n    // templ component scripts are scoped, so we expose a stable name here.\n    window.selectMethodForEndpoint = function (id, method) {\n      const methodStyles = {\n        GET: [\"border-blue-500\", \"bg-blue-500/20\", \"text-blue-400\"],\n        POST: [\"border-green-500\", \"bg-green-500/20\", \"text-green-400\"],\n        PUT: [\"border-yellow-500\", \"bg-yellow-500/20\", \"text-yellow-400\"],\n        PATCH: [\"border-purple-500\", \"bg-purple-500/20\", \"text-purple-400\"],\n        DELETE: [\"border-red-500\", \"bg-red-500/20\", \"text-red-400\"]\n      };\n      const allMethodClasses = [\n        \"border-blue-500\",\"bg-blue-500/20\",\"text-blue-400\",\n        \"border-green-500\",\"bg-green-500/20\",\"text-green-400\",\n        \"border-yellow-500\",\"bg-yellow-500/20\",\"text-yellow-400\",\n        \"border-purple-500\",\"bg-purple-500/20\",\"text-purple-400\",\n        \"border-red-500\",\"bg-red-500/20\",\"text-red-400\"\n      ];\n      const neutralClasses = [\"border-neutral-700\", \"text-neutral-400\"];\n      [\"GET\", \"POST\", \"PUT\", \"PATCH\", \"DELETE\"].forEach(m => {\n        const btn = document.getElementById(\"method-\" + m + \"-\" + id);\n        if (btn) {\n          btn.classList.remove(...allMethodClasses);\n          btn.classList.remove(...neutralClasses);\n          btn.classList.add(...neutralClasses);\n        }\n      });\n      const selected = document.getElementById(\"method-\" + method + \"-\" + id);\n      if (selected) {\n        selected.classList.remove(...neutralClasses);\n        const style = methodStyles[method] || methodStyles.GET;\n        selected.classList.add(...style);\n      }\n      const input = document.getElementById(\"selected-method-\" + id);\n      if (input) input.value = method;\n    };\n  </script><script>\n    document.addEventListener(\"DOMContentLoaded\", () => {\n      document.querySelectorAll('input[id^=\"selected-method-\"]').forEach(el => {\n        const id = el.id.replace(\"selected-method-\", \"\");\n        const method = el.value || \"GET\";\n        selectMethodForEndpoint(id, method);\n      });\n    });\n  </script><style>\n    .ace_editor,\n    .ace_scroller,\n    .ace_content {\n      background: #0b0b0c !important;\n      color: #eee !important;\n    }\n  </style></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
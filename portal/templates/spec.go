@@ -34,3 +34,38 @@ type Lang struct {
 	Icon    string
 	AceMode string
 }
+
+type Run struct {
+	RequestID string
+	Method    string
+	Endpoint  string
+	Source    string
+	Status    int32
+	LatencyMs int64
+	ColdStart bool
+	Async     bool
+	Bytes     string
+	Error     string
+	StartedAt string
+}
+
+type RunsSummary struct {
+	Total        int64
+	ServerErrors int64
+	ClientErrors int64
+	ColdStarts   int64
+	AvgLatencyMs int64
+	P95LatencyMs int64
+}
+
+// RunsFilter echoes the runs page query so the form keeps its values.
+type RunsFilter struct {
+	Window string
+	Status string
+	Source string
+	Cold   string
+	Query  string
+	Error  string
+	// Older links to the next page when the current one is full.
+	Older string
+}
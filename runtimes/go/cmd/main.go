@@ -37,9 +37,15 @@ func startHTTPServer(state *pkg.AppState, settings *pkg.Settings, logger *slog.L
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", pkg.Handle)
 
+	// the ingestor speaks cleartext HTTP/2 to go runtimes, HTTP/1.1 still
+	// works for everything else
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
 	server := &http.Server{
 		Addr:              ":" + port,
 		Handler:           mux,
+		Protocols:         protocols,
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 	logger.Info("starting http server", "addr", server.Addr)
@@ -21,6 +21,23 @@ type Settings struct {
 	UseTelemetry bool   `env:"USE_TELEMETRY"`
 
 	HttpPort string `env:"HTTP_PORT"`
+
+	// Dev is set by the local dev emulator, DATABASE_URL and REDIS_URL are
+	// optional then.
+	Dev bool `env:"LITEFUNCTIONS_DEV" default:"false"`
+
+	PayloadInlineLimit int `env:"PAYLOAD_INLINE_LIMIT" default:"524288"`
+
+	InvokeTimeout string `env:"INVOKE_TIMEOUT" default:"30s"`
+
+	// Concurrency bounds the async requests handled at once, PriorityWeights
+	// how often each lane is picked while they're all backed up and
+	// QueueLimit how many requests a lane holds before dropping new ones.
+	// Sync requests skip the lanes, SyncConcurrency bounds them on their own.
+	Concurrency     int    `env:"CONCURRENCY" default:"32"`
+	PriorityWeights string `env:"PRIORITY_WEIGHTS" default:"high=6,normal=3,low=1"`
+	QueueLimit      int    `env:"QUEUE_LIMIT" default:"1000"`
+	SyncConcurrency int    `env:"SYNC_CONCURRENCY" default:"32"`
 }
 
 var (
@@ -9,28 +9,90 @@ import (
 	"github.com/nats-io/nats.go"
 )
 
-func Consume(ctx context.Context, state *AppState, subject string) error {
+// Consume subscribes to every lane under prefix. Async requests are queued
+// per priority, at most QUEUE_LIMIT each, and run at most CONCURRENCY at a
+// time, taken from the lanes by their PRIORITY_WEIGHTS. Sync requests have
+// callers waiting on them, they skip the queues and run as they arrive, at
+// most SYNC_CONCURRENCY at a time.
+func Consume(ctx context.Context, state *AppState, prefix string) error {
 	logger := slog.Default().With(
 		"project", settings.Project,
 		"function", settings.Name,
 	)
-	logger.Info("waiting for messages")
-	sub, err := state.Nc.Subscribe(subject, func(msg *nats.Msg) {
-		logger.Info("received event", "subject", msg.Subject)
+	weights, err := parseWeights(settings.PriorityWeights)
+	if err != nil {
+		return err
+	}
+	serve := func(msg *nats.Msg) {
 		parts := strings.Split(msg.Subject, ".")
 		reqID := parts[len(parts)-1]
 		logger.Info("request id extracted", "request_id", reqID)
-		payload := append([]byte(nil), msg.Data...)
-		go handleMessage(state, logger, reqID, payload)
+		payload, release, err := resolvePayload(state, msg)
+		if err != nil {
+			logger.Error("failed to resolve payload", "error", err, "request_id", reqID)
+			return
+		}
+		handleMessage(state, logger, reqID, payload)
+		if err := release(); err != nil {
+			logger.Warn("failed to release payload", "error", err, "request_id", reqID)
+		}
+	}
+
+	syncSlots := make(chan struct{}, max(settings.SyncConcurrency, 1))
+	syncSub, err := state.Nc.Subscribe(prefix+"."+SyncLane+".*", func(msg *nats.Msg) {
+		logger.Info("received event", "subject", msg.Subject)
+		// with every slot taken, requests wait in the subscription
+		syncSlots <- struct{}{}
+		go func() {
+			defer func() { <-syncSlots }()
+			serve(msg)
+		}()
 	})
 	if err != nil {
 		return err
 	}
-	defer sub.Unsubscribe()
+	defer syncSub.Unsubscribe()
+	if err := syncSub.SetPendingLimits(max(settings.QueueLimit, 1), -1); err != nil {
+		return err
+	}
 
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
+	queued := newLanes(weights, settings.QueueLimit)
+	for i, priority := range Priorities {
+		subject := prefix + "." + priority + ".*"
+		if priority == "normal" {
+			subject = prefix + ".*"
+		}
+		sub, err := state.Nc.Subscribe(subject, func(msg *nats.Msg) {
+			if !queued.push(i, msg) {
+				logger.Warn("lane full, dropping request", "priority", priority, "subject", msg.Subject)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		defer sub.Unsubscribe()
+	}
+	logger.Info("waiting for messages", "concurrency", settings.Concurrency, "sync_concurrency", settings.SyncConcurrency,
+		"weights", weights, "queue_limit", settings.QueueLimit)
+
+	slots := make(chan struct{}, max(settings.Concurrency, 1))
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case slots <- struct{}{}:
+		}
+		// the lane is chosen once a slot is free, so urgent requests that
+		// arrived meanwhile go first
+		msg, err := queued.next(ctx)
+		if err != nil {
+			return err
+		}
+		logger.Info("received event", "subject", msg.Subject)
+		go func() {
+			defer func() { <-slots }()
+			serve(msg)
+		}()
 	}
 }
 
@@ -45,8 +107,16 @@ func handleMessage(state *AppState, logger *slog.Logger, reqID string, payload [
 		return
 	}
 
+	subject := fmt.Sprintf("%s.%s.res.go.%s", settings.Project, settings.Name, reqID)
+	seq := 0
 	for res := range out {
-		if err := state.Nc.Publish(fmt.Sprintf("%s.%s.res.go.%s", settings.Project, settings.Name, reqID), res); err != nil {
+		msg, err := resultMessage(state, subject, fmt.Sprintf("%s.%s.res.%s.%d", settings.Project, settings.Name, reqID, seq), res)
+		seq++
+		if err != nil {
+			logger.Error("failed to offload response", "error", err, "request_id", reqID)
+			continue
+		}
+		if err := state.Nc.PublishMsg(msg); err != nil {
 			logger.Error("failed to publish response", "error", err, "request_id", reqID)
 		}
 	}
@@ -59,9 +129,9 @@ func StartFunction(ctx context.Context, state *AppState) error {
 		"function", settings.Name,
 	)
 	name := fmt.Sprintf("%s-%s", settings.Project, settings.Name)
-	subject := fmt.Sprintf("%s.%s.exec.go.*", settings.Project, settings.Name)
-	logger.Info("starting consumer", "subject", subject, "name", name)
-	err := Consume(ctx, state, subject)
+	prefix := fmt.Sprintf("%s.%s.exec.go", settings.Project, settings.Name)
+	logger.Info("starting consumer", "prefix", prefix, "name", name)
+	err := Consume(ctx, state, prefix)
 	if err != nil {
 		return fmt.Errorf("ERR-CONSUMER: %v", err)
 	}
@@ -0,0 +1,144 @@
+package pkg
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Functions call each other through the ingestors, which answer requests on
+// InvokeSubject.{project}.{name} with the same policies and dispatch as
+// public requests.
+const (
+	InvokeSubject      = "litefunctions.invoke"
+	InvokeMethodHeader = "X-Litefunction-Method"
+	InvokeStatusHeader = "X-Litefunction-Status"
+	CallerHeader       = "X-Litefunction-Caller"
+	CallChainHeader    = "X-Litefunction-Call-Chain"
+	CallTokenHeader    = "X-Litefunction-Call-Token"
+	DeadlineHeader     = "X-Litefunction-Deadline"
+	TraceparentHeader  = "Traceparent"
+	TracestateHeader   = "Tracestate"
+)
+
+// identityHeaders are copied from the request being served so the callee sees
+// the same end user. The ingestor only trusts the identity signed into the
+// call token, the others are informational.
+var identityHeaders = []string{
+	CallTokenHeader,
+	"X-Litefunction-Api-Key-Id",
+	"X-Litefunction-Subject",
+	"X-Litefunction-Claims",
+	TracestateHeader,
+}
+
+type InvokeRequest struct {
+	Function string
+	Method   string
+	Header   http.Header
+	Body     []byte
+}
+
+type InvokeResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Invoke calls another function of this project. parent is the header of the
+// request being served, or nil outside of one: the caller's identity, trace
+// context and remaining deadline are carried over from it, and the call fails
+// with 508 if it would loop back or go deeper than the ingestor allows.
+func (s *AppState) Invoke(ctx context.Context, parent http.Header, req InvokeRequest) (*InvokeResponse, error) {
+	if req.Function == "" || strings.ContainsAny(req.Function, ". *>") {
+		return nil, fmt.Errorf("ERR-INVOKE: invalid function name %q", req.Function)
+	}
+	if parent == nil {
+		parent = http.Header{}
+	}
+	if raw := parent.Get(DeadlineHeader); raw != "" {
+		if deadline, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, deadline)
+			defer cancel()
+		}
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		timeout, err := time.ParseDuration(settings.InvokeTimeout)
+		if err != nil {
+			timeout = 30 * time.Second
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	deadline, _ := ctx.Deadline()
+
+	msg := nats.NewMsg(fmt.Sprintf("%s.%s.%s", InvokeSubject, settings.Project, req.Function))
+	for k, vals := range req.Header {
+		if !strings.HasPrefix(http.CanonicalHeaderKey(k), "X-Litefunction-") {
+			msg.Header[k] = vals
+		}
+	}
+	for _, k := range identityHeaders {
+		if v := parent.Get(k); v != "" {
+			msg.Header.Set(k, v)
+		}
+	}
+	self := settings.Project + "/" + settings.Name
+	chain := self
+	if prev := parent.Get(CallChainHeader); prev != "" {
+		chain = prev + "," + self
+	}
+	msg.Header.Set(CallerHeader, self)
+	msg.Header.Set(CallChainHeader, chain)
+	msg.Header.Set(DeadlineHeader, deadline.UTC().Format(time.RFC3339Nano))
+	msg.Header.Set(TraceparentHeader, childTraceparent(parent.Get(TraceparentHeader)))
+	if req.Method != "" {
+		msg.Header.Set(InvokeMethodHeader, req.Method)
+	}
+	msg.Data = req.Body
+
+	res, err := s.Nc.RequestMsgWithContext(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("ERR-INVOKE: %s: %v", req.Function, err)
+	}
+	status, err := strconv.Atoi(res.Header.Get(InvokeStatusHeader))
+	if err != nil {
+		return nil, fmt.Errorf("ERR-INVOKE: %s: reply without status", req.Function)
+	}
+	header := http.Header(res.Header).Clone()
+	header.Del(InvokeStatusHeader)
+	return &InvokeResponse{Status: status, Header: header, Body: res.Data}, nil
+}
+
+// Invoke calls another function on behalf of the HTTP request r being served.
+func Invoke(r *http.Request, req InvokeRequest) (*InvokeResponse, error) {
+	if appState == nil {
+		return nil, fmt.Errorf("ERR-INVOKE: runtime state not initialized")
+	}
+	return appState.Invoke(r.Context(), r.Header, req)
+}
+
+// childTraceparent continues the caller's W3C trace with a new span, or
+// starts a trace when there is none.
+func childTraceparent(parent string) string {
+	traceID, flags := randomHex(16), "01"
+	if parts := strings.Split(parent, "-"); len(parts) == 4 && len(parts[1]) == 32 && len(parts[3]) == 2 {
+		traceID, flags = parts[1], parts[3]
+	}
+	return fmt.Sprintf("00-%s-%s-%s", traceID, randomHex(8), flags)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
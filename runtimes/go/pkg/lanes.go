@@ -0,0 +1,127 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Priorities are the async lanes, highest first. The ingestor publishes
+// normal requests to {project}.{name}.exec.go.{id} and the others to
+// {project}.{name}.exec.go.{priority}.{id}.
+var Priorities = []string{"high", "normal", "low"}
+
+// SyncLane carries requests a caller waits on, to
+// {project}.{name}.exec.go.sync.{id}.
+const SyncLane = "sync"
+
+// lanes queues received requests per priority, up to limit per lane. next
+// hands them out by smooth weighted round robin: with every lane backed up, a
+// lane gets a share of the picks proportional to its weight, so higher lanes
+// drain first without starving lower ones.
+type lanes struct {
+	mu      sync.Mutex
+	queues  [][]*nats.Msg
+	weights []int
+	current []int
+	limit   int
+	ready   chan struct{}
+}
+
+func newLanes(weights []int, limit int) *lanes {
+	return &lanes{
+		queues:  make([][]*nats.Msg, len(weights)),
+		weights: weights,
+		current: make([]int, len(weights)),
+		limit:   max(limit, 1),
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+// push queues msg, or reports false when its lane is full.
+func (l *lanes) push(lane int, msg *nats.Msg) bool {
+	l.mu.Lock()
+	if len(l.queues[lane]) >= l.limit {
+		l.mu.Unlock()
+		return false
+	}
+	l.queues[lane] = append(l.queues[lane], msg)
+	l.mu.Unlock()
+	select {
+	case l.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// next blocks until a request is queued or ctx is done.
+func (l *lanes) next(ctx context.Context) (*nats.Msg, error) {
+	for {
+		if msg := l.pick(); msg != nil {
+			return msg, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-l.ready:
+		}
+	}
+}
+
+func (l *lanes) pick() *nats.Msg {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	best, total := -1, 0
+	for i, q := range l.queues {
+		if len(q) == 0 {
+			continue
+		}
+		l.current[i] += l.weights[i]
+		total += l.weights[i]
+		if best < 0 || l.current[i] > l.current[best] {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	l.current[best] -= total
+	msg := l.queues[best][0]
+	l.queues[best][0] = nil
+	l.queues[best] = l.queues[best][1:]
+	return msg
+}
+
+// parseWeights reads PRIORITY_WEIGHTS, e.g. "high=6,normal=3,low=1", into
+// weights ordered like Priorities. Lanes left out get a weight of 1.
+func parseWeights(raw string) ([]int, error) {
+	weights := make([]int, len(Priorities))
+	for i := range weights {
+		weights[i] = 1
+	}
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		weight, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || err != nil || weight <= 0 {
+			return nil, fmt.Errorf("invalid priority weight %q", part)
+		}
+		lane := -1
+		for i, p := range Priorities {
+			if p == strings.TrimSpace(name) {
+				lane = i
+			}
+		}
+		if lane < 0 {
+			return nil, fmt.Errorf("unknown priority %q", name)
+		}
+		weights[lane] = weight
+	}
+	return weights, nil
+}
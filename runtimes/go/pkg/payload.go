@@ -0,0 +1,106 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Bodies above the ingestor's inline limit travel through a JetStream object
+// store, the NATS message then only carries PayloadRefHeader.
+const (
+	PayloadsBucket   = "litefunctions-payloads"
+	PayloadRefHeader = "X-Litefunction-Payload-Ref"
+)
+
+// OpenPayload fetches an offloaded body by reference. References are scoped
+// to the function, so a message can't point at another function's objects.
+// The object is left in place for a redelivered message, ReleasePayload
+// removes it once the request was handled.
+func OpenPayload(ctx context.Context, state *AppState, ref string) ([]byte, error) {
+	if !strings.HasPrefix(ref, fmt.Sprintf("%s.%s.", settings.Project, settings.Name)) {
+		return nil, fmt.Errorf("ERR-PAYLOAD: reference %q belongs to another function", ref)
+	}
+	obj, err := payloadStore(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	data, err := obj.GetBytes(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("ERR-PAYLOAD: %v", err)
+	}
+	return data, nil
+}
+
+// ReleasePayload removes an offloaded body. Ones left behind expire with
+// the bucket's TTL.
+func ReleasePayload(ctx context.Context, state *AppState, ref string) error {
+	obj, err := payloadStore(ctx, state)
+	if err != nil {
+		return err
+	}
+	if err := obj.Delete(ctx, ref); err != nil {
+		return fmt.Errorf("ERR-PAYLOAD: %v", err)
+	}
+	return nil
+}
+
+// resolvePayload returns the request body, following a claim check if the
+// ingestor offloaded it, and a release func to call once it was handled.
+func resolvePayload(state *AppState, msg *nats.Msg) ([]byte, func() error, error) {
+	ref := msg.Header.Get(PayloadRefHeader)
+	if ref == "" {
+		return append([]byte(nil), msg.Data...), func() error { return nil }, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	data, err := OpenPayload(ctx, state, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	release := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return ReleasePayload(ctx, state, ref)
+	}
+	return data, release, nil
+}
+
+// resultMessage offloads results above the inline limit so they aren't
+// rejected by the NATS max payload.
+func resultMessage(state *AppState, subject, ref string, res []byte) (*nats.Msg, error) {
+	msg := &nats.Msg{Subject: subject, Data: res}
+	if settings.PayloadInlineLimit <= 0 || len(res) <= settings.PayloadInlineLimit {
+		return msg, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	obj, err := payloadStore(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := obj.Put(ctx, jetstream.ObjectMeta{Name: ref}, bytes.NewReader(res)); err != nil {
+		return nil, fmt.Errorf("ERR-PAYLOAD: %v", err)
+	}
+	msg.Data = nil
+	msg.Header = nats.Header{}
+	msg.Header.Set(PayloadRefHeader, ref)
+	return msg, nil
+}
+
+func payloadStore(ctx context.Context, state *AppState) (jetstream.ObjectStore, error) {
+	js, err := jetstream.New(state.Nc)
+	if err != nil {
+		return nil, fmt.Errorf("ERR-PAYLOAD: %v", err)
+	}
+	obj, err := js.ObjectStore(ctx, PayloadsBucket)
+	if err != nil {
+		return nil, fmt.Errorf("ERR-PAYLOAD: %v", err)
+	}
+	return obj, nil
+}
@@ -17,6 +17,10 @@ type AppState struct {
 	Nc          *nats.Conn
 }
 
+// appState is the runtime's state once NewAppState succeeded, for handlers
+// that aren't handed it.
+var appState *AppState
+
 func NewAppState(ctx context.Context) (*AppState, error) {
 	settings := LoadSettings()
 	if err := validateSettings(settings); err != nil {
@@ -31,24 +35,30 @@ func NewAppState(ctx context.Context) (*AppState, error) {
 		settings.RedisUrl != "",
 	)
 
-	dbPool, err := pgxpool.New(ctx, settings.DatabaseUrl)
-	if err != nil {
-		return nil, fmt.Errorf("ERR-DB-CONN: %v", err)
+	// the local dev emulator runs functions without a database or redis
+	// unless they are configured
+	var dbPool *pgxpool.Pool
+	if settings.DatabaseUrl != "" {
+		pool, err := pgxpool.New(ctx, settings.DatabaseUrl)
+		if err != nil {
+			return nil, fmt.Errorf("ERR-DB-CONN: %v", err)
+		}
+		dbPool = pool
 	}
 
-	redisOptions, err := redis.ParseURL(settings.RedisUrl)
-	if err != nil {
-		return nil, fmt.Errorf("ERR-REDIS-PARSE: %v", err)
-	}
-	if redisOptions.Password == "" && settings.RedisPassword != "" {
-		redisOptions.Password = settings.RedisPassword
-	}
-	redisClient := redis.NewClient(redisOptions)
-	if err := redisClient.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("ERR-REDIS-CONN: %v", err)
-	}
-	if err := redisClient.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("ERR-REDIS-CONN: %v", err)
+	var redisClient *redis.Client
+	if settings.RedisUrl != "" {
+		redisOptions, err := redis.ParseURL(settings.RedisUrl)
+		if err != nil {
+			return nil, fmt.Errorf("ERR-REDIS-PARSE: %v", err)
+		}
+		if redisOptions.Password == "" && settings.RedisPassword != "" {
+			redisOptions.Password = settings.RedisPassword
+		}
+		redisClient = redis.NewClient(redisOptions)
+		if err := redisClient.Ping(ctx).Err(); err != nil {
+			return nil, fmt.Errorf("ERR-REDIS-CONN: %v", err)
+		}
 	}
 
 	nc, err := nats.Connect(settings.NatsUrl)
@@ -56,11 +66,12 @@ func NewAppState(ctx context.Context) (*AppState, error) {
 		return nil, fmt.Errorf("ERR-NATS-CONN: %v", err)
 	}
 
-	return &AppState{
+	appState = &AppState{
 		DBPool:      dbPool,
 		RedisClient: redisClient,
 		Nc:          nc,
-	}, nil
+	}
+	return appState, nil
 }
 
 func validateSettings(settings *Settings) error {
@@ -70,10 +81,10 @@ func validateSettings(settings *Settings) error {
 	if settings.Name == "" {
 		return fmt.Errorf("ERR-SETTINGS: NAME is required (set env NAME)")
 	}
-	if settings.DatabaseUrl == "" {
+	if settings.DatabaseUrl == "" && !settings.Dev {
 		return fmt.Errorf("ERR-SETTINGS: DATABASE_URL is required (set env DATABASE_URL)")
 	}
-	if settings.RedisUrl == "" {
+	if settings.RedisUrl == "" && !settings.Dev {
 		return fmt.Errorf("ERR-SETTINGS: REDIS_URL is required (set env REDIS_URL)")
 	}
 	if settings.NatsUrl == "" {
@@ -13,6 +13,7 @@ local function start_event_loop(state)
   server:settimeout(0)
 
   local clients = {}
+  local pending = false
 
   while true do
     local readset = { server, state.nats.sock }
@@ -20,7 +21,8 @@ local function start_event_loop(state)
       readset[#readset + 1] = c
     end
 
-    local ready = socket.select(readset, nil, 0.25)
+    -- don't idle while requests are queued
+    local ready = socket.select(readset, nil, pending and 0 or 0.25)
     for _, s in ipairs(ready) do
       if s == server then
         local c = server:accept()
@@ -55,12 +57,13 @@ local function start_event_loop(state)
         end
       end
     end
+    pending = consumer.drain(state)
   end
 end
 
 local state, err = state_mod.new()
 if not state then
-  error("failed to connect nats: " .. tostring(err))
+  error("failed to start lua runtime: " .. tostring(err))
 end
 
 local ok, sync_err = funcs.sync_repo_and_reload()
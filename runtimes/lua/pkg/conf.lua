@@ -8,6 +8,11 @@ M.settings = {
   git_user = os.getenv("GIT_USER") or "",
   git_token = os.getenv("GIT_TOKEN") or "",
   http_port = tonumber(os.getenv("HTTP_PORT") or "8080") or 8080,
+  -- requests run one at a time: priority_weights sets how often each async
+  -- lane is picked while they're all backed up, queue_limit how many
+  -- requests a lane holds before dropping new ones
+  priority_weights = os.getenv("PRIORITY_WEIGHTS") or "high=6,normal=3,low=1",
+  queue_limit = tonumber(os.getenv("QUEUE_LIMIT") or "1000") or 1000,
 }
 
 if M.settings.project == "" or M.settings.nats_url == "" then
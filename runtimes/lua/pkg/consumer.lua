@@ -1,43 +1,88 @@
 local conf = require("pkg.conf")
 local funcs = require("pkg.functions")
+local lanes = require("pkg.lanes")
 
 local M = {}
 
-function M.consume_function(state, name)
-  local subject = string.format("%s.%s.exec.lua.*", conf.settings.project, name)
-  local sid, sub_err = state.nats:subscribe(subject, function(msg_subject, payload)
-    local parts = {}
-    for token in string.gmatch(msg_subject, "[^.]+") do
-      parts[#parts + 1] = token
-    end
+local function request_id(msg_subject)
+  local parts = {}
+  for token in string.gmatch(msg_subject, "[^.]+") do
+    parts[#parts + 1] = token
+  end
+  -- the request id is always the last token, whatever the lane
+  return parts[#parts] or ""
+end
 
-    local req_id = parts[5] or ""
-    if req_id == "" then
-      return
-    end
+local function handle(state, req)
+  local res_subject = string.format("%s.%s.res.lua.%s", conf.settings.project, req.name, req.id)
+  local out, err = funcs.invoke_lua(req.name, req.payload)
+  if err then
+    out = err
+    conf.log("ERROR", "lua async invoke failed", {
+      function_name = req.name,
+      error = err,
+    })
+  end
+  state.nats:publish(res_subject, out)
+end
 
-    local res_subject = string.format("%s.%s.res.lua.%s", conf.settings.project, name, req_id)
-    local out, err = funcs.invoke_lua(name, payload)
-    if err then
-      out = err
-      conf.log("ERROR", "lua async invoke failed", {
+-- consume_function queues the function's requests for drain: async ones per
+-- priority lane, sync ones apart so they never wait behind async work. Lanes
+-- hold at most QUEUE_LIMIT requests, further ones are dropped.
+function M.consume_function(state, name)
+  local prefix = string.format("%s.%s.exec.lua", conf.settings.project, name)
+  local sids = {}
+
+  local function subscribe(queued, lane, subject)
+    local sid, sub_err = state.nats:subscribe(subject, function(msg_subject, payload)
+      local req_id = request_id(msg_subject)
+      if req_id == "" then
+        return
+      end
+      if not queued:push(lane, { name = name, id = req_id, payload = payload }) then
+        conf.log("WARN", "lane full, dropping request", { function_name = name, subject = msg_subject })
+      end
+    end)
+    if not sid then
+      conf.log("ERROR", "failed to start lua consumer", {
         function_name = name,
-        error = err,
+        subject = subject,
+        error = sub_err,
       })
+      return false
     end
-    state.nats:publish(res_subject, out)
-  end)
+    sids[#sids + 1] = sid
+    return true
+  end
 
-  if sid then
-    state.consumers[name] = sid
-    conf.log("INFO", "lua consumer started", { function_name = name, subject = subject })
-  else
-    conf.log("ERROR", "failed to start lua consumer", {
-      function_name = name,
-      subject = subject,
-      error = sub_err,
-    })
+  local ok = subscribe(state.sync_queue, 1, lanes.lane_subject(prefix, lanes.SYNC_LANE))
+  for i, priority in ipairs(lanes.PRIORITIES) do
+    ok = ok and subscribe(state.async_lanes, i, lanes.lane_subject(prefix, priority))
+  end
+  if not ok then
+    for _, sid in ipairs(sids) do
+      state.nats:unsubscribe(sid)
+    end
+    return
   end
+  state.consumers[name] = sids
+  conf.log("INFO", "lua consumer started", { function_name = name, prefix = prefix })
+end
+
+-- drain runs the queued sync requests, then one async request picked by the
+-- lane weights, so that sync requests and higher lanes that arrive meanwhile
+-- go next. It reports whether requests are still queued.
+function M.drain(state)
+  local req = state.sync_queue:pick()
+  while req do
+    handle(state, req)
+    req = state.sync_queue:pick()
+  end
+  req = state.async_lanes:pick()
+  if req then
+    handle(state, req)
+  end
+  return not (state.sync_queue:empty() and state.async_lanes:empty())
 end
 
 function M.reconcile_consumers(state)
@@ -46,9 +91,11 @@ function M.reconcile_consumers(state)
     desired[name] = true
   end
 
-  for name, sid in pairs(state.consumers) do
+  for name, sids in pairs(state.consumers) do
     if not desired[name] then
-      state.nats:unsubscribe(sid)
+      for _, sid in ipairs(sids) do
+        state.nats:unsubscribe(sid)
+      end
       state.consumers[name] = nil
     end
   end
@@ -0,0 +1,110 @@
+-- Priorities are the async lanes, highest first. The ingestor publishes
+-- normal requests to {project}.{name}.exec.lua.{id}, the others to
+-- {project}.{name}.exec.lua.{priority}.{id} and requests a caller waits on to
+-- {project}.{name}.exec.lua.sync.{id}.
+local M = {
+  PRIORITIES = { "high", "normal", "low" },
+  SYNC_LANE = "sync",
+}
+
+function M.lane_subject(prefix, lane)
+  if lane == "normal" then
+    return prefix .. ".*"
+  end
+  return string.format("%s.%s.*", prefix, lane)
+end
+
+-- parse_weights reads PRIORITY_WEIGHTS, e.g. "high=6,normal=3,low=1", into
+-- weights ordered like PRIORITIES. Lanes left out get a weight of 1.
+function M.parse_weights(raw)
+  local weights = {}
+  for i = 1, #M.PRIORITIES do
+    weights[i] = 1
+  end
+  for part in string.gmatch(raw or "", "[^,]+") do
+    part = part:match("^%s*(.-)%s*$")
+    if part ~= "" then
+      local name, value = part:match("^([^=]-)%s*=%s*(%d+)$")
+      local weight = tonumber(value)
+      if not name or not weight or weight <= 0 then
+        return nil, string.format("invalid priority weight %q", part)
+      end
+      local lane
+      for i, p in ipairs(M.PRIORITIES) do
+        if p == name then
+          lane = i
+        end
+      end
+      if not lane then
+        return nil, string.format("unknown priority %q", name)
+      end
+      weights[lane] = weight
+    end
+  end
+  return weights
+end
+
+-- Lanes queues received requests per lane, up to limit each. pick hands them
+-- out by smooth weighted round robin: with every lane backed up, a lane gets
+-- a share of the picks proportional to its weight, so higher lanes drain
+-- first without starving lower ones.
+local Lanes = {}
+Lanes.__index = Lanes
+
+function M.new(weights, limit)
+  local self = setmetatable({
+    weights = weights,
+    limit = math.max(limit or 1, 1),
+    queues = {},
+    current = {},
+  }, Lanes)
+  for i = 1, #weights do
+    self.queues[i] = { first = 1, last = 0 }
+    self.current[i] = 0
+  end
+  return self
+end
+
+-- push queues item, or reports false when its lane is full.
+function Lanes:push(lane, item)
+  local q = self.queues[lane]
+  if q.last - q.first + 1 >= self.limit then
+    return false
+  end
+  q.last = q.last + 1
+  q[q.last] = item
+  return true
+end
+
+function Lanes:empty()
+  for _, q in ipairs(self.queues) do
+    if q.last >= q.first then
+      return false
+    end
+  end
+  return true
+end
+
+function Lanes:pick()
+  local best, total = nil, 0
+  for i, q in ipairs(self.queues) do
+    if q.last >= q.first then
+      self.current[i] = self.current[i] + self.weights[i]
+      total = total + self.weights[i]
+      if not best or self.current[i] > self.current[best] then
+        best = i
+      end
+    end
+  end
+  if not best then
+    return nil
+  end
+  self.current[best] = self.current[best] - total
+  local q = self.queues[best]
+  local item = q[q.first]
+  q[q.first] = nil
+  q.first = q.first + 1
+  return item
+end
+
+return M
@@ -2,6 +2,7 @@ local socket = require("socket")
 local cjson = require("cjson.safe")
 
 local conf = require("pkg.conf")
+local lanes = require("pkg.lanes")
 
 local M = {}
 
@@ -129,6 +130,10 @@ function NATS:poll_once()
 end
 
 function M.new()
+  local weights, werr = lanes.parse_weights(conf.settings.priority_weights)
+  if not weights then
+    return nil, werr
+  end
   local nats, err = NATS.new(conf.settings.nats_url)
   if not nats then
     return nil, err
@@ -145,6 +150,8 @@ function M.new()
   return {
     nats = nats,
     consumers = {},
+    sync_queue = lanes.new({ 1 }, conf.settings.queue_limit),
+    async_lanes = lanes.new(weights, conf.settings.queue_limit),
   }
 end
 
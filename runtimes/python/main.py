@@ -15,6 +15,7 @@ from fastapi import FastAPI, Request, Response
 from nats.aio.msg import Msg
 
 from pkg.conf import settings
+from pkg.lanes import PRIORITIES, SYNC_LANE, Lanes, lane_subject, parse_weights
 from pkg.state import AppState
 
 logger = logging.getLogger(__name__)
@@ -233,20 +234,62 @@ async def invoke_module(
 
 
 async def consume_function(state: AppState, name: str) -> None:
-    subject = f"{settings.project}.{name}.exec.py.*"
-    consumer = await state.js.pull_subscribe(subject, stream=settings.project)
-    logger.info("python consumer started function=%s subject=%s", name, subject)
-
-    while True:
-        msgs = await consumer.fetch(timeout=None)
-        msg: Msg = msgs[0]
-        await msg.ack()
+    """Async requests are queued per priority, at most QUEUE_LIMIT each, and
+    run at most CONCURRENCY at a time, taken from the lanes by their
+    PRIORITY_WEIGHTS. Sync requests have callers waiting on them, they skip
+    the queues and run as they arrive, at most SYNC_CONCURRENCY at a time."""
+    prefix = f"{settings.project}.{name}.exec.py"
+    weights = parse_weights(settings.priority_weights)
+    queued: Lanes[Msg] = Lanes(weights, settings.queue_limit)
+    running: set[asyncio.Task] = set()
+
+    async def handle(msg: Msg, slots: asyncio.Semaphore) -> None:
         req_id = msg.subject.split(".")[-1]
         try:
             async for out in invoke_module(state, name, req_id, msg.data):
                 await state.nc.publish(f"{settings.project}.{name}.res.py.{req_id}", out)
         except Exception as exc:
             logger.exception("failed to handle async event for %s: %s", name, exc)
+        finally:
+            slots.release()
+
+    def start(msg: Msg, slots: asyncio.Semaphore) -> None:
+        task = asyncio.create_task(handle(msg, slots))
+        running.add(task)
+        task.add_done_callback(running.discard)
+
+    async def fill(lane: int, subject: str) -> None:
+        consumer = await state.js.pull_subscribe(subject, stream=settings.project)
+        while True:
+            msgs = await consumer.fetch(timeout=None)
+            for msg in msgs:
+                await msg.ack()
+                await queued.push(lane, msg)
+
+    async def serve_sync() -> None:
+        slots = asyncio.Semaphore(max(settings.sync_concurrency, 1))
+        consumer = await state.js.pull_subscribe(lane_subject(prefix, SYNC_LANE), stream=settings.project)
+        while True:
+            # with every slot taken, requests wait in the stream
+            await slots.acquire()
+            msgs = await consumer.fetch(timeout=None)
+            await msgs[0].ack()
+            start(msgs[0], slots)
+
+    fillers = [asyncio.create_task(fill(i, lane_subject(prefix, p))) for i, p in enumerate(PRIORITIES)]
+    fillers.append(asyncio.create_task(serve_sync()))
+    logger.info("python consumer started function=%s prefix=%s weights=%s", name, prefix, weights)
+
+    slots = asyncio.Semaphore(max(settings.concurrency, 1))
+    try:
+        while True:
+            await slots.acquire()
+            # the lane is chosen once a slot is free, so urgent requests that
+            # arrived meanwhile go first
+            start(await queued.next(), slots)
+    finally:
+        for task in fillers:
+            task.cancel()
 
 
 async def reconcile_consumers(state: AppState) -> None:
@@ -23,5 +23,14 @@ class Settings(BaseSettings):
     redis_password: str | None = None
     redis_max_connections: int
 
+    # concurrency bounds the async requests handled at once, priority_weights
+    # how often each lane is picked while they're all backed up and
+    # queue_limit how many requests a lane holds. Sync requests skip the
+    # lanes, sync_concurrency bounds them on their own.
+    concurrency: int = 32
+    priority_weights: str = "high=6,normal=3,low=1"
+    queue_limit: int = 1000
+    sync_concurrency: int = 32
+
 
 settings = Settings()
@@ -52,7 +52,7 @@ async def start_function(state: AppState, name: str):
     setattr(handler, "__name__", name)
     if hasattr(module, "handle"):
         consumer = await state.js.pull_subscribe(
-            f"{settings.project}.{name}.exec.py.*", stream=settings.project
+            f"{settings.project}.{name}.exec.py.>", stream=settings.project
         )
         await consume(state, consumer, module.handle)
 
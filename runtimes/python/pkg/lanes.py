@@ -0,0 +1,78 @@
+import asyncio
+from typing import Generic, TypeVar
+
+# Priorities are the async lanes, highest first. The ingestor publishes
+# normal requests to {project}.{name}.exec.py.{id}, the others to
+# {project}.{name}.exec.py.{priority}.{id} and requests a caller waits on to
+# {project}.{name}.exec.py.sync.{id}.
+PRIORITIES = ["high", "normal", "low"]
+SYNC_LANE = "sync"
+
+T = TypeVar("T")
+
+
+def lane_subject(prefix: str, lane: str) -> str:
+    if lane == "normal":
+        return f"{prefix}.*"
+    return f"{prefix}.{lane}.*"
+
+
+def parse_weights(raw: str) -> list[int]:
+    """Reads PRIORITY_WEIGHTS, e.g. "high=6,normal=3,low=1", into weights
+    ordered like PRIORITIES. Lanes left out get a weight of 1."""
+    weights = [1] * len(PRIORITIES)
+    for part in raw.split(","):
+        part = part.strip()
+        if not part:
+            continue
+        name, sep, value = part.partition("=")
+        try:
+            weight = int(value.strip())
+        except ValueError:
+            weight = 0
+        if not sep or weight <= 0:
+            raise ValueError(f"invalid priority weight {part!r}")
+        if name.strip() not in PRIORITIES:
+            raise ValueError(f"unknown priority {name!r}")
+        weights[PRIORITIES.index(name.strip())] = weight
+    return weights
+
+
+class Lanes(Generic[T]):
+    """Queues received requests per priority, up to limit per lane. next hands
+    them out by smooth weighted round robin: with every lane backed up, a lane
+    gets a share of the picks proportional to its weight, so higher lanes
+    drain first without starving lower ones."""
+
+    def __init__(self, weights: list[int], limit: int):
+        self.weights = weights
+        self.current = [0] * len(weights)
+        self.queues: list[asyncio.Queue[T]] = [asyncio.Queue(maxsize=max(limit, 1)) for _ in weights]
+        self.ready = asyncio.Event()
+
+    async def push(self, lane: int, item: T) -> None:
+        """Waits while the lane is full, so requests stay in the stream."""
+        await self.queues[lane].put(item)
+        self.ready.set()
+
+    async def next(self) -> T:
+        while True:
+            item = self.pick()
+            if item is not None:
+                return item
+            self.ready.clear()
+            await self.ready.wait()
+
+    def pick(self) -> T | None:
+        best, total = -1, 0
+        for i, queue in enumerate(self.queues):
+            if queue.empty():
+                continue
+            self.current[i] += self.weights[i]
+            total += self.weights[i]
+            if best < 0 or self.current[i] > self.current[best]:
+                best = i
+        if best < 0:
+            return None
+        self.current[best] -= total
+        return self.queues[best].get_nowait()
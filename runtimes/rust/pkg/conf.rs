@@ -16,6 +16,15 @@ pub struct Settings {
     pub use_telemetry: bool,
 
     pub http_port: String,
+
+    /// Bounds the async requests handled at once, priority_weights sets how
+    /// often each lane is picked while they're all backed up and queue_limit
+    /// how many requests a lane holds before dropping new ones. Sync requests
+    /// skip the lanes, sync_concurrency bounds them on their own.
+    pub concurrency: usize,
+    pub priority_weights: String,
+    pub queue_limit: usize,
+    pub sync_concurrency: usize,
 }
 
 static SETTINGS: OnceLock<Settings> = OnceLock::new();
@@ -40,6 +49,11 @@ impl Settings {
             use_telemetry: parse_bool(&env_var("USE_TELEMETRY")),
 
             http_port: env_var("HTTP_PORT"),
+
+            concurrency: parse_usize(&env_var("CONCURRENCY"), 32),
+            priority_weights: env_or("PRIORITY_WEIGHTS", "high=6,normal=3,low=1"),
+            queue_limit: parse_usize(&env_var("QUEUE_LIMIT"), 1000),
+            sync_concurrency: parse_usize(&env_var("SYNC_CONCURRENCY"), 32),
         }
     }
 }
@@ -51,3 +65,14 @@ fn env_var(key: &str) -> String {
 fn parse_bool(value: &str) -> bool {
     matches!(value.trim().to_lowercase().as_str(), "1" | "true" | "yes" | "on")
 }
+
+fn env_or(key: &str, default: &str) -> String {
+    env::var(key)
+        .ok()
+        .filter(|v| !v.is_empty())
+        .unwrap_or_else(|| default.to_string())
+}
+
+fn parse_usize(value: &str, default: usize) -> usize {
+    value.trim().parse().unwrap_or(default)
+}
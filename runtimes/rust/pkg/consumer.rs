@@ -1,40 +1,85 @@
+use crate::pkg::Result;
 use crate::pkg::conf::load_settings;
 use crate::pkg::function_async::stream_handler;
+use crate::pkg::lanes::{Lanes, PRIORITIES, SYNC_LANE, lane_subject, parse_weights};
 use crate::pkg::state::AppState;
-use crate::pkg::Result;
+use async_nats::Message;
 use futures::StreamExt;
+use std::sync::Arc;
+use tokio::sync::Semaphore;
 
+/// Async requests are queued per priority, at most QUEUE_LIMIT each, and run
+/// at most CONCURRENCY at a time, taken from the lanes by their
+/// PRIORITY_WEIGHTS. Sync requests have callers waiting on them, they skip the
+/// queues and run as they arrive, at most SYNC_CONCURRENCY at a time.
 pub async fn start_function(state: AppState) -> Result<()> {
     let settings = load_settings();
-    let subject = format!("{}.{}.exec.go.*", settings.project, settings.name);
-    tracing::info!(subject = %subject, "starting consumer");
+    let prefix = format!("{}.{}.exec.go", settings.project, settings.name);
+    let weights = parse_weights(&settings.priority_weights).map_err(anyhow::Error::msg)?;
+    tracing::info!(prefix = %prefix, weights = ?weights, "starting consumer");
+
+    let mut sync_sub = state.nc.subscribe(lane_subject(&prefix, SYNC_LANE)).await?;
+    let sync_state = state.clone();
+    tokio::spawn(async move {
+        let slots = Arc::new(Semaphore::new(settings.sync_concurrency.max(1)));
+        while let Some(msg) = sync_sub.next().await {
+            // with every slot taken, requests wait in the subscription
+            let Ok(permit) = slots.clone().acquire_owned().await else {
+                return;
+            };
+            let state = sync_state.clone();
+            tokio::spawn(async move {
+                handle_message(&state, msg).await;
+                drop(permit);
+            });
+        }
+    });
 
-    let mut sub = state.nc.subscribe(subject).await?;
+    let queued = Arc::new(Lanes::new(weights, settings.queue_limit));
+    for (lane, priority) in PRIORITIES.into_iter().enumerate() {
+        let mut sub = state.nc.subscribe(lane_subject(&prefix, priority)).await?;
+        let queued = queued.clone();
+        tokio::spawn(async move {
+            while let Some(msg) = sub.next().await {
+                if let Err(msg) = queued.push(lane, msg) {
+                    tracing::warn!(priority = %priority, subject = %msg.subject, "lane full, dropping request");
+                }
+            }
+        });
+    }
     tracing::info!("waiting for messages");
 
-    while let Some(msg) = sub.next().await {
-        let req_id = msg.subject.split('.').last().unwrap_or("");
-        if req_id.is_empty() {
-            continue;
-        }
+    let slots = Arc::new(Semaphore::new(settings.concurrency.max(1)));
+    loop {
+        let permit = slots.clone().acquire_owned().await?;
+        // the lane is chosen once a slot is free, so urgent requests that
+        // arrived meanwhile go first
+        let msg = queued.next().await;
+        let state = state.clone();
+        tokio::spawn(async move {
+            handle_message(&state, msg).await;
+            drop(permit);
+        });
+    }
+}
 
-        tracing::info!(subject = %msg.subject, request_id = %req_id, "received event");
+async fn handle_message(state: &AppState, msg: Message) {
+    let settings = load_settings();
+    let req_id = msg.subject.split('.').next_back().unwrap_or("");
+    if req_id.is_empty() {
+        return;
+    }
+    tracing::info!(subject = %msg.subject, request_id = %req_id, "received event");
 
-        let (tx, rx) = tokio::sync::mpsc::channel(1);
-        let _ = tx.send(msg.payload.to_vec()).await;
-        drop(tx);
+    let (tx, rx) = tokio::sync::mpsc::channel(1);
+    let _ = tx.send(msg.payload.to_vec()).await;
+    drop(tx);
 
-        let mut out = stream_handler(rx);
-        while let Some(res) = out.recv().await {
-            state
-                .nc
-                .publish(
-                    format!("{}.{}.res.go.{}", settings.project, settings.name, req_id),
-                    res.into(),
-                )
-                .await?;
+    let mut out = stream_handler(rx);
+    while let Some(res) = out.recv().await {
+        let subject = format!("{}.{}.res.go.{}", settings.project, settings.name, req_id);
+        if let Err(err) = state.nc.publish(subject, res.into()).await {
+            tracing::error!(error = %err, request_id = %req_id, "failed to publish response");
         }
     }
-
-    Ok(())
 }
@@ -0,0 +1,112 @@
+use std::collections::VecDeque;
+use std::sync::Mutex;
+use tokio::sync::Notify;
+
+/// Priorities are the async lanes, highest first. The ingestor publishes
+/// normal requests to {project}.{name}.exec.go.{id}, the others to
+/// {project}.{name}.exec.go.{priority}.{id} and requests a caller waits on to
+/// {project}.{name}.exec.go.sync.{id}.
+pub const PRIORITIES: [&str; 3] = ["high", "normal", "low"];
+pub const SYNC_LANE: &str = "sync";
+
+pub fn lane_subject(prefix: &str, lane: &str) -> String {
+    if lane == "normal" {
+        format!("{prefix}.*")
+    } else {
+        format!("{prefix}.{lane}.*")
+    }
+}
+
+/// Reads PRIORITY_WEIGHTS, e.g. "high=6,normal=3,low=1", into weights ordered
+/// like PRIORITIES. Lanes left out get a weight of 1.
+pub fn parse_weights(raw: &str) -> Result<Vec<u32>, String> {
+    let mut weights = vec![1; PRIORITIES.len()];
+    for part in raw.split(',').map(str::trim).filter(|p| !p.is_empty()) {
+        let (name, value) = part
+            .split_once('=')
+            .ok_or_else(|| format!("invalid priority weight {part:?}"))?;
+        let weight = match value.trim().parse::<u32>() {
+            Ok(w) if w > 0 => w,
+            _ => return Err(format!("invalid priority weight {part:?}")),
+        };
+        let lane = PRIORITIES
+            .iter()
+            .position(|p| *p == name.trim())
+            .ok_or_else(|| format!("unknown priority {name:?}"))?;
+        weights[lane] = weight;
+    }
+    Ok(weights)
+}
+
+/// Queues received requests per lane, up to limit each. next hands them out
+/// by smooth weighted round robin: with every lane backed up, a lane gets a
+/// share of the picks proportional to its weight, so higher lanes drain first
+/// without starving lower ones.
+pub struct Lanes<T> {
+    inner: Mutex<Inner<T>>,
+    ready: Notify,
+}
+
+struct Inner<T> {
+    queues: Vec<VecDeque<T>>,
+    weights: Vec<u32>,
+    current: Vec<i64>,
+    limit: usize,
+}
+
+impl<T> Lanes<T> {
+    pub fn new(weights: Vec<u32>, limit: usize) -> Self {
+        Lanes {
+            inner: Mutex::new(Inner {
+                queues: weights.iter().map(|_| VecDeque::new()).collect(),
+                current: vec![0; weights.len()],
+                weights,
+                limit: limit.max(1),
+            }),
+            ready: Notify::new(),
+        }
+    }
+
+    /// Queues item, or hands it back when its lane is full.
+    pub fn push(&self, lane: usize, item: T) -> Result<(), T> {
+        {
+            let mut inner = self.inner.lock().unwrap();
+            if inner.queues[lane].len() >= inner.limit {
+                return Err(item);
+            }
+            inner.queues[lane].push_back(item);
+        }
+        self.ready.notify_one();
+        Ok(())
+    }
+
+    /// Waits until a request is queued.
+    pub async fn next(&self) -> T {
+        loop {
+            if let Some(item) = self.pick() {
+                return item;
+            }
+            self.ready.notified().await;
+        }
+    }
+
+    fn pick(&self) -> Option<T> {
+        let mut inner = self.inner.lock().unwrap();
+        let inner = &mut *inner;
+        let mut best: Option<usize> = None;
+        let mut total = 0i64;
+        for (i, queue) in inner.queues.iter().enumerate() {
+            if queue.is_empty() {
+                continue;
+            }
+            inner.current[i] += i64::from(inner.weights[i]);
+            total += i64::from(inner.weights[i]);
+            if best.is_none_or(|b| inner.current[i] > inner.current[b]) {
+                best = Some(i);
+            }
+        }
+        let best = best?;
+        inner.current[best] -= total;
+        inner.queues[best].pop_front()
+    }
+}
@@ -2,6 +2,7 @@ pub mod conf;
 pub mod consumer;
 pub mod function;
 pub mod function_async;
+pub mod lanes;
 pub mod state;
 
 pub type Result<T> = std::result::Result<T, anyhow::Error>;
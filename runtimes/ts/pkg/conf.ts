@@ -6,6 +6,14 @@ export type Settings = {
   gitUser: string;
   gitToken: string;
   name: string;
+  // concurrency bounds the async requests handled at once, priorityWeights
+  // how often each lane is picked while they're all backed up and queueLimit
+  // how many requests a lane holds before dropping new ones. Sync requests
+  // skip the priority lanes, syncConcurrency bounds them on their own.
+  concurrency: number;
+  priorityWeights: string;
+  queueLimit: number;
+  syncConcurrency: number;
 };
 
 function requiredEnv(name: string): string {
@@ -24,6 +32,10 @@ export const settings: Settings = {
   gitUser: process.env.GIT_USER ?? "",
   gitToken: process.env.GIT_TOKEN ?? "",
   name: process.env.NAME ?? "",
+  concurrency: Number.parseInt(process.env.CONCURRENCY ?? "32", 10) || 32,
+  priorityWeights: process.env.PRIORITY_WEIGHTS ?? "high=6,normal=3,low=1",
+  queueLimit: Number.parseInt(process.env.QUEUE_LIMIT ?? "1000", 10) || 1000,
+  syncConcurrency: Number.parseInt(process.env.SYNC_CONCURRENCY ?? "32", 10) || 32,
 };
 
 export function log(msg: string, attrs: Record<string, unknown> = {}) {
@@ -1,34 +1,79 @@
+import type { Msg, Subscription } from "nats";
 import type { AppState } from "./state";
 import { log, settings } from "./conf";
 import { invokeModule, listFunctions, syncRepoAndReload } from "./functions";
+import { Lanes, PRIORITIES, SYNC_LANE, Slots, laneSubject, parseWeights } from "./lanes";
 
 const CONSUMERS = new Map<string, { cancel: () => void; task: Promise<void> }>();
 
+// consumeFunction queues async requests per priority, at most QUEUE_LIMIT
+// each, and runs at most CONCURRENCY at a time, taken from the lanes by their
+// PRIORITY_WEIGHTS. Sync requests have callers waiting on them, they skip the
+// priority lanes and run as they arrive, at most SYNC_CONCURRENCY at a time.
 export async function consumeFunction(state: AppState, name: string): Promise<void> {
-  const subject = `${settings.project}.${name}.exec.ts.*`;
-  const sub = state.nc.subscribe(subject);
+  const prefix = `${settings.project}.${name}.exec.ts`;
+  const weights = parseWeights(settings.priorityWeights);
+  const queued = new Lanes<Msg>(weights, settings.queueLimit);
+  const syncQueued = new Lanes<Msg>([1], settings.queueLimit);
 
-  const task = (async () => {
-    for await (const msg of sub) {
-      const parts = msg.subject.split(".");
-      const reqID = parts[4] ?? "";
-      const resSubject = `${settings.project}.${name}.res.ts.${reqID}`;
-      try {
-        for await (const out of invokeModule(state, name, reqID, msg.data)) {
-          state.nc.publish(resSubject, out);
+  const subscribe = (lanes: Lanes<Msg>, lane: number, subject: string): Subscription =>
+    state.nc.subscribe(subject, {
+      callback: (err, msg) => {
+        if (err) {
+          return;
         }
-      } catch (err) {
-        console.error(`${new Date().toISOString()} [ERROR] async invoke failed`, {
-          project: settings.project,
-          function: name,
-          error: String(err),
-        });
+        if (!lanes.push(lane, msg)) {
+          console.warn(`${new Date().toISOString()} [WARN] lane full, dropping request`, {
+            function: name,
+            subject: msg.subject,
+          });
+        }
+      },
+    });
+  const subs = PRIORITIES.map((priority, i) => subscribe(queued, i, laneSubject(prefix, priority)));
+  subs.push(subscribe(syncQueued, 0, laneSubject(prefix, SYNC_LANE)));
+
+  const handle = async (msg: Msg): Promise<void> => {
+    const parts = msg.subject.split(".");
+    const reqID = parts[parts.length - 1] ?? "";
+    const resSubject = `${settings.project}.${name}.res.ts.${reqID}`;
+    try {
+      for await (const out of invokeModule(state, name, reqID, msg.data)) {
+        state.nc.publish(resSubject, out);
       }
+    } catch (err) {
+      console.error(`${new Date().toISOString()} [ERROR] async invoke failed`, {
+        project: settings.project,
+        function: name,
+        error: String(err),
+      });
     }
-  })();
+  };
+  const serve = async (lanes: Lanes<Msg>, slots: Slots): Promise<void> => {
+    for (;;) {
+      // the lane is chosen once a slot is free, so urgent requests that
+      // arrived meanwhile go first
+      await slots.acquire();
+      const msg = await lanes.next();
+      if (msg === undefined) {
+        return;
+      }
+      void handle(msg).finally(() => slots.release());
+    }
+  };
+
+  const task = Promise.all([
+    serve(queued, new Slots(settings.concurrency)),
+    serve(syncQueued, new Slots(settings.syncConcurrency)),
+  ]).then(() => undefined);
+  const cancel = () => {
+    subs.forEach((sub) => sub.unsubscribe());
+    queued.close();
+    syncQueued.close();
+  };
 
-  CONSUMERS.set(name, { cancel: () => sub.unsubscribe(), task });
-  log("ts consumer started", { function: name, subject });
+  CONSUMERS.set(name, { cancel, task });
+  log("ts consumer started", { function: name, prefix, weights: weights.join(",") });
 }
 
 export async function reconcileConsumers(state: AppState): Promise<void> {
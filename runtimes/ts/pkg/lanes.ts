@@ -0,0 +1,133 @@
+// Priorities are the async lanes, highest first. The ingestor publishes
+// normal requests to {project}.{name}.exec.ts.{id}, the others to
+// {project}.{name}.exec.ts.{priority}.{id} and requests a caller waits on to
+// {project}.{name}.exec.ts.sync.{id}.
+export const PRIORITIES = ["high", "normal", "low"];
+export const SYNC_LANE = "sync";
+
+export function laneSubject(prefix: string, lane: string): string {
+  return lane === "normal" ? `${prefix}.*` : `${prefix}.${lane}.*`;
+}
+
+// parseWeights reads PRIORITY_WEIGHTS, e.g. "high=6,normal=3,low=1", into
+// weights ordered like PRIORITIES. Lanes left out get a weight of 1.
+export function parseWeights(raw: string): number[] {
+  const weights = PRIORITIES.map(() => 1);
+  for (let part of raw.split(",")) {
+    part = part.trim();
+    if (!part) {
+      continue;
+    }
+    const [name, value] = part.split("=", 2).map((s) => s.trim());
+    const weight = Number(value);
+    if (value === undefined || !Number.isInteger(weight) || weight <= 0) {
+      throw new Error(`invalid priority weight "${part}"`);
+    }
+    const lane = PRIORITIES.indexOf(name);
+    if (lane < 0) {
+      throw new Error(`unknown priority "${name}"`);
+    }
+    weights[lane] = weight;
+  }
+  return weights;
+}
+
+// Lanes queues received requests per lane, up to limit each. next hands them
+// out by smooth weighted round robin: with every lane backed up, a lane gets
+// a share of the picks proportional to its weight, so higher lanes drain
+// first without starving lower ones.
+export class Lanes<T> {
+  private queues: T[][];
+  private current: number[];
+  private waiting: (() => void) | undefined;
+  private closed = false;
+
+  constructor(
+    private weights: number[],
+    private limit: number,
+  ) {
+    this.queues = weights.map(() => []);
+    this.current = weights.map(() => 0);
+  }
+
+  // push queues item, or reports false when its lane is full.
+  push(lane: number, item: T): boolean {
+    if (this.queues[lane].length >= Math.max(this.limit, 1)) {
+      return false;
+    }
+    this.queues[lane].push(item);
+    this.wake();
+    return true;
+  }
+
+  // next waits for a request, undefined once the lanes are closed.
+  async next(): Promise<T | undefined> {
+    for (;;) {
+      const item = this.pick();
+      if (item !== undefined || this.closed) {
+        return item;
+      }
+      await new Promise<void>((resolve) => {
+        this.waiting = resolve;
+      });
+    }
+  }
+
+  close(): void {
+    this.closed = true;
+    this.wake();
+  }
+
+  private wake(): void {
+    const resolve = this.waiting;
+    this.waiting = undefined;
+    resolve?.();
+  }
+
+  private pick(): T | undefined {
+    let best = -1;
+    let total = 0;
+    this.queues.forEach((queue, i) => {
+      if (queue.length === 0) {
+        return;
+      }
+      this.current[i] += this.weights[i];
+      total += this.weights[i];
+      if (best < 0 || this.current[i] > this.current[best]) {
+        best = i;
+      }
+    });
+    if (best < 0) {
+      return undefined;
+    }
+    this.current[best] -= total;
+    return this.queues[best].shift();
+  }
+}
+
+// Slots bounds the requests running at once.
+export class Slots {
+  private free: number;
+  private waiting: (() => void)[] = [];
+
+  constructor(size: number) {
+    this.free = Math.max(size, 1);
+  }
+
+  async acquire(): Promise<void> {
+    if (this.free > 0) {
+      this.free--;
+      return;
+    }
+    await new Promise<void>((resolve) => this.waiting.push(resolve));
+  }
+
+  release(): void {
+    const next = this.waiting.shift();
+    if (next) {
+      next();
+      return;
+    }
+    this.free++;
+  }
+}